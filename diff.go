@@ -0,0 +1,356 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//go:generate go run golang.org/x/tools/cmd/stringer -type=DifferenceKind -trimprefix=DifferenceKind
+
+// DifferenceKind is the kind of a difference reported by Diff.
+type DifferenceKind uint8
+
+const (
+	DifferenceKindUnknown DifferenceKind = iota
+	// DifferenceKindTypeMismatch indicates that the values have different types
+	DifferenceKindTypeMismatch
+	// DifferenceKindValueChanged indicates that the values have the same type, but are not equal
+	DifferenceKindValueChanged
+	// DifferenceKindAdded indicates that an element, entry, or field only exists in the second value
+	DifferenceKindAdded
+	// DifferenceKindRemoved indicates that an element, entry, or field only exists in the first value
+	DifferenceKindRemoved
+)
+
+// Difference is a structural difference between two values.
+//
+// Path is the location of the difference, relative to the compared values,
+// e.g. `.ownedNFTs[42].metadata["name"]`:
+// fields are accessed with `.`, array elements and dictionary entries with `[]`.
+// The path of a difference between the compared values themselves is empty.
+//
+// A is nil for added values, and B is nil for removed values.
+type Difference struct {
+	Path string
+	Kind DifferenceKind
+	A    Value
+	B    Value
+}
+
+func (d Difference) String() string {
+	var builder strings.Builder
+	if d.Path == "" {
+		builder.WriteString("(root)")
+	} else {
+		builder.WriteString(d.Path)
+	}
+	builder.WriteString(": ")
+
+	switch d.Kind {
+	case DifferenceKindTypeMismatch:
+		fmt.Fprintf(&builder, "type mismatch: %s != %s", diffTypeString(d.A), diffTypeString(d.B))
+	case DifferenceKindValueChanged:
+		fmt.Fprintf(&builder, "value changed: %s != %s", d.A, d.B)
+	case DifferenceKindAdded:
+		fmt.Fprintf(&builder, "added: %s", d.B)
+	case DifferenceKindRemoved:
+		fmt.Fprintf(&builder, "removed: %s", d.A)
+	default:
+		builder.WriteString(d.Kind.String())
+	}
+
+	return builder.String()
+}
+
+func diffTypeString(value Value) string {
+	ty := value.Type()
+	if ty == nil {
+		return fmt.Sprintf("%T", value)
+	}
+	return ty.ID()
+}
+
+// DiffOption configures Diff.
+type DiffOption func(*differ)
+
+// WithFixedPointTolerance makes Diff consider fixed-point values equal
+// if their absolute difference is at most the given tolerance,
+// e.g. 0.00000100 to ignore rounding in the last two decimal places.
+func WithFixedPointTolerance(tolerance UFix64) DiffOption {
+	return func(d *differ) {
+		d.fixedPointTolerance = uint64(tolerance)
+	}
+}
+
+// Diff returns the structural differences between the two given values.
+//
+// Arrays are compared element by element.
+// Dictionaries are compared as unordered maps, i.e. the order of their entries is ignored.
+// Composites are compared field by field, if they have the same type.
+//
+// Values of different types, e.g. an Int and a UInt8, or two structs of different composite types,
+// are reported as a type mismatch, and are not compared further.
+// Static types, like the element type of an array, are only compared if both values have one,
+// so values decoded from different encodings can be compared.
+//
+// The differences are reported in the order of the first value,
+// followed by the entries added in the second value.
+func Diff(a, b Value, options ...DiffOption) []Difference {
+	d := &differ{}
+	for _, option := range options {
+		option(d)
+	}
+	d.diff("", a, b)
+	return d.differences
+}
+
+type differ struct {
+	differences         []Difference
+	fixedPointTolerance uint64
+}
+
+func (d *differ) report(path string, kind DifferenceKind, a, b Value) {
+	d.differences = append(
+		d.differences,
+		Difference{
+			Path: path,
+			Kind: kind,
+			A:    a,
+			B:    b,
+		},
+	)
+}
+
+func (d *differ) diff(path string, a, b Value) {
+	switch {
+	case a == nil && b == nil:
+		return
+	case a == nil:
+		d.report(path, DifferenceKindAdded, nil, b)
+		return
+	case b == nil:
+		d.report(path, DifferenceKindRemoved, a, nil)
+		return
+	}
+
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		d.report(path, DifferenceKindTypeMismatch, a, b)
+		return
+	}
+
+	switch a := a.(type) {
+	case Optional:
+		b := b.(Optional)
+		switch {
+		case a.Value == nil && b.Value == nil:
+			return
+		case a.Value == nil || b.Value == nil:
+			d.report(path, DifferenceKindValueChanged, a, b)
+		default:
+			d.diff(path, a.Value, b.Value)
+		}
+
+	case Array:
+		b := b.(Array)
+		if a.ArrayType != nil && b.ArrayType != nil &&
+			!a.ArrayType.Equal(b.ArrayType) {
+
+			d.report(path, DifferenceKindTypeMismatch, a, b)
+			return
+		}
+		d.diffArrays(path, a.Values, b.Values)
+
+	case Dictionary:
+		b := b.(Dictionary)
+		if a.DictionaryType != nil && b.DictionaryType != nil &&
+			!a.DictionaryType.Equal(b.DictionaryType) {
+
+			d.report(path, DifferenceKindTypeMismatch, a, b)
+			return
+		}
+		d.diffDictionaries(path, a.Pairs, b.Pairs)
+
+	case Struct, Resource, Event, Contract, Enum, Attachment:
+		d.diffComposites(path, a, b)
+
+	case Fix64:
+		b := b.(Fix64)
+		// NOTE: the difference is computed in uint64 (two's complement),
+		// as the difference of two int64 values may overflow int64
+		difference := uint64(a) - uint64(b)
+		if a < b {
+			difference = uint64(b) - uint64(a)
+		}
+		if difference > d.fixedPointTolerance {
+			d.report(path, DifferenceKindValueChanged, a, b)
+		}
+
+	case UFix64:
+		b := b.(UFix64)
+		difference := uint64(a) - uint64(b)
+		if a < b {
+			difference = uint64(b) - uint64(a)
+		}
+		if difference > d.fixedPointTolerance {
+			d.report(path, DifferenceKindValueChanged, a, b)
+		}
+
+	case TypeValue:
+		if !diffTypesEqual(a.StaticType, b.(TypeValue).StaticType) {
+			d.report(path, DifferenceKindValueChanged, a, b)
+		}
+
+	default:
+		// All other values are compared by their string representation,
+		// which includes all their data, e.g. the address and path of a capability
+		if a.String() != b.String() {
+			d.report(path, DifferenceKindValueChanged, a, b)
+		}
+	}
+}
+
+func diffTypesEqual(a, b Type) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.ID() == b.ID()
+}
+
+func (d *differ) diffArrays(path string, a, b []Value) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		elementPath := fmt.Sprintf("%s[%d]", path, i)
+
+		var aElement, bElement Value
+		if i < len(a) {
+			aElement = a[i]
+		}
+		if i < len(b) {
+			bElement = b[i]
+		}
+
+		d.diff(elementPath, aElement, bElement)
+	}
+}
+
+// diffDictionaryKey returns a string which identifies the given dictionary key.
+// Keys of different types, e.g. an Int and a UInt8 in an AnyStruct-keyed dictionary, are different.
+func diffDictionaryKey(key Value) string {
+	return fmt.Sprintf("%T:%s", key, key)
+}
+
+func (d *differ) diffDictionaries(path string, a, b []KeyValuePair) {
+	bValues := make(map[string]Value, len(b))
+	for _, pair := range b {
+		bValues[diffDictionaryKey(pair.Key)] = pair.Value
+	}
+
+	aKeys := make(map[string]struct{}, len(a))
+
+	for _, pair := range a {
+		key := diffDictionaryKey(pair.Key)
+		aKeys[key] = struct{}{}
+
+		entryPath := fmt.Sprintf("%s[%s]", path, pair.Key)
+
+		bValue, ok := bValues[key]
+		if !ok {
+			d.report(entryPath, DifferenceKindRemoved, pair.Value, nil)
+			continue
+		}
+
+		d.diff(entryPath, pair.Value, bValue)
+	}
+
+	for _, pair := range b {
+		if _, ok := aKeys[diffDictionaryKey(pair.Key)]; ok {
+			continue
+		}
+
+		entryPath := fmt.Sprintf("%s[%s]", path, pair.Key)
+		d.report(entryPath, DifferenceKindAdded, nil, pair.Value)
+	}
+}
+
+func (d *differ) diffComposites(path string, a, b Value) {
+	if !diffTypesEqual(a.Type(), b.Type()) {
+		d.report(path, DifferenceKindTypeMismatch, a, b)
+		return
+	}
+
+	aComposite := a.(HasFields)
+	bComposite := b.(HasFields)
+
+	aFields := aComposite.GetFields()
+	bFields := bComposite.GetFields()
+
+	aValues := aComposite.GetFieldValues()
+	bValues := bComposite.GetFieldValues()
+
+	// Without field names, e.g. when the value has no type,
+	// the fields can only be compared by position
+
+	if aFields == nil || bFields == nil {
+		d.diffArrays(path, aValues, bValues)
+		return
+	}
+
+	bValuesByName := make(map[string]Value, len(bFields))
+	for i, field := range bFields {
+		if i < len(bValues) {
+			bValuesByName[field.Identifier] = bValues[i]
+		}
+	}
+
+	aNames := make(map[string]struct{}, len(aFields))
+
+	for i, field := range aFields {
+		if i >= len(aValues) {
+			break
+		}
+
+		name := field.Identifier
+		aNames[name] = struct{}{}
+
+		fieldPath := path + "." + name
+
+		bValue, ok := bValuesByName[name]
+		if !ok {
+			d.report(fieldPath, DifferenceKindRemoved, aValues[i], nil)
+			continue
+		}
+
+		d.diff(fieldPath, aValues[i], bValue)
+	}
+
+	for i, field := range bFields {
+		if i >= len(bValues) {
+			break
+		}
+
+		name := field.Identifier
+		if _, ok := aNames[name]; ok {
+			continue
+		}
+
+		d.report(path+"."+name, DifferenceKindAdded, nil, bValues[i])
+	}
+}
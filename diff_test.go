@@ -0,0 +1,280 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/tests/utils"
+)
+
+func TestDiff(t *testing.T) {
+
+	t.Parallel()
+
+	nftType := NewResourceType(
+		utils.TestLocation,
+		"NFT",
+		[]Field{
+			{Identifier: "id", Type: UInt64Type{}},
+			{Identifier: "metadata", Type: &DictionaryType{KeyType: StringType{}, ElementType: StringType{}}},
+		},
+		nil,
+	)
+
+	collectionType := NewResourceType(
+		utils.TestLocation,
+		"Collection",
+		[]Field{
+			{Identifier: "ownedNFTs", Type: &DictionaryType{KeyType: UInt64Type{}, ElementType: nftType}},
+		},
+		nil,
+	)
+
+	newNFT := func(id uint64, name string) Resource {
+		return NewResource([]Value{
+			UInt64(id),
+			NewDictionary([]KeyValuePair{
+				{Key: String("name"), Value: String(name)},
+			}),
+		}).WithType(nftType)
+	}
+
+	newCollection := func(nfts ...Resource) Resource {
+		pairs := make([]KeyValuePair, len(nfts))
+		for i, nft := range nfts {
+			pairs[i] = KeyValuePair{
+				Key:   nft.Fields[0],
+				Value: nft,
+			}
+		}
+		return NewResource([]Value{
+			NewDictionary(pairs),
+		}).WithType(collectionType)
+	}
+
+	t.Run("equal", func(t *testing.T) {
+		t.Parallel()
+
+		a := newCollection(newNFT(1, "a"), newNFT(42, "b"))
+		b := newCollection(newNFT(1, "a"), newNFT(42, "b"))
+
+		assert.Empty(t, Diff(a, b))
+	})
+
+	t.Run("dictionary order is ignored", func(t *testing.T) {
+		t.Parallel()
+
+		a := newCollection(newNFT(1, "a"), newNFT(42, "b"))
+		b := newCollection(newNFT(42, "b"), newNFT(1, "a"))
+
+		assert.Empty(t, Diff(a, b))
+	})
+
+	t.Run("nested value changed", func(t *testing.T) {
+		t.Parallel()
+
+		a := newCollection(newNFT(1, "a"), newNFT(42, "b"))
+		b := newCollection(newNFT(42, "c"), newNFT(1, "a"))
+
+		differences := Diff(a, b)
+		assert.Equal(t,
+			[]Difference{
+				{
+					Path: `.ownedNFTs[42].metadata["name"]`,
+					Kind: DifferenceKindValueChanged,
+					A:    String("b"),
+					B:    String("c"),
+				},
+			},
+			differences,
+		)
+		require.Len(t, differences, 1)
+		assert.Equal(t,
+			`.ownedNFTs[42].metadata["name"]: value changed: "b" != "c"`,
+			differences[0].String(),
+		)
+	})
+
+	t.Run("dictionary entries added and removed", func(t *testing.T) {
+		t.Parallel()
+
+		a := newCollection(newNFT(1, "a"), newNFT(2, "b"))
+		b := newCollection(newNFT(3, "c"), newNFT(1, "a"))
+
+		differences := Diff(a, b)
+		assert.Equal(t,
+			[]Difference{
+				{
+					Path: `.ownedNFTs[2]`,
+					Kind: DifferenceKindRemoved,
+					A:    newNFT(2, "b"),
+				},
+				{
+					Path: `.ownedNFTs[3]`,
+					Kind: DifferenceKindAdded,
+					B:    newNFT(3, "c"),
+				},
+			},
+			differences,
+		)
+	})
+
+	t.Run("array elements", func(t *testing.T) {
+		t.Parallel()
+
+		a := NewArray([]Value{NewInt(1), NewInt(2), NewInt(3)})
+		b := NewArray([]Value{NewInt(1), NewInt(4)})
+
+		assert.Equal(t,
+			[]Difference{
+				{
+					Path: `[1]`,
+					Kind: DifferenceKindValueChanged,
+					A:    NewInt(2),
+					B:    NewInt(4),
+				},
+				{
+					Path: `[2]`,
+					Kind: DifferenceKindRemoved,
+					A:    NewInt(3),
+				},
+			},
+			Diff(a, b),
+		)
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		a := NewArray([]Value{NewInt(1), NewOptional(NewInt(2))})
+		b := NewArray([]Value{NewUInt8(1), NewInt(2)})
+
+		differences := Diff(a, b)
+		assert.Equal(t,
+			[]Difference{
+				{
+					Path: `[0]`,
+					Kind: DifferenceKindTypeMismatch,
+					A:    NewInt(1),
+					B:    NewUInt8(1),
+				},
+				{
+					Path: `[1]`,
+					Kind: DifferenceKindTypeMismatch,
+					A:    NewOptional(NewInt(2)),
+					B:    NewInt(2),
+				},
+			},
+			differences,
+		)
+		require.Len(t, differences, 2)
+		assert.Equal(t, `[0]: type mismatch: Int != UInt8`, differences[0].String())
+	})
+
+	t.Run("composite type mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		otherNFTType := NewResourceType(
+			common.StringLocation("other"),
+			"NFT",
+			nftType.Fields,
+			nil,
+		)
+
+		a := newNFT(1, "a")
+		b := NewResource(a.Fields).WithType(otherNFTType)
+
+		differences := Diff(a, b)
+		require.Len(t, differences, 1)
+		assert.Equal(t, DifferenceKindTypeMismatch, differences[0].Kind)
+		assert.Equal(t, `(root): type mismatch: S.test.NFT != S.other.NFT`, differences[0].String())
+	})
+
+	t.Run("static types are only compared if present", func(t *testing.T) {
+		t.Parallel()
+
+		a := NewArray([]Value{NewInt(1)})
+		b := NewArray([]Value{NewInt(1)}).
+			WithType(&VariableSizedArrayType{ElementType: IntType{}})
+		c := NewArray([]Value{NewInt(1)}).
+			WithType(&VariableSizedArrayType{ElementType: AnyStructType{}})
+
+		assert.Empty(t, Diff(a, b))
+		assert.Empty(t, Diff(a, c))
+
+		differences := Diff(b, c)
+		require.Len(t, differences, 1)
+		assert.Equal(t, DifferenceKindTypeMismatch, differences[0].Kind)
+	})
+
+	t.Run("optionals", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Empty(t, Diff(NewOptional(nil), NewOptional(nil)))
+
+		assert.Equal(t,
+			[]Difference{
+				{
+					Kind: DifferenceKindValueChanged,
+					A:    NewOptional(nil),
+					B:    NewOptional(NewInt(1)),
+				},
+			},
+			Diff(NewOptional(nil), NewOptional(NewInt(1))),
+		)
+	})
+
+	t.Run("fixed-point tolerance", func(t *testing.T) {
+		t.Parallel()
+
+		a := NewArray([]Value{UFix64(100_000_000), Fix64(-100_000_000)})
+		b := NewArray([]Value{UFix64(100_000_050), Fix64(-100_000_100)})
+
+		assert.Len(t, Diff(a, b), 2)
+		assert.Empty(t, Diff(a, b, WithFixedPointTolerance(100)))
+
+		differences := Diff(a, b, WithFixedPointTolerance(50))
+		require.Len(t, differences, 1)
+		assert.Equal(t, `[1]`, differences[0].Path)
+	})
+
+	t.Run("fixed-point tolerance, extreme values", func(t *testing.T) {
+		t.Parallel()
+
+		min := Fix64(math.MinInt64)
+		max := Fix64(math.MaxInt64)
+
+		// The difference of the extreme values overflows int64
+
+		assert.Len(t, Diff(min, max, WithFixedPointTolerance(math.MaxInt64)), 1)
+		assert.Len(t, Diff(max, min, WithFixedPointTolerance(math.MaxInt64)), 1)
+		assert.Empty(t, Diff(min, max, WithFixedPointTolerance(math.MaxUint64)))
+
+		assert.Len(t, Diff(min, Fix64(1), WithFixedPointTolerance(math.MaxInt64)), 1)
+		assert.Empty(t, Diff(min, Fix64(0), WithFixedPointTolerance(1<<63)))
+		assert.Empty(t, Diff(min, min+1, WithFixedPointTolerance(1)))
+		assert.Empty(t, Diff(max, max-1, WithFixedPointTolerance(1)))
+	})
+}
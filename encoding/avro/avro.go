@@ -0,0 +1,195 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package avro implements the export of Cadence events to Apache Avro.
+//
+// The Avro schema of an event type is derived from the event type's fields,
+// using a deterministic mapping of Cadence types to Avro types:
+//
+//   - Bool is a boolean, String and Character are strings
+//   - Int8, Int16, Int32, UInt8, UInt16, Word8 and Word16 are ints
+//   - Int64, UInt32 and Word32 are longs
+//   - UInt64, Word64, and the 128-bit and 256-bit integer types
+//     are decimals with scale 0, and a precision which fits all values of the type
+//   - Int and UInt, which are unbounded, are strings of the decimal representation
+//   - Fix64 and UFix64 are decimals with scale 8
+//   - Address is the fixed type `Address` of size 8
+//   - paths, capabilities and types are strings, e.g. `/storage/foo`
+//   - optionals are unions of null and the inner type, nested optionals are flattened
+//   - arrays are arrays
+//   - dictionaries with String keys are maps,
+//     all other dictionaries are arrays of records with the fields `key` and `value`
+//   - composites, e.g. nested structs, are records with the composite's fields,
+//     named after the composite's type ID, with dots replaced by underscores
+//
+// Values of abstract types, e.g. AnyStruct or an interface type,
+// have no static Avro type, and are encoded as strings containing their JSON-Cadence encoding.
+//
+// Encoder encodes events into Avro binary records,
+// and ContainerWriter writes them into Avro object container files.
+//
+// Parquet is not supported directly, but Avro container files
+// can be converted to Parquet with standard tools.
+package avro
+
+import (
+	"encoding/json"
+)
+
+// Schema is an Avro schema, which marshals to its JSON representation.
+type Schema interface {
+	isSchema()
+}
+
+// PrimitiveSchema is a primitive Avro type, e.g. `long`,
+// or a reference to a named type defined earlier in the schema.
+type PrimitiveSchema string
+
+var _ Schema = PrimitiveSchema("")
+
+func (PrimitiveSchema) isSchema() {}
+
+const (
+	NullSchema    PrimitiveSchema = "null"
+	BooleanSchema PrimitiveSchema = "boolean"
+	IntSchema     PrimitiveSchema = "int"
+	LongSchema    PrimitiveSchema = "long"
+	BytesSchema   PrimitiveSchema = "bytes"
+	StringSchema  PrimitiveSchema = "string"
+)
+
+// RecordSchema is an Avro record.
+type RecordSchema struct {
+	Name   string        `json:"name"`
+	Doc    string        `json:"doc,omitempty"`
+	Fields []RecordField `json:"fields"`
+}
+
+var _ Schema = &RecordSchema{}
+
+func (*RecordSchema) isSchema() {}
+
+func (s *RecordSchema) MarshalJSON() ([]byte, error) {
+	type record RecordSchema
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*record
+	}{
+		Type:   "record",
+		record: (*record)(s),
+	})
+}
+
+// RecordField is a field of an Avro record.
+type RecordField struct {
+	Name string `json:"name"`
+	Type Schema `json:"type"`
+}
+
+// FixedSchema is an Avro fixed-size byte sequence.
+type FixedSchema struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+var _ Schema = &FixedSchema{}
+
+func (*FixedSchema) isSchema() {}
+
+func (s *FixedSchema) MarshalJSON() ([]byte, error) {
+	type fixed FixedSchema
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*fixed
+	}{
+		Type:  "fixed",
+		fixed: (*fixed)(s),
+	})
+}
+
+// DecimalSchema is an Avro decimal, an arbitrary-precision signed decimal number,
+// which is represented as the two's-complement big-endian bytes of the unscaled integer.
+type DecimalSchema struct {
+	Precision int `json:"precision"`
+	Scale     int `json:"scale"`
+}
+
+var _ Schema = &DecimalSchema{}
+
+func (*DecimalSchema) isSchema() {}
+
+func (s *DecimalSchema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type        string `json:"type"`
+		LogicalType string `json:"logicalType"`
+		Precision   int    `json:"precision"`
+		Scale       int    `json:"scale"`
+	}{
+		Type:        string(BytesSchema),
+		LogicalType: "decimal",
+		Precision:   s.Precision,
+		Scale:       s.Scale,
+	})
+}
+
+// ArraySchema is an Avro array.
+type ArraySchema struct {
+	Items Schema `json:"items"`
+}
+
+var _ Schema = &ArraySchema{}
+
+func (*ArraySchema) isSchema() {}
+
+func (s *ArraySchema) MarshalJSON() ([]byte, error) {
+	type array ArraySchema
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*array
+	}{
+		Type:  "array",
+		array: (*array)(s),
+	})
+}
+
+// MapSchema is an Avro map, which has string keys.
+type MapSchema struct {
+	Values Schema `json:"values"`
+}
+
+var _ Schema = &MapSchema{}
+
+func (*MapSchema) isSchema() {}
+
+func (s *MapSchema) MarshalJSON() ([]byte, error) {
+	type mapSchema MapSchema
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		*mapSchema
+	}{
+		Type:      "map",
+		mapSchema: (*mapSchema)(s),
+	})
+}
+
+// UnionSchema is an Avro union.
+type UnionSchema []Schema
+
+var _ Schema = UnionSchema{}
+
+func (UnionSchema) isSchema() {}
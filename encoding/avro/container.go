@@ -0,0 +1,136 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package avro
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+
+	"github.com/onflow/cadence"
+)
+
+// containerMagic is the magic number at the start of an Avro object container file
+var containerMagic = []byte{'O', 'b', 'j', 1}
+
+const containerSyncMarkerLength = 16
+
+// containerBlockSize is the size in bytes after which a block of records is written
+const containerBlockSize = 64 * 1024
+
+// ContainerWriter writes events into an Avro object container file.
+//
+// Records are written uncompressed, in blocks of about 64 KiB.
+// The file is deterministic: the sync marker is derived from the schema.
+type ContainerWriter struct {
+	writer     io.Writer
+	encoder    *Encoder
+	syncMarker []byte
+	block      []byte
+	count      int64
+}
+
+// NewContainerWriter returns a new writer which writes events of the given type to the given writer.
+// The header of the file, which contains the schema, is written immediately.
+func NewContainerWriter(w io.Writer, eventType *cadence.EventType) (*ContainerWriter, error) {
+	encoder, err := NewEncoder(eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := json.Marshal(encoder.Schema())
+	if err != nil {
+		return nil, err
+	}
+
+	schemaHash := sha256.Sum256(schema)
+	syncMarker := schemaHash[:containerSyncMarkerLength]
+
+	// The header consists of the magic number, the metadata map, and the sync marker
+
+	header := append([]byte{}, containerMagic...)
+	header = appendLong(header, 2)
+	header = appendBytes(header, []byte("avro.schema"))
+	header = appendBytes(header, schema)
+	header = appendBytes(header, []byte("avro.codec"))
+	header = appendBytes(header, []byte("null"))
+	header = appendLong(header, 0)
+	header = append(header, syncMarker...)
+
+	_, err = w.Write(header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ContainerWriter{
+		writer:     w,
+		encoder:    encoder,
+		syncMarker: syncMarker,
+	}, nil
+}
+
+// Write writes the given event.
+// The event is buffered until the current block is full, or until Flush is called.
+func (w *ContainerWriter) Write(event cadence.Event) error {
+	block, err := w.encoder.AppendEncode(w.block, event)
+	if err != nil {
+		return err
+	}
+	w.block = block
+	w.count++
+
+	if len(w.block) >= containerBlockSize {
+		return w.Flush()
+	}
+
+	return nil
+}
+
+// Flush writes the buffered events as a block.
+func (w *ContainerWriter) Flush() error {
+	if w.count == 0 {
+		return nil
+	}
+
+	// A block consists of the number of records, the size of the records in bytes,
+	// the records, and the sync marker
+
+	header := appendLong(nil, w.count)
+	header = appendLong(header, int64(len(w.block)))
+
+	_, err := w.writer.Write(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.writer.Write(w.block)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.writer.Write(w.syncMarker)
+	if err != nil {
+		return err
+	}
+
+	w.block = w.block[:0]
+	w.count = 0
+
+	return nil
+}
@@ -0,0 +1,407 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package avro
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+)
+
+// Encoder encodes events of a specific event type into Avro binary records.
+type Encoder struct {
+	eventType *cadence.EventType
+	schema    *RecordSchema
+	encode    valueEncoder
+}
+
+// NewEncoder returns a new encoder for events of the given type.
+func NewEncoder(eventType *cadence.EventType) (*Encoder, error) {
+	schema, encode, err := newCompiler().compileEvent(eventType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Encoder{
+		eventType: eventType,
+		schema:    schema,
+		encode:    encode,
+	}, nil
+}
+
+// Schema returns the Avro schema of the records.
+func (e *Encoder) Schema() *RecordSchema {
+	return e.schema
+}
+
+// Encode returns the Avro binary encoding of the given event.
+func (e *Encoder) Encode(event cadence.Event) ([]byte, error) {
+	return e.AppendEncode(nil, event)
+}
+
+// AppendEncode appends the Avro binary encoding of the given event to the given buffer.
+func (e *Encoder) AppendEncode(b []byte, event cadence.Event) ([]byte, error) {
+	b, err := e.encode(b, event)
+	if err != nil {
+		return nil, fmt.Errorf("avro: failed to encode event %s: %w", e.eventType.ID(), err)
+	}
+	return b, nil
+}
+
+// TypeMismatchError is returned when a value does not have the type of the schema.
+type TypeMismatchError struct {
+	ExpectedType cadence.Type
+	Value        cadence.Value
+}
+
+var _ error = TypeMismatchError{}
+
+func newTypeMismatchError(expectedType cadence.Type, value cadence.Value) TypeMismatchError {
+	return TypeMismatchError{
+		ExpectedType: expectedType,
+		Value:        value,
+	}
+}
+
+func (e TypeMismatchError) Error() string {
+	var actualType string
+	if e.Value == nil {
+		actualType = "nil"
+	} else if ty := e.Value.Type(); ty != nil {
+		actualType = ty.ID()
+	} else {
+		actualType = fmt.Sprintf("%T", e.Value)
+	}
+
+	return fmt.Sprintf(
+		"value type mismatch: expected %s, got %s",
+		e.ExpectedType.ID(),
+		actualType,
+	)
+}
+
+// appendLong appends the zig-zag variable-length encoding of an Avro int or long
+func appendLong(b []byte, n int64) []byte {
+	return binary.AppendVarint(b, n)
+}
+
+// appendBytes appends an Avro bytes or string value, i.e. its length followed by its bytes
+func appendBytes(b []byte, data []byte) []byte {
+	b = appendLong(b, int64(len(data)))
+	return append(b, data...)
+}
+
+// appendDecimal appends the two's-complement big-endian representation of the given integer
+// as an Avro bytes value
+func appendDecimal(b []byte, i *big.Int) []byte {
+	return appendBytes(b, twosComplement(i))
+}
+
+func twosComplement(i *big.Int) []byte {
+	switch i.Sign() {
+	case 0:
+		return []byte{0}
+
+	case 1:
+		data := i.Bytes()
+		if data[0]&0x80 != 0 {
+			data = append([]byte{0}, data...)
+		}
+		return data
+
+	default:
+		// For a negative integer x, the bytes are the inverted bytes of -x-1
+		magnitude := new(big.Int).Neg(i)
+		magnitude.Sub(magnitude, big.NewInt(1))
+		data := magnitude.Bytes()
+		for j := range data {
+			data[j] = ^data[j]
+		}
+		if len(data) == 0 || data[0]&0x80 == 0 {
+			data = append([]byte{0xff}, data...)
+		}
+		return data
+	}
+}
+
+func encodeBool(b []byte, value cadence.Value) ([]byte, error) {
+	boolValue, ok := value.(cadence.Bool)
+	if !ok {
+		return nil, newTypeMismatchError(cadence.BoolType{}, value)
+	}
+	if boolValue {
+		return append(b, 1), nil
+	}
+	return append(b, 0), nil
+}
+
+func encodeString(b []byte, value cadence.Value) ([]byte, error) {
+	stringValue, ok := value.(cadence.String)
+	if !ok {
+		return nil, newTypeMismatchError(cadence.StringType{}, value)
+	}
+	return appendBytes(b, []byte(stringValue)), nil
+}
+
+func encodeCharacter(b []byte, value cadence.Value) ([]byte, error) {
+	character, ok := value.(cadence.Character)
+	if !ok {
+		return nil, newTypeMismatchError(cadence.CharacterType{}, value)
+	}
+	return appendBytes(b, []byte(character)), nil
+}
+
+func encodeBytes(b []byte, value cadence.Value) ([]byte, error) {
+	bytesValue, ok := value.(cadence.Bytes)
+	if !ok {
+		return nil, newTypeMismatchError(cadence.BytesType{}, value)
+	}
+	return appendBytes(b, bytesValue), nil
+}
+
+func encodeAddress(b []byte, value cadence.Value) ([]byte, error) {
+	address, ok := value.(cadence.Address)
+	if !ok {
+		return nil, newTypeMismatchError(cadence.AddressType{}, value)
+	}
+	// Fixed values are encoded without length
+	return append(b, address[:]...), nil
+}
+
+func encodePath(b []byte, value cadence.Value) ([]byte, error) {
+	path, ok := value.(cadence.Path)
+	if !ok {
+		return nil, newTypeMismatchError(cadence.PathType{}, value)
+	}
+	return appendBytes(b, []byte(path.String())), nil
+}
+
+func encodeCapability(b []byte, value cadence.Value) ([]byte, error) {
+	capability, ok := value.(cadence.Capability)
+	if !ok {
+		return nil, newTypeMismatchError(&cadence.CapabilityType{}, value)
+	}
+	return appendBytes(b, []byte(capability.String())), nil
+}
+
+func encodeTypeValue(b []byte, value cadence.Value) ([]byte, error) {
+	typeValue, ok := value.(cadence.TypeValue)
+	if !ok {
+		return nil, newTypeMismatchError(cadence.MetaType{}, value)
+	}
+	var typeID string
+	if typeValue.StaticType != nil {
+		typeID = typeValue.StaticType.ID()
+	}
+	return appendBytes(b, []byte(typeID)), nil
+}
+
+// encodeDynamic encodes a value of an abstract type, as a string containing its JSON-Cadence encoding
+func encodeDynamic(b []byte, value cadence.Value) ([]byte, error) {
+	encoded, err := jsoncdc.Encode(value)
+	if err != nil {
+		return nil, err
+	}
+	return appendBytes(b, encoded), nil
+}
+
+// integerValue returns the integer of the given value, if it has the given integer type
+func integerValue(ty cadence.Type, value cadence.Value) (*big.Int, bool) {
+	if value == nil || value.Type() == nil || !value.Type().Equal(ty) {
+		return nil, false
+	}
+
+	switch value := value.(type) {
+	case cadence.Int8:
+		return big.NewInt(int64(value)), true
+	case cadence.Int16:
+		return big.NewInt(int64(value)), true
+	case cadence.Int32:
+		return big.NewInt(int64(value)), true
+	case cadence.Int64:
+		return big.NewInt(int64(value)), true
+	case cadence.UInt8:
+		return big.NewInt(int64(value)), true
+	case cadence.UInt16:
+		return big.NewInt(int64(value)), true
+	case cadence.UInt32:
+		return big.NewInt(int64(value)), true
+	case cadence.UInt64:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case cadence.Word8:
+		return big.NewInt(int64(value)), true
+	case cadence.Word16:
+		return big.NewInt(int64(value)), true
+	case cadence.Word32:
+		return big.NewInt(int64(value)), true
+	case cadence.Word64:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case cadence.Fix64:
+		return big.NewInt(int64(value)), true
+	case cadence.UFix64:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case cadence.Int:
+		return value.Value, true
+	case cadence.UInt:
+		return value.Value, true
+	case cadence.Int128:
+		return value.Value, true
+	case cadence.Int256:
+		return value.Value, true
+	case cadence.UInt128:
+		return value.Value, true
+	case cadence.UInt256:
+		return value.Value, true
+	case cadence.Word128:
+		return value.Value, true
+	case cadence.Word256:
+		return value.Value, true
+	}
+
+	return nil, false
+}
+
+// newIntegerEncoder returns an encoder for integer values which fit into an Avro long
+func newIntegerEncoder(ty cadence.Type) valueEncoder {
+	return func(b []byte, value cadence.Value) ([]byte, error) {
+		i, ok := integerValue(ty, value)
+		if !ok {
+			return nil, newTypeMismatchError(ty, value)
+		}
+		return appendLong(b, i.Int64()), nil
+	}
+}
+
+// newDecimalEncoder returns an encoder for integer and fixed-point values, as Avro decimals
+func newDecimalEncoder(ty cadence.Type) valueEncoder {
+	return func(b []byte, value cadence.Value) ([]byte, error) {
+		i, ok := integerValue(ty, value)
+		if !ok {
+			return nil, newTypeMismatchError(ty, value)
+		}
+		return appendDecimal(b, i), nil
+	}
+}
+
+// newIntegerStringEncoder returns an encoder for unbounded integer values, as decimal strings
+func newIntegerStringEncoder(ty cadence.Type) valueEncoder {
+	return func(b []byte, value cadence.Value) ([]byte, error) {
+		i, ok := integerValue(ty, value)
+		if !ok {
+			return nil, newTypeMismatchError(ty, value)
+		}
+		return appendBytes(b, []byte(i.String())), nil
+	}
+}
+
+func newOptionalEncoder(innerEncoder valueEncoder) valueEncoder {
+	return func(b []byte, value cadence.Value) ([]byte, error) {
+		// Nested optionals are flattened
+		for {
+			optional, ok := value.(cadence.Optional)
+			if !ok {
+				break
+			}
+			value = optional.Value
+		}
+
+		if value == nil {
+			// Union branch 0: null
+			return appendLong(b, 0), nil
+		}
+
+		b = appendLong(b, 1)
+		return innerEncoder(b, value)
+	}
+}
+
+func newArrayEncoder(ty cadence.ArrayType, itemEncoder valueEncoder) valueEncoder {
+	return func(b []byte, value cadence.Value) ([]byte, error) {
+		array, ok := value.(cadence.Array)
+		if !ok {
+			return nil, newTypeMismatchError(ty, value)
+		}
+
+		var err error
+		if len(array.Values) > 0 {
+			// All items are written in a single block
+			b = appendLong(b, int64(len(array.Values)))
+			for i, element := range array.Values {
+				b, err = itemEncoder(b, element)
+				if err != nil {
+					return nil, fmt.Errorf("element %d: %w", i, err)
+				}
+			}
+		}
+		return appendLong(b, 0), nil
+	}
+}
+
+func newMapEncoder(ty *cadence.DictionaryType, valueEncoder valueEncoder) valueEncoder {
+	return func(b []byte, value cadence.Value) ([]byte, error) {
+		dictionary, ok := value.(cadence.Dictionary)
+		if !ok {
+			return nil, newTypeMismatchError(ty, value)
+		}
+
+		var err error
+		if len(dictionary.Pairs) > 0 {
+			b = appendLong(b, int64(len(dictionary.Pairs)))
+			for _, pair := range dictionary.Pairs {
+				b, err = encodeString(b, pair.Key)
+				if err != nil {
+					return nil, err
+				}
+				b, err = valueEncoder(b, pair.Value)
+				if err != nil {
+					return nil, fmt.Errorf("entry %s: %w", pair.Key, err)
+				}
+			}
+		}
+		return appendLong(b, 0), nil
+	}
+}
+
+func newEntriesEncoder(ty *cadence.DictionaryType, keyEncoder, valueEncoder valueEncoder) valueEncoder {
+	return func(b []byte, value cadence.Value) ([]byte, error) {
+		dictionary, ok := value.(cadence.Dictionary)
+		if !ok {
+			return nil, newTypeMismatchError(ty, value)
+		}
+
+		var err error
+		if len(dictionary.Pairs) > 0 {
+			b = appendLong(b, int64(len(dictionary.Pairs)))
+			for _, pair := range dictionary.Pairs {
+				b, err = keyEncoder(b, pair.Key)
+				if err != nil {
+					return nil, err
+				}
+				b, err = valueEncoder(b, pair.Value)
+				if err != nil {
+					return nil, fmt.Errorf("entry %s: %w", pair.Key, err)
+				}
+			}
+		}
+		return appendLong(b, 0), nil
+	}
+}
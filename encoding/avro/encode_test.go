@@ -0,0 +1,239 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+)
+
+func newTestEvent(eventType *cadence.EventType) cadence.Event {
+	infoType := eventType.Fields[8].Type.(*cadence.StructType)
+
+	return cadence.NewEvent([]cadence.Value{
+		cadence.UInt64(1),
+		cadence.UFix64(150_000_000),
+		cadence.NewOptional(nil),
+		cadence.BytesToAddress([]byte{0x1}),
+		cadence.NewDictionary([]cadence.KeyValuePair{
+			{Key: cadence.String("a"), Value: cadence.String("b")},
+		}),
+		cadence.NewDictionary([]cadence.KeyValuePair{
+			{Key: cadence.BytesToAddress([]byte{0x2}), Value: cadence.Int8(-1)},
+		}),
+		cadence.NewArray([]cadence.Value{}),
+		cadence.String("x"),
+		cadence.NewStruct([]cadence.Value{
+			cadence.String("n"),
+			cadence.NewOptional(
+				cadence.NewStruct([]cadence.Value{
+					cadence.String("m"),
+					cadence.NewOptional(nil),
+				}).WithType(infoType),
+			),
+		}).WithType(infoType),
+		cadence.NewInt(-300),
+	}).WithType(eventType)
+}
+
+func TestEncode(t *testing.T) {
+
+	t.Parallel()
+
+	eventType := newTestEventType()
+
+	encoder, err := NewEncoder(eventType)
+	require.NoError(t, err)
+
+	encoded, err := encoder.Encode(newTestEvent(eventType))
+	require.NoError(t, err)
+
+	data, err := jsoncdc.Encode(cadence.String("x"))
+	require.NoError(t, err)
+
+	var expected []byte
+	expected = append(expected,
+		// id: decimal 1
+		0x02, 0x01,
+		// amount: decimal 150000000
+		0x08, 0x08, 0xf0, 0xd1, 0x80,
+		// to: null
+		0x00,
+		// from: fixed address
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		// metadata: block of 1 entry, "a": "b", end
+		0x02, 0x02, 'a', 0x02, 'b', 0x00,
+		// balances: block of 1 entry, key address, value -1, end
+		0x02,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02,
+		0x01,
+		0x00,
+		// tags: end
+		0x00,
+	)
+	// data: JSON-Cadence
+	expected = appendBytes(expected, data)
+	expected = append(expected,
+		// info.name: "n"
+		0x02, 'n',
+		// info.next: non-null
+		0x02,
+		// info.next.name: "m"
+		0x02, 'm',
+		// info.next.next: null
+		0x00,
+		// count: "-300"
+		0x08, '-', '3', '0', '0',
+	)
+
+	assert.Equal(t, expected, encoded)
+}
+
+func TestEncodeTypeMismatch(t *testing.T) {
+
+	t.Parallel()
+
+	eventType := newTestEventType()
+
+	encoder, err := NewEncoder(eventType)
+	require.NoError(t, err)
+
+	t.Run("event type", func(t *testing.T) {
+		t.Parallel()
+
+		otherEventType := cadence.NewEventType(
+			testLocation,
+			"Token.Withdraw",
+			eventType.Fields,
+			nil,
+		)
+
+		_, err := encoder.Encode(newTestEvent(otherEventType))
+		require.ErrorAs(t, err, &TypeMismatchError{})
+	})
+
+	t.Run("field type", func(t *testing.T) {
+		t.Parallel()
+
+		event := newTestEvent(eventType)
+		event.Fields[0] = cadence.UInt8(1)
+
+		_, err := encoder.Encode(event)
+		require.ErrorAs(t, err, &TypeMismatchError{})
+		require.ErrorContains(t, err, "field id")
+	})
+}
+
+func TestTwosComplement(t *testing.T) {
+
+	t.Parallel()
+
+	for value, expected := range map[int64][]byte{
+		0:    {0x00},
+		1:    {0x01},
+		127:  {0x7f},
+		128:  {0x00, 0x80},
+		255:  {0x00, 0xff},
+		256:  {0x01, 0x00},
+		-1:   {0xff},
+		-128: {0x80},
+		-129: {0xff, 0x7f},
+		-256: {0xff, 0x00},
+	} {
+		assert.Equal(t, expected, twosComplement(big.NewInt(value)), "%d", value)
+	}
+}
+
+func TestContainerWriter(t *testing.T) {
+
+	t.Parallel()
+
+	eventType := newTestEventType()
+
+	var buffer bytes.Buffer
+
+	writer, err := NewContainerWriter(&buffer, eventType)
+	require.NoError(t, err)
+
+	event := newTestEvent(eventType)
+
+	// Events are buffered until flushed
+
+	require.NoError(t, writer.Write(event))
+	require.NoError(t, writer.Write(event))
+
+	headerLength := buffer.Len()
+
+	require.NoError(t, writer.Flush())
+
+	// Flushing without buffered events does not write an empty block
+
+	require.NoError(t, writer.Flush())
+
+	data := buffer.Bytes()
+
+	schema, err := json.Marshal(writer.encoder.Schema())
+	require.NoError(t, err)
+
+	var expectedHeader []byte
+	expectedHeader = append(expectedHeader, 'O', 'b', 'j', 1)
+	expectedHeader = appendLong(expectedHeader, 2)
+	expectedHeader = appendBytes(expectedHeader, []byte("avro.schema"))
+	expectedHeader = appendBytes(expectedHeader, schema)
+	expectedHeader = appendBytes(expectedHeader, []byte("avro.codec"))
+	expectedHeader = appendBytes(expectedHeader, []byte("null"))
+	expectedHeader = appendLong(expectedHeader, 0)
+
+	require.Equal(t, len(expectedHeader)+containerSyncMarkerLength, headerLength)
+	require.Equal(t, expectedHeader, data[:len(expectedHeader)])
+
+	syncMarker := data[len(expectedHeader):headerLength]
+
+	record, err := writer.encoder.Encode(event)
+	require.NoError(t, err)
+
+	var expectedBlock []byte
+	expectedBlock = appendLong(expectedBlock, 2)
+	expectedBlock = appendLong(expectedBlock, int64(2*len(record)))
+	expectedBlock = append(expectedBlock, record...)
+	expectedBlock = append(expectedBlock, record...)
+	expectedBlock = append(expectedBlock, syncMarker...)
+
+	require.Equal(t, expectedBlock, data[headerLength:])
+
+	// The file is deterministic
+
+	var buffer2 bytes.Buffer
+
+	writer2, err := NewContainerWriter(&buffer2, eventType)
+	require.NoError(t, err)
+	require.NoError(t, writer2.Write(event))
+	require.NoError(t, writer2.Write(event))
+	require.NoError(t, writer2.Flush())
+
+	require.Equal(t, buffer.Bytes(), buffer2.Bytes())
+}
@@ -0,0 +1,356 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package avro
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+)
+
+// valueEncoder appends the Avro binary encoding of the given value to the given buffer
+type valueEncoder func(b []byte, value cadence.Value) ([]byte, error)
+
+// compiler derives the Avro schema of Cadence types,
+// together with the encoders for values of these types
+type compiler struct {
+	// records contains the records of the composite types compiled so far, by type ID.
+	// Later occurrences of a composite type refer to the record by name
+	records map[string]*compiledRecord
+	// names contains the names of all named types defined so far
+	names       map[string]struct{}
+	addressUsed bool
+}
+
+type compiledRecord struct {
+	name   string
+	encode valueEncoder
+}
+
+func newCompiler() *compiler {
+	return &compiler{
+		records: map[string]*compiledRecord{},
+		names:   map[string]struct{}{},
+	}
+}
+
+// EventSchema returns the Avro schema of the given event type.
+func EventSchema(eventType *cadence.EventType) (*RecordSchema, error) {
+	schema, _, err := newCompiler().compileEvent(eventType)
+	return schema, err
+}
+
+func (c *compiler) compileEvent(eventType *cadence.EventType) (*RecordSchema, valueEncoder, error) {
+	if eventType == nil {
+		return nil, nil, fmt.Errorf("missing event type")
+	}
+
+	schema, encode, err := c.compileComposite(eventType, eventType.Fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return schema.(*RecordSchema), encode, nil
+}
+
+// recordName returns the Avro name of the record of the composite type with the given type ID,
+// e.g. `A_0000000000000001_Token_Deposit` for `A.0000000000000001.Token.Deposit`
+func recordName(typeID string) string {
+	return sanitizeName(strings.ReplaceAll(typeID, ".", "_"))
+}
+
+// sanitizeName replaces all characters which are not allowed in Avro names by underscores
+func sanitizeName(name string) string {
+	var builder strings.Builder
+	for i, r := range name {
+		switch {
+		case 'A' <= r && r <= 'Z',
+			'a' <= r && r <= 'z',
+			r == '_',
+			i > 0 && '0' <= r && r <= '9':
+
+			builder.WriteRune(r)
+		default:
+			builder.WriteByte('_')
+		}
+	}
+	return builder.String()
+}
+
+// declareName declares a new named type, with the given name,
+// or with the name and a numeric suffix, if the name is already declared
+func (c *compiler) declareName(name string) string {
+	declaredName := name
+	for i := 2; ; i++ {
+		if _, ok := c.names[declaredName]; !ok {
+			break
+		}
+		declaredName = fmt.Sprintf("%s_%d", name, i)
+	}
+	c.names[declaredName] = struct{}{}
+	return declaredName
+}
+
+func (c *compiler) compileComposite(
+	compositeType cadence.Type,
+	fields []cadence.Field,
+) (
+	Schema,
+	valueEncoder,
+	error,
+) {
+	typeID := compositeType.ID()
+
+	record, ok := c.records[typeID]
+	if ok {
+		// The record is already defined, or currently being defined (recursive types).
+		// Refer to it by name, and look up its encoder lazily
+		return PrimitiveSchema(record.name),
+			func(b []byte, value cadence.Value) ([]byte, error) {
+				return record.encode(b, value)
+			},
+			nil
+	}
+
+	record = &compiledRecord{
+		name: c.declareName(recordName(typeID)),
+	}
+	c.records[typeID] = record
+
+	schema := &RecordSchema{
+		Name:   record.name,
+		Doc:    typeID,
+		Fields: make([]RecordField, len(fields)),
+	}
+
+	fieldEncoders := make([]valueEncoder, len(fields))
+
+	for i, field := range fields {
+		fieldSchema, fieldEncoder, err := c.compile(field.Type, record.name+"_"+field.Identifier)
+		if err != nil {
+			return nil, nil, fmt.Errorf("field %s of %s: %w", field.Identifier, typeID, err)
+		}
+
+		schema.Fields[i] = RecordField{
+			Name: sanitizeName(field.Identifier),
+			Type: fieldSchema,
+		}
+		fieldEncoders[i] = fieldEncoder
+	}
+
+	record.encode = func(b []byte, value cadence.Value) ([]byte, error) {
+		composite, ok := value.(cadence.HasFields)
+		if !ok || value.Type() == nil || value.Type().ID() != typeID {
+			return nil, newTypeMismatchError(compositeType, value)
+		}
+
+		fieldValues := composite.GetFieldValues()
+		if len(fieldValues) != len(fieldEncoders) {
+			return nil, fmt.Errorf(
+				"invalid value of type %s: got %d fields, expected %d",
+				typeID,
+				len(fieldValues),
+				len(fieldEncoders),
+			)
+		}
+
+		var err error
+		for i, fieldValue := range fieldValues {
+			b, err = fieldEncoders[i](b, fieldValue)
+			if err != nil {
+				return nil, fmt.Errorf("field %s of %s: %w", fields[i].Identifier, typeID, err)
+			}
+		}
+		return b, nil
+	}
+
+	return schema, record.encode, nil
+}
+
+// compile returns the Avro schema of the given Cadence type, and the encoder for values of the type.
+// The name hint is used to name records which have no composite type, i.e. dictionary entries.
+func (c *compiler) compile(ty cadence.Type, nameHint string) (Schema, valueEncoder, error) {
+	switch ty := ty.(type) {
+	case cadence.BoolType:
+		return BooleanSchema, encodeBool, nil
+
+	case cadence.StringType:
+		return StringSchema, encodeString, nil
+
+	case cadence.CharacterType:
+		return StringSchema, encodeCharacter, nil
+
+	case cadence.BytesType:
+		return BytesSchema, encodeBytes, nil
+
+	case cadence.Int8Type,
+		cadence.Int16Type,
+		cadence.Int32Type,
+		cadence.UInt8Type,
+		cadence.UInt16Type,
+		cadence.Word8Type,
+		cadence.Word16Type:
+
+		return IntSchema, newIntegerEncoder(ty), nil
+
+	case cadence.Int64Type,
+		cadence.UInt32Type,
+		cadence.Word32Type:
+
+		return LongSchema, newIntegerEncoder(ty), nil
+
+	case cadence.UInt64Type, cadence.Word64Type:
+		return &DecimalSchema{Precision: 20}, newDecimalEncoder(ty), nil
+
+	case cadence.Int128Type, cadence.UInt128Type, cadence.Word128Type:
+		return &DecimalSchema{Precision: 39}, newDecimalEncoder(ty), nil
+
+	case cadence.Int256Type:
+		return &DecimalSchema{Precision: 77}, newDecimalEncoder(ty), nil
+
+	case cadence.UInt256Type, cadence.Word256Type:
+		return &DecimalSchema{Precision: 78}, newDecimalEncoder(ty), nil
+
+	case cadence.IntType, cadence.UIntType:
+		return StringSchema, newIntegerStringEncoder(ty), nil
+
+	case cadence.Fix64Type:
+		return &DecimalSchema{Precision: 19, Scale: 8}, newDecimalEncoder(ty), nil
+
+	case cadence.UFix64Type:
+		return &DecimalSchema{Precision: 20, Scale: 8}, newDecimalEncoder(ty), nil
+
+	case cadence.AddressType:
+		if c.addressUsed {
+			return PrimitiveSchema(addressSchemaName), encodeAddress, nil
+		}
+		c.addressUsed = true
+		c.names[addressSchemaName] = struct{}{}
+		return &FixedSchema{Name: addressSchemaName, Size: cadence.AddressLength}, encodeAddress, nil
+
+	case cadence.PathType,
+		cadence.StoragePathType,
+		cadence.PublicPathType,
+		cadence.PrivatePathType,
+		cadence.CapabilityPathType:
+
+		return StringSchema, encodePath, nil
+
+	case *cadence.CapabilityType:
+		return StringSchema, encodeCapability, nil
+
+	case cadence.MetaType:
+		return StringSchema, encodeTypeValue, nil
+
+	case *cadence.OptionalType:
+		// Avro does not allow nested unions, so nested optionals are flattened
+		innerType := ty.Type
+		for {
+			optionalType, ok := innerType.(*cadence.OptionalType)
+			if !ok {
+				break
+			}
+			innerType = optionalType.Type
+		}
+
+		innerSchema, innerEncoder, err := c.compile(innerType, nameHint)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return UnionSchema{NullSchema, innerSchema},
+			newOptionalEncoder(innerEncoder),
+			nil
+
+	case cadence.ArrayType:
+		return c.compileArray(ty, nameHint)
+
+	case *cadence.DictionaryType:
+		return c.compileDictionary(ty, nameHint)
+
+	case *cadence.StructType:
+		return c.compileComposite(ty, ty.Fields)
+
+	case *cadence.ResourceType:
+		return c.compileComposite(ty, ty.Fields)
+
+	case *cadence.EventType:
+		return c.compileComposite(ty, ty.Fields)
+
+	case *cadence.ContractType:
+		return c.compileComposite(ty, ty.Fields)
+
+	case *cadence.EnumType:
+		return c.compileComposite(ty, ty.Fields)
+
+	case *cadence.AttachmentType:
+		return c.compileComposite(ty, ty.Fields)
+	}
+
+	// All other types, e.g. AnyStruct or interface types, are abstract,
+	// and values are encoded with their dynamic type in JSON-Cadence
+	return StringSchema, encodeDynamic, nil
+}
+
+const addressSchemaName = "Address"
+
+func (c *compiler) compileArray(ty cadence.ArrayType, nameHint string) (Schema, valueEncoder, error) {
+	itemSchema, itemEncoder, err := c.compile(ty.Element(), nameHint+"_item")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &ArraySchema{Items: itemSchema},
+		newArrayEncoder(ty, itemEncoder),
+		nil
+}
+
+func (c *compiler) compileDictionary(ty *cadence.DictionaryType, nameHint string) (Schema, valueEncoder, error) {
+	valueSchema, valueEncoder, err := c.compile(ty.ElementType, nameHint+"_value")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, ok := ty.KeyType.(cadence.StringType); ok {
+		return &MapSchema{Values: valueSchema},
+			newMapEncoder(ty, valueEncoder),
+			nil
+	}
+
+	// Avro maps only support string keys,
+	// so all other dictionaries are arrays of key-value records
+
+	keySchema, keyEncoder, err := c.compile(ty.KeyType, nameHint+"_key")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entrySchema := &RecordSchema{
+		Name: c.declareName(sanitizeName(nameHint + "_entry")),
+		Fields: []RecordField{
+			{Name: "key", Type: keySchema},
+			{Name: "value", Type: valueSchema},
+		},
+	}
+
+	return &ArraySchema{Items: entrySchema},
+		newEntriesEncoder(ty, keyEncoder, valueEncoder),
+		nil
+}
@@ -0,0 +1,209 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package avro
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+)
+
+var testLocation = common.NewAddressLocation(nil, common.MustBytesToAddress([]byte{0x1}), "Token")
+
+func newTestEventType() *cadence.EventType {
+	infoType := cadence.NewStructType(
+		testLocation,
+		"Token.Info",
+		nil,
+		nil,
+	)
+	infoType.Fields = []cadence.Field{
+		{Identifier: "name", Type: cadence.StringType{}},
+		{Identifier: "next", Type: &cadence.OptionalType{Type: infoType}},
+	}
+
+	return cadence.NewEventType(
+		testLocation,
+		"Token.Deposit",
+		[]cadence.Field{
+			{Identifier: "id", Type: cadence.UInt64Type{}},
+			{Identifier: "amount", Type: cadence.UFix64Type{}},
+			{Identifier: "to", Type: &cadence.OptionalType{Type: cadence.AddressType{}}},
+			{Identifier: "from", Type: cadence.AddressType{}},
+			{
+				Identifier: "metadata",
+				Type: &cadence.DictionaryType{
+					KeyType:     cadence.StringType{},
+					ElementType: cadence.StringType{},
+				},
+			},
+			{
+				Identifier: "balances",
+				Type: &cadence.DictionaryType{
+					KeyType:     cadence.AddressType{},
+					ElementType: cadence.Int8Type{},
+				},
+			},
+			{Identifier: "tags", Type: &cadence.VariableSizedArrayType{ElementType: cadence.StringType{}}},
+			{Identifier: "data", Type: cadence.AnyStructType{}},
+			{Identifier: "info", Type: infoType},
+			{Identifier: "count", Type: cadence.IntType{}},
+		},
+		nil,
+	)
+}
+
+func TestEventSchema(t *testing.T) {
+
+	t.Parallel()
+
+	schema, err := EventSchema(newTestEventType())
+	require.NoError(t, err)
+
+	actual, err := json.MarshalIndent(schema, "", "  ")
+	require.NoError(t, err)
+
+	require.JSONEq(t,
+		// language=json
+		`
+          {
+            "type": "record",
+            "name": "A_0000000000000001_Token_Deposit",
+            "doc": "A.0000000000000001.Token.Deposit",
+            "fields": [
+              {
+                "name": "id",
+                "type": {"type": "bytes", "logicalType": "decimal", "precision": 20, "scale": 0}
+              },
+              {
+                "name": "amount",
+                "type": {"type": "bytes", "logicalType": "decimal", "precision": 20, "scale": 8}
+              },
+              {
+                "name": "to",
+                "type": ["null", {"type": "fixed", "name": "Address", "size": 8}]
+              },
+              {
+                "name": "from",
+                "type": "Address"
+              },
+              {
+                "name": "metadata",
+                "type": {"type": "map", "values": "string"}
+              },
+              {
+                "name": "balances",
+                "type": {
+                  "type": "array",
+                  "items": {
+                    "type": "record",
+                    "name": "A_0000000000000001_Token_Deposit_balances_entry",
+                    "fields": [
+                      {"name": "key", "type": "Address"},
+                      {"name": "value", "type": "int"}
+                    ]
+                  }
+                }
+              },
+              {
+                "name": "tags",
+                "type": {"type": "array", "items": "string"}
+              },
+              {
+                "name": "data",
+                "type": "string"
+              },
+              {
+                "name": "info",
+                "type": {
+                  "type": "record",
+                  "name": "A_0000000000000001_Token_Info",
+                  "doc": "A.0000000000000001.Token.Info",
+                  "fields": [
+                    {"name": "name", "type": "string"},
+                    {"name": "next", "type": ["null", "A_0000000000000001_Token_Info"]}
+                  ]
+                }
+              },
+              {
+                "name": "count",
+                "type": "string"
+              }
+            ]
+          }
+        `,
+		string(actual),
+	)
+
+	// The schema is deterministic
+
+	schema2, err := EventSchema(newTestEventType())
+	require.NoError(t, err)
+
+	actual2, err := json.MarshalIndent(schema2, "", "  ")
+	require.NoError(t, err)
+	require.Equal(t, string(actual), string(actual2))
+}
+
+func TestEventSchemaNameConflicts(t *testing.T) {
+
+	t.Parallel()
+
+	// The entry record of the dictionary field has the same name
+	// as the record of the composite type `Token.Deposit_balances_entry`
+
+	conflictingType := cadence.NewStructType(
+		testLocation,
+		"Token.Deposit_balances_entry",
+		[]cadence.Field{},
+		nil,
+	)
+
+	eventType := cadence.NewEventType(
+		testLocation,
+		"Token.Deposit",
+		[]cadence.Field{
+			{Identifier: "conflicting", Type: conflictingType},
+			{
+				Identifier: "balances",
+				Type: &cadence.DictionaryType{
+					KeyType:     cadence.UInt8Type{},
+					ElementType: cadence.UInt8Type{},
+				},
+			},
+		},
+		nil,
+	)
+
+	schema, err := EventSchema(eventType)
+	require.NoError(t, err)
+
+	require.Equal(t,
+		"A_0000000000000001_Token_Deposit_balances_entry",
+		schema.Fields[0].Type.(*RecordSchema).Name,
+	)
+	require.Equal(t,
+		"A_0000000000000001_Token_Deposit_balances_entry_2",
+		schema.Fields[1].Type.(*ArraySchema).Items.(*RecordSchema).Name,
+	)
+}
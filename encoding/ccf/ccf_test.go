@@ -15661,6 +15661,7 @@ func TestHasMsgPrefix(t *testing.T) {
 		{name: "not implemented", msg: []byte{0xd8, ccf.CBORTagTypeDef, 0x82, 0x00, 0x00}, expected: false},
 		{name: "ccf-typedef-and-value-message", msg: []byte{0xd8, ccf.CBORTagTypeDefAndValue, 0x82, 0x00, 0x00}, expected: true},
 		{name: "ccf-type-and-value-message", msg: []byte{0xd8, ccf.CBORTagTypeAndValue, 0x82, 0xd8, ccf.CBORTagSimpleType, 0x18, 0x32, 0xf6}, expected: true},
+		{name: "ccf-typedef-and-value-sequence-message", msg: []byte{0xd8, ccf.CBORTagTypeDefAndValueSequence, 0x82, 0x80, 0x80}, expected: false},
 	}
 
 	for _, tc := range testCases {
@@ -43,11 +43,11 @@ package ccf
 // there is no need to append new tag numbers in 232-255.
 
 const (
-	// CBOR tag numbers (128-135) for root objects (131-135 are reserved)
+	// CBOR tag numbers (128-135) for root objects (132-135 are reserved)
 	CBORTagTypeDef = 128 + iota
 	CBORTagTypeDefAndValue
 	CBORTagTypeAndValue
-	_
+	CBORTagTypeDefAndValueSequence
 	_
 	_
 	_
@@ -59,13 +59,32 @@ func HasMsgPrefix(msg []byte) bool {
 	// Since ccf-typedef-message isn't implemented yet,
 	// check for these two messages:
 	// - ccf-typedef-and-value-message
-	// - ccf-type-and-valu8e-message
+	// - ccf-type-and-value-message
 
 	return msg[0] == 0xd8 && // 0xd8 is major type 6, semantic tag
 		(msg[1] == CBORTagTypeDefAndValue || msg[1] == CBORTagTypeAndValue) &&
 		msg[2] == 0x82 // 0x82 is CBOR array head of 2 elements
 }
 
+// HasSequencePrefix returns true if the msg prefix (first few bytes)
+// matches a ccf-typedef-and-value-sequence-message (see sequence.md).
+// WARNING: For simplicity and performance, this does not check
+// if msg is actually a CCF sequence message, or well-formed, or valid.
+func HasSequencePrefix(msg []byte) bool {
+
+	// A sequence message is a CBOR tag (tag number: CBORTagTypeDefAndValueSequence, content: array of 2 elements).
+	// Minimum size is 5 bytes: tag number (2 bytes) + min size for array of 2 elements (3 bytes).
+	const minSequenceMsgSize = 5
+
+	if len(msg) < minSequenceMsgSize {
+		return false
+	}
+
+	return msg[0] == 0xd8 && // 0xd8 is major type 6, semantic tag
+		msg[1] == CBORTagTypeDefAndValueSequence &&
+		msg[2] == 0x82 // 0x82 is CBOR array head of 2 elements
+}
+
 // defaultCBORDecMode
 //
 // See https://github.com/fxamacker/cbor:
@@ -118,6 +137,22 @@ type DecMode interface {
 	NewDecoder(gauge common.MemoryGauge, b []byte) *Decoder
 }
 
+// SequenceDecMode is a DecMode which can also decode CCF-encoded sequences.
+type SequenceDecMode interface {
+	DecMode
+
+	// DecodeSequence returns the Cadence values decoded from their CCF-encoded
+	// representation as a sequence.
+	//
+	// This function returns an error if the bytes represent CCF that is malformed,
+	// invalid, or does not comply with requirements in the CCF specification.
+	DecodeSequence(gauge common.MemoryGauge, b []byte) ([]cadence.Value, error)
+
+	// NewSequenceDecoder initializes a SequenceDecoder that will decode
+	// the values of the CCF-encoded sequence in the given bytes.
+	NewSequenceDecoder(gauge common.MemoryGauge, b []byte) *SequenceDecoder
+}
+
 // EnforceSortMode specifies how the decoder should enforce sort order.
 type EnforceSortMode int
 
@@ -165,6 +200,25 @@ type decMode struct {
 // DecMode returns CCF decoding mode which contains immutable decoding options.
 // The returned DecMode is safe for concurrent use.
 func (opts DecOptions) DecMode() (DecMode, error) {
+	dm, err := opts.decMode()
+	if err != nil {
+		return nil, err
+	}
+	return dm, nil
+}
+
+// SequenceDecMode returns CCF decoding mode which contains immutable decoding options,
+// and which can also decode CCF-encoded sequences.
+// The returned SequenceDecMode is safe for concurrent use.
+func (opts DecOptions) SequenceDecMode() (SequenceDecMode, error) {
+	dm, err := opts.decMode()
+	if err != nil {
+		return nil, err
+	}
+	return dm, nil
+}
+
+func (opts DecOptions) decMode() (*decMode, error) {
 	if !opts.EnforceSortCompositeFields.valid() {
 		return nil, fmt.Errorf("ccf: invalid EnforceSortCompositeFields %d", opts.EnforceSortCompositeFields)
 	}
@@ -0,0 +1,260 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ccf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	goRuntime "runtime"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	cadenceErrors "github.com/onflow/cadence/runtime/errors"
+)
+
+// SequenceDecoder decodes the values of a CCF-encoded
+// ccf-typedef-and-value-sequence-message one at a time,
+// without decoding all values first.
+type SequenceDecoder struct {
+	decoder *Decoder
+	size    int
+
+	// types are the shared type definitions, decoded with the first value
+	types *cadenceTypeByCCFTypeID
+	// count is the number of values in the sequence
+	count uint64
+	// decoded is the number of values decoded so far
+	decoded uint64
+	// started is true once the type definitions have been decoded
+	started bool
+	// err is the error which stopped decoding, if any
+	err error
+}
+
+// NewSequenceDecoder initializes a SequenceDecoder that will decode
+// the values of the CCF-encoded sequence in the given bytes.
+func (dm *decMode) NewSequenceDecoder(gauge common.MemoryGauge, b []byte) *SequenceDecoder {
+	return &SequenceDecoder{
+		decoder: dm.NewDecoder(gauge, b),
+		size:    len(b),
+	}
+}
+
+// DecodeSequence returns the Cadence values decoded from their CCF-encoded
+// representation as a sequence.
+//
+// This function returns an error if the bytes represent CCF that is malformed,
+// invalid, or does not comply with requirements in the CCF specification.
+func (dm *decMode) DecodeSequence(gauge common.MemoryGauge, b []byte) ([]cadence.Value, error) {
+	dec := dm.NewSequenceDecoder(gauge, b)
+
+	// NOTE: the values are not preallocated based on the count of the sequence,
+	// as the count is declared by the input, and the slot of a value
+	// is much larger than the smallest possible encoded item
+
+	values := []cadence.Value{}
+
+	for {
+		value, err := dec.Decode()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+}
+
+// NewSequenceDecoder initializes a SequenceDecoder that will decode
+// the values of the CCF-encoded sequence in the given bytes.
+// Default CCF decoding options are used.
+func NewSequenceDecoder(gauge common.MemoryGauge, b []byte) *SequenceDecoder {
+	return defaultDecMode.NewSequenceDecoder(gauge, b)
+}
+
+// DecodeSequence returns the Cadence values decoded from their CCF-encoded
+// representation as a sequence. Default CCF decoding options are used.
+//
+// This function returns an error if the bytes represent CCF that is malformed,
+// invalid, or does not comply with requirements in the CCF specification.
+func DecodeSequence(gauge common.MemoryGauge, b []byte) ([]cadence.Value, error) {
+	return defaultDecMode.DecodeSequence(gauge, b)
+}
+
+// Count returns the number of values in the sequence.
+func (d *SequenceDecoder) Count() (count int, err error) {
+	err = d.decodeWithRecover(d.start)
+	if err != nil {
+		return 0, err
+	}
+	return int(d.count), nil
+}
+
+// Decode decodes the next value of the sequence.
+//
+// This function returns io.EOF after the last value has been decoded,
+// or an error if the bytes represent CCF that is malformed,
+// invalid, or does not comply with requirements in the CCF specification.
+// Requirements which concern the whole sequence, like type definitions
+// which are not referenced by any value, are only checked after the last value.
+func (d *SequenceDecoder) Decode() (value cadence.Value, err error) {
+	err = d.decodeWithRecover(func() error {
+		err := d.start()
+		if err != nil {
+			return err
+		}
+
+		if d.decoded == d.count {
+			return d.finish()
+		}
+
+		value, err = d.decoder.decodeTypeAndValue(d.types)
+		if err != nil {
+			return err
+		}
+
+		d.decoded++
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (d *SequenceDecoder) decodeWithRecover(f func() error) (err error) {
+	if d.err != nil {
+		return d.err
+	}
+
+	// Capture panics that occur during decoding.
+	defer func() {
+		// Recover panic error if there is any.
+		if r := recover(); r != nil {
+			// Don't recover Go errors, internal errors, or non-errors.
+			switch r := r.(type) {
+			case goRuntime.Error, cadenceErrors.InternalError:
+				panic(r)
+			case error:
+				err = r
+			default:
+				panic(r)
+			}
+		}
+
+		// Add context to error if there is any,
+		// and stop decoding: the decoder is in an unknown state
+		if err != nil {
+			if err != io.EOF {
+				err = cadenceErrors.NewDefaultUserError("ccf: failed to decode: %s", err)
+			}
+			d.err = err
+		}
+	}()
+
+	return f()
+}
+
+// start decodes the head of the message and the type definitions as
+// language=CDDL
+// ccf-typedef-and-value-sequence-message =
+//
+//	; cbor-tag-typedef-and-value-sequence
+//	#6.131([
+//	  typedef: [* (
+//	    struct-type
+//	    / resource-type
+//	    / contract-type
+//	    / event-type
+//	    / enum-type
+//	    / struct-interface-type
+//	    / resource-interface-type
+//	    / contract-interface-type
+//	  )],
+//	  values: [* inline-type-and-value]
+//	])
+func (d *SequenceDecoder) start() error {
+	if d.started {
+		return nil
+	}
+
+	dec := d.decoder.dec
+
+	tagNum, err := dec.DecodeTagNumber()
+	if err != nil {
+		return err
+	}
+
+	if tagNum != CBORTagTypeDefAndValueSequence {
+		return fmt.Errorf(
+			"unexpected top level CCF message with CBOR tag number %d (expected %d)",
+			tagNum,
+			CBORTagTypeDefAndValueSequence,
+		)
+	}
+
+	// Decode array head of length 2
+	err = decodeCBORArrayWithKnownSize(dec, 2)
+	if err != nil {
+		return err
+	}
+
+	// element 0: typedef, which might be empty
+	typeDefCount, err := dec.DecodeArrayHead()
+	if err != nil {
+		return err
+	}
+
+	if typeDefCount == 0 {
+		d.types = newCadenceTypeByCCFTypeID()
+	} else {
+		d.types, err = d.decoder.decodeTypeDefsOfCount(typeDefCount)
+		if err != nil {
+			return err
+		}
+	}
+
+	// element 1: values
+	d.count, err = dec.DecodeArrayHead()
+	if err != nil {
+		return err
+	}
+
+	d.started = true
+
+	return nil
+}
+
+// finish checks the requirements which concern the whole sequence,
+// and returns io.EOF if they are met
+func (d *SequenceDecoder) finish() error {
+	// Check if there is any unreferenced type definition.
+	if d.types.hasUnreferenced() {
+		return errors.New("found unreferenced type definition")
+	}
+
+	if d.decoder.dec.NumBytesDecoded() != d.size {
+		return fmt.Errorf("decoded %d bytes, received %d bytes", d.decoder.dec.NumBytesDecoded(), d.size)
+	}
+
+	return io.EOF
+}
@@ -60,6 +60,12 @@ func (d *Decoder) decodeTypeDefs() (*cadenceTypeByCCFTypeID, error) {
 		return nil, errors.New("found 0 type definition in composite-typedef (expected at least 1 type definition)")
 	}
 
+	return d.decodeTypeDefsOfCount(count)
+}
+
+// decodeTypeDefsOfCount decodes the given number of composite/interface type definitions,
+// after the array head of composite-typedef has been decoded.
+func (d *Decoder) decodeTypeDefsOfCount(count uint64) (*cadenceTypeByCCFTypeID, error) {
 	types := newCadenceTypeByCCFTypeID()
 
 	// NOTE: composite fields are not decoded while composite types are decoded
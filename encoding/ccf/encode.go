@@ -73,6 +73,17 @@ type EncMode interface {
 	NewEncoder(w io.Writer) *Encoder
 }
 
+// SequenceEncMode is an EncMode which can also encode CCF sequences.
+type SequenceEncMode interface {
+	EncMode
+
+	// EncodeSequence returns the CCF-encoded representation of the given values,
+	// as a single message which contains the type definitions shared by all values once.
+	//
+	// This function returns an error if any of the Cadence values cannot be represented in CCF.
+	EncodeSequence(values []cadence.Value) ([]byte, error)
+}
+
 type SortMode int
 
 const (
@@ -113,6 +124,24 @@ type encMode struct {
 // EncMode returns CCF encoding mode, which contains immutable encoding options
 // and is safe for concurrent use.
 func (opts EncOptions) EncMode() (EncMode, error) {
+	em, err := opts.encMode()
+	if err != nil {
+		return nil, err
+	}
+	return em, nil
+}
+
+// SequenceEncMode returns CCF encoding mode, which contains immutable encoding options,
+// can also encode CCF sequences, and is safe for concurrent use.
+func (opts EncOptions) SequenceEncMode() (SequenceEncMode, error) {
+	em, err := opts.encMode()
+	if err != nil {
+		return nil, err
+	}
+	return em, nil
+}
+
+func (opts EncOptions) encMode() (*encMode, error) {
 	if !opts.SortCompositeFields.valid() {
 		return nil, fmt.Errorf("ccf: invalid SortCompositeFields %d", opts.SortCompositeFields)
 	}
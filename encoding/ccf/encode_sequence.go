@@ -0,0 +1,166 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ccf
+
+import (
+	"bytes"
+	"fmt"
+	goRuntime "runtime"
+
+	"github.com/onflow/cadence"
+	cadenceErrors "github.com/onflow/cadence/runtime/errors"
+)
+
+// EncodeSequence returns the CCF-encoded representation of the given values,
+// as a single message which contains the type definitions shared by all values once.
+//
+// This function returns an error if any of the Cadence values cannot be represented in CCF.
+func (em *encMode) EncodeSequence(values []cadence.Value) ([]byte, error) {
+	var w bytes.Buffer
+
+	enc := em.NewEncoder(&w)
+	defer enc.enc.Close()
+
+	err := enc.EncodeSequence(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.Bytes(), nil
+}
+
+// EncodeSequence returns the CCF-encoded representation of the given values
+// by using default CCF encoding options, as a single message which contains
+// the type definitions shared by all values once.
+// This function returns an error if any of the Cadence values cannot be represented in CCF.
+func EncodeSequence(values []cadence.Value) ([]byte, error) {
+	return defaultEncMode.EncodeSequence(values)
+}
+
+// EncodeSequence writes the CCF-encoded representation of the given values to this
+// encoder's io.Writer, as a single ccf-typedef-and-value-sequence-message.
+//
+// All composite and interface types of all values are defined once,
+// and are referred to by CCF type ID from all values.
+// The encoding is deterministic: the type definitions are sorted by Cadence type ID,
+// and the values are encoded in the given order.
+//
+// This function returns an error if the given values' types are not supported
+// by the encoder, or if values have different types with the same type ID.
+func (e *Encoder) EncodeSequence(values []cadence.Value) (err error) {
+	// capture panics
+	defer func() {
+		// Recover panic error if there is any.
+		if r := recover(); r != nil {
+			// Don't recover Go errors, internal errors, or non-errors.
+			switch r := r.(type) {
+			case goRuntime.Error, cadenceErrors.InternalError:
+				panic(r)
+			case error:
+				err = r
+			default:
+				panic(r)
+			}
+		}
+
+		// Add context to error if there is any.
+		if err != nil {
+			err = fmt.Errorf(
+				"ccf: failed to encode sequence of %d values: %s",
+				len(values),
+				err,
+			)
+		}
+	}()
+
+	for i, value := range values {
+		if value == nil {
+			return fmt.Errorf("unexpected nil value at index %d", i)
+		}
+	}
+
+	// Traverse values to find all composite types.
+	types, tids, err := compositeTypesFromValues(values)
+	if err != nil {
+		return err
+	}
+
+	err = e.encodeTypeDefAndValueSequence(values, types, tids)
+	if err != nil {
+		return err
+	}
+
+	return e.enc.Flush()
+}
+
+// encodeTypeDefAndValueSequence encodes type definitions and values as
+// language=CDDL
+// ccf-typedef-and-value-sequence-message =
+//
+//	; cbor-tag-typedef-and-value-sequence
+//	#6.131([
+//	  typedef: [* (
+//	    struct-type
+//	    / resource-type
+//	    / contract-type
+//	    / event-type
+//	    / enum-type
+//	    / struct-interface-type
+//	    / resource-interface-type
+//	    / contract-interface-type
+//	  )],
+//	  values: [* inline-type-and-value]
+//	])
+func (e *Encoder) encodeTypeDefAndValueSequence(
+	values []cadence.Value,
+	types []cadence.Type,
+	tids ccfTypeIDByCadenceType,
+) error {
+	// Encode tag number cbor-tag-typedef-and-value-sequence and array head of length 2.
+	err := e.enc.EncodeRawBytes([]byte{
+		// tag number
+		0xd8, CBORTagTypeDefAndValueSequence,
+		// array, 2 items follow
+		0x82,
+	})
+	if err != nil {
+		return err
+	}
+
+	// element 0: typedef, which might be empty
+	err = e.encodeTypeDefs(types, tids)
+	if err != nil {
+		return err
+	}
+
+	// element 1: values
+	err = e.enc.EncodeArrayHead(uint64(len(values)))
+	if err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		err = e.encodeInlineTypeAndValue(value, tids)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
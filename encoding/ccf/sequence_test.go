@@ -0,0 +1,357 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ccf_test
+
+import (
+	"io"
+	goRuntime "runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/ccf"
+	"github.com/onflow/cadence/runtime/tests/utils"
+)
+
+var deterministicSequenceEncMode, _ = ccf.EncOptions{
+	SortCompositeFields: ccf.SortBytewiseLexical,
+	SortRestrictedTypes: ccf.SortBytewiseLexical,
+}.SequenceEncMode()
+
+var deterministicSequenceDecMode, _ = ccf.DecOptions{
+	EnforceSortCompositeFields: ccf.EnforceSortBytewiseLexical,
+	EnforceSortRestrictedTypes: ccf.EnforceSortBytewiseLexical,
+}.SequenceDecMode()
+
+func newSequenceTestEventType() *cadence.EventType {
+	return &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "FungibleToken.Deposit",
+		Fields: []cadence.Field{
+			{Identifier: "to", Type: cadence.NewOptionalType(cadence.TheAddressType)},
+			{Identifier: "amount", Type: cadence.TheUFix64Type},
+		},
+	}
+}
+
+func newSequenceTestEvents(count int) []cadence.Value {
+	events := make([]cadence.Value, count)
+
+	for i := range events {
+		// Use a separate type instance for each event, like in decoded events
+		eventType := newSequenceTestEventType()
+
+		events[i] = cadence.NewEvent([]cadence.Value{
+			cadence.NewOptional(cadence.BytesToAddress([]byte{byte(i)})),
+			cadence.UFix64(uint64(i) * 1_00000000),
+		}).WithType(eventType)
+	}
+
+	return events
+}
+
+func valuesWithCachedTypeID(values []cadence.Value) []cadence.Value {
+	result := make([]cadence.Value, len(values))
+	for i, value := range values {
+		result[i] = cadence.ValueWithCachedTypeID(value)
+	}
+	return result
+}
+
+func TestEncodeSequence(t *testing.T) {
+
+	t.Parallel()
+
+	structType := &cadence.StructType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "S",
+		Fields: []cadence.Field{
+			{Identifier: "a", Type: cadence.TheIntType},
+		},
+	}
+
+	t.Run("events", func(t *testing.T) {
+		t.Parallel()
+
+		events := newSequenceTestEvents(500)
+
+		encoded, err := ccf.EventsEncMode.EncodeSequence(events)
+		require.NoError(t, err)
+
+		assert.True(t, ccf.HasSequencePrefix(encoded))
+		assert.False(t, ccf.HasMsgPrefix(encoded))
+
+		decoded, err := ccf.EventsDecMode.DecodeSequence(nil, encoded)
+		require.NoError(t, err)
+
+		assert.Equal(t, valuesWithCachedTypeID(events), valuesWithCachedTypeID(decoded))
+
+		// The type definition is only encoded once
+
+		single, err := ccf.EventsEncMode.Encode(events[0])
+		require.NoError(t, err)
+
+		assert.Less(t, len(encoded), len(events)*len(single)/2)
+	})
+
+	t.Run("mixed", func(t *testing.T) {
+		t.Parallel()
+
+		values := []cadence.Value{
+			cadence.NewInt(1),
+			newSequenceTestEvents(1)[0],
+			cadence.NewArray([]cadence.Value{
+				cadence.NewStruct([]cadence.Value{cadence.NewInt(2)}).WithType(structType),
+			}).WithType(cadence.NewVariableSizedArrayType(cadence.TheAnyStructType)),
+			cadence.String("test"),
+		}
+
+		encoded, err := ccf.EncodeSequence(values)
+		require.NoError(t, err)
+
+		decoded, err := ccf.DecodeSequence(nil, encoded)
+		require.NoError(t, err)
+
+		assert.Equal(t, valuesWithCachedTypeID(values), valuesWithCachedTypeID(decoded))
+	})
+
+	t.Run("no type definitions", func(t *testing.T) {
+		t.Parallel()
+
+		values := []cadence.Value{
+			cadence.NewInt(1),
+			cadence.String("test"),
+		}
+
+		encoded, err := ccf.EncodeSequence(values)
+		require.NoError(t, err)
+
+		decoded, err := ccf.DecodeSequence(nil, encoded)
+		require.NoError(t, err)
+
+		assert.Equal(t, valuesWithCachedTypeID(values), valuesWithCachedTypeID(decoded))
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		encoded, err := ccf.EncodeSequence(nil)
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			[]byte{
+				// tag
+				0xd8, ccf.CBORTagTypeDefAndValueSequence,
+				// array, 2 items follow
+				0x82,
+				// typedef: array, 0 items follow
+				0x80,
+				// values: array, 0 items follow
+				0x80,
+			},
+			encoded,
+		)
+
+		decoded, err := ccf.DecodeSequence(nil, encoded)
+		require.NoError(t, err)
+
+		assert.Empty(t, decoded)
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		t.Parallel()
+
+		values := []cadence.Value{
+			cadence.NewStruct([]cadence.Value{cadence.NewInt(2)}).WithType(structType),
+			newSequenceTestEvents(1)[0],
+		}
+
+		encoded, err := deterministicSequenceEncMode.EncodeSequence(values)
+		require.NoError(t, err)
+
+		// Type definitions are sorted by type ID, so they do not depend on the order of the values
+
+		reversed, err := deterministicSequenceEncMode.EncodeSequence([]cadence.Value{values[1], values[0]})
+		require.NoError(t, err)
+
+		decoded, err := deterministicSequenceDecMode.DecodeSequence(nil, reversed)
+		require.NoError(t, err)
+
+		reencoded, err := deterministicSequenceEncMode.EncodeSequence([]cadence.Value{decoded[1], decoded[0]})
+		require.NoError(t, err)
+
+		assert.Equal(t, encoded, reencoded)
+	})
+
+	t.Run("conflicting types", func(t *testing.T) {
+		t.Parallel()
+
+		otherEventType := newSequenceTestEventType()
+		otherEventType.Fields = otherEventType.Fields[:1]
+
+		values := []cadence.Value{
+			newSequenceTestEvents(1)[0],
+			cadence.NewEvent([]cadence.Value{
+				cadence.NewOptional(nil),
+			}).WithType(otherEventType),
+		}
+
+		_, err := ccf.EncodeSequence(values)
+		require.ErrorContains(t, err, "found conflicting definitions of type S.test.FungibleToken.Deposit")
+	})
+
+	t.Run("nil value", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ccf.EncodeSequence([]cadence.Value{cadence.NewInt(1), nil})
+		require.ErrorContains(t, err, "unexpected nil value at index 1")
+	})
+}
+
+func TestSequenceDecoder(t *testing.T) {
+
+	t.Parallel()
+
+	events := newSequenceTestEvents(10)
+
+	encoded, err := ccf.EncodeSequence(events)
+	require.NoError(t, err)
+
+	t.Run("one at a time", func(t *testing.T) {
+		t.Parallel()
+
+		decoder := ccf.NewSequenceDecoder(nil, encoded)
+
+		count, err := decoder.Count()
+		require.NoError(t, err)
+		assert.Equal(t, len(events), count)
+
+		for _, event := range events {
+			decoded, err := decoder.Decode()
+			require.NoError(t, err)
+			assert.Equal(t,
+				cadence.ValueWithCachedTypeID(event),
+				cadence.ValueWithCachedTypeID(decoded),
+			)
+		}
+
+		_, err = decoder.Decode()
+		require.Equal(t, io.EOF, err)
+
+		_, err = decoder.Decode()
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("trailing bytes", func(t *testing.T) {
+		t.Parallel()
+
+		data := append(append([]byte{}, encoded...), 0x00)
+
+		_, err := ccf.DecodeSequence(nil, data)
+		require.ErrorContains(t, err, "decoded")
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		t.Parallel()
+
+		decoder := ccf.NewSequenceDecoder(nil, encoded[:len(encoded)-1])
+
+		var err error
+		for i := 0; i < len(events) && err == nil; i++ {
+			_, err = decoder.Decode()
+		}
+
+		require.Error(t, err)
+		require.NotEqual(t, io.EOF, err)
+
+		// Decoding stops after an error
+		_, nextErr := decoder.Decode()
+		require.Equal(t, err, nextErr)
+	})
+
+	t.Run("large declared count", func(t *testing.T) {
+		t.Parallel()
+
+		const count = 4_000_000
+
+		data := []byte{
+			// tag
+			0xd8, ccf.CBORTagTypeDefAndValueSequence,
+			// array, 2 items follow
+			0x82,
+			// typedef: array, 0 items follow
+			0x80,
+			// values: array, 4,000,000 items follow
+			0x9a, 0x00, 0x3d, 0x09, 0x00,
+		}
+		// Each item is the smallest well-formed CBOR item,
+		// an unsigned integer, which is not a valid value
+		data = append(data, make([]byte, count)...)
+
+		var before, after goRuntime.MemStats
+		goRuntime.ReadMemStats(&before)
+
+		_, err := ccf.DecodeSequence(nil, data)
+		require.Error(t, err)
+
+		goRuntime.ReadMemStats(&after)
+
+		// The values are not preallocated based on the declared count,
+		// which would allocate 16 bytes per item
+		assert.Less(t, after.TotalAlloc-before.TotalAlloc, uint64(count*8))
+	})
+
+	t.Run("not a sequence", func(t *testing.T) {
+		t.Parallel()
+
+		single, err := ccf.Encode(events[0])
+		require.NoError(t, err)
+
+		_, err = ccf.DecodeSequence(nil, single)
+		require.ErrorContains(t, err, "unexpected top level CCF message")
+	})
+}
+
+func TestHasSequencePrefix(t *testing.T) {
+
+	t.Parallel()
+
+	type testCase struct {
+		name     string
+		msg      []byte
+		expected bool
+	}
+
+	testCases := []testCase{
+		{name: "empty", msg: nil, expected: false},
+		{name: "too short", msg: []byte{0xd8, ccf.CBORTagTypeDefAndValueSequence, 0x82}, expected: false},
+		{name: "not CCF", msg: []byte{'a', 'b', 'c', 'd', 'e'}, expected: false},
+		{name: "ccf-typedef-and-value-message", msg: []byte{0xd8, ccf.CBORTagTypeDefAndValue, 0x82, 0x00, 0x00}, expected: false},
+		{name: "ccf-type-and-value-message", msg: []byte{0xd8, ccf.CBORTagTypeAndValue, 0x82, 0xd8, ccf.CBORTagSimpleType, 0x18, 0x32, 0xf6}, expected: false},
+		{name: "ccf-typedef-and-value-sequence-message", msg: []byte{0xd8, ccf.CBORTagTypeDefAndValueSequence, 0x82, 0x80, 0x80}, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ccf.HasSequencePrefix(tc.msg))
+		})
+	}
+}
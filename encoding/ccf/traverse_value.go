@@ -19,6 +19,7 @@
 package ccf
 
 import (
+	"fmt"
 	"sort"
 
 	"github.com/onflow/cadence"
@@ -28,17 +29,25 @@ type compositeTypes struct {
 	ids           ccfTypeIDByCadenceType
 	abstractTypes map[string]bool
 	types         []cadence.Type
+	// conflictErr is the first conflict between different types with the same type ID.
+	// Conflicts are only detected when checkConflicts is true.
+	checkConflicts bool
+	conflictErr    error
 }
 
-// compositeTypesFromValue returns all composite/interface types for value v.
-// Returned types are sorted unique list of static and runtime composite/interface types.
-// NOTE: nested composite/interface types are included in the returned types.
-func compositeTypesFromValue(v cadence.Value) ([]cadence.Type, ccfTypeIDByCadenceType) {
-	ct := &compositeTypes{
+func newCompositeTypes() *compositeTypes {
+	return &compositeTypes{
 		ids:           make(ccfTypeIDByCadenceType),
 		abstractTypes: make(map[string]bool),
 		types:         make([]cadence.Type, 0, 1),
 	}
+}
+
+// compositeTypesFromValue returns all composite/interface types for value v.
+// Returned types are sorted unique list of static and runtime composite/interface types.
+// NOTE: nested composite/interface types are included in the returned types.
+func compositeTypesFromValue(v cadence.Value) ([]cadence.Type, ccfTypeIDByCadenceType) {
+	ct := newCompositeTypes()
 
 	// Traverse v to get all unique:
 	// - static composite types
@@ -52,15 +61,39 @@ func compositeTypesFromValue(v cadence.Value) ([]cadence.Type, ccfTypeIDByCadenc
 		return ct.types, ct.ids
 	}
 
-	// Sort Cadence types by Cadence type ID.
+	ct.sort()
+
+	return ct.types, ct.ids
+}
+
+// compositeTypesFromValues returns all composite/interface types for values vs,
+// like compositeTypesFromValue.
+// It returns an error if different types with the same type ID are found,
+// as they cannot share a type definition.
+func compositeTypesFromValues(vs []cadence.Value) ([]cadence.Type, ccfTypeIDByCadenceType, error) {
+	ct := newCompositeTypes()
+	ct.checkConflicts = true
+
+	for _, v := range vs {
+		ct.traverseValue(v)
+		if ct.conflictErr != nil {
+			return nil, nil, ct.conflictErr
+		}
+	}
+
+	ct.sort()
+
+	return ct.types, ct.ids, nil
+}
+
+// sort sorts the types by Cadence type ID,
+// and assigns the sorted index as the local CCF type ID
+func (ct *compositeTypes) sort() {
 	sort.Sort(bytewiseCadenceTypeInPlaceSorter(ct.types))
 
-	// Assign sorted array index as local ccf ID.
 	for i, typ := range ct.types {
 		ct.ids[typ.ID()] = ccfTypeID(i)
 	}
-
-	return ct.types, ct.ids
 }
 
 func (ct *compositeTypes) traverseValue(v cadence.Value) {
@@ -233,9 +266,48 @@ func (ct *compositeTypes) traverseType(typ cadence.Type) (checkRuntimeType bool)
 func (ct *compositeTypes) add(t cadence.Type) bool {
 	cadenceTypeID := t.ID()
 	if _, ok := ct.ids[cadenceTypeID]; ok {
+		if ct.checkConflicts && ct.conflictErr == nil {
+			ct.checkConflict(t)
+		}
 		return false
 	}
 	ct.ids[cadenceTypeID] = 0
 	ct.types = append(ct.types, t)
 	return true
 }
+
+// checkConflict records a conflict if the given type has the same type ID as an already added type,
+// but different fields
+func (ct *compositeTypes) checkConflict(t cadence.Type) {
+	compositeType, ok := t.(cadence.CompositeType)
+	if !ok {
+		return
+	}
+
+	cadenceTypeID := t.ID()
+
+	for _, existing := range ct.types {
+		if existing.ID() != cadenceTypeID {
+			continue
+		}
+
+		existingCompositeType, ok := existing.(cadence.CompositeType)
+		if !ok || !fieldsEqual(existingCompositeType.CompositeFields(), compositeType.CompositeFields()) {
+			ct.conflictErr = fmt.Errorf("found conflicting definitions of type %s", cadenceTypeID)
+		}
+		return
+	}
+}
+
+func fieldsEqual(a, b []cadence.Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, field := range a {
+		other := b[i]
+		if field.Identifier != other.Identifier || field.Type.ID() != other.Type.ID() {
+			return false
+		}
+	}
+	return true
+}
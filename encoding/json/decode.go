@@ -139,6 +139,11 @@ const (
 )
 
 func (d *Decoder) decodeJSON(v any) cadence.Value {
+	// values may have already been decoded by the StreamDecoder
+	if decoded, ok := v.(decodedValue); ok {
+		return decoded.value
+	}
+
 	obj := toObject(v)
 
 	typeStr := obj.GetString(typeKey)
@@ -0,0 +1,967 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+// SchemaDialect is the JSON Schema dialect of the schemas generated by GenerateSchema
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// valueDefinitionName is the name of the schema definition
+// which describes any JSON-Cadence value
+const valueDefinitionName = "Value"
+
+// Schema is a JSON Schema document.
+//
+// Only the keywords needed to describe JSON-Cadence encoded values are supported.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Const                *string            `json:"const,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	PrefixItems          []*Schema          `json:"prefixItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Not                  *Schema            `json:"not,omitempty"`
+}
+
+// GenerateSchema returns a JSON Schema document which describes
+// the JSON-Cadence encoding of values of the given type.
+//
+// Composite types are described by definitions, keyed by their type ID.
+// Values of abstract types, e.g. AnyStruct, are only checked to be JSON-Cadence values.
+// Values of fixed-size integer types are checked to be in the range of the type,
+// but values of fixed-point types are only checked to have the format of the type.
+func GenerateSchema(typ cadence.Type) (schema *Schema, err error) {
+	// capture panics that occur during generation
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr, isError := r.(error)
+			if !isError {
+				panic(r)
+			}
+
+			err = fmt.Errorf("failed to generate schema: %w", panicErr)
+		}
+	}()
+
+	generator := &schemaGenerator{
+		defs: map[string]*Schema{
+			valueDefinitionName: anyValueSchema(),
+		},
+	}
+
+	schema = generator.schema(typ)
+	schema.Schema = SchemaDialect
+	schema.Defs = generator.defs
+
+	return schema, nil
+}
+
+type schemaGenerator struct {
+	defs map[string]*Schema
+}
+
+func (g *schemaGenerator) schema(typ cadence.Type) *Schema {
+	switch typ := typ.(type) {
+	case cadence.VoidType:
+		return &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				typeKey: constSchema(voidTypeStr),
+			},
+			Required:             []string{typeKey},
+			AdditionalProperties: boolPtr(false),
+		}
+
+	case cadence.NeverType:
+		return &Schema{
+			Description: "Never",
+			Not:         &Schema{},
+		}
+
+	case cadence.BoolType:
+		return valueObjectSchema(boolTypeStr, &Schema{Type: "boolean"})
+
+	case cadence.StringType:
+		return valueObjectSchema(stringTypeStr, &Schema{Type: "string"})
+
+	case cadence.CharacterType:
+		return valueObjectSchema(characterTypeStr, &Schema{Type: "string"})
+
+	case cadence.AddressType:
+		return valueObjectSchema(addressTypeStr, addressSchema())
+
+	case *cadence.OptionalType:
+		return valueObjectSchema(
+			optionalTypeStr,
+			&Schema{
+				AnyOf: []*Schema{
+					{Type: "null"},
+					g.schema(typ.Type),
+				},
+			},
+		)
+
+	case *cadence.VariableSizedArrayType:
+		return valueObjectSchema(
+			arrayTypeStr,
+			&Schema{
+				Type:  "array",
+				Items: g.schema(typ.ElementType),
+			},
+		)
+
+	case *cadence.ConstantSizedArrayType:
+		size := int(typ.Size)
+		return valueObjectSchema(
+			arrayTypeStr,
+			&Schema{
+				Type:     "array",
+				Items:    g.schema(typ.ElementType),
+				MinItems: &size,
+				MaxItems: &size,
+			},
+		)
+
+	case *cadence.DictionaryType:
+		return valueObjectSchema(
+			dictionaryTypeStr,
+			&Schema{
+				Type: "array",
+				Items: &Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						keyKey:   g.schema(typ.KeyType),
+						valueKey: g.schema(typ.ElementType),
+					},
+					Required:             []string{keyKey, valueKey},
+					AdditionalProperties: boolPtr(false),
+				},
+			},
+		)
+
+	case *cadence.StructType:
+		return g.compositeSchema(structTypeStr, typ)
+
+	case *cadence.ResourceType:
+		return g.compositeSchema(resourceTypeStr, typ)
+
+	case *cadence.EventType:
+		return g.compositeSchema(eventTypeStr, typ)
+
+	case *cadence.ContractType:
+		return g.compositeSchema(contractTypeStr, typ)
+
+	case *cadence.EnumType:
+		return g.compositeSchema(enumTypeStr, typ)
+
+	case *cadence.AttachmentType:
+		return g.compositeSchema(attachmentTypeStr, typ)
+
+	case *cadence.RestrictedType:
+		// A restricted type with a composite type as the restricted type
+		// has values of exactly that composite type.
+		// Otherwise, values may be of any composite type conforming to the restrictions
+		if compositeType, ok := typ.Type.(cadence.CompositeType); ok {
+			return g.schema(compositeType)
+		}
+		return anyCompositeSchema()
+
+	case *cadence.ReferenceType:
+		// References are exported as the referenced value
+		return g.schema(typ.Type)
+
+	case cadence.PathType:
+		return pathSchema(
+			common.PathDomainStorage,
+			common.PathDomainPublic,
+			common.PathDomainPrivate,
+		)
+
+	case cadence.StoragePathType:
+		return pathSchema(common.PathDomainStorage)
+
+	case cadence.CapabilityPathType:
+		return pathSchema(common.PathDomainPublic, common.PathDomainPrivate)
+
+	case cadence.PublicPathType:
+		return pathSchema(common.PathDomainPublic)
+
+	case cadence.PrivatePathType:
+		return pathSchema(common.PathDomainPrivate)
+
+	case cadence.MetaType:
+		return valueObjectSchema(
+			typeTypeStr,
+			&Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					staticTypeKey: {},
+				},
+				Required:             []string{staticTypeKey},
+				AdditionalProperties: boolPtr(false),
+			},
+		)
+
+	case *cadence.CapabilityType:
+		return valueObjectSchema(capabilityTypeStr, capabilitySchema())
+
+	case *cadence.FunctionType:
+		return valueObjectSchema(
+			functionTypeStr,
+			&Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					functionTypeKey: {Type: "object"},
+				},
+				Required:             []string{functionTypeKey},
+				AdditionalProperties: boolPtr(false),
+			},
+		)
+
+	case nil:
+		panic(errors.NewDefaultUserError("missing type"))
+	}
+
+	if schema := numberSchema(typ.ID()); schema != nil {
+		return schema
+	}
+
+	// All other types, e.g. AnyStruct, have values of various types
+	return valueRefSchema()
+}
+
+func (g *schemaGenerator) compositeSchema(kind string, compositeType cadence.CompositeType) *Schema {
+	typeID := compositeType.ID()
+
+	// Composite types may be recursive,
+	// so they are defined once, and referred to everywhere
+
+	ref := &Schema{
+		Ref: definitionRef(typeID),
+	}
+
+	if _, ok := g.defs[typeID]; ok {
+		return ref
+	}
+
+	fields := compositeType.CompositeFields()
+	fieldSchemas := make([]*Schema, len(fields))
+
+	definition := valueObjectSchema(
+		kind,
+		&Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				idKey: constSchema(typeID),
+				fieldsKey: {
+					Type:        "array",
+					PrefixItems: fieldSchemas,
+					MinItems:    intPtr(len(fields)),
+					MaxItems:    intPtr(len(fields)),
+				},
+			},
+			Required:             []string{idKey, fieldsKey},
+			AdditionalProperties: boolPtr(false),
+		},
+	)
+	definition.Description = compositeType.CompositeTypeQualifiedIdentifier()
+
+	g.defs[typeID] = definition
+
+	for i, field := range fields {
+		fieldSchemas[i] = &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				nameKey:  constSchema(field.Identifier),
+				valueKey: g.schema(field.Type),
+			},
+			Required:             []string{nameKey, valueKey},
+			AdditionalProperties: boolPtr(false),
+		}
+	}
+
+	return ref
+}
+
+var compositeTypeStrs = []string{
+	structTypeStr,
+	resourceTypeStr,
+	eventTypeStr,
+	contractTypeStr,
+	enumTypeStr,
+	attachmentTypeStr,
+}
+
+var valueTypeStrs = []string{
+	voidTypeStr,
+	optionalTypeStr,
+	boolTypeStr,
+	characterTypeStr,
+	stringTypeStr,
+	addressTypeStr,
+	intTypeStr,
+	int8TypeStr,
+	int16TypeStr,
+	int32TypeStr,
+	int64TypeStr,
+	int128TypeStr,
+	int256TypeStr,
+	uintTypeStr,
+	uint8TypeStr,
+	uint16TypeStr,
+	uint32TypeStr,
+	uint64TypeStr,
+	uint128TypeStr,
+	uint256TypeStr,
+	word8TypeStr,
+	word16TypeStr,
+	word32TypeStr,
+	word64TypeStr,
+	word128TypeStr,
+	word256TypeStr,
+	fix64TypeStr,
+	ufix64TypeStr,
+	arrayTypeStr,
+	dictionaryTypeStr,
+	structTypeStr,
+	resourceTypeStr,
+	attachmentTypeStr,
+	eventTypeStr,
+	contractTypeStr,
+	linkTypeStr,
+	accountLinkTypeStr,
+	pathTypeStr,
+	typeTypeStr,
+	capabilityTypeStr,
+	enumTypeStr,
+	functionTypeStr,
+}
+
+// anyValueSchema returns a schema which describes any JSON-Cadence value.
+// Only the type of the value is checked.
+func anyValueSchema() *Schema {
+	return &Schema{
+		Description: "JSON-Cadence value",
+		Type:        "object",
+		Properties: map[string]*Schema{
+			typeKey: {Enum: valueTypeStrs},
+		},
+		Required: []string{typeKey},
+	}
+}
+
+func anyCompositeSchema() *Schema {
+	schema := valueObjectSchema(
+		"",
+		&Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				idKey: {Type: "string"},
+				fieldsKey: {
+					Type: "array",
+					Items: &Schema{
+						Type: "object",
+						Properties: map[string]*Schema{
+							nameKey:  {Type: "string"},
+							valueKey: valueRefSchema(),
+						},
+						Required:             []string{nameKey, valueKey},
+						AdditionalProperties: boolPtr(false),
+					},
+				},
+			},
+			Required:             []string{idKey, fieldsKey},
+			AdditionalProperties: boolPtr(false),
+		},
+	)
+	schema.Properties[typeKey] = &Schema{Enum: compositeTypeStrs}
+	return schema
+}
+
+func valueRefSchema() *Schema {
+	return &Schema{
+		Ref: definitionRef(valueDefinitionName),
+	}
+}
+
+func valueObjectSchema(typeStr string, value *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			typeKey:  constSchema(typeStr),
+			valueKey: value,
+		},
+		Required:             []string{typeKey, valueKey},
+		AdditionalProperties: boolPtr(false),
+	}
+}
+
+func constSchema(value string) *Schema {
+	return &Schema{
+		Const: &value,
+	}
+}
+
+func addressSchema() *Schema {
+	return &Schema{
+		Type:    "string",
+		Pattern: "^0x([0-9a-fA-F]{2}){0,8}$",
+	}
+}
+
+var identifierPattern = "^[A-Za-z_][A-Za-z0-9_]*$"
+
+func pathSchema(domains ...common.PathDomain) *Schema {
+	domainSchema := &Schema{}
+	if len(domains) == 1 {
+		domainSchema = constSchema(domains[0].Identifier())
+	} else {
+		for _, domain := range domains {
+			domainSchema.Enum = append(domainSchema.Enum, domain.Identifier())
+		}
+	}
+
+	return valueObjectSchema(
+		pathTypeStr,
+		&Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				domainKey: domainSchema,
+				identifierKey: {
+					Type:    "string",
+					Pattern: identifierPattern,
+				},
+			},
+			Required:             []string{domainKey, identifierKey},
+			AdditionalProperties: boolPtr(false),
+		},
+	)
+}
+
+func capabilitySchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			pathKey:       pathSchema(common.PathDomainPublic, common.PathDomainPrivate),
+			addressKey:    addressSchema(),
+			borrowTypeKey: {},
+			idKey:         integerValueSchema(sema.UInt64Type),
+		},
+		Required:             []string{addressKey, borrowTypeKey},
+		AdditionalProperties: boolPtr(false),
+	}
+}
+
+// numberSchema returns the schema for the number type with the given type ID,
+// or nil if the type is not a number type
+func numberSchema(typeID string) *Schema {
+	var numberType sema.Type
+	for _, ty := range sema.AllNumberTypes {
+		if string(ty.ID()) == typeID {
+			numberType = ty
+			break
+		}
+	}
+	if numberType == nil {
+		return nil
+	}
+
+	if !numberType.(sema.IntegerRangedType).IsSuperType() {
+		return concreteNumberSchema(numberType)
+	}
+
+	// Values of number super-types, e.g. Integer,
+	// may be of any of the concrete subtypes
+
+	schema := &Schema{
+		Description: typeID,
+	}
+	for _, ty := range sema.AllNumberTypes {
+		if ty.(sema.IntegerRangedType).IsSuperType() ||
+			!sema.IsSubType(ty, numberType) {
+
+			continue
+		}
+		schema.AnyOf = append(schema.AnyOf, concreteNumberSchema(ty))
+	}
+	return schema
+}
+
+func concreteNumberSchema(numberType sema.Type) *Schema {
+	typeStr := string(numberType.ID())
+
+	switch numberType {
+	case sema.Fix64Type:
+		return valueObjectSchema(typeStr, &Schema{
+			Type:    "string",
+			Pattern: `^-?[0-9]+\.[0-9]{1,8}$`,
+		})
+
+	case sema.UFix64Type:
+		return valueObjectSchema(typeStr, &Schema{
+			Type:    "string",
+			Pattern: `^[0-9]+\.[0-9]{1,8}$`,
+		})
+	}
+
+	return valueObjectSchema(typeStr, integerValueSchema(numberType))
+}
+
+// integerValueSchema returns the schema for the encoded value of the given integer type.
+// The pattern of a fixed-size integer type only matches integers in the range of the type.
+func integerValueSchema(integerType sema.Type) *Schema {
+	rangedType := integerType.(sema.IntegerRangedType)
+
+	max := rangedType.MaxInt()
+	if max == nil {
+		sign := ""
+		if min := rangedType.MinInt(); min == nil || min.Sign() < 0 {
+			sign = "-?"
+		}
+
+		return &Schema{
+			Type:    "string",
+			Pattern: fmt.Sprintf("^%s[0-9]+$", sign),
+		}
+	}
+
+	pattern := fmt.Sprintf("0*(%s)", decimalRangePattern(max))
+
+	if min := rangedType.MinInt(); min.Sign() < 0 {
+		negativePattern := fmt.Sprintf("-0*(%s)", decimalRangePattern(new(big.Int).Neg(min)))
+		pattern = fmt.Sprintf("%s|%s", pattern, negativePattern)
+	}
+
+	return &Schema{
+		Type:    "string",
+		Pattern: fmt.Sprintf("^(%s)$", pattern),
+	}
+}
+
+// decimalRangePattern returns a regular expression which matches
+// the decimal representations without leading zeros of all integers from 0 to the given maximum.
+func decimalRangePattern(max *big.Int) string {
+	digits := max.String()
+	length := len(digits)
+
+	repeatDigit := func(count int) string {
+		switch count {
+		case 0:
+			return ""
+		case 1:
+			return "[0-9]"
+		default:
+			return fmt.Sprintf("[0-9]{%d}", count)
+		}
+	}
+
+	digitRange := func(low, high byte) string {
+		if low == high {
+			return string(low)
+		}
+		return fmt.Sprintf("[%c-%c]", low, high)
+	}
+
+	// Zero, and all integers with fewer digits than the maximum
+
+	alternatives := []string{"0"}
+	if length > 1 {
+		alternatives = append(
+			alternatives,
+			fmt.Sprintf("[1-9][0-9]{0,%d}", length-2),
+		)
+	}
+
+	// All integers with as many digits as the maximum,
+	// which share a prefix with the maximum,
+	// followed by a smaller digit, followed by any digits
+
+	for i := 0; i < length; i++ {
+		lowest := byte('0')
+		if i == 0 {
+			lowest = '1'
+		}
+
+		digit := digits[i]
+		if digit <= lowest {
+			continue
+		}
+
+		alternatives = append(
+			alternatives,
+			digits[:i]+digitRange(lowest, digit-1)+repeatDigit(length-i-1),
+		)
+	}
+
+	// The maximum itself
+
+	alternatives = append(alternatives, digits)
+
+	return strings.Join(alternatives, "|")
+}
+
+func definitionRef(name string) string {
+	return "#/$defs/" + escapePointerToken(name)
+}
+
+func escapePointerToken(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
+
+func unescapePointerToken(token string) string {
+	token = strings.ReplaceAll(token, "~1", "/")
+	return strings.ReplaceAll(token, "~0", "~")
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// ValidationError is a violation of a schema
+type ValidationError struct {
+	// Pointer is the JSON Pointer (RFC 6901) to the invalid part of the document
+	Pointer string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	pointer := e.Pointer
+	if pointer == "" {
+		pointer = "/"
+	}
+	return fmt.Sprintf("%s: %s", pointer, e.Message)
+}
+
+// ValidationErrors are all violations of a schema by a document
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	var builder strings.Builder
+	builder.WriteString("invalid JSON-Cadence value:")
+	for _, err := range e {
+		builder.WriteString("\n\t")
+		builder.WriteString(err.Error())
+	}
+	return builder.String()
+}
+
+// Validate validates the given JSON document against the schema.
+//
+// This function returns an error if the document is not valid JSON,
+// or ValidationErrors, which report every violation of the schema.
+func (s *Schema) Validate(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document any
+	err := decoder.Decode(&document)
+	if err != nil {
+		return errors.NewDefaultUserError("failed to decode JSON: %w", err)
+	}
+	if decoder.More() {
+		return errors.NewDefaultUserError("failed to decode JSON: unexpected data after value")
+	}
+
+	validator := &schemaValidator{
+		root: s,
+	}
+	validator.validate(s, document, "")
+
+	if len(validator.errors) > 0 {
+		return validator.errors
+	}
+
+	return nil
+}
+
+// Validate validates the given JSON document against the schema
+// of the JSON-Cadence encoding of the given type.
+//
+// This function returns an error if the document is not valid JSON,
+// or ValidationErrors, which report every violation of the schema.
+func Validate(typ cadence.Type, data []byte) error {
+	schema, err := GenerateSchema(typ)
+	if err != nil {
+		return err
+	}
+
+	return schema.Validate(data)
+}
+
+type schemaValidator struct {
+	root   *Schema
+	errors ValidationErrors
+}
+
+func (v *schemaValidator) report(pointer string, format string, args ...any) {
+	v.errors = append(v.errors, ValidationError{
+		Pointer: pointer,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// matches returns true if the instance is valid according to the schema,
+// without reporting any errors
+func (v *schemaValidator) matches(schema *Schema, instance any) bool {
+	validator := &schemaValidator{
+		root: v.root,
+	}
+	validator.validate(schema, instance, "")
+	return len(validator.errors) == 0
+}
+
+func (v *schemaValidator) resolve(ref string) *Schema {
+	const prefix = "#/$defs/"
+	if !strings.HasPrefix(ref, prefix) {
+		panic(errors.NewUnexpectedError("unsupported schema reference: %s", ref))
+	}
+	schema, ok := v.root.Defs[unescapePointerToken(strings.TrimPrefix(ref, prefix))]
+	if !ok {
+		panic(errors.NewUnexpectedError("unknown schema reference: %s", ref))
+	}
+	return schema
+}
+
+func (v *schemaValidator) validate(schema *Schema, instance any, pointer string) {
+	if schema.Ref != "" {
+		v.validate(v.resolve(schema.Ref), instance, pointer)
+	}
+
+	if schema.Not != nil && v.matches(schema.Not, instance) {
+		v.report(pointer, "value is not allowed")
+		return
+	}
+
+	if schema.Type != "" {
+		instanceType := jsonTypeName(instance)
+		if instanceType != schema.Type {
+			v.report(pointer, "expected %s, got %s", schema.Type, instanceType)
+			return
+		}
+	}
+
+	if schema.Const != nil && instance != *schema.Const {
+		v.report(pointer, "expected %s, got %s", strconv.Quote(*schema.Const), jsonValueString(instance))
+		return
+	}
+
+	if schema.Enum != nil && !enumContains(schema.Enum, instance) {
+		v.report(pointer, "expected one of %s, got %s", strings.Join(schema.Enum, ", "), jsonValueString(instance))
+		return
+	}
+
+	if schema.AnyOf != nil {
+		v.validateAnyOf(schema, instance, pointer)
+	}
+
+	switch instance := instance.(type) {
+	case string:
+		if schema.Pattern != "" && !compilePattern(schema.Pattern).MatchString(instance) {
+			v.report(pointer, "%s does not match pattern %s", strconv.Quote(instance), schema.Pattern)
+		}
+
+	case map[string]any:
+		v.validateObject(schema, instance, pointer)
+
+	case []any:
+		v.validateArray(schema, instance, pointer)
+	}
+}
+
+func (v *schemaValidator) validateObject(schema *Schema, instance map[string]any, pointer string) {
+	for _, name := range schema.Required {
+		if _, ok := instance[name]; !ok {
+			v.report(pointer, "missing property %s", strconv.Quote(name))
+		}
+	}
+
+	// Validate the properties in a deterministic order
+
+	names := make([]string, 0, len(instance))
+	for name := range instance {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		propertyPointer := pointer + "/" + escapePointerToken(name)
+
+		propertySchema, ok := schema.Properties[name]
+		if !ok {
+			if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+				v.report(propertyPointer, "unexpected property")
+			}
+			continue
+		}
+
+		v.validate(propertySchema, instance[name], propertyPointer)
+	}
+}
+
+func (v *schemaValidator) validateArray(schema *Schema, instance []any, pointer string) {
+	if schema.MinItems != nil && len(instance) < *schema.MinItems {
+		v.report(pointer, "expected at least %d elements, got %d", *schema.MinItems, len(instance))
+	}
+
+	if schema.MaxItems != nil && len(instance) > *schema.MaxItems {
+		v.report(pointer, "expected at most %d elements, got %d", *schema.MaxItems, len(instance))
+	}
+
+	for i, element := range instance {
+		elementPointer := pointer + "/" + strconv.Itoa(i)
+
+		if i < len(schema.PrefixItems) {
+			v.validate(schema.PrefixItems[i], element, elementPointer)
+		} else if schema.Items != nil {
+			v.validate(schema.Items, element, elementPointer)
+		}
+	}
+}
+
+func (v *schemaValidator) validateAnyOf(schema *Schema, instance any, pointer string) {
+	for _, alternative := range schema.AnyOf {
+		if v.matches(alternative, instance) {
+			return
+		}
+	}
+
+	// None of the alternatives match.
+	// If the instance was likely meant to be of one of the alternatives,
+	// e.g. it has the JSON type and the JSON-Cadence type of only one alternative,
+	// report the violations of it, as they are more helpful than a generic error
+
+	var candidate *Schema
+	candidates := 0
+	for _, alternative := range schema.AnyOf {
+		if v.isCandidate(alternative, instance) {
+			candidate = alternative
+			candidates++
+		}
+	}
+
+	if candidates == 1 {
+		v.validate(candidate, instance, pointer)
+		return
+	}
+
+	v.report(pointer, "value does not match any of the allowed types")
+}
+
+func (v *schemaValidator) isCandidate(schema *Schema, instance any) bool {
+	if schema.Ref != "" {
+		schema = v.resolve(schema.Ref)
+	}
+
+	if schema.Type != "" && schema.Type != jsonTypeName(instance) {
+		return false
+	}
+
+	object, ok := instance.(map[string]any)
+	if !ok {
+		return true
+	}
+
+	typeSchema, ok := schema.Properties[typeKey]
+	if !ok {
+		return true
+	}
+
+	return v.matches(typeSchema, object[typeKey])
+}
+
+func jsonTypeName(instance any) string {
+	switch instance.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		panic(errors.NewUnreachableError())
+	}
+}
+
+func jsonValueString(instance any) string {
+	if s, ok := instance.(string); ok {
+		return strconv.Quote(s)
+	}
+	return jsonTypeName(instance)
+}
+
+func enumContains(enum []string, instance any) bool {
+	s, ok := instance.(string)
+	if !ok {
+		return false
+	}
+	for _, value := range enum {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
+
+var compiledPatterns sync.Map
+
+func compilePattern(pattern string) *regexp.Regexp {
+	if compiled, ok := compiledPatterns.Load(pattern); ok {
+		return compiled.(*regexp.Regexp)
+	}
+	compiled := regexp.MustCompile(pattern)
+	compiledPatterns.Store(pattern, compiled)
+	return compiled
+}
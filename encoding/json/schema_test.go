@@ -0,0 +1,407 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json_test
+
+import (
+	goJSON "encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/tests/utils"
+)
+
+func TestGenerateSchema(t *testing.T) {
+
+	t.Parallel()
+
+	schema, err := json.GenerateSchema(&cadence.OptionalType{Type: cadence.Int8Type{}})
+	require.NoError(t, err)
+
+	actual, err := goJSON.Marshal(schema)
+	require.NoError(t, err)
+
+	// language=json
+	expected := `
+      {
+        "$schema": "https://json-schema.org/draft/2020-12/schema",
+        "$defs": {
+          "Value": {
+            "description": "JSON-Cadence value",
+            "type": "object",
+            "properties": {
+              "type": {
+                "enum": [
+                  "Void", "Optional", "Bool", "Character", "String", "Address",
+                  "Int", "Int8", "Int16", "Int32", "Int64", "Int128", "Int256",
+                  "UInt", "UInt8", "UInt16", "UInt32", "UInt64", "UInt128", "UInt256",
+                  "Word8", "Word16", "Word32", "Word64", "Word128", "Word256",
+                  "Fix64", "UFix64", "Array", "Dictionary",
+                  "Struct", "Resource", "Attachment", "Event", "Contract",
+                  "Link", "AccountLink", "Path", "Type", "Capability", "Enum", "Function"
+                ]
+              }
+            },
+            "required": ["type"]
+          }
+        },
+        "type": "object",
+        "properties": {
+          "type": {"const": "Optional"},
+          "value": {
+            "anyOf": [
+              {"type": "null"},
+              {
+                "type": "object",
+                "properties": {
+                  "type": {"const": "Int8"},
+                  "value": {"type": "string", "pattern": "^(0*(0|[1-9][0-9]{0,1}|1[0-1][0-9]|12[0-6]|127)|-0*(0|[1-9][0-9]{0,1}|1[0-1][0-9]|12[0-7]|128))$"}
+                },
+                "required": ["type", "value"],
+                "additionalProperties": false
+              }
+            ]
+          }
+        },
+        "required": ["type", "value"],
+        "additionalProperties": false
+      }
+    `
+
+	assert.JSONEq(t, expected, string(actual))
+}
+
+func TestValidateEncodedValues(t *testing.T) {
+
+	t.Parallel()
+
+	location := utils.TestLocation
+
+	nodeType := &cadence.StructType{
+		Location:            location,
+		QualifiedIdentifier: "Node",
+	}
+	nodeType.Fields = []cadence.Field{
+		{Identifier: "value", Type: cadence.UFix64Type{}},
+		{Identifier: "next", Type: &cadence.OptionalType{Type: nodeType}},
+	}
+
+	kindType := &cadence.EnumType{
+		Location:            location,
+		QualifiedIdentifier: "Kind",
+		RawType:             cadence.UInt8Type{},
+		Fields: []cadence.Field{
+			{Identifier: "rawValue", Type: cadence.UInt8Type{}},
+		},
+	}
+
+	vaultType := &cadence.ResourceType{
+		Location:            location,
+		QualifiedIdentifier: "Vault",
+		Fields: []cadence.Field{
+			{Identifier: "uuid", Type: cadence.UInt64Type{}},
+			{Identifier: "balance", Type: cadence.Fix64Type{}},
+		},
+	}
+
+	eventType := &cadence.EventType{
+		Location:            location,
+		QualifiedIdentifier: "Deposit",
+		Fields: []cadence.Field{
+			{Identifier: "to", Type: &cadence.OptionalType{Type: cadence.AddressType{}}},
+			{Identifier: "kinds", Type: &cadence.VariableSizedArrayType{ElementType: kindType}},
+			{Identifier: "tags", Type: &cadence.DictionaryType{
+				KeyType:     cadence.StringType{},
+				ElementType: cadence.AnyStructType{},
+			}},
+			{Identifier: "amount", Type: cadence.IntegerType{}},
+		},
+	}
+
+	type testCase struct {
+		name  string
+		typ   cadence.Type
+		value cadence.Value
+	}
+
+	test := func(test testCase) {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := json.Encode(test.value)
+			require.NoError(t, err)
+
+			err = json.Validate(test.typ, data)
+			require.NoError(t, err)
+		})
+	}
+
+	for _, testCase := range []testCase{
+		{
+			name:  "Void",
+			typ:   cadence.VoidType{},
+			value: cadence.NewVoid(),
+		},
+		{
+			name:  "Int8",
+			typ:   cadence.Int8Type{},
+			value: cadence.NewInt8(-128),
+		},
+		{
+			name:  "UInt256",
+			typ:   cadence.UInt256Type{},
+			value: cadence.NewUInt256(42),
+		},
+		{
+			name:  "Fix64",
+			typ:   cadence.Fix64Type{},
+			value: cadence.Fix64(-1_50000000),
+		},
+		{
+			name:  "Address",
+			typ:   cadence.AddressType{},
+			value: cadence.BytesToAddress([]byte{0x1}),
+		},
+		{
+			name: "constant-sized array",
+			typ: &cadence.ConstantSizedArrayType{
+				Size:        2,
+				ElementType: cadence.BoolType{},
+			},
+			value: cadence.NewArray([]cadence.Value{cadence.NewBool(true), cadence.NewBool(false)}),
+		},
+		{
+			name:  "storage path",
+			typ:   cadence.StoragePathType{},
+			value: cadence.Path{Domain: common.PathDomainStorage, Identifier: "vault"},
+		},
+		{
+			name: "recursive struct",
+			typ:  nodeType,
+			value: cadence.NewStruct([]cadence.Value{
+				cadence.UFix64(1_00000000),
+				cadence.NewOptional(
+					cadence.NewStruct([]cadence.Value{
+						cadence.UFix64(2_00000000),
+						cadence.NewOptional(nil),
+					}).WithType(nodeType),
+				),
+			}).WithType(nodeType),
+		},
+		{
+			name: "restricted resource",
+			typ: &cadence.RestrictedType{
+				Type:         vaultType,
+				Restrictions: []cadence.Type{},
+			},
+			value: cadence.NewResource([]cadence.Value{
+				cadence.NewUInt64(1),
+				cadence.Fix64(10_00000000),
+			}).WithType(vaultType),
+		},
+		{
+			name: "restricted AnyResource",
+			typ: &cadence.RestrictedType{
+				Type:         cadence.AnyResourceType{},
+				Restrictions: []cadence.Type{},
+			},
+			value: cadence.NewResource([]cadence.Value{
+				cadence.NewUInt64(1),
+				cadence.Fix64(10_00000000),
+			}).WithType(vaultType),
+		},
+		{
+			name: "event",
+			typ:  eventType,
+			value: cadence.NewEvent([]cadence.Value{
+				cadence.NewOptional(cadence.BytesToAddress([]byte{0x2})),
+				cadence.NewArray([]cadence.Value{
+					cadence.NewEnum([]cadence.Value{cadence.NewUInt8(1)}).WithType(kindType),
+				}),
+				cadence.NewDictionary([]cadence.KeyValuePair{
+					{Key: cadence.String("a"), Value: cadence.String("b")},
+					{Key: cadence.String("c"), Value: cadence.NewInt(1)},
+				}),
+				cadence.NewWord64(7),
+			}).WithType(eventType),
+		},
+	} {
+		test(testCase)
+	}
+}
+
+func TestValidateReportsAllViolations(t *testing.T) {
+
+	t.Parallel()
+
+	eventType := &cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "Deposit",
+		Fields: []cadence.Field{
+			{Identifier: "to", Type: &cadence.OptionalType{Type: cadence.AddressType{}}},
+			{Identifier: "amount", Type: cadence.UInt8Type{}},
+			{Identifier: "path", Type: cadence.PublicPathType{}},
+			{Identifier: "tags", Type: &cadence.VariableSizedArrayType{ElementType: cadence.StringType{}}},
+		},
+	}
+
+	// language=json
+	data := `
+      {
+        "type": "Event",
+        "value": {
+          "id": "S.test.Withdraw",
+          "fields": [
+            {"name": "to", "value": {"type": "Optional", "value": {"type": "Address", "value": "0x1"}}},
+            {"name": "amount", "value": {"type": "UInt8", "value": "-1"}},
+            {"name": "path", "value": {"type": "Path", "value": {"domain": "storage", "identifier": "x"}}},
+            {"name": "tags", "value": {"type": "Array", "value": [{"type": "String", "value": 1}], "extra": true}}
+          ]
+        }
+      }
+    `
+
+	err := json.Validate(eventType, []byte(data))
+	require.Error(t, err)
+
+	var validationErrors json.ValidationErrors
+	require.ErrorAs(t, err, &validationErrors)
+
+	assert.Equal(t,
+		json.ValidationErrors{
+			{
+				Pointer: "/value/fields/0/value/value/value",
+				Message: `"0x1" does not match pattern ^0x([0-9a-fA-F]{2}){0,8}$`,
+			},
+			{
+				Pointer: "/value/fields/1/value/value",
+				Message: `"-1" does not match pattern ^(0*(0|[1-9][0-9]{0,1}|1[0-9]{2}|2[0-4][0-9]|25[0-4]|255))$`,
+			},
+			{
+				Pointer: "/value/fields/2/value/value/domain",
+				Message: `expected "public", got "storage"`,
+			},
+			{
+				Pointer: "/value/fields/3/value/extra",
+				Message: "unexpected property",
+			},
+			{
+				Pointer: "/value/fields/3/value/value/0/value",
+				Message: "expected string, got number",
+			},
+			{
+				Pointer: "/value/id",
+				Message: `expected "S.test.Deposit", got "S.test.Withdraw"`,
+			},
+		},
+		validationErrors,
+	)
+}
+
+func TestValidateInvalidJSON(t *testing.T) {
+
+	t.Parallel()
+
+	err := json.Validate(cadence.IntType{}, []byte(`{"type": "Int",`))
+	require.Error(t, err)
+
+	var validationErrors json.ValidationErrors
+	require.False(t, errors.As(err, &validationErrors))
+}
+
+func TestValidateIntegerRanges(t *testing.T) {
+
+	t.Parallel()
+
+	type testCase struct {
+		typ     cadence.Type
+		valid   []string
+		invalid []string
+	}
+
+	for _, testCase := range []testCase{
+		{
+			typ:     cadence.UInt8Type{},
+			valid:   []string{"0", "9", "10", "99", "100", "199", "200", "249", "250", "255", "0255"},
+			invalid: []string{"256", "260", "300", "1000", "-1", "-0"},
+		},
+		{
+			typ:     cadence.Int8Type{},
+			valid:   []string{"-128", "-100", "-0", "0", "99", "127"},
+			invalid: []string{"-129", "128", "130", "200", "1000"},
+		},
+		{
+			typ:     cadence.Word16Type{},
+			valid:   []string{"0", "65535", "59999", "65499"},
+			invalid: []string{"65536", "65540", "66000", "70000", "100000"},
+		},
+		{
+			typ:     cadence.UInt64Type{},
+			valid:   []string{"0", "18446744073709551615", "9999999999999999999"},
+			invalid: []string{"18446744073709551616", "99999999999999999999", "100000000000000000000"},
+		},
+		{
+			typ:     cadence.Int64Type{},
+			valid:   []string{"-9223372036854775808", "9223372036854775807"},
+			invalid: []string{"-9223372036854775809", "9223372036854775808"},
+		},
+		{
+			typ: cadence.UInt256Type{},
+			valid: []string{
+				"115792089237316195423570985008687907853269984665640564039457584007913129639935",
+			},
+			invalid: []string{
+				"115792089237316195423570985008687907853269984665640564039457584007913129639936",
+				"1000000000000000000000000000000000000000000000000000000000000000000000000000000",
+			},
+		},
+		{
+			typ:     cadence.UIntType{},
+			valid:   []string{"0", "1000000000000000000000000000000000000000000000000000000000000000000000000000000"},
+			invalid: []string{"-1"},
+		},
+	} {
+		testCase := testCase
+
+		typeID := testCase.typ.ID()
+
+		t.Run(typeID, func(t *testing.T) {
+			t.Parallel()
+
+			validate := func(value string) error {
+				data := fmt.Sprintf(`{"type":%q,"value":%q}`, typeID, value)
+				return json.Validate(testCase.typ, []byte(data))
+			}
+
+			for _, value := range testCase.valid {
+				assert.NoError(t, validate(value), value)
+			}
+
+			for _, value := range testCase.invalid {
+				assert.Error(t, validate(value), value)
+			}
+		})
+	}
+}
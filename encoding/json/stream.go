@@ -0,0 +1,327 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+)
+
+// StreamLimits are the limits enforced by a StreamDecoder
+type StreamLimits struct {
+	// MaxDepth is the maximum nesting depth of JSON arrays and objects.
+	// Zero means no limit
+	MaxDepth int
+	// MaxSize is the maximum number of bytes read from the reader.
+	// Zero means no limit
+	MaxSize int64
+}
+
+// A StreamDecoder decodes JSON-encoded representations of Cadence values
+// token by token, instead of decoding the whole JSON document first.
+//
+// Values are decoded as soon as they are complete,
+// so only the JSON objects which are currently being decoded are kept in memory,
+// in addition to the decoded values.
+type StreamDecoder struct {
+	decoder *Decoder
+	tokens  *json.Decoder
+	limits  StreamLimits
+	depth   int
+}
+
+// NewStreamDecoder initializes a StreamDecoder that will decode JSON-encoded bytes from the
+// given io.Reader, enforcing the given limits.
+func NewStreamDecoder(
+	gauge common.MemoryGauge,
+	r io.Reader,
+	limits StreamLimits,
+	options ...Option,
+) *StreamDecoder {
+	if limits.MaxSize > 0 {
+		r = &sizeLimitedReader{
+			reader:    r,
+			remaining: limits.MaxSize,
+			limit:     limits.MaxSize,
+		}
+	}
+
+	decoder := &Decoder{
+		gauge: gauge,
+	}
+	for _, option := range options {
+		option(decoder)
+	}
+
+	return &StreamDecoder{
+		decoder: decoder,
+		tokens:  json.NewDecoder(r),
+		limits:  limits,
+	}
+}
+
+// decodedValue is a JSON-Cadence value which has already been decoded.
+// It is a placeholder for the value in the JSON objects and arrays that are still being decoded.
+type decodedValue struct {
+	value cadence.Value
+}
+
+// The properties of JSON-Cadence values which contain static types.
+// Static types are not JSON-Cadence values, even though they may have a `type` property
+var staticTypeKeys = map[string]struct{}{
+	staticTypeKey:   {},
+	borrowTypeKey:   {},
+	functionTypeKey: {},
+}
+
+// Decode reads the next JSON-encoded value from the io.Reader and decodes it to a
+// Cadence value.
+//
+// This function returns an error if the bytes represent JSON that is malformed,
+// does not conform to the JSON Cadence specification, or exceeds the limits.
+func (d *StreamDecoder) Decode() (value cadence.Value, err error) {
+	// capture panics that occur during decoding
+	defer func() {
+		if r := recover(); r != nil {
+			err = d.recoveredError(r)
+		}
+	}()
+
+	return d.decoder.decodeJSON(d.readValue(false)), nil
+}
+
+// DecodeArrayElements reads the next JSON-encoded value from the io.Reader,
+// which must be an array, and decodes its elements one at a time.
+// The given function is called with each element, in order, and the array is never materialized.
+// If the function returns an error, decoding stops and the error is returned.
+//
+// If the `value` property of the array precedes its `type` property, like in values encoded by Encode,
+// the type of the array is only checked after all elements have been decoded.
+func (d *StreamDecoder) DecodeArrayElements(f func(element cadence.Value) error) (err error) {
+	// capture panics that occur during decoding
+	defer func() {
+		if r := recover(); r != nil {
+			err = d.recoveredError(r)
+		}
+	}()
+
+	d.expectDelim('{')
+	d.enter()
+
+	var hasType, hasValue bool
+
+	for d.tokens.More() {
+		key := d.readKey()
+
+		switch key {
+		case typeKey:
+			typeStr := toString(d.readValue(false))
+			if typeStr != arrayTypeStr {
+				panic(errors.NewDefaultUserError("expected JSON-Cadence array, got %s", typeStr))
+			}
+			hasType = true
+
+		case valueKey:
+			d.expectDelim('[')
+			d.enter()
+
+			for d.tokens.More() {
+				element := d.decoder.decodeJSON(d.readValue(false))
+				err := f(element)
+				if err != nil {
+					return err
+				}
+			}
+
+			d.expectDelim(']')
+			d.exit()
+			hasValue = true
+
+		default:
+			panic(errors.NewDefaultUserError("unexpected property in JSON-Cadence array: %s", key))
+		}
+	}
+
+	d.expectDelim('}')
+	d.exit()
+
+	if !hasType {
+		panic(errors.NewDefaultUserError("missing property: %s", typeKey))
+	}
+	if !hasValue {
+		panic(errors.NewDefaultUserError("missing property: %s", valueKey))
+	}
+
+	return nil
+}
+
+func (d *StreamDecoder) recoveredError(r any) error {
+	panicErr, isError := r.(error)
+	if !isError {
+		panic(r)
+	}
+
+	if _, ok := panicErr.(jsonTokenError); ok {
+		return errors.NewDefaultUserError("failed to decode JSON: %w", panicErr)
+	}
+
+	return errors.NewDefaultUserError("failed to decode JSON-Cadence value: %w", panicErr)
+}
+
+// jsonTokenError is an error reading a token,
+// i.e. the input is malformed JSON, or exceeds the size limit
+type jsonTokenError struct {
+	err error
+}
+
+func (e jsonTokenError) Error() string {
+	return e.err.Error()
+}
+
+func (e jsonTokenError) Unwrap() error {
+	return e.err
+}
+
+func (d *StreamDecoder) token() json.Token {
+	token, err := d.tokens.Token()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		panic(jsonTokenError{err: err})
+	}
+	return token
+}
+
+func (d *StreamDecoder) expectDelim(delim json.Delim) {
+	token := d.token()
+	if token != delim {
+		panic(errors.NewDefaultUserError("expected JSON %s, got %v", delim, token))
+	}
+}
+
+func (d *StreamDecoder) readKey() string {
+	// object keys are always strings, the JSON decoder ensures this
+	return d.token().(string)
+}
+
+func (d *StreamDecoder) enter() {
+	d.depth++
+	if d.limits.MaxDepth > 0 && d.depth > d.limits.MaxDepth {
+		panic(errors.NewDefaultUserError("maximum depth of %d exceeded", d.limits.MaxDepth))
+	}
+}
+
+func (d *StreamDecoder) exit() {
+	d.depth--
+}
+
+// readValue reads the next JSON value.
+//
+// JSON-Cadence values are decoded as soon as they are read,
+// and are returned as decodedValue.
+// Raw values are static types, which are returned as-is.
+func (d *StreamDecoder) readValue(raw bool) any {
+	token := d.token()
+
+	switch token {
+	case json.Delim('{'):
+		return d.readObject(raw)
+	case json.Delim('['):
+		return d.readArray(raw)
+	}
+
+	return token
+}
+
+func (d *StreamDecoder) readObject(raw bool) any {
+	d.enter()
+
+	obj := map[string]any{}
+
+	for d.tokens.More() {
+		key := d.readKey()
+		_, isStaticType := staticTypeKeys[key]
+		obj[key] = d.readValue(raw || isStaticType)
+	}
+
+	d.expectDelim('}')
+	d.exit()
+
+	// Only objects with a type are JSON-Cadence values.
+	// Other objects, like dictionary entries and composite fields, are part of the enclosing value
+
+	if raw {
+		return obj
+	}
+
+	if _, ok := obj[typeKey].(string); !ok {
+		return obj
+	}
+
+	return decodedValue{
+		value: d.decoder.decodeJSON(obj),
+	}
+}
+
+func (d *StreamDecoder) readArray(raw bool) []any {
+	d.enter()
+
+	elements := make([]any, 0)
+
+	for d.tokens.More() {
+		elements = append(elements, d.readValue(raw))
+	}
+
+	d.expectDelim(']')
+	d.exit()
+
+	return elements
+}
+
+// sizeLimitedReader is an io.Reader which fails when more than the given number of bytes are read
+type sizeLimitedReader struct {
+	reader    io.Reader
+	remaining int64
+	limit     int64
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		// Only fail if there actually is more data
+		var probe [1]byte
+		n, err := r.reader.Read(probe[:])
+		if n > 0 {
+			return 0, errors.NewDefaultUserError("maximum size of %d bytes exceeded", r.limit)
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
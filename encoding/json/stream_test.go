@@ -0,0 +1,283 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/tests/utils"
+)
+
+type testMemoryGauge struct {
+	meter map[common.MemoryKind]uint64
+}
+
+func newTestMemoryGauge() *testMemoryGauge {
+	return &testMemoryGauge{
+		meter: make(map[common.MemoryKind]uint64),
+	}
+}
+
+func (g *testMemoryGauge) MeterMemory(usage common.MemoryUsage) error {
+	g.meter[usage.Kind] += usage.Amount
+	return nil
+}
+
+func TestStreamDecode(t *testing.T) {
+
+	t.Parallel()
+
+	structType := &cadence.StructType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "Metadata",
+		Fields: []cadence.Field{
+			{Identifier: "name", Type: cadence.StringType{}},
+			{Identifier: "tags", Type: &cadence.DictionaryType{
+				KeyType:     cadence.StringType{},
+				ElementType: cadence.UFix64Type{},
+			}},
+		},
+	}
+
+	values := map[string]cadence.Value{
+		"Void":     cadence.NewVoid(),
+		"Int":      cadence.NewInt(-42),
+		"Optional": cadence.NewOptional(cadence.NewOptional(nil)),
+		"Array": cadence.NewArray([]cadence.Value{
+			cadence.String("a"),
+			cadence.NewArray([]cadence.Value{}),
+		}),
+		"Struct": cadence.NewStruct([]cadence.Value{
+			cadence.String("test"),
+			cadence.NewDictionary([]cadence.KeyValuePair{
+				{Key: cadence.String("a"), Value: cadence.UFix64(1_00000000)},
+			}),
+		}).WithType(structType),
+		"Path": cadence.Path{
+			Domain:     common.PathDomainStorage,
+			Identifier: "vault",
+		},
+		"Type": cadence.NewTypeValue(&cadence.RestrictedType{
+			Type:         structType,
+			Restrictions: []cadence.Type{},
+		}),
+		"Capability": cadence.NewPathCapability(
+			cadence.BytesToAddress([]byte{0x1}),
+			cadence.Path{
+				Domain:     common.PathDomainPublic,
+				Identifier: "vault",
+			},
+			&cadence.ReferenceType{Type: structType},
+		),
+	}
+
+	for name, value := range values {
+		value := value
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := json.Encode(value)
+			require.NoError(t, err)
+
+			expectedGauge := newTestMemoryGauge()
+			expected, err := json.Decode(expectedGauge, data)
+			require.NoError(t, err)
+
+			actualGauge := newTestMemoryGauge()
+			decoder := json.NewStreamDecoder(actualGauge, bytes.NewReader(data), json.StreamLimits{})
+			actual, err := decoder.Decode()
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, actual)
+			assert.Equal(t, expectedGauge.meter, actualGauge.meter)
+		})
+	}
+}
+
+func TestStreamDecodeTypeBeforeValue(t *testing.T) {
+
+	t.Parallel()
+
+	// language=json
+	data := `
+      {"type": "Dictionary", "value": [
+        {"key": {"type": "String", "value": "a"}, "value": {"type": "Array", "value": [{"type": "Bool", "value": true}]}}
+      ]}
+      {"type": "Int8", "value": "1"}
+    `
+
+	decoder := json.NewStreamDecoder(nil, strings.NewReader(data), json.StreamLimits{})
+
+	value, err := decoder.Decode()
+	require.NoError(t, err)
+	assert.Equal(t,
+		cadence.NewDictionary([]cadence.KeyValuePair{
+			{
+				Key:   cadence.String("a"),
+				Value: cadence.NewArray([]cadence.Value{cadence.NewBool(true)}),
+			},
+		}),
+		value,
+	)
+
+	value, err = decoder.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, cadence.NewInt8(1), value)
+}
+
+func TestStreamDecodeInvalid(t *testing.T) {
+
+	t.Parallel()
+
+	for name, data := range map[string]string{
+		"malformed JSON":      `{"type": "Int", "value": `,
+		"invalid integer":     `{"type": "Int8", "value": "1000"}`,
+		"missing type":        `{"value": "1"}`,
+		"invalid array":       `{"type": "Array", "value": [{"key": {"type": "Int", "value": "1"}}]}`,
+		"invalid composite":   `{"type": "Struct", "value": {"id": "S.test.S", "fields": [{"value": {"type": "Int", "value": "1"}}]}}`,
+		"unexpected property": `{"type": "Int", "value": "1", "extra": true}`,
+	} {
+		data := data
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			decoder := json.NewStreamDecoder(nil, strings.NewReader(data), json.StreamLimits{})
+			_, err := decoder.Decode()
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestStreamDecodeLimits(t *testing.T) {
+
+	t.Parallel()
+
+	value := cadence.NewArray([]cadence.Value{
+		cadence.NewArray([]cadence.Value{
+			cadence.NewArray([]cadence.Value{
+				cadence.NewInt(1),
+			}),
+		}),
+	})
+
+	data, err := json.Encode(value)
+	require.NoError(t, err)
+
+	// Each JSON-Cadence array is an object containing an array
+
+	decode := func(limits json.StreamLimits) error {
+		decoder := json.NewStreamDecoder(nil, bytes.NewReader(data), limits)
+		_, err := decoder.Decode()
+		return err
+	}
+
+	t.Run("depth", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, decode(json.StreamLimits{MaxDepth: 7}))
+
+		err := decode(json.StreamLimits{MaxDepth: 6})
+		require.ErrorContains(t, err, "maximum depth of 6 exceeded")
+	})
+
+	t.Run("size", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, decode(json.StreamLimits{MaxSize: int64(len(data))}))
+
+		err := decode(json.StreamLimits{MaxSize: int64(len(data)) - 2})
+		require.ErrorContains(t, err, "maximum size of")
+	})
+}
+
+func TestStreamDecodeArrayElements(t *testing.T) {
+
+	t.Parallel()
+
+	const count = 1000
+
+	elements := make([]cadence.Value, count)
+	for i := range elements {
+		elements[i] = cadence.NewArray([]cadence.Value{
+			cadence.NewUInt64(uint64(i)),
+		})
+	}
+
+	data, err := json.Encode(cadence.NewArray(elements))
+	require.NoError(t, err)
+
+	t.Run("all elements", func(t *testing.T) {
+		t.Parallel()
+
+		decoder := json.NewStreamDecoder(nil, bytes.NewReader(data), json.StreamLimits{})
+
+		var decoded []cadence.Value
+		err := decoder.DecodeArrayElements(func(element cadence.Value) error {
+			decoded = append(decoded, element)
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, elements, decoded)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		t.Parallel()
+
+		decoder := json.NewStreamDecoder(nil, bytes.NewReader(data), json.StreamLimits{})
+
+		stopErr := errors.New("stop")
+
+		decoded := 0
+		err := decoder.DecodeArrayElements(func(element cadence.Value) error {
+			decoded++
+			if decoded == 10 {
+				return stopErr
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, stopErr)
+		assert.Equal(t, 10, decoded)
+	})
+
+	t.Run("not an array", func(t *testing.T) {
+		t.Parallel()
+
+		// language=json
+		data := `{"type": "Dictionary", "value": []}`
+
+		decoder := json.NewStreamDecoder(nil, strings.NewReader(data), json.StreamLimits{})
+
+		err := decoder.DecodeArrayElements(func(element cadence.Value) error {
+			return nil
+		})
+		require.ErrorContains(t, err, "expected JSON-Cadence array, got Dictionary")
+	})
+}
@@ -0,0 +1,1127 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+// Marshaler is implemented by Go types which can marshal themselves into a Cadence value.
+type Marshaler interface {
+	MarshalCadence() (Value, error)
+}
+
+// Unmarshaler is implemented by Go types which can unmarshal a Cadence value into themselves.
+type Unmarshaler interface {
+	UnmarshalCadence(value Value) error
+}
+
+// CompositeTyped is implemented by Go struct types which want to be marshaled
+// into a specific composite, e.g. an event or a resource, instead of a plain struct.
+//
+// The method is called on the zero value of the struct type.
+// If the returned type has no fields, Marshal sets them from the struct's tagged fields.
+type CompositeTyped interface {
+	CadenceCompositeType() CompositeType
+}
+
+// MarshalTypeError is returned by Marshal when a Go value cannot be represented as a Cadence value.
+type MarshalTypeError struct {
+	GoType reflect.Type
+	Reason string
+	Path   string
+}
+
+var _ error = &MarshalTypeError{}
+
+func (e *MarshalTypeError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("cadence: cannot marshal Go value of type ")
+	sb.WriteString(e.GoType.String())
+	if e.Path != "" {
+		sb.WriteString(" at ")
+		sb.WriteString(e.Path)
+	}
+	if e.Reason != "" {
+		sb.WriteString(": ")
+		sb.WriteString(e.Reason)
+	}
+	return sb.String()
+}
+
+// UnmarshalTypeError is returned by Unmarshal when a Cadence value cannot be stored in a Go value.
+type UnmarshalTypeError struct {
+	GoType reflect.Type
+	Value  TypeID
+	Reason string
+	Path   string
+}
+
+var _ error = &UnmarshalTypeError{}
+
+func (e *UnmarshalTypeError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("cadence: cannot unmarshal ")
+	sb.WriteString(string(e.Value))
+	sb.WriteString(" into Go value of type ")
+	sb.WriteString(e.GoType.String())
+	if e.Path != "" {
+		sb.WriteString(" at ")
+		sb.WriteString(e.Path)
+	}
+	if e.Reason != "" {
+		sb.WriteString(": ")
+		sb.WriteString(e.Reason)
+	}
+	return sb.String()
+}
+
+var (
+	valueType         = reflect.TypeOf((*Value)(nil)).Elem()
+	marshalerType     = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	unmarshalerType   = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	compositeTypedTyp = reflect.TypeOf((*CompositeTyped)(nil)).Elem()
+	bigIntType        = reflect.TypeOf(big.Int{})
+	commonAddressType = reflect.TypeOf(common.Address{})
+)
+
+// marshalIntegerTypes are the integer types which can be used as a type override in a struct tag,
+// mapped to the sema type which provides the range of the type.
+var marshalIntegerTypes = map[string]*sema.NumericType{
+	sema.IntTypeName:     sema.IntType,
+	sema.Int8TypeName:    sema.Int8Type,
+	sema.Int16TypeName:   sema.Int16Type,
+	sema.Int32TypeName:   sema.Int32Type,
+	sema.Int64TypeName:   sema.Int64Type,
+	sema.Int128TypeName:  sema.Int128Type,
+	sema.Int256TypeName:  sema.Int256Type,
+	sema.UIntTypeName:    sema.UIntType,
+	sema.UInt8TypeName:   sema.UInt8Type,
+	sema.UInt16TypeName:  sema.UInt16Type,
+	sema.UInt32TypeName:  sema.UInt32Type,
+	sema.UInt64TypeName:  sema.UInt64Type,
+	sema.UInt128TypeName: sema.UInt128Type,
+	sema.UInt256TypeName: sema.UInt256Type,
+	sema.Word8TypeName:   sema.Word8Type,
+	sema.Word16TypeName:  sema.Word16Type,
+	sema.Word32TypeName:  sema.Word32Type,
+	sema.Word64TypeName:  sema.Word64Type,
+	sema.Word128TypeName: sema.Word128Type,
+	sema.Word256TypeName: sema.Word256Type,
+}
+
+// marshalOverrideTypes are all types which can be used as a type override in a struct tag
+var marshalOverrideTypes = map[string]Type{
+	sema.IntTypeName:       TheIntType,
+	sema.Int8TypeName:      TheInt8Type,
+	sema.Int16TypeName:     TheInt16Type,
+	sema.Int32TypeName:     TheInt32Type,
+	sema.Int64TypeName:     TheInt64Type,
+	sema.Int128TypeName:    TheInt128Type,
+	sema.Int256TypeName:    TheInt256Type,
+	sema.UIntTypeName:      TheUIntType,
+	sema.UInt8TypeName:     TheUInt8Type,
+	sema.UInt16TypeName:    TheUInt16Type,
+	sema.UInt32TypeName:    TheUInt32Type,
+	sema.UInt64TypeName:    TheUInt64Type,
+	sema.UInt128TypeName:   TheUInt128Type,
+	sema.UInt256TypeName:   TheUInt256Type,
+	sema.Word8TypeName:     TheWord8Type,
+	sema.Word16TypeName:    TheWord16Type,
+	sema.Word32TypeName:    TheWord32Type,
+	sema.Word64TypeName:    TheWord64Type,
+	sema.Word128TypeName:   TheWord128Type,
+	sema.Word256TypeName:   TheWord256Type,
+	sema.Fix64TypeName:     TheFix64Type,
+	sema.UFix64TypeName:    TheUFix64Type,
+	sema.StringType.Name:   TheStringType,
+	sema.CharacterTypeName: TheCharacterType,
+	sema.PathType.Name:     ThePathType,
+}
+
+// structField is a Go struct field which has a `cadence` tag.
+//
+// The tag has the form `cadence:"name"` or `cadence:"name,Type"`,
+// where the optional type overrides the Cadence type of the field,
+// e.g. `cadence:"amount,UFix64"` for an uint64 holding the raw UFix64 value.
+// For pointers, slices, arrays and maps the override applies to the elements.
+type structField struct {
+	index    int
+	name     string
+	override string
+}
+
+func structFields(t reflect.Type) ([]structField, error) {
+	var fields []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, override, ok := parseStructFieldTag(field)
+		if !ok {
+			continue
+		}
+
+		if !field.IsExported() {
+			return nil, fmt.Errorf("field %s is tagged but not exported", field.Name)
+		}
+
+		if override != "" {
+			if _, ok := marshalOverrideTypes[override]; !ok {
+				return nil, fmt.Errorf("field %s has unsupported type override %s", field.Name, override)
+			}
+		}
+
+		fields = append(fields, structField{
+			index:    i,
+			name:     name,
+			override: override,
+		})
+	}
+
+	return fields, nil
+}
+
+// parseStructFieldTag returns the Cadence field name and the type override
+// of the `cadence` tag of the given struct field.
+// It returns false if the field has no tag, an empty tag, or the tag "-".
+// If the tag has no name, e.g. `cadence:",UFix64"`, the name of the struct field is used.
+//
+// The tag is parsed the same way by Marshal, Unmarshal, and DecodeFields,
+// so one tag works with all of them.
+func parseStructFieldTag(field reflect.StructField) (name string, override string, ok bool) {
+	tag := field.Tag.Get("cadence")
+	if tag == "" || tag == "-" {
+		return "", "", false
+	}
+
+	name, override, _ = strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, override, true
+}
+
+func appendFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// Marshal returns the Cadence value for the given Go value.
+//
+// Booleans, strings, Go integers, *big.Int, common.Address and Cadence values are supported.
+// Pointers are marshaled to optionals, slices and arrays to arrays, and maps to dictionaries.
+// Structs are marshaled to composites, using the fields which have a `cadence` struct tag,
+// see CompositeTyped for how to choose the kind and type of the composite.
+// Types implementing Marshaler marshal themselves.
+func Marshal(v any) (Value, error) {
+	m := &marshaler{
+		compositeTypes: map[reflect.Type]CompositeType{},
+	}
+	return m.marshal(reflect.ValueOf(v), "", "")
+}
+
+type marshaler struct {
+	compositeTypes map[reflect.Type]CompositeType
+}
+
+func (m *marshaler) marshal(v reflect.Value, override string, path string) (Value, error) {
+	if !v.IsValid() {
+		return NewOptional(nil), nil
+	}
+
+	t := v.Type()
+
+	switch {
+	case t.Kind() == reflect.Interface:
+		if v.IsNil() {
+			return NewOptional(nil), nil
+		}
+		return m.marshal(v.Elem(), override, path)
+
+	case implementsDirectly(t, valueType):
+		if t.Kind() == reflect.Pointer && v.IsNil() {
+			return NewOptional(nil), nil
+		}
+		return v.Interface().(Value), nil
+
+	case implementsDirectly(t, marshalerType):
+		if t.Kind() == reflect.Pointer && v.IsNil() {
+			return NewOptional(nil), nil
+		}
+		value, err := v.Interface().(Marshaler).MarshalCadence()
+		if err != nil {
+			return nil, &MarshalTypeError{
+				GoType: t,
+				Path:   path,
+				Reason: err.Error(),
+			}
+		}
+		return value, nil
+
+	case t == commonAddressType:
+		return Address(v.Interface().(common.Address)), nil
+	}
+
+	if override != "" && t.Kind() != reflect.Pointer {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			// The override applies to the elements
+		default:
+			return m.marshalOverride(v, override, path)
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return NewBool(v.Bool()), nil
+
+	case reflect.String:
+		value, err := NewString(v.String())
+		if err != nil {
+			return nil, &MarshalTypeError{
+				GoType: t,
+				Path:   path,
+				Reason: err.Error(),
+			}
+		}
+		return value, nil
+
+	case reflect.Int:
+		return NewIntFromBig(big.NewInt(v.Int())), nil
+	case reflect.Int8:
+		return NewInt8(int8(v.Int())), nil
+	case reflect.Int16:
+		return NewInt16(int16(v.Int())), nil
+	case reflect.Int32:
+		return NewInt32(int32(v.Int())), nil
+	case reflect.Int64:
+		return NewInt64(v.Int()), nil
+
+	case reflect.Uint:
+		return NewUIntFromBig(new(big.Int).SetUint64(v.Uint()))
+	case reflect.Uint8:
+		return NewUInt8(uint8(v.Uint())), nil
+	case reflect.Uint16:
+		return NewUInt16(uint16(v.Uint())), nil
+	case reflect.Uint32:
+		return NewUInt32(uint32(v.Uint())), nil
+	case reflect.Uint64:
+		return NewUInt64(v.Uint()), nil
+
+	case reflect.Pointer:
+		if t.Elem() == bigIntType {
+			if v.IsNil() {
+				return nil, &MarshalTypeError{
+					GoType: t,
+					Path:   path,
+					Reason: "nil big integer",
+				}
+			}
+			return m.marshal(v.Elem(), override, path)
+		}
+		if v.IsNil() {
+			return NewOptional(nil), nil
+		}
+		value, err := m.marshal(v.Elem(), override, path)
+		if err != nil {
+			return nil, err
+		}
+		return NewOptional(value), nil
+
+	case reflect.Slice, reflect.Array:
+		arrayType, err := m.staticType(t, override, path)
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]Value, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values[i], err = m.marshal(v.Index(i), override, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+		}
+		return NewArray(values).WithType(arrayType.(ArrayType)), nil
+
+	case reflect.Map:
+		dictionaryType, err := m.staticType(t, override, path)
+		if err != nil {
+			return nil, err
+		}
+
+		pairs := make([]KeyValuePair, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, err := m.marshal(iter.Key(), "", path)
+			if err != nil {
+				return nil, err
+			}
+			value, err := m.marshal(iter.Value(), override, fmt.Sprintf("%s[%s]", path, key))
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, KeyValuePair{
+				Key:   key,
+				Value: value,
+			})
+		}
+
+		// Go maps are unordered, sort the pairs so the result is deterministic
+		sort.Slice(pairs, func(i, j int) bool {
+			return pairs[i].Key.String() < pairs[j].Key.String()
+		})
+
+		return NewDictionary(pairs).WithType(dictionaryType.(*DictionaryType)), nil
+
+	case reflect.Struct:
+		if t == bigIntType {
+			i := v.Interface().(big.Int)
+			return NewIntFromBig(new(big.Int).Set(&i)), nil
+		}
+		return m.marshalStruct(v, path)
+	}
+
+	return nil, &MarshalTypeError{
+		GoType: t,
+		Path:   path,
+		Reason: "unsupported type",
+	}
+}
+
+func (m *marshaler) marshalStruct(v reflect.Value, path string) (Value, error) {
+	t := v.Type()
+
+	staticType, err := m.compositeType(t, path)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := structFields(t)
+	if err != nil {
+		return nil, &MarshalTypeError{
+			GoType: t,
+			Path:   path,
+			Reason: err.Error(),
+		}
+	}
+
+	values := make([]Value, len(fields))
+	for i, field := range fields {
+		values[i], err = m.marshal(
+			v.Field(field.index),
+			field.override,
+			appendFieldPath(path, field.name),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch staticType := staticType.(type) {
+	case *StructType:
+		return NewStruct(values).WithType(staticType), nil
+	case *ResourceType:
+		return NewResource(values).WithType(staticType), nil
+	case *EventType:
+		return NewEvent(values).WithType(staticType), nil
+	case *ContractType:
+		return NewContract(values).WithType(staticType), nil
+	case *EnumType:
+		return NewEnum(values).WithType(staticType), nil
+	case *AttachmentType:
+		return NewAttachment(values).WithType(staticType), nil
+	}
+
+	return nil, &MarshalTypeError{
+		GoType: t,
+		Path:   path,
+		Reason: fmt.Sprintf("unsupported composite type %T", staticType),
+	}
+}
+
+func (m *marshaler) marshalOverride(v reflect.Value, override string, path string) (Value, error) {
+	t := v.Type()
+
+	newError := func(reason string) error {
+		return &MarshalTypeError{
+			GoType: t,
+			Path:   path,
+			Reason: fmt.Sprintf("cannot marshal as %s: %s", override, reason),
+		}
+	}
+
+	switch override {
+	case sema.StringType.Name, sema.CharacterTypeName, sema.PathType.Name:
+		if t.Kind() != reflect.String {
+			return nil, newError("not a string")
+		}
+		s := v.String()
+
+		var value Value
+		var err error
+		switch override {
+		case sema.StringType.Name:
+			value, err = NewString(s)
+		case sema.CharacterTypeName:
+			value, err = NewCharacter(s)
+		default:
+			value, err = parsePath(s)
+		}
+		if err != nil {
+			return nil, newError(err.Error())
+		}
+		return value, nil
+
+	case sema.Fix64TypeName, sema.UFix64TypeName:
+		// Strings are decimal representations, e.g. "1.5",
+		// integers are raw values, e.g. 1_50000000
+		if t.Kind() == reflect.String {
+			var value Value
+			var err error
+			if override == sema.Fix64TypeName {
+				value, err = NewFix64(v.String())
+			} else {
+				value, err = NewUFix64(v.String())
+			}
+			if err != nil {
+				return nil, newError(err.Error())
+			}
+			return value, nil
+		}
+
+		i, ok := goIntegerToBig(v)
+		if !ok {
+			return nil, newError("not an integer or string")
+		}
+		if override == sema.Fix64TypeName {
+			if !i.IsInt64() {
+				return nil, newError("value out of range")
+			}
+			return Fix64(i.Int64()), nil
+		}
+		if !i.IsUint64() {
+			return nil, newError("value out of range")
+		}
+		return UFix64(i.Uint64()), nil
+	}
+
+	i, ok := goIntegerToBig(v)
+	if !ok {
+		return nil, newError("not an integer")
+	}
+	value, err := newIntegerFromBig(override, i)
+	if err != nil {
+		return nil, newError(err.Error())
+	}
+	return value, nil
+}
+
+// staticType returns the Cadence type of the values marshaled from the given Go type.
+func (m *marshaler) staticType(t reflect.Type, override string, path string) (Type, error) {
+	switch {
+	case t.Kind() == reflect.Interface:
+		return TheAnyStructType, nil
+
+	case implementsDirectly(t, valueType):
+		value := reflect.Zero(t).Interface().(Value)
+		switch value.(type) {
+		case NumberValue, Bool, String, Character, Address:
+			return value.Type(), nil
+		case Path:
+			return ThePathType, nil
+		}
+		return TheAnyStructType, nil
+
+	case implementsDirectly(t, marshalerType):
+		// Types marshaling themselves into composites, e.g. enums,
+		// must provide the complete type, including the fields
+		if t.Implements(compositeTypedTyp) {
+			compositeType := reflect.Zero(t).Interface().(CompositeTyped).CadenceCompositeType()
+			if compositeType.CompositeFields() != nil {
+				return compositeType, nil
+			}
+		}
+		return TheAnyStructType, nil
+
+	case t == commonAddressType:
+		return TheAddressType, nil
+
+	case t == bigIntType:
+		if override != "" {
+			return marshalOverrideTypes[override], nil
+		}
+		return TheIntType, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if t.Elem() == bigIntType {
+			return m.staticType(t.Elem(), override, path)
+		}
+		elementType, err := m.staticType(t.Elem(), override, path)
+		if err != nil {
+			return nil, err
+		}
+		return NewOptionalType(elementType), nil
+
+	case reflect.Slice:
+		elementType, err := m.staticType(t.Elem(), override, path)
+		if err != nil {
+			return nil, err
+		}
+		return NewVariableSizedArrayType(elementType), nil
+
+	case reflect.Array:
+		elementType, err := m.staticType(t.Elem(), override, path)
+		if err != nil {
+			return nil, err
+		}
+		return NewConstantSizedArrayType(uint(t.Len()), elementType), nil
+
+	case reflect.Map:
+		keyType, err := m.staticType(t.Key(), "", path)
+		if err != nil {
+			return nil, err
+		}
+		elementType, err := m.staticType(t.Elem(), override, path)
+		if err != nil {
+			return nil, err
+		}
+		return NewDictionaryType(keyType, elementType), nil
+
+	case reflect.Struct:
+		return m.compositeType(t, path)
+	}
+
+	if override != "" {
+		return marshalOverrideTypes[override], nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return TheBoolType, nil
+	case reflect.String:
+		return TheStringType, nil
+	case reflect.Int:
+		return TheIntType, nil
+	case reflect.Int8:
+		return TheInt8Type, nil
+	case reflect.Int16:
+		return TheInt16Type, nil
+	case reflect.Int32:
+		return TheInt32Type, nil
+	case reflect.Int64:
+		return TheInt64Type, nil
+	case reflect.Uint:
+		return TheUIntType, nil
+	case reflect.Uint8:
+		return TheUInt8Type, nil
+	case reflect.Uint16:
+		return TheUInt16Type, nil
+	case reflect.Uint32:
+		return TheUInt32Type, nil
+	case reflect.Uint64:
+		return TheUInt64Type, nil
+	}
+
+	return nil, &MarshalTypeError{
+		GoType: t,
+		Path:   path,
+		Reason: "unsupported type",
+	}
+}
+
+// compositeType returns the composite type for the given Go struct type.
+// The type is registered before its fields are determined, so recursive types are supported.
+func (m *marshaler) compositeType(t reflect.Type, path string) (CompositeType, error) {
+	if compositeType, ok := m.compositeTypes[t]; ok {
+		return compositeType, nil
+	}
+
+	var compositeType CompositeType
+	if t.Implements(compositeTypedTyp) {
+		compositeType = reflect.Zero(t).Interface().(CompositeTyped).CadenceCompositeType()
+	} else if reflect.PointerTo(t).Implements(compositeTypedTyp) {
+		compositeType = reflect.New(t).Interface().(CompositeTyped).CadenceCompositeType()
+	} else {
+		compositeType = &StructType{
+			QualifiedIdentifier: t.Name(),
+		}
+	}
+
+	m.compositeTypes[t] = compositeType
+
+	if compositeType.CompositeFields() != nil {
+		return compositeType, nil
+	}
+
+	fields, err := structFields(t)
+	if err != nil {
+		return nil, &MarshalTypeError{
+			GoType: t,
+			Path:   path,
+			Reason: err.Error(),
+		}
+	}
+
+	compositeFields := make([]Field, len(fields))
+	for i, field := range fields {
+		fieldType, err := m.staticType(
+			t.Field(field.index).Type,
+			field.override,
+			appendFieldPath(path, field.name),
+		)
+		if err != nil {
+			return nil, err
+		}
+		compositeFields[i] = NewField(field.name, fieldType)
+	}
+
+	compositeType.SetCompositeFields(compositeFields)
+
+	return compositeType, nil
+}
+
+// implementsDirectly returns true if the given type implements the given interface,
+// and is not a pointer to a type that implements it.
+// Pointers to such types are marshaled as optionals.
+func implementsDirectly(t reflect.Type, iface reflect.Type) bool {
+	if !t.Implements(iface) {
+		return false
+	}
+	return t.Kind() != reflect.Pointer || !t.Elem().Implements(iface)
+}
+
+func parsePath(s string) (Path, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 3 || parts[0] != "" || parts[2] == "" {
+		return Path{}, fmt.Errorf("invalid path %q", s)
+	}
+	return NewPath(common.PathDomainFromIdentifier(parts[1]), parts[2])
+}
+
+func goIntegerToBig(v reflect.Value) (*big.Int, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(v.Uint()), true
+	case reflect.Pointer:
+		if v.Type().Elem() == bigIntType && !v.IsNil() {
+			return new(big.Int).Set(v.Interface().(*big.Int)), true
+		}
+	case reflect.Struct:
+		if v.Type() == bigIntType {
+			i := v.Interface().(big.Int)
+			return new(big.Int).Set(&i), true
+		}
+	}
+	return nil, false
+}
+
+// newIntegerFromBig returns the value of the integer type with the given name,
+// or an error if the integer is out of range of the type.
+func newIntegerFromBig(typeName string, i *big.Int) (Value, error) {
+	numericType, ok := marshalIntegerTypes[typeName]
+	if !ok {
+		return nil, fmt.Errorf("%s is not an integer type", typeName)
+	}
+
+	if min := numericType.MinInt(); min != nil && i.Cmp(min) < 0 {
+		return nil, fmt.Errorf("value exceeds min of %s", typeName)
+	}
+	if max := numericType.MaxInt(); max != nil && i.Cmp(max) > 0 {
+		return nil, fmt.Errorf("value exceeds max of %s", typeName)
+	}
+
+	switch typeName {
+	case sema.IntTypeName:
+		return NewIntFromBig(i), nil
+	case sema.Int8TypeName:
+		return NewInt8(int8(i.Int64())), nil
+	case sema.Int16TypeName:
+		return NewInt16(int16(i.Int64())), nil
+	case sema.Int32TypeName:
+		return NewInt32(int32(i.Int64())), nil
+	case sema.Int64TypeName:
+		return NewInt64(i.Int64()), nil
+	case sema.Int128TypeName:
+		return NewInt128FromBig(i)
+	case sema.Int256TypeName:
+		return NewInt256FromBig(i)
+	case sema.UIntTypeName:
+		return NewUIntFromBig(i)
+	case sema.UInt8TypeName:
+		return NewUInt8(uint8(i.Uint64())), nil
+	case sema.UInt16TypeName:
+		return NewUInt16(uint16(i.Uint64())), nil
+	case sema.UInt32TypeName:
+		return NewUInt32(uint32(i.Uint64())), nil
+	case sema.UInt64TypeName:
+		return NewUInt64(i.Uint64()), nil
+	case sema.UInt128TypeName:
+		return NewUInt128FromBig(i)
+	case sema.UInt256TypeName:
+		return NewUInt256FromBig(i)
+	case sema.Word8TypeName:
+		return NewWord8(uint8(i.Uint64())), nil
+	case sema.Word16TypeName:
+		return NewWord16(uint16(i.Uint64())), nil
+	case sema.Word32TypeName:
+		return NewWord32(uint32(i.Uint64())), nil
+	case sema.Word64TypeName:
+		return NewWord64(i.Uint64()), nil
+	case sema.Word128TypeName:
+		return NewWord128FromBig(i)
+	default:
+		return NewWord256FromBig(i)
+	}
+}
+
+// integerToBig returns the value of the given Cadence integer as a big integer.
+func integerToBig(value Value) (*big.Int, bool) {
+	switch value := value.(type) {
+	case Int:
+		return new(big.Int).Set(value.Value), true
+	case Int8:
+		return big.NewInt(int64(value)), true
+	case Int16:
+		return big.NewInt(int64(value)), true
+	case Int32:
+		return big.NewInt(int64(value)), true
+	case Int64:
+		return big.NewInt(int64(value)), true
+	case Int128:
+		return new(big.Int).Set(value.Value), true
+	case Int256:
+		return new(big.Int).Set(value.Value), true
+	case UInt:
+		return new(big.Int).Set(value.Value), true
+	case UInt8:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case UInt16:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case UInt32:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case UInt64:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case UInt128:
+		return new(big.Int).Set(value.Value), true
+	case UInt256:
+		return new(big.Int).Set(value.Value), true
+	case Word8:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case Word16:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case Word32:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case Word64:
+		return new(big.Int).SetUint64(uint64(value)), true
+	case Word128:
+		return new(big.Int).Set(value.Value), true
+	case Word256:
+		return new(big.Int).Set(value.Value), true
+	}
+	return nil, false
+}
+
+// Unmarshal stores the given Cadence value in the Go value pointed to by v.
+//
+// It is the inverse of Marshal: optionals are unmarshaled into pointers,
+// arrays into slices and arrays, dictionaries into maps, and composites into structs,
+// using the fields which have a `cadence` struct tag.
+// Integers are unmarshaled into Go integers if they fit, and into big.Int.
+// Fixed-point values are unmarshaled into Go integers as raw values, and into strings as decimals.
+// Types implementing Unmarshaler unmarshal themselves.
+func Unmarshal(value Value, v any) error {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("cadence: Unmarshal requires a non-nil pointer, got %T", v)
+	}
+	return unmarshal(value, target.Elem(), "", "")
+}
+
+func unmarshal(value Value, target reflect.Value, override string, path string) error {
+	t := target.Type()
+
+	newError := func(reason string) error {
+		var typeID TypeID
+		if value == nil {
+			typeID = "nil"
+		} else if staticType := value.Type(); staticType != nil {
+			typeID = TypeID(staticType.ID())
+		} else {
+			// Values constructed without a type, e.g. using NewArray
+			typeID = TypeID(reflect.TypeOf(value).Name())
+		}
+		return &UnmarshalTypeError{
+			GoType: t,
+			Value:  typeID,
+			Path:   path,
+			Reason: reason,
+		}
+	}
+
+	if value == nil {
+		return newError("missing value")
+	}
+
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		err := target.Addr().Interface().(Unmarshaler).UnmarshalCadence(value)
+		if err != nil {
+			return newError(err.Error())
+		}
+		return nil
+	}
+
+	valueGoType := reflect.TypeOf(value)
+	if valueGoType.AssignableTo(t) {
+		target.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	if optional, ok := value.(Optional); ok {
+		if optional.Value == nil {
+			if t.Kind() != reflect.Pointer {
+				return newError("nil can only be unmarshaled into a pointer")
+			}
+			target.Set(reflect.Zero(t))
+			return nil
+		}
+
+		if t.Kind() == reflect.Pointer && t.Elem() != bigIntType {
+			element := reflect.New(t.Elem())
+			err := unmarshal(optional.Value, element.Elem(), override, path)
+			if err != nil {
+				return err
+			}
+			target.Set(element)
+			return nil
+		}
+
+		value = optional.Value
+		valueGoType = reflect.TypeOf(value)
+		if valueGoType.AssignableTo(t) {
+			target.Set(reflect.ValueOf(value))
+			return nil
+		}
+	}
+
+	if override != "" {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			// The override applies to the elements
+		default:
+			if !isOverrideType(value, override) {
+				return newError(fmt.Sprintf("expected %s", override))
+			}
+		}
+	}
+
+	switch t {
+	case bigIntType:
+		i, ok := integerToBig(value)
+		if !ok {
+			return newError("not an integer")
+		}
+		target.Addr().Interface().(*big.Int).Set(i)
+		return nil
+
+	case commonAddressType:
+		address, ok := value.(Address)
+		if !ok {
+			return newError("not an address")
+		}
+		target.Set(reflect.ValueOf(common.Address(address)))
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		element := reflect.New(t.Elem())
+		err := unmarshal(value, element.Elem(), override, path)
+		if err != nil {
+			return err
+		}
+		target.Set(element)
+		return nil
+
+	case reflect.Bool:
+		b, ok := value.(Bool)
+		if !ok {
+			return newError("not a boolean")
+		}
+		target.SetBool(bool(b))
+		return nil
+
+	case reflect.String:
+		switch value := value.(type) {
+		case String:
+			target.SetString(string(value))
+		case Character:
+			target.SetString(string(value))
+		case Fix64, UFix64, Path:
+			target.SetString(value.String())
+		default:
+			return newError("not a string, character, fixed-point number or path")
+		}
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i *big.Int
+		switch value := value.(type) {
+		case Fix64:
+			i = big.NewInt(int64(value))
+		case UFix64:
+			i = new(big.Int).SetUint64(uint64(value))
+		default:
+			var ok bool
+			i, ok = integerToBig(value)
+			if !ok {
+				return newError("not a number")
+			}
+		}
+		if !i.IsInt64() || target.OverflowInt(i.Int64()) {
+			return newError("value out of range")
+		}
+		target.SetInt(i.Int64())
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var i *big.Int
+		switch value := value.(type) {
+		case Fix64:
+			i = big.NewInt(int64(value))
+		case UFix64:
+			i = new(big.Int).SetUint64(uint64(value))
+		default:
+			var ok bool
+			i, ok = integerToBig(value)
+			if !ok {
+				return newError("not a number")
+			}
+		}
+		if !i.IsUint64() || target.OverflowUint(i.Uint64()) {
+			return newError("value out of range")
+		}
+		target.SetUint(i.Uint64())
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		array, ok := value.(Array)
+		if !ok {
+			return newError("not an array")
+		}
+
+		if t.Kind() == reflect.Slice {
+			target.Set(reflect.MakeSlice(t, len(array.Values), len(array.Values)))
+		} else if t.Len() != len(array.Values) {
+			return newError(fmt.Sprintf(
+				"expected %d elements, got %d",
+				t.Len(),
+				len(array.Values),
+			))
+		}
+
+		for i, element := range array.Values {
+			err := unmarshal(element, target.Index(i), override, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		dictionary, ok := value.(Dictionary)
+		if !ok {
+			return newError("not a dictionary")
+		}
+
+		result := reflect.MakeMapWithSize(t, len(dictionary.Pairs))
+		for _, pair := range dictionary.Pairs {
+			elementPath := fmt.Sprintf("%s[%s]", path, pair.Key)
+
+			key := reflect.New(t.Key()).Elem()
+			err := unmarshal(pair.Key, key, "", elementPath)
+			if err != nil {
+				return err
+			}
+
+			element := reflect.New(t.Elem()).Elem()
+			err = unmarshal(pair.Value, element, override, elementPath)
+			if err != nil {
+				return err
+			}
+
+			result.SetMapIndex(key, element)
+		}
+		target.Set(result)
+		return nil
+
+	case reflect.Struct:
+		composite, ok := value.(HasFields)
+		if !ok {
+			return newError("not a composite")
+		}
+
+		fields, err := structFields(t)
+		if err != nil {
+			return newError(err.Error())
+		}
+
+		fieldValues := GetFieldsMappedByName(composite)
+
+		for _, field := range fields {
+			fieldPath := appendFieldPath(path, field.name)
+
+			fieldValue, ok := fieldValues[field.name]
+			if !ok {
+				return newError(fmt.Sprintf("missing field %s", field.name))
+			}
+
+			err := unmarshal(fieldValue, target.Field(field.index), field.override, fieldPath)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return newError("unsupported type")
+}
+
+func isOverrideType(value Value, override string) bool {
+	if override == sema.PathType.Name {
+		_, ok := value.(Path)
+		return ok
+	}
+	staticType := value.Type()
+	return staticType != nil && staticType.ID() == override
+}
@@ -0,0 +1,474 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cadence
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/tests/utils"
+)
+
+type marshalTestToken struct {
+	ID       uint64            `cadence:"id"`
+	Name     string            `cadence:"name"`
+	Balance  uint64            `cadence:"balance,UFix64"`
+	Supply   *big.Int          `cadence:"supply,UInt256"`
+	Owner    *common.Address   `cadence:"owner"`
+	Tags     []string          `cadence:"tags"`
+	Metadata map[string]string `cadence:"metadata"`
+	Ignored  string
+}
+
+type marshalTestDeposited struct {
+	To     common.Address     `cadence:"to"`
+	Amount string             `cadence:"amount,UFix64"`
+	Path   string             `cadence:"path,Path"`
+	Tokens []marshalTestToken `cadence:"tokens"`
+	Next   *marshalTestDeposited
+}
+
+func (marshalTestDeposited) CadenceCompositeType() CompositeType {
+	return &EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "Deposited",
+	}
+}
+
+type marshalTestNode struct {
+	Value int              `cadence:"value,Int8"`
+	Next  *marshalTestNode `cadence:"next"`
+}
+
+func TestMarshal(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("primitives", func(t *testing.T) {
+		t.Parallel()
+
+		for _, test := range []struct {
+			value    any
+			expected Value
+		}{
+			{true, NewBool(true)},
+			{"foo", String("foo")},
+			{-1, NewInt(-1)},
+			{int8(-2), NewInt8(-2)},
+			{int64(-3), NewInt64(-3)},
+			{uint(4), NewUInt(4)},
+			{uint16(5), NewUInt16(5)},
+			{big.NewInt(6), NewInt(6)},
+			{common.MustBytesToAddress([]byte{0x1}), NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 1})},
+			{UFix64(1_50000000), UFix64(1_50000000)},
+			{(*int)(nil), NewOptional(nil)},
+			{(*UFix64)(nil), NewOptional(nil)},
+			{&[]UFix64{1}[0], NewOptional(UFix64(1))},
+		} {
+			value, err := Marshal(test.value)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+		}
+	})
+
+	t.Run("event", func(t *testing.T) {
+		t.Parallel()
+
+		owner := common.MustBytesToAddress([]byte{0x2})
+
+		value, err := Marshal(marshalTestDeposited{
+			To:     common.MustBytesToAddress([]byte{0x1}),
+			Amount: "12.5",
+			Path:   "/storage/tokens",
+			Tokens: []marshalTestToken{
+				{
+					ID:       1,
+					Name:     "one",
+					Balance:  1_00000000,
+					Supply:   big.NewInt(1000),
+					Owner:    &owner,
+					Tags:     []string{"a"},
+					Metadata: map[string]string{"b": "2", "a": "1"},
+					Ignored:  "ignored",
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		tokenType := &StructType{
+			QualifiedIdentifier: "marshalTestToken",
+			Fields: []Field{
+				{Identifier: "id", Type: TheUInt64Type},
+				{Identifier: "name", Type: TheStringType},
+				{Identifier: "balance", Type: TheUFix64Type},
+				{Identifier: "supply", Type: TheUInt256Type},
+				{Identifier: "owner", Type: NewOptionalType(TheAddressType)},
+				{Identifier: "tags", Type: NewVariableSizedArrayType(TheStringType)},
+				{Identifier: "metadata", Type: NewDictionaryType(TheStringType, TheStringType)},
+			},
+		}
+
+		eventType := &EventType{
+			Location:            utils.TestLocation,
+			QualifiedIdentifier: "Deposited",
+			Fields: []Field{
+				{Identifier: "to", Type: TheAddressType},
+				{Identifier: "amount", Type: TheUFix64Type},
+				{Identifier: "path", Type: ThePathType},
+				{Identifier: "tokens", Type: NewVariableSizedArrayType(tokenType)},
+			},
+		}
+
+		amount, err := NewUFix64("12.5")
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			NewEvent([]Value{
+				NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 1}),
+				amount,
+				Path{Domain: common.PathDomainStorage, Identifier: "tokens"},
+				NewArray([]Value{
+					NewStruct([]Value{
+						NewUInt64(1),
+						String("one"),
+						UFix64(1_00000000),
+						NewUInt256(1000),
+						NewOptional(NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 2})),
+						NewArray([]Value{String("a")}).
+							WithType(NewVariableSizedArrayType(TheStringType)),
+						NewDictionary([]KeyValuePair{
+							{Key: String("a"), Value: String("1")},
+							{Key: String("b"), Value: String("2")},
+						}).WithType(NewDictionaryType(TheStringType, TheStringType)),
+					}).WithType(tokenType),
+				}).WithType(NewVariableSizedArrayType(tokenType)),
+			}).WithType(eventType),
+			value,
+		)
+
+		var result marshalTestDeposited
+		err = Unmarshal(value, &result)
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			marshalTestDeposited{
+				To:     common.MustBytesToAddress([]byte{0x1}),
+				Amount: "12.50000000",
+				Path:   "/storage/tokens",
+				Tokens: []marshalTestToken{
+					{
+						ID:       1,
+						Name:     "one",
+						Balance:  1_00000000,
+						Supply:   big.NewInt(1000),
+						Owner:    &owner,
+						Tags:     []string{"a"},
+						Metadata: map[string]string{"b": "2", "a": "1"},
+					},
+				},
+			},
+			result,
+		)
+	})
+
+	t.Run("recursive", func(t *testing.T) {
+		t.Parallel()
+
+		value, err := Marshal(&marshalTestNode{
+			Value: 1,
+			Next: &marshalTestNode{
+				Value: 2,
+			},
+		})
+		require.NoError(t, err)
+
+		var result *marshalTestNode
+		err = Unmarshal(value, &result)
+		require.NoError(t, err)
+
+		require.NotNil(t, result)
+		require.NotNil(t, result.Next)
+		assert.Equal(t, 1, result.Value)
+		assert.Equal(t, 2, result.Next.Value)
+		assert.Nil(t, result.Next.Next)
+	})
+
+	t.Run("override out of range", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Marshal(marshalTestNode{Value: 200})
+		var marshalErr *MarshalTypeError
+		require.ErrorAs(t, err, &marshalErr)
+		assert.Equal(t, "value", marshalErr.Path)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := Marshal(1.5)
+		var marshalErr *MarshalTypeError
+		require.ErrorAs(t, err, &marshalErr)
+	})
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("integers", func(t *testing.T) {
+		t.Parallel()
+
+		var i8 int8
+		require.NoError(t, Unmarshal(NewInt(-5), &i8))
+		assert.Equal(t, int8(-5), i8)
+
+		var u uint
+		require.NoError(t, Unmarshal(NewWord64(7), &u))
+		assert.Equal(t, uint(7), u)
+
+		var b big.Int
+		require.NoError(t, Unmarshal(NewInt256(-8), &b))
+		assert.Equal(t, big.NewInt(-8), &b)
+
+		var raw uint64
+		require.NoError(t, Unmarshal(UFix64(1_50000000), &raw))
+		assert.Equal(t, uint64(1_50000000), raw)
+	})
+
+	t.Run("overflow", func(t *testing.T) {
+		t.Parallel()
+
+		var i8 int8
+		err := Unmarshal(NewInt(300), &i8)
+		var unmarshalErr *UnmarshalTypeError
+		require.ErrorAs(t, err, &unmarshalErr)
+		assert.Equal(t, TypeID("Int"), unmarshalErr.Value)
+
+		var u uint
+		err = Unmarshal(NewInt(-1), &u)
+		require.ErrorAs(t, err, &unmarshalErr)
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		var token marshalTestToken
+		err := Unmarshal(
+			NewStruct([]Value{
+				NewUInt64(1),
+				NewInt(2),
+			}).WithType(&StructType{
+				QualifiedIdentifier: "Token",
+				Fields: []Field{
+					{Identifier: "id", Type: TheUInt64Type},
+					{Identifier: "name", Type: TheIntType},
+				},
+			}),
+			&token,
+		)
+		var unmarshalErr *UnmarshalTypeError
+		require.ErrorAs(t, err, &unmarshalErr)
+		assert.Equal(t, "name", unmarshalErr.Path)
+		assert.Equal(t,
+			"cadence: cannot unmarshal Int into Go value of type string at name: "+
+				"not a string, character, fixed-point number or path",
+			err.Error(),
+		)
+	})
+
+	t.Run("override mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		var node marshalTestNode
+		err := Unmarshal(
+			NewStruct([]Value{
+				NewInt16(1),
+				NewOptional(nil),
+			}).WithType(&StructType{
+				QualifiedIdentifier: "Node",
+				Fields: []Field{
+					{Identifier: "value", Type: TheInt16Type},
+					{Identifier: "next", Type: NewOptionalType(TheAnyStructType)},
+				},
+			}),
+			&node,
+		)
+		var unmarshalErr *UnmarshalTypeError
+		require.ErrorAs(t, err, &unmarshalErr)
+		assert.Equal(t, "expected Int8", unmarshalErr.Reason)
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		t.Parallel()
+
+		var node marshalTestNode
+		err := Unmarshal(
+			NewStruct([]Value{
+				NewInt8(1),
+			}).WithType(&StructType{
+				QualifiedIdentifier: "Node",
+				Fields: []Field{
+					{Identifier: "value", Type: TheInt8Type},
+				},
+			}),
+			&node,
+		)
+		var unmarshalErr *UnmarshalTypeError
+		require.ErrorAs(t, err, &unmarshalErr)
+		assert.Equal(t, "missing field next", unmarshalErr.Reason)
+	})
+
+	t.Run("nil into non-pointer", func(t *testing.T) {
+		t.Parallel()
+
+		var s string
+		err := Unmarshal(NewOptional(nil), &s)
+		var unmarshalErr *UnmarshalTypeError
+		require.ErrorAs(t, err, &unmarshalErr)
+	})
+
+	t.Run("array length", func(t *testing.T) {
+		t.Parallel()
+
+		var a [2]int
+		err := Unmarshal(NewArray([]Value{NewInt(1)}), &a)
+		var unmarshalErr *UnmarshalTypeError
+		require.ErrorAs(t, err, &unmarshalErr)
+	})
+
+	t.Run("values", func(t *testing.T) {
+		t.Parallel()
+
+		var values []Value
+		require.NoError(t, Unmarshal(NewArray([]Value{NewInt(1), String("a")}), &values))
+		assert.Equal(t, []Value{NewInt(1), String("a")}, values)
+
+		var m map[Value]any
+		require.NoError(t, Unmarshal(
+			NewDictionary([]KeyValuePair{
+				{Key: String("a"), Value: NewOptional(nil)},
+			}),
+			&m,
+		))
+		assert.Equal(t, map[Value]any{String("a"): NewOptional(nil)}, m)
+	})
+
+	t.Run("pointer to value", func(t *testing.T) {
+		t.Parallel()
+
+		var p *UFix64
+		require.NoError(t, Unmarshal(NewOptional(UFix64(7)), &p))
+		require.NotNil(t, p)
+		assert.Equal(t, UFix64(7), *p)
+
+		require.NoError(t, Unmarshal(NewOptional(nil), &p))
+		assert.Nil(t, p)
+	})
+
+	t.Run("not a pointer", func(t *testing.T) {
+		t.Parallel()
+
+		var i int
+		require.Error(t, Unmarshal(NewInt(1), i))
+	})
+}
+
+func TestUnmarshalEvent(t *testing.T) {
+
+	t.Parallel()
+
+	type tokensDeposited struct {
+		To     *common.Address `cadence:"to"`
+		Amount string          `cadence:"amount,UFix64"`
+	}
+
+	amount, err := NewUFix64("1.5")
+	require.NoError(t, err)
+
+	to := common.MustBytesToAddress([]byte{0x1})
+
+	event := NewEvent([]Value{
+		NewOptional(NewAddress(to)),
+		amount,
+	}).WithType(&EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "FungibleToken.TokensDeposited",
+		Fields: []Field{
+			{Identifier: "to", Type: NewOptionalType(TheAddressType)},
+			{Identifier: "amount", Type: TheUFix64Type},
+		},
+	})
+
+	var result tokensDeposited
+	err = Unmarshal(event, &result)
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		tokensDeposited{
+			To:     &to,
+			Amount: "1.50000000",
+		},
+		result,
+	)
+}
+
+func TestMarshalTagsDecodeFields(t *testing.T) {
+
+	t.Parallel()
+
+	// The tags of a struct used with Marshal and Unmarshal also work with DecodeFields
+
+	type deposit struct {
+		Amount   UFix64 `cadence:"amount,UFix64"`
+		Receiver Address
+		Memo     String `cadence:",String"`
+		Ignored  String `cadence:"-"`
+	}
+
+	event := NewEvent([]Value{
+		UFix64(150000000),
+		String("rent"),
+	}).WithType(&EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: "Deposited",
+		Fields: []Field{
+			{Identifier: "amount", Type: TheUFix64Type},
+			{Identifier: "Memo", Type: TheStringType},
+		},
+	})
+
+	var decoded deposit
+	err := DecodeFields(event, &decoded)
+	require.NoError(t, err)
+
+	var unmarshaled deposit
+	err = Unmarshal(event, &unmarshaled)
+	require.NoError(t, err)
+
+	expected := deposit{
+		Amount: UFix64(150000000),
+		Memo:   "rent",
+	}
+	assert.Equal(t, expected, decoded)
+	assert.Equal(t, expected, unmarshaled)
+}
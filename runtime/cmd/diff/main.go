@@ -0,0 +1,164 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// A utility program that reports the structural differences between two Cadence values,
+// e.g. script results before and after a network upgrade.
+//
+// The values can be encoded in JSON-Cadence or CCF (binary or hex-encoded).
+// Dictionaries are compared as unordered maps, so reordered entries are not reported.
+//
+// The exit status is 0 if the values are equal, 1 if they differ, and 2 if an error occurred.
+
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/ccf"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+)
+
+const (
+	formatAuto    = "auto"
+	formatJSON    = "json"
+	formatCCF     = "ccf"
+	formatCCFHex  = "ccf-hex"
+	exitDifferent = 1
+	exitError     = 2
+)
+
+var formatFlag = flag.String(
+	"format",
+	formatAuto,
+	"encoding of the values: auto, json, ccf, or ccf-hex",
+)
+
+var toleranceFlag = flag.String(
+	"tolerance",
+	"",
+	"maximum absolute difference of fixed-point values which are considered equal, e.g. 0.00000100",
+)
+
+func main() {
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "usage: %s [options] <a> <b>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) != 2 {
+		flag.Usage()
+		os.Exit(exitError)
+	}
+
+	var options []cadence.DiffOption
+	if *toleranceFlag != "" {
+		tolerance, err := cadence.NewUFix64(*toleranceFlag)
+		if err != nil {
+			fail("invalid tolerance: %s", err)
+		}
+		options = append(options, cadence.WithFixedPointTolerance(tolerance))
+	}
+
+	a := readValue(args[0])
+	b := readValue(args[1])
+
+	differences := cadence.Diff(a, b, options...)
+	for _, difference := range differences {
+		fmt.Println(difference)
+	}
+
+	if len(differences) > 0 {
+		os.Exit(exitDifferent)
+	}
+}
+
+func fail(format string, args ...any) {
+	_, _ = fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(exitError)
+}
+
+func readValue(path string) cadence.Value {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fail("failed to read %s: %s", path, err)
+	}
+
+	value, err := decodeValue(data, *formatFlag)
+	if err != nil {
+		fail("failed to decode %s: %s", path, err)
+	}
+
+	return value
+}
+
+func decodeValue(data []byte, format string) (cadence.Value, error) {
+	if format == formatAuto {
+		format = detectFormat(data)
+	}
+
+	switch format {
+	case formatJSON:
+		return jsoncdc.Decode(nil, data)
+
+	case formatCCF:
+		return ccf.Decode(nil, data)
+
+	case formatCCFHex:
+		decoded, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+		if err != nil {
+			return nil, err
+		}
+		return ccf.Decode(nil, decoded)
+
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// detectFormat detects the encoding of the given data:
+// JSON-Cadence values are JSON objects, and CCF messages start with a CBOR tag,
+// which is never a valid hex digit.
+func detectFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return formatJSON
+	}
+
+	if len(trimmed) > 0 && len(trimmed)%2 == 0 {
+		isHex := true
+		for _, c := range trimmed {
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				isHex = false
+				break
+			}
+		}
+		if isHex {
+			return formatCCFHex
+		}
+	}
+
+	return formatCCF
+}
@@ -0,0 +1,259 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// A utility program that parses a state dump in JSON Lines format,
+// checks the integrity of the slabs of all accounts, and repairs them:
+//
+//   - Slabs which are not reachable from any storage map are deleted
+//   - Values which refer to missing slabs or contain slabs which cannot be decoded
+//     are moved into the quarantine storage domain of their account
+//
+// By default, only a report is produced. Pass -output to write the repaired state dump.
+
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+var gzipFlag = flag.Bool("gzip", false, "set true if input file is gzipped")
+var dryRunFlag = flag.Bool("dry-run", false, "only report problems and the repair plan, do not repair")
+var outputFlag = flag.String("output", "", "write the repaired state dump to the given file")
+var reportFlag = flag.String("report", "", "write the report to the given file, instead of standard output")
+
+type encodedKeyPart struct {
+	Value string
+}
+
+type encodedKey struct {
+	KeyParts []encodedKeyPart
+}
+
+type encodedEntry struct {
+	Value string
+	Key   encodedKey
+}
+
+func main() {
+	flag.Parse()
+
+	if *outputFlag != "" && *dryRunFlag {
+		log.Fatal("-output cannot be used with -dry-run")
+	}
+
+	args := flag.Args()
+	if len(args) < 1 {
+		panic("missing path argument")
+	}
+
+	registers := readFile(args[0])
+
+	// Only repair the registers if the repaired state dump is written,
+	// otherwise the report would claim repairs were applied
+
+	var report *Report
+	if *outputFlag == "" {
+		report = Analyze(registers)
+	} else {
+		var err error
+		report, err = Repair(registers)
+		if err != nil {
+			log.Fatalf("Failed to repair: %s", err)
+		}
+	}
+
+	writeReport(report)
+
+	if *outputFlag != "" {
+		writeFile(*outputFlag, registers)
+	}
+
+	if !report.IsHealthy() {
+		log.Printf(
+			"found problems: %d dangling storage IDs, %d undecodable slabs, %d broken storage maps, %d unreachable slabs",
+			len(report.DanglingStorageIDs),
+			len(report.UndecodableSlabs),
+			len(report.BrokenStorageMaps),
+			len(report.OrphanedSlabs)+len(report.KeptUnreachableSlabs),
+		)
+	}
+}
+
+func readFile(path string) registers {
+	log.Println("Reading file ...")
+
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	var inputReader io.Reader = file
+	if *gzipFlag {
+		gzipReader, err := gzip.NewReader(inputReader)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer gzipReader.Close()
+		inputReader = gzipReader
+	}
+
+	registers, err := read(bufio.NewReader(inputReader))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("read %d registers", len(registers))
+
+	return registers
+}
+
+// read reads the registers of a state dump in JSON Lines format
+func read(reader io.Reader) (registers, error) {
+	result := registers{}
+
+	decoder := json.NewDecoder(reader)
+
+	for line := 1; ; line++ {
+		var e encodedEntry
+
+		err := decoder.Decode(&e)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		currentKeyPartCount := len(e.Key.KeyParts)
+		if currentKeyPartCount < keyPartCount {
+			if currentKeyPartCount > 0 {
+				return nil, fmt.Errorf("invalid storage key parts on line %d: %#+v", line, e.Key)
+			}
+			continue
+		}
+
+		var key registerKey
+		for i := 0; i < keyPartCount; i++ {
+			keyPart := e.Key.KeyParts[i].Value
+			k, err := hex.DecodeString(keyPart)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"failed to hex-decode key part %d on line %d (%s): %w",
+					i, line, keyPart, err,
+				)
+			}
+			// Treat bytes as string,
+			// so resulting array of strings can be used as a map key
+			key[i] = string(k)
+		}
+
+		data, err := hex.DecodeString(e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value on line %d: %w", line, err)
+		}
+
+		// Ignore empty registers
+		if len(data) > 0 {
+			result[key] = data
+		}
+	}
+
+	return result, nil
+}
+
+func writeFile(path string, registers registers) {
+	log.Println("Writing file ...")
+
+	file, err := os.Create(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer file.Close()
+
+	var output io.Writer = file
+	if *gzipFlag {
+		gzipWriter := gzip.NewWriter(output)
+		defer gzipWriter.Close()
+		output = gzipWriter
+	}
+
+	writer := bufio.NewWriter(output)
+	defer writer.Flush()
+
+	err = write(writer, registers)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// write writes the registers as a state dump in JSON Lines format, sorted by key
+func write(writer io.Writer, registers registers) error {
+	encoder := json.NewEncoder(writer)
+
+	for _, key := range registers.sortedKeys() {
+		var keyParts []encodedKeyPart
+
+		for _, keyPart := range key {
+			keyParts = append(keyParts, encodedKeyPart{
+				Value: hex.EncodeToString([]byte(keyPart)),
+			})
+		}
+
+		entry := encodedEntry{
+			Value: hex.EncodeToString(registers[key]),
+			Key: encodedKey{
+				KeyParts: keyParts,
+			},
+		}
+
+		err := encoder.Encode(entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeReport(report *Report) {
+	var output io.Writer = os.Stdout
+	if *reportFlag != "" {
+		file, err := os.Create(*reportFlag)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(report)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,244 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/onflow/atree"
+
+	"github.com/onflow/cadence/runtime/interpreter"
+)
+
+const keyPartCount = 3
+
+// registerKey is the key of a register in a state dump:
+// the owner, the controller (unused, always empty), and the key.
+type registerKey [keyPartCount]string
+
+func newRegisterKey(owner []byte, key []byte) registerKey {
+	return registerKey{
+		string(owner),
+		"",
+		string(key),
+	}
+}
+
+func (k registerKey) address() atree.Address {
+	var address atree.Address
+	copy(address[:], k[0])
+	return address
+}
+
+// '$' + 8 byte index
+const slabKeyLength = 9
+
+// storageID returns the storage ID of the slab stored in the register,
+// or atree.StorageIDUndefined if the register does not store a slab.
+func (k registerKey) storageID() atree.StorageID {
+	key := k[2]
+	if len(key) != slabKeyLength || key[0] != '$' {
+		return atree.StorageIDUndefined
+	}
+	var result atree.StorageID
+	result.Address = k.address()
+	copy(result.Index[:], key[1:])
+	return result
+}
+
+func storageIDRegisterKey(id atree.StorageID) registerKey {
+	return registerKey{
+		string(id.Address[:]),
+		"",
+		"$" + string(id.Index[:]),
+	}
+}
+
+// registers are the registers of a state dump
+type registers map[registerKey][]byte
+
+// sortedKeys returns the keys of all registers, in a deterministic order
+func (r registers) sortedKeys() []registerKey {
+	keys := make([]registerKey, 0, len(r))
+
+	// NOTE: iteration over map is safe,
+	// as result is sorted below
+
+	for key := range r { //nolint:maprange
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		a := keys[i]
+		b := keys[j]
+		for k := 0; k < keyPartCount; k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+
+	return keys
+}
+
+func decodeStorable(decoder *cbor.StreamDecoder, storableSlabStorageID atree.StorageID) (atree.Storable, error) {
+	return interpreter.DecodeStorable(decoder, storableSlabStorageID, nil)
+}
+
+func decodeTypeInfo(decoder *cbor.StreamDecoder) (atree.TypeInfo, error) {
+	return interpreter.DecodeTypeInfo(decoder, nil)
+}
+
+func decodeSlab(id atree.StorageID, data []byte) (atree.Slab, error) {
+	return atree.DecodeSlab(
+		id,
+		data,
+		interpreter.CBORDecMode,
+		decodeStorable,
+		decodeTypeInfo,
+	)
+}
+
+// ledger is an atree.Ledger backed by registers
+type ledger struct {
+	registers registers
+	// nextStorageIndices are the next storage indices to allocate, per account
+	nextStorageIndices map[atree.Address]uint64
+}
+
+var _ atree.Ledger = &ledger{}
+
+func newLedger(registers registers) *ledger {
+	nextStorageIndices := map[atree.Address]uint64{}
+
+	// NOTE: iteration over map is safe,
+	// as only the maximum index is determined
+
+	for key := range registers { //nolint:maprange
+		storageID := key.storageID()
+		if storageID == atree.StorageIDUndefined {
+			continue
+		}
+
+		index := binary.BigEndian.Uint64(storageID.Index[:])
+		if index >= nextStorageIndices[storageID.Address] {
+			nextStorageIndices[storageID.Address] = index + 1
+		}
+	}
+
+	return &ledger{
+		registers:          registers,
+		nextStorageIndices: nextStorageIndices,
+	}
+}
+
+func (l *ledger) GetValue(owner, key []byte) (value []byte, err error) {
+	return l.registers[newRegisterKey(owner, key)], nil
+}
+
+func (l *ledger) SetValue(owner, key, value []byte) (err error) {
+	registerKey := newRegisterKey(owner, key)
+	if len(value) == 0 {
+		delete(l.registers, registerKey)
+	} else {
+		l.registers[registerKey] = value
+	}
+	return nil
+}
+
+func (l *ledger) ValueExists(owner, key []byte) (exists bool, err error) {
+	value := l.registers[newRegisterKey(owner, key)]
+	return len(value) > 0, nil
+}
+
+func (l *ledger) AllocateStorageIndex(owner []byte) (result atree.StorageIndex, err error) {
+	var address atree.Address
+	copy(address[:], owner)
+
+	index := l.nextStorageIndices[address]
+	if index == 0 {
+		// Storage index 0 is reserved
+		index = 1
+	}
+	l.nextStorageIndices[address] = index + 1
+
+	binary.BigEndian.PutUint64(result[:], index)
+	return result, nil
+}
+
+// readOnlySlabStorage is an atree.SlabStorage which decodes slabs from registers,
+// and does not allow modifications.
+type readOnlySlabStorage struct {
+	registers registers
+}
+
+var _ atree.SlabStorage = readOnlySlabStorage{}
+
+func (s readOnlySlabStorage) Retrieve(id atree.StorageID) (atree.Slab, bool, error) {
+	data, ok := s.registers[storageIDRegisterKey(id)]
+	if !ok {
+		return nil, false, nil
+	}
+
+	slab, err := decodeSlab(id, data)
+	if err != nil {
+		return nil, true, err
+	}
+
+	return slab, true, nil
+}
+
+func (readOnlySlabStorage) Store(_ atree.StorageID, _ atree.Slab) error {
+	panic("unexpected Store call")
+}
+
+func (readOnlySlabStorage) Remove(_ atree.StorageID) error {
+	panic("unexpected Remove call")
+}
+
+func (readOnlySlabStorage) GenerateStorageID(_ atree.Address) (atree.StorageID, error) {
+	panic("unexpected GenerateStorageID call")
+}
+
+func (readOnlySlabStorage) SlabIterator() (atree.SlabIterator, error) {
+	panic("unexpected SlabIterator call")
+}
+
+func (s readOnlySlabStorage) Count() int {
+	return len(s.registers)
+}
+
+// isStorageIndex returns true if the given register value is a storage index,
+// i.e. the register points to the root slab of a storage map
+func isStorageIndex(value []byte) bool {
+	return len(value) == len(atree.StorageIndex{})
+}
+
+// storageMapRootID returns the storage ID of the storage map stored in the given register
+func storageMapRootID(key registerKey, value []byte) atree.StorageID {
+	var index atree.StorageIndex
+	copy(index[:], value)
+	return atree.StorageID{
+		Address: key.address(),
+		Index:   index,
+	}
+}
@@ -0,0 +1,523 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/onflow/atree"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/stdlib"
+)
+
+// QuarantineStorageDomain is the storage domain into which values are moved
+// which cannot be decoded or which refer to missing slabs.
+// The domain is not accessible by programs.
+const QuarantineStorageDomain = "quarantine"
+
+// storageDomains are the storage domains which are known to contain storage maps.
+// Registers of other domains are also storage maps if they reference an existing slab,
+// e.g. the storage maps of domains added by an embedder
+var storageDomains = map[string]struct{}{
+	common.PathDomainStorage.Identifier():       {},
+	common.PathDomainPrivate.Identifier():       {},
+	common.PathDomainPublic.Identifier():        {},
+	runtime.StorageDomainContract:               {},
+	stdlib.InboxStorageDomain:                   {},
+	stdlib.CapabilityControllerStorageDomain:    {},
+	stdlib.PathCapabilityStorageDomain:          {},
+	stdlib.AccountCapabilityStorageDomain:       {},
+	stdlib.CapabilityControllerTagStorageDomain: {},
+	QuarantineStorageDomain:                     {},
+}
+
+// DanglingStorageID is a reference to a slab which does not exist
+type DanglingStorageID struct {
+	StorageID    string `json:"storageID"`
+	ReferencedBy string `json:"referencedBy"`
+}
+
+// UndecodableSlab is a slab which cannot be decoded,
+// e.g. because it contains an encoded static type which cannot be decoded
+type UndecodableSlab struct {
+	StorageID string `json:"storageID"`
+	Error     string `json:"error"`
+}
+
+// BrokenStorageMap is a storage map which cannot be iterated, and cannot be repaired automatically
+type BrokenStorageMap struct {
+	Address string `json:"address"`
+	Domain  string `json:"domain"`
+	Error   string `json:"error"`
+}
+
+// StorageEntry is an entry of a storage map
+type StorageEntry struct {
+	Address string `json:"address"`
+	Domain  string `json:"domain"`
+	Key     string `json:"key"`
+	// QuarantineKey is the key of the entry in the quarantine storage map
+	QuarantineKey string `json:"quarantineKey"`
+}
+
+// UnreachableSlab is a slab which is not referenced from any storage map
+type UnreachableSlab struct {
+	StorageID string `json:"storageID"`
+	Size      int    `json:"size"`
+}
+
+// Report is the result of analyzing the slabs of a state dump,
+// and the plan for repairing it
+type Report struct {
+	StorageMapCount    int                 `json:"storageMapCount"`
+	SlabCount          int                 `json:"slabCount"`
+	ReachableSlabCount int                 `json:"reachableSlabCount"`
+	DanglingStorageIDs []DanglingStorageID `json:"danglingStorageIDs"`
+	UndecodableSlabs   []UndecodableSlab   `json:"undecodableSlabs"`
+	BrokenStorageMaps  []BrokenStorageMap  `json:"brokenStorageMaps"`
+	// QuarantinedValues are the storage map entries which are moved into the quarantine domain
+	QuarantinedValues []StorageEntry `json:"quarantinedValues"`
+	// OrphanedSlabs are the unreachable slabs which are deleted
+	OrphanedSlabs []UnreachableSlab `json:"orphanedSlabs"`
+	// KeptUnreachableSlabs are the unreachable slabs which are kept,
+	// because the account has slabs which cannot be decoded,
+	// so it cannot be determined if the slabs are actually unreachable
+	KeptUnreachableSlabs []UnreachableSlab `json:"keptUnreachableSlabs"`
+	Applied              bool              `json:"applied"`
+}
+
+// IsHealthy returns true if no problems were found
+func (r *Report) IsHealthy() bool {
+	return len(r.DanglingStorageIDs) == 0 &&
+		len(r.UndecodableSlabs) == 0 &&
+		len(r.BrokenStorageMaps) == 0 &&
+		len(r.OrphanedSlabs) == 0 &&
+		len(r.KeptUnreachableSlabs) == 0
+}
+
+type quarantineEntry struct {
+	rootID        atree.StorageID
+	key           interpreter.StorageMapKey
+	quarantineKey interpreter.StringStorageMapKey
+}
+
+type analyzer struct {
+	registers registers
+	storage   readOnlySlabStorage
+	report    *Report
+	// healthy records for each visited slab if it and all its descendants exist and can be decoded
+	healthy map[atree.StorageID]bool
+	// unsafeAccounts are the accounts which have slabs that cannot be decoded
+	unsafeAccounts map[atree.Address]struct{}
+	quarantine     []quarantineEntry
+	orphans        []atree.StorageID
+}
+
+// Analyze finds unreachable slabs, dangling storage IDs, and values which cannot be decoded,
+// and plans their repair.
+func Analyze(registers registers) *Report {
+	a := newAnalyzer(registers)
+	a.analyze()
+	return a.report
+}
+
+func newAnalyzer(registers registers) *analyzer {
+	return &analyzer{
+		registers: registers,
+		storage: readOnlySlabStorage{
+			registers: registers,
+		},
+		report:         &Report{},
+		healthy:        map[atree.StorageID]bool{},
+		unsafeAccounts: map[atree.Address]struct{}{},
+	}
+}
+
+func (a *analyzer) analyze() {
+	keys := a.registers.sortedKeys()
+
+	for _, key := range keys {
+		if key.storageID() != atree.StorageIDUndefined {
+			a.report.SlabCount++
+			continue
+		}
+
+		value := a.registers[key]
+		if !isStorageIndex(value) {
+			continue
+		}
+
+		domain := key[2]
+		rootID := storageMapRootID(key, value)
+
+		// Registers of unknown domains which hold a storage index
+		// are storage maps if the referenced slab exists.
+		// If the slab is not a storage map, the account is considered unsafe,
+		// so none of its slabs are deleted
+
+		if _, ok := storageDomains[domain]; !ok {
+			if _, ok := a.registers[storageIDRegisterKey(rootID)]; !ok {
+				continue
+			}
+		}
+
+		a.report.StorageMapCount++
+		a.analyzeStorageMap(domain, rootID)
+	}
+
+	a.report.ReachableSlabCount = len(a.healthy)
+
+	for _, key := range keys {
+		storageID := key.storageID()
+		if storageID == atree.StorageIDUndefined {
+			continue
+		}
+
+		if _, ok := a.healthy[storageID]; ok {
+			continue
+		}
+
+		unreachableSlab := UnreachableSlab{
+			StorageID: storageID.String(),
+			Size:      len(a.registers[key]),
+		}
+
+		if _, ok := a.unsafeAccounts[storageID.Address]; ok {
+			a.report.KeptUnreachableSlabs = append(a.report.KeptUnreachableSlabs, unreachableSlab)
+		} else {
+			a.report.OrphanedSlabs = append(a.report.OrphanedSlabs, unreachableSlab)
+			a.orphans = append(a.orphans, storageID)
+		}
+	}
+}
+
+func (a *analyzer) analyzeStorageMap(domain string, rootID atree.StorageID) {
+	address := common.Address(rootID.Address)
+
+	reportBroken := func(err error) {
+		a.report.BrokenStorageMaps = append(
+			a.report.BrokenStorageMaps,
+			BrokenStorageMap{
+				Address: address.HexWithPrefix(),
+				Domain:  domain,
+				Error:   err.Error(),
+			},
+		)
+		a.unsafeAccounts[rootID.Address] = struct{}{}
+
+		// Still mark all slabs which can be found as reachable,
+		// so they are not deleted
+		a.visitSlab(rootID, atree.StorageIDUndefined)
+	}
+
+	storageMap, err := atree.NewMapWithRootID(
+		a.storage,
+		rootID,
+		atree.NewDefaultDigesterBuilder(),
+	)
+	if err != nil {
+		reportBroken(err)
+		return
+	}
+
+	var keys []atree.Value
+	err = storageMap.IterateKeys(func(key atree.Value) (resume bool, err error) {
+		keys = append(keys, key)
+		return true, nil
+	})
+	if err != nil {
+		reportBroken(err)
+		return
+	}
+
+	for _, key := range keys {
+		storageMapKey, err := newStorageMapKey(key)
+		if err != nil {
+			reportBroken(err)
+			return
+		}
+
+		storable, err := storageMap.Get(
+			storageMapKey.AtreeValueCompare,
+			storageMapKey.AtreeValueHashInput,
+			storageMapKey.AtreeValue(),
+		)
+		if err != nil {
+			reportBroken(err)
+			return
+		}
+
+		if a.visitStorable(storable, rootID) {
+			continue
+		}
+
+		if domain == QuarantineStorageDomain {
+			continue
+		}
+
+		quarantineKey := fmt.Sprintf("%s/%s", domain, storageMapKey)
+
+		a.report.QuarantinedValues = append(
+			a.report.QuarantinedValues,
+			StorageEntry{
+				Address:       address.HexWithPrefix(),
+				Domain:        domain,
+				Key:           fmt.Sprint(storageMapKey),
+				QuarantineKey: quarantineKey,
+			},
+		)
+
+		a.quarantine = append(
+			a.quarantine,
+			quarantineEntry{
+				rootID:        rootID,
+				key:           storageMapKey,
+				quarantineKey: interpreter.StringStorageMapKey(quarantineKey),
+			},
+		)
+	}
+
+	// Visit the slabs of the storage map itself.
+	// The slabs of the values were already visited above
+	a.visitSlab(rootID, atree.StorageIDUndefined)
+}
+
+func newStorageMapKey(key atree.Value) (interpreter.StorageMapKey, error) {
+	switch key := key.(type) {
+	case interpreter.StringAtreeValue:
+		return interpreter.StringStorageMapKey(key), nil
+	case interpreter.Uint64AtreeValue:
+		return interpreter.Uint64StorageMapKey(key), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage map key: %T", key)
+	}
+}
+
+// visitStorable visits all slabs referenced by the given storable.
+// Returns true if all referenced slabs exist and can be decoded.
+func (a *analyzer) visitStorable(storable atree.Storable, parentID atree.StorageID) bool {
+	if storageIDStorable, ok := storable.(atree.StorageIDStorable); ok {
+		return a.visitSlab(atree.StorageID(storageIDStorable), parentID)
+	}
+
+	healthy := true
+	for _, childStorable := range storable.ChildStorables() {
+		if !a.visitStorable(childStorable, parentID) {
+			healthy = false
+		}
+	}
+	return healthy
+}
+
+// visitSlab visits the slab with the given ID, and all slabs it references.
+// Returns true if all slabs exist and can be decoded.
+func (a *analyzer) visitSlab(storageID atree.StorageID, parentID atree.StorageID) bool {
+	healthy, ok := a.healthy[storageID]
+	if ok {
+		return healthy
+	}
+
+	data, ok := a.registers[storageIDRegisterKey(storageID)]
+	if !ok {
+		referencedBy := ""
+		if parentID != atree.StorageIDUndefined {
+			referencedBy = parentID.String()
+		}
+		a.report.DanglingStorageIDs = append(
+			a.report.DanglingStorageIDs,
+			DanglingStorageID{
+				StorageID:    storageID.String(),
+				ReferencedBy: referencedBy,
+			},
+		)
+		return false
+	}
+
+	// Mark the slab as visited before visiting the children,
+	// to prevent infinite recursion for malformed cyclic slabs
+	a.healthy[storageID] = false
+
+	slab, err := decodeSlab(storageID, data)
+	if err != nil {
+		a.report.UndecodableSlabs = append(
+			a.report.UndecodableSlabs,
+			UndecodableSlab{
+				StorageID: storageID.String(),
+				Error:     err.Error(),
+			},
+		)
+		a.unsafeAccounts[storageID.Address] = struct{}{}
+		return false
+	}
+
+	healthy = true
+	for _, childStorable := range slab.ChildStorables() {
+		if !a.visitStorable(childStorable, storageID) {
+			healthy = false
+		}
+	}
+
+	a.healthy[storageID] = healthy
+	return healthy
+}
+
+// storableValue is an atree.Value which is stored as the given storable.
+// It allows moving an encoded value between storage maps without decoding it.
+type storableValue struct {
+	storable atree.Storable
+}
+
+var _ atree.Value = storableValue{}
+
+func (v storableValue) Storable(_ atree.SlabStorage, _ atree.Address, _ uint64) (atree.Storable, error) {
+	return v.storable, nil
+}
+
+// Repair analyzes the given registers and applies the repair plan:
+// Values which cannot be decoded or which refer to missing slabs
+// are moved into the quarantine storage domain of their account,
+// and orphaned slabs are deleted.
+func Repair(registers registers) (*Report, error) {
+	a := newAnalyzer(registers)
+	a.analyze()
+
+	err := a.apply()
+	if err != nil {
+		return a.report, err
+	}
+
+	a.report.Applied = true
+	return a.report, nil
+}
+
+func (a *analyzer) apply() error {
+	ledger := newLedger(a.registers)
+
+	storage := atree.NewPersistentSlabStorage(
+		atree.NewLedgerBaseStorage(ledger),
+		interpreter.CBOREncMode,
+		interpreter.CBORDecMode,
+		decodeStorable,
+		decodeTypeInfo,
+	)
+
+	quarantineMaps := map[atree.Address]*atree.OrderedMap{}
+
+	getQuarantineMap := func(address atree.Address) (*atree.OrderedMap, error) {
+		quarantineMap, ok := quarantineMaps[address]
+		if ok {
+			return quarantineMap, nil
+		}
+
+		var err error
+
+		value := a.registers[newRegisterKey(address[:], []byte(QuarantineStorageDomain))]
+		if isStorageIndex(value) {
+			var index atree.StorageIndex
+			copy(index[:], value)
+			quarantineMap, err = atree.NewMapWithRootID(
+				storage,
+				atree.StorageID{
+					Address: address,
+					Index:   index,
+				},
+				atree.NewDefaultDigesterBuilder(),
+			)
+		} else {
+			quarantineMap, err = atree.NewMap(
+				storage,
+				address,
+				atree.NewDefaultDigesterBuilder(),
+				interpreter.EmptyTypeInfo{},
+			)
+			if err == nil {
+				index := quarantineMap.StorageID().Index
+				err = ledger.SetValue(address[:], []byte(QuarantineStorageDomain), index[:])
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		quarantineMaps[address] = quarantineMap
+		return quarantineMap, nil
+	}
+
+	// Move values into quarantine
+
+	for _, entry := range a.quarantine {
+		storageMap, err := atree.NewMapWithRootID(
+			storage,
+			entry.rootID,
+			atree.NewDefaultDigesterBuilder(),
+		)
+		if err != nil {
+			return err
+		}
+
+		_, valueStorable, err := storageMap.Remove(
+			entry.key.AtreeValueCompare,
+			entry.key.AtreeValueHashInput,
+			entry.key.AtreeValue(),
+		)
+		if err != nil {
+			return err
+		}
+
+		quarantineMap, err := getQuarantineMap(entry.rootID.Address)
+		if err != nil {
+			return err
+		}
+
+		existingStorable, err := quarantineMap.Set(
+			entry.quarantineKey.AtreeValueCompare,
+			entry.quarantineKey.AtreeValueHashInput,
+			entry.quarantineKey.AtreeValue(),
+			storableValue{
+				storable: valueStorable,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		if existingStorable != nil {
+			return fmt.Errorf(
+				"failed to quarantine value: key %s already exists in quarantine of account %s",
+				entry.quarantineKey,
+				common.Address(entry.rootID.Address),
+			)
+		}
+	}
+
+	err := storage.Commit()
+	if err != nil {
+		return err
+	}
+
+	// Delete orphaned slabs
+
+	for _, storageID := range a.orphans {
+		delete(a.registers, storageIDRegisterKey(storageID))
+	}
+
+	return nil
+}
@@ -0,0 +1,457 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/onflow/atree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/tests/utils"
+)
+
+// newTestRegisters returns the registers of a state in which the given accounts
+// store an array of large strings at /storage/array, and a small string at /storage/string.
+// It returns the registers of the slabs of the large strings, per account.
+func newTestRegisters(t *testing.T, addresses ...common.Address) (registers, map[common.Address][]registerKey) {
+	registers := registers{}
+
+	storage := runtime.NewStorage(newLedger(registers), nil)
+
+	inter, err := interpreter.NewInterpreter(
+		nil,
+		utils.TestLocation,
+		&interpreter.Config{
+			Storage: storage,
+		},
+	)
+	require.NoError(t, err)
+
+	for _, address := range addresses {
+		storageMap := storage.GetStorageMap(address, common.PathDomainStorage.Identifier(), true)
+
+		array := interpreter.NewArrayValue(
+			inter,
+			interpreter.EmptyLocationRange,
+			interpreter.VariableSizedStaticType{
+				Type: interpreter.PrimitiveStaticTypeString,
+			},
+			address,
+			interpreter.NewUnmeteredStringValue(strings.Repeat("a", 1000)),
+			interpreter.NewUnmeteredStringValue(strings.Repeat("b", 1000)),
+		)
+
+		storageMap.WriteValue(inter, interpreter.StringStorageMapKey("array"), array)
+		storageMap.WriteValue(
+			inter,
+			interpreter.StringStorageMapKey("string"),
+			interpreter.NewUnmeteredStringValue("test"),
+		)
+	}
+
+	err = storage.Commit(inter, false)
+	require.NoError(t, err)
+
+	// The slabs of the large strings are the slabs which are neither
+	// the root slab of the storage map, nor the root slab of the array
+
+	stringSlabs := map[common.Address][]registerKey{}
+
+	for _, key := range registers.sortedKeys() {
+		storageID := key.storageID()
+		if storageID == atree.StorageIDUndefined {
+			continue
+		}
+
+		address := common.Address(storageID.Address)
+
+		storageMap := storage.GetStorageMap(address, common.PathDomainStorage.Identifier(), false)
+		if storageID == storageMap.StorageID() {
+			continue
+		}
+
+		arrayStorable := storageMap.ReadStorable(interpreter.StringStorageMapKey("array"))
+		if storageID == atree.StorageID(arrayStorable.(atree.StorageIDStorable)) {
+			continue
+		}
+
+		stringSlabs[address] = append(stringSlabs[address], key)
+	}
+
+	for _, address := range addresses {
+		require.Len(t, stringSlabs[address], 2)
+	}
+
+	return registers, stringSlabs
+}
+
+func TestAnalyze(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("healthy", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := common.MustBytesToAddress([]byte{0x1})
+
+		registers, _ := newTestRegisters(t, address)
+
+		report := Analyze(registers)
+
+		assert.True(t, report.IsHealthy())
+		assert.Equal(t, 1, report.StorageMapCount)
+		// storage map, array, and two strings
+		assert.Equal(t, 4, report.SlabCount)
+		assert.Equal(t, 4, report.ReachableSlabCount)
+	})
+
+	t.Run("orphaned slab", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := common.MustBytesToAddress([]byte{0x1})
+
+		registers, stringSlabs := newTestRegisters(t, address)
+
+		orphanID := atree.StorageID{
+			Address: atree.Address(address),
+			Index:   atree.StorageIndex{0, 0, 0, 0, 0, 0, 0, 100},
+		}
+		registers[storageIDRegisterKey(orphanID)] = registers[stringSlabs[address][0]]
+
+		report := Analyze(registers)
+
+		assert.False(t, report.IsHealthy())
+		assert.Equal(t, 5, report.SlabCount)
+		assert.Equal(t, 4, report.ReachableSlabCount)
+		assert.Equal(t,
+			[]UnreachableSlab{
+				{
+					StorageID: orphanID.String(),
+					Size:      len(registers[stringSlabs[address][0]]),
+				},
+			},
+			report.OrphanedSlabs,
+		)
+		assert.Empty(t, report.KeptUnreachableSlabs)
+		assert.Empty(t, report.QuarantinedValues)
+		assert.False(t, report.Applied)
+	})
+
+	t.Run("dangling storage ID", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := common.MustBytesToAddress([]byte{0x1})
+
+		registers, stringSlabs := newTestRegisters(t, address)
+
+		missingKey := stringSlabs[address][0]
+		delete(registers, missingKey)
+
+		report := Analyze(registers)
+
+		assert.False(t, report.IsHealthy())
+		require.Len(t, report.DanglingStorageIDs, 1)
+		assert.Equal(t,
+			missingKey.storageID().String(),
+			report.DanglingStorageIDs[0].StorageID,
+		)
+		assert.Equal(t,
+			[]StorageEntry{
+				{
+					Address:       address.HexWithPrefix(),
+					Domain:        "storage",
+					Key:           "array",
+					QuarantineKey: "storage/array",
+				},
+			},
+			report.QuarantinedValues,
+		)
+		assert.Empty(t, report.OrphanedSlabs)
+	})
+
+	t.Run("storage map in unknown domain", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := common.MustBytesToAddress([]byte{0x1})
+
+		registers, _ := newTestRegisters(t, address)
+
+		// Store a large array in the storage map of a domain
+		// which is not one of the known storage domains
+
+		const domain = "embedder"
+
+		storage := runtime.NewStorage(newLedger(registers), nil)
+
+		inter, err := interpreter.NewInterpreter(
+			nil,
+			utils.TestLocation,
+			&interpreter.Config{
+				Storage: storage,
+			},
+		)
+		require.NoError(t, err)
+
+		storageMap := storage.GetStorageMap(address, domain, true)
+		storageMap.WriteValue(
+			inter,
+			interpreter.StringStorageMapKey("array"),
+			interpreter.NewArrayValue(
+				inter,
+				interpreter.EmptyLocationRange,
+				interpreter.VariableSizedStaticType{
+					Type: interpreter.PrimitiveStaticTypeString,
+				},
+				address,
+				interpreter.NewUnmeteredStringValue(strings.Repeat("c", 1000)),
+			),
+		)
+
+		err = storage.Commit(inter, false)
+		require.NoError(t, err)
+
+		report := Analyze(registers)
+
+		assert.True(t, report.IsHealthy())
+		assert.Equal(t, 2, report.StorageMapCount)
+		assert.Equal(t, report.SlabCount, report.ReachableSlabCount)
+		assert.Empty(t, report.OrphanedSlabs)
+
+		// Repairing the state keeps the slabs of the storage map
+
+		slabCount := report.SlabCount
+
+		report, err = Repair(registers)
+		require.NoError(t, err)
+		assert.Empty(t, report.OrphanedSlabs)
+
+		assert.Equal(t, slabCount, Analyze(registers).SlabCount)
+
+		storage = runtime.NewStorage(newLedger(registers), nil)
+		storageMap = storage.GetStorageMap(address, domain, false)
+		require.NotNil(t, storageMap)
+		assert.NotNil(t, storageMap.ReadStorable(interpreter.StringStorageMapKey("array")))
+	})
+
+	t.Run("storage index in unknown domain", func(t *testing.T) {
+
+		t.Parallel()
+
+		address := common.MustBytesToAddress([]byte{0x1})
+
+		registers, stringSlabs := newTestRegisters(t, address)
+
+		// A register of an unknown domain which holds a value
+		// that looks like a storage index, but does not reference a slab,
+		// is not a storage map
+
+		registers[newRegisterKey(address[:], []byte("counter"))] = []byte{0, 0, 0, 0, 0, 0, 0, 42}
+
+		report := Analyze(registers)
+
+		assert.True(t, report.IsHealthy())
+		assert.Equal(t, 1, report.StorageMapCount)
+
+		// A register of an unknown domain which references a slab that is not a storage map
+		// makes the account unsafe, so its unreachable slabs are kept
+
+		stringSlabID := stringSlabs[address][0].storageID()
+
+		registers[newRegisterKey(address[:], []byte("other"))] = stringSlabID.Index[:]
+
+		orphanID := atree.StorageID{
+			Address: atree.Address(address),
+			Index:   atree.StorageIndex{0, 0, 0, 0, 0, 0, 0, 100},
+		}
+		registers[storageIDRegisterKey(orphanID)] = []byte{0x1}
+
+		report = Analyze(registers)
+
+		assert.False(t, report.IsHealthy())
+		assert.Equal(t, 2, report.StorageMapCount)
+		require.Len(t, report.BrokenStorageMaps, 1)
+		assert.Equal(t, "other", report.BrokenStorageMaps[0].Domain)
+		assert.Empty(t, report.OrphanedSlabs)
+		assert.Equal(t,
+			[]UnreachableSlab{
+				{
+					StorageID: orphanID.String(),
+					Size:      1,
+				},
+			},
+			report.KeptUnreachableSlabs,
+		)
+	})
+
+	t.Run("undecodable slab", func(t *testing.T) {
+
+		t.Parallel()
+
+		address1 := common.MustBytesToAddress([]byte{0x1})
+		address2 := common.MustBytesToAddress([]byte{0x2})
+
+		registers, stringSlabs := newTestRegisters(t, address1, address2)
+
+		// Corrupt a slab of the first account
+
+		corruptedKey := stringSlabs[address1][0]
+		registers[corruptedKey] = []byte{0xff, 0xff}
+
+		// Add orphaned slabs to both accounts
+
+		orphanIndex := atree.StorageIndex{0, 0, 0, 0, 0, 0, 0, 100}
+
+		orphanID1 := atree.StorageID{
+			Address: atree.Address(address1),
+			Index:   orphanIndex,
+		}
+		registers[storageIDRegisterKey(orphanID1)] = []byte{0x1}
+
+		orphanID2 := atree.StorageID{
+			Address: atree.Address(address2),
+			Index:   orphanIndex,
+		}
+		registers[storageIDRegisterKey(orphanID2)] = []byte{0x1}
+
+		report := Analyze(registers)
+
+		assert.False(t, report.IsHealthy())
+		require.Len(t, report.UndecodableSlabs, 1)
+		assert.Equal(t,
+			corruptedKey.storageID().String(),
+			report.UndecodableSlabs[0].StorageID,
+		)
+		assert.Equal(t,
+			[]StorageEntry{
+				{
+					Address:       address1.HexWithPrefix(),
+					Domain:        "storage",
+					Key:           "array",
+					QuarantineKey: "storage/array",
+				},
+			},
+			report.QuarantinedValues,
+		)
+
+		// The orphaned slab of the first account is kept,
+		// as it might be referenced by the undecodable slab
+
+		assert.Equal(t,
+			[]UnreachableSlab{
+				{
+					StorageID: orphanID1.String(),
+					Size:      1,
+				},
+			},
+			report.KeptUnreachableSlabs,
+		)
+		assert.Equal(t,
+			[]UnreachableSlab{
+				{
+					StorageID: orphanID2.String(),
+					Size:      1,
+				},
+			},
+			report.OrphanedSlabs,
+		)
+	})
+}
+
+func TestRepair(t *testing.T) {
+
+	t.Parallel()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+
+	registers, stringSlabs := newTestRegisters(t, address)
+
+	// Add an orphaned slab, and delete a slab referenced by the array
+
+	orphanID := atree.StorageID{
+		Address: atree.Address(address),
+		Index:   atree.StorageIndex{0, 0, 0, 0, 0, 0, 0, 100},
+	}
+	registers[storageIDRegisterKey(orphanID)] = []byte{0x1}
+
+	delete(registers, stringSlabs[address][0])
+
+	report, err := Repair(registers)
+	require.NoError(t, err)
+
+	assert.True(t, report.Applied)
+	assert.Len(t, report.OrphanedSlabs, 1)
+	assert.Len(t, report.QuarantinedValues, 1)
+
+	// The orphaned slab got deleted
+
+	assert.NotContains(t, registers, storageIDRegisterKey(orphanID))
+
+	// The array got moved into quarantine, the string is still stored
+
+	storage := runtime.NewStorage(newLedger(registers), nil)
+
+	storageMap := storage.GetStorageMap(address, common.PathDomainStorage.Identifier(), false)
+	require.NotNil(t, storageMap)
+
+	assert.Nil(t, storageMap.ReadStorable(interpreter.StringStorageMapKey("array")))
+	assert.NotNil(t, storageMap.ReadStorable(interpreter.StringStorageMapKey("string")))
+
+	quarantineMap := storage.GetStorageMap(address, QuarantineStorageDomain, false)
+	require.NotNil(t, quarantineMap)
+
+	assert.NotNil(t, quarantineMap.ReadStorable(interpreter.StringStorageMapKey("storage/array")))
+
+	// Analyzing the repaired state only reports the dangling storage ID in the quarantined value
+
+	report = Analyze(registers)
+
+	assert.Len(t, report.DanglingStorageIDs, 1)
+	assert.Empty(t, report.QuarantinedValues)
+	assert.Empty(t, report.OrphanedSlabs)
+	assert.Empty(t, report.KeptUnreachableSlabs)
+}
+
+func TestReadWrite(t *testing.T) {
+
+	t.Parallel()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+
+	registers, _ := newTestRegisters(t, address)
+
+	var buffer bytes.Buffer
+	err := write(&buffer, registers)
+	require.NoError(t, err)
+
+	result, err := read(&buffer)
+	require.NoError(t, err)
+
+	assert.Equal(t, registers, result)
+}
@@ -0,0 +1,135 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// A utility program that replays a transaction or script execution
+// from a replay log, without access to the original host environment.
+//
+// With -debug, execution pauses at the first statement,
+// and the interactive debugger can be used to step through the execution.
+// Execution can also be paused by sending an interrupt signal (Ctrl+C).
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/cmd/execute"
+	"github.com/onflow/cadence/runtime/interpreter"
+)
+
+var debugFlag = flag.Bool("debug", false, "pause at the first statement and start the interactive debugger")
+
+func main() {
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		log.Fatal("missing path argument")
+	}
+
+	replayLog, err := readLog(args[0])
+	if err != nil {
+		log.Fatalf("Failed to read replay log: %s", err)
+	}
+
+	debugger := interpreter.NewDebugger()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	go func() {
+		for range signals {
+			debugger.RequestPause()
+		}
+	}()
+
+	if *debugFlag {
+		debugger.RequestPause()
+	}
+
+	type replayResult struct {
+		result *runtime.ReplayResult
+		err    error
+	}
+
+	done := make(chan replayResult)
+
+	go func() {
+		result, err := runtime.Replay(replayLog, debugger)
+		done <- replayResult{
+			result: result,
+			err:    err,
+		}
+	}()
+
+	for {
+		select {
+		case stop := <-debugger.Stops():
+			execute.NewInteractiveDebugger(debugger, stop).Run()
+
+		case replayed := <-done:
+			if replayed.err != nil {
+				log.Fatalf("Replay failed: %s", replayed.err)
+			}
+
+			printResult(replayed.result)
+			return
+		}
+	}
+}
+
+func readLog(path string) (*runtime.ReplayLog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var replayLog runtime.ReplayLog
+	err = json.Unmarshal(data, &replayLog)
+	if err != nil {
+		return nil, err
+	}
+
+	return &replayLog, nil
+}
+
+func printResult(result *runtime.ReplayResult) {
+	if result.Err != nil {
+		fmt.Printf("Replay succeeded, execution failed identically: %s\n", result.Err)
+		return
+	}
+
+	if result.Value == nil {
+		fmt.Println("Replay succeeded")
+		return
+	}
+
+	encoded, err := jsoncdc.Encode(result.Value)
+	if err != nil {
+		log.Fatalf("Failed to encode result: %s", err)
+	}
+
+	fmt.Printf("Replay succeeded, result: %s\n", encoded)
+}
@@ -0,0 +1,1249 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto/ecdh"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/onflow/atree"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
+)
+
+// serviceAddress is the address of the service account,
+// which pays for transactions by default and holds all deployed contracts.
+var serviceAddress = common.MustBytesToAddress([]byte{0x1})
+
+// accountStorageCapacity is the storage capacity of every account.
+// Storage limits are not enforced.
+const accountStorageCapacity = 100 * 1024 * 1024
+
+// accountKeyWeight is the weight of the key of accounts created by the blockchain.
+// It is also the total weight of keys required to sign for an account.
+const accountKeyWeight = 1000
+
+// defaultAccountKeys are the keys of accounts which are created without explicit keys.
+var defaultAccountKeys = []stdlib.Key{
+	{
+		SignAlgo: sema.SignatureAlgorithmECDSA_P256,
+		HashAlgo: sema.HashAlgorithmSHA3_256,
+		Weight:   accountKeyWeight,
+	},
+}
+
+// Blockchain is an in-memory blockchain which executes scripts and transactions
+// directly with the Cadence runtime.
+//
+// The blockchain keeps the complete state (registers, accounts, and contracts)
+// for every committed block, so it can be reset to any previous height,
+// and snapshots of the state can be created and loaded.
+type Blockchain struct {
+	runtime runtime.Runtime
+	// state is the state of the pending block.
+	// It is modified in place, so interfaces which hold it always see the current state.
+	state               *chainState
+	blocks              []*block
+	pendingEvents       []cadence.Event
+	pendingTransactions []*pendingTransaction
+	snapshots           map[string]*blockchainSnapshot
+	logs                []string
+	timeOffset          time.Duration
+	executionCount      uint64
+	readFile            func(path string) (string, error)
+	stdlibHandler       stdlib.StandardLibraryHandler
+	// coverageReport is the coverage of the contracts by all executed scripts and transactions
+	coverageReport *runtime.CoverageReport
+	// pendingBlockHeight is the height of the pending block.
+	// pendingBlockID and pendingBlockTimestamp replace the default ID and timestamp
+	// of the pending block, if they are set.
+	pendingBlockHeight    uint64
+	pendingBlockID        *stdlib.BlockHash
+	pendingBlockTimestamp *time.Time
+	// random generates the random values if a seed was set.
+	// Otherwise, random values are cryptographically secure.
+	random *rand.Rand
+	// queuedRandomValues are returned before any other random values
+	queuedRandomValues []uint64
+}
+
+var _ stdlib.Blockchain = &Blockchain{}
+var _ stdlib.EventImporter = &Blockchain{}
+
+// chainState is the complete state of the blockchain at a point in time.
+type chainState struct {
+	registers      map[registerKey][]byte
+	storageIndices map[string]atree.StorageIndex
+	accounts       map[common.Address]*account
+	lastAddress    uint64
+	uuid           uint64
+	// mocks are the addresses of the test doubles of mocked contracts, by contract name
+	mocks map[string]common.Address
+}
+
+type registerKey struct {
+	owner string
+	key   string
+}
+
+type account struct {
+	keys []stdlib.AccountKey
+	// sequenceNumbers are the sequence numbers of the keys which proposed transactions, by key index
+	sequenceNumbers map[int]uint64
+	contracts       map[string][]byte
+	lastID          uint64
+}
+
+type block struct {
+	height    uint64
+	id        stdlib.BlockHash
+	timestamp time.Time
+	// state is the state after the block was committed
+	state  *chainState
+	events []cadence.Event
+}
+
+type pendingTransaction struct {
+	code        string
+	authorizers []common.Address
+	// signers sign with all their keys
+	signers       []common.Address
+	keySignatures []stdlib.KeySignature
+	payer         common.Address
+	// proposalKey is nil if the first key of the payer proposes, with its current sequence number
+	proposalKey *stdlib.ProposalKey
+	arguments   [][]byte
+}
+
+type blockchainSnapshot struct {
+	state         *chainState
+	blocks        []*block
+	pendingEvents []cadence.Event
+}
+
+// NewBlockchain returns a new blockchain with a service account and a committed genesis block.
+// The given function is used to read the code of deployed contracts.
+func NewBlockchain(readFile func(path string) (string, error)) *Blockchain {
+	coverageReport := runtime.NewCoverageReport()
+
+	b := &Blockchain{
+		runtime: runtime.NewInterpreterRuntime(runtime.Config{
+			CoverageReport: coverageReport,
+		}),
+		coverageReport: coverageReport,
+		state: &chainState{
+			registers:      map[registerKey][]byte{},
+			storageIndices: map[string]atree.StorageIndex{},
+			accounts:       map[common.Address]*account{},
+			mocks:          map[string]common.Address{},
+		},
+		snapshots: map[string]*blockchainSnapshot{},
+		readFile:  readFile,
+	}
+
+	// Only contracts are covered, not scripts and transactions
+	coverageReport.WithLocationFilter(func(location common.Location) bool {
+		_, ok := location.(common.AddressLocation)
+		return ok
+	})
+
+	address := b.state.createAccount(defaultAccountKeys)
+	if address != serviceAddress {
+		panic(errors.NewUnreachableError())
+	}
+
+	err := b.CommitBlock()
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+func (s *chainState) clone() *chainState {
+	registers := make(map[registerKey][]byte, len(s.registers))
+	for key, value := range s.registers {
+		// NOTE: register values are never modified in place
+		registers[key] = value
+	}
+
+	storageIndices := make(map[string]atree.StorageIndex, len(s.storageIndices))
+	for owner, index := range s.storageIndices {
+		storageIndices[owner] = index
+	}
+
+	accounts := make(map[common.Address]*account, len(s.accounts))
+	for address, account := range s.accounts {
+		accounts[address] = account.clone()
+	}
+
+	mocks := make(map[string]common.Address, len(s.mocks))
+	for name, address := range s.mocks {
+		mocks[name] = address
+	}
+
+	return &chainState{
+		registers:      registers,
+		storageIndices: storageIndices,
+		accounts:       accounts,
+		lastAddress:    s.lastAddress,
+		uuid:           s.uuid,
+		mocks:          mocks,
+	}
+}
+
+// restore replaces the state with a copy of the given state.
+func (s *chainState) restore(other *chainState) {
+	*s = *other.clone()
+}
+
+// createAccount creates a new account with the given keys.
+// Addresses are assigned sequentially, and the public keys are derived from the address,
+// so the same sequence of operations always produces the same accounts.
+//
+// The keys must have been validated with validateAccountKeys.
+func (s *chainState) createAccount(keys []stdlib.Key) common.Address {
+	s.lastAddress++
+
+	var address common.Address
+	binary.BigEndian.PutUint64(address[:], s.lastAddress)
+
+	accountKeys := make([]stdlib.AccountKey, len(keys))
+	for index, key := range keys {
+		accountKeys[index] = stdlib.AccountKey{
+			PublicKey: accountPublicKey(address, index, key.SignAlgo),
+			KeyIndex:  index,
+			Weight:    key.Weight,
+			HashAlgo:  key.HashAlgo,
+		}
+	}
+
+	s.accounts[address] = &account{
+		keys:            accountKeys,
+		sequenceNumbers: map[int]uint64{},
+		contracts:       map[string][]byte{},
+	}
+
+	return address
+}
+
+// validateAccountKeys checks that accounts can be created with the given keys.
+func validateAccountKeys(keys []stdlib.Key) error {
+	for index, key := range keys {
+		if key.Weight < 0 || key.Weight > accountKeyWeight {
+			return errors.NewDefaultUserError(
+				"invalid weight %d of key %d: must be between 0 and %d",
+				key.Weight,
+				index,
+				accountKeyWeight,
+			)
+		}
+
+		if accountPublicKeyLength(key.SignAlgo) == 0 {
+			return errors.NewDefaultUserError(
+				"invalid signature algorithm of key %d: %s is not supported",
+				index,
+				key.SignAlgo.Name(),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (a *account) clone() *account {
+	keys := make([]stdlib.AccountKey, len(a.keys))
+	copy(keys, a.keys)
+
+	sequenceNumbers := make(map[int]uint64, len(a.sequenceNumbers))
+	for index, sequenceNumber := range a.sequenceNumbers {
+		sequenceNumbers[index] = sequenceNumber
+	}
+
+	contracts := make(map[string][]byte, len(a.contracts))
+	for name, code := range a.contracts {
+		contracts[name] = code
+	}
+
+	return &account{
+		keys:            keys,
+		sequenceNumbers: sequenceNumbers,
+		contracts:       contracts,
+		lastID:          a.lastID,
+	}
+}
+
+func (a *account) contractNames() []string {
+	names := make([]string, 0, len(a.contracts))
+	for name := range a.contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// accountPublicKeyLength returns the length of public keys of the given signature algorithm,
+// or 0 if the signature algorithm is not supported.
+func accountPublicKeyLength(signAlgo sema.SignatureAlgorithm) int {
+	switch signAlgo {
+	case sema.SignatureAlgorithmECDSA_P256,
+		sema.SignatureAlgorithmECDSA_secp256k1:
+		return 64
+	case sema.SignatureAlgorithmBLS_BLS12_381:
+		return 96
+	default:
+		return 0
+	}
+}
+
+// accountPublicKey returns the public key with the given index of the given account,
+// derived deterministically from the address and the key index.
+//
+// ECDSA P-256 keys are valid curve points. Keys of the other signature algorithms
+// only have the right length, as transaction signatures are not verified cryptographically.
+func accountPublicKey(
+	address common.Address,
+	keyIndex int,
+	signAlgo sema.SignatureAlgorithm,
+) *stdlib.PublicKey {
+	seedData := []byte("cadence-test-account-key:")
+	seedData = append(seedData, address[:]...)
+	seedData = binary.BigEndian.AppendUint64(seedData, uint64(keyIndex))
+	seed := sha256.Sum256(seedData)
+
+	if signAlgo != sema.SignatureAlgorithmECDSA_P256 {
+		var publicKey []byte
+		for len(publicKey) < accountPublicKeyLength(signAlgo) {
+			publicKey = append(publicKey, seed[:]...)
+			seed = sha256.Sum256(seed[:])
+		}
+
+		return &stdlib.PublicKey{
+			PublicKey: publicKey[:accountPublicKeyLength(signAlgo)],
+			SignAlgo:  signAlgo,
+		}
+	}
+
+	for {
+		privateKey, err := ecdh.P256().NewPrivateKey(seed[:])
+		if err == nil {
+			// Drop the uncompressed point prefix
+			publicKey := privateKey.PublicKey().Bytes()[1:]
+
+			return &stdlib.PublicKey{
+				PublicKey: publicKey,
+				SignAlgo:  sema.SignatureAlgorithmECDSA_P256,
+			}
+		}
+
+		// The seed is not a valid scalar, which is extremely unlikely
+		seed = sha256.Sum256(seed[:])
+	}
+}
+
+func (b *block) runtimeBlock() runtime.Block {
+	return runtime.Block{
+		Height:    b.height,
+		View:      b.height,
+		Hash:      b.id,
+		Timestamp: b.timestamp.UnixNano(),
+	}
+}
+
+// defaultBlockID returns the ID of the block with the given height,
+// unless the ID was set explicitly.
+func defaultBlockID(height uint64) stdlib.BlockHash {
+	var encodedHeight [8]byte
+	binary.BigEndian.PutUint64(encodedHeight[:], height)
+
+	return sha256.Sum256(encodedHeight[:])
+}
+
+func fixedBlock(block runtime.Block) func() runtime.Block {
+	return func() runtime.Block {
+		return block
+	}
+}
+
+func (b *Blockchain) latestBlock() *block {
+	return b.blocks[len(b.blocks)-1]
+}
+
+// pendingBlock returns the block in which transactions are currently executed.
+func (b *Blockchain) pendingBlock() *block {
+	pendingBlock := &block{
+		height:    b.pendingBlockHeight,
+		id:        defaultBlockID(b.pendingBlockHeight),
+		timestamp: b.now(),
+	}
+
+	if b.pendingBlockID != nil {
+		pendingBlock.id = *b.pendingBlockID
+	}
+
+	if b.pendingBlockTimestamp != nil {
+		pendingBlock.timestamp = *b.pendingBlockTimestamp
+	}
+
+	return pendingBlock
+}
+
+// resetPendingBlock starts a new pending block after the latest block,
+// with the default height, ID, and timestamp.
+func (b *Blockchain) resetPendingBlock() {
+	b.pendingBlockHeight = b.latestBlock().height + 1
+	b.pendingBlockID = nil
+	b.pendingBlockTimestamp = nil
+}
+
+// blockAtHeight returns the committed block with the given height.
+// Heights of blocks are increasing, but not necessarily consecutive.
+func (b *Blockchain) blockAtHeight(height uint64) (*block, bool) {
+	index := sort.Search(len(b.blocks), func(index int) bool {
+		return b.blocks[index].height >= height
+	})
+	if index == len(b.blocks) || b.blocks[index].height != height {
+		return nil, false
+	}
+	return b.blocks[index], true
+}
+
+func (b *Blockchain) now() time.Time {
+	return time.Now().Add(b.timeOffset)
+}
+
+func (b *Blockchain) account(address common.Address) (*stdlib.Account, error) {
+	account, ok := b.state.accounts[address]
+	if !ok {
+		return nil, errors.NewDefaultUserError("account %s does not exist", address.HexWithPrefix())
+	}
+
+	var publicKey *stdlib.PublicKey
+	if len(account.keys) > 0 {
+		publicKey = account.keys[0].PublicKey
+	}
+
+	return &stdlib.Account{
+		Address:   address,
+		PublicKey: publicKey,
+	}, nil
+}
+
+func (b *Blockchain) nextLocationID() (id [32]byte) {
+	b.executionCount++
+	binary.BigEndian.PutUint64(id[:], b.executionCount)
+	return
+}
+
+func (b *Blockchain) RunScript(
+	inter *interpreter.Interpreter,
+	code string,
+	arguments []interpreter.Value,
+) *stdlib.ScriptResult {
+
+	encodedArguments, err := encodeArguments(inter, arguments)
+	if err != nil {
+		return &stdlib.ScriptResult{
+			Error: err,
+		}
+	}
+
+	// Scripts are executed against the latest committed block,
+	// and all changes are discarded
+
+	latestBlock := b.latestBlock()
+
+	runtimeInterface := b.newExecutionInterface(
+		latestBlock.state.clone(),
+		fixedBlock(latestBlock.runtimeBlock()),
+	)
+
+	value, err := b.runtime.ExecuteScript(
+		runtime.Script{
+			Source:    []byte(code),
+			Arguments: encodedArguments,
+		},
+		runtime.Context{
+			Interface:      runtimeInterface,
+			Location:       common.ScriptLocation(b.nextLocationID()),
+			CoverageReport: b.coverageReport,
+		},
+	)
+
+	b.logs = append(b.logs, runtimeInterface.logs...)
+
+	if err != nil {
+		return &stdlib.ScriptResult{
+			Error:           err,
+			ComputationUsed: runtimeInterface.computationUsed,
+			MemoryUsed:      runtimeInterface.memoryUsed,
+		}
+	}
+
+	result, err := runtime.ImportValue(
+		inter,
+		interpreter.EmptyLocationRange,
+		b.stdlibHandler,
+		value,
+		nil,
+	)
+	if err != nil {
+		return &stdlib.ScriptResult{
+			Error: err,
+		}
+	}
+
+	return &stdlib.ScriptResult{
+		Value:           result,
+		ComputationUsed: runtimeInterface.computationUsed,
+		MemoryUsed:      runtimeInterface.memoryUsed,
+	}
+}
+
+func encodeArguments(inter *interpreter.Interpreter, arguments []interpreter.Value) ([][]byte, error) {
+	encodedArguments := make([][]byte, 0, len(arguments))
+
+	for _, argument := range arguments {
+		exportedArgument, err := runtime.ExportValue(
+			argument,
+			inter,
+			interpreter.EmptyLocationRange,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedArgument, err := jsoncdc.Encode(exportedArgument)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedArguments = append(encodedArguments, encodedArgument)
+	}
+
+	return encodedArguments, nil
+}
+
+func (b *Blockchain) CreateAccount() (*stdlib.Account, error) {
+	return b.CreateAccountWithKeys(defaultAccountKeys)
+}
+
+func (b *Blockchain) CreateAccountWithKeys(keys []stdlib.Key) (*stdlib.Account, error) {
+	err := validateAccountKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	address := b.state.createAccount(keys)
+	return b.account(address)
+}
+
+func (b *Blockchain) GetAccount(address interpreter.AddressValue) (*stdlib.Account, error) {
+	return b.account(common.Address(address))
+}
+
+func (b *Blockchain) SequenceNumber(address common.Address, keyIndex int) (uint64, error) {
+	account, ok := b.state.accounts[address]
+	if !ok {
+		return 0, errors.NewDefaultUserError("account %s does not exist", address.HexWithPrefix())
+	}
+
+	if keyIndex < 0 || keyIndex >= len(account.keys) {
+		return 0, errors.NewDefaultUserError(
+			"key %d of account %s does not exist",
+			keyIndex,
+			address.HexWithPrefix(),
+		)
+	}
+
+	return account.sequenceNumbers[keyIndex], nil
+}
+
+func (b *Blockchain) AddTransaction(
+	inter *interpreter.Interpreter,
+	transaction *stdlib.Transaction,
+) error {
+
+	encodedArguments, err := encodeArguments(inter, transaction.Arguments)
+	if err != nil {
+		return err
+	}
+
+	signerAddresses := make([]common.Address, 0, len(transaction.Signers))
+	for _, signer := range transaction.Signers {
+		signerAddresses = append(signerAddresses, signer.Address)
+	}
+
+	payer := serviceAddress
+	if transaction.Payer != nil {
+		payer = *transaction.Payer
+	}
+
+	b.pendingTransactions = append(
+		b.pendingTransactions,
+		&pendingTransaction{
+			code:          transaction.Code,
+			authorizers:   transaction.Authorizers,
+			signers:       signerAddresses,
+			keySignatures: transaction.KeySignatures,
+			payer:         payer,
+			proposalKey:   transaction.ProposalKey,
+			arguments:     encodedArguments,
+		},
+	)
+
+	return nil
+}
+
+func (b *Blockchain) ExecuteNextTransaction() *stdlib.TransactionResult {
+	if len(b.pendingTransactions) == 0 {
+		return nil
+	}
+
+	transaction := b.pendingTransactions[0]
+	b.pendingTransactions = b.pendingTransactions[1:]
+
+	outcome, err := b.executeTransaction(transaction)
+
+	return &stdlib.TransactionResult{
+		Error:           err,
+		Events:          outcome.events,
+		Logs:            outcome.logs,
+		ComputationUsed: outcome.computationUsed,
+		MemoryUsed:      outcome.memoryUsed,
+	}
+}
+
+// transactionOutcome is the outcome of the execution of a transaction.
+type transactionOutcome struct {
+	events          []cadence.Event
+	logs            []string
+	computationUsed uint64
+	memoryUsed      uint64
+}
+
+// executeTransaction executes the given transaction in the pending block,
+// and returns the events, logs, and resource usage of the transaction.
+// If the transaction fails, all its changes are reverted, and no events are returned.
+func (b *Blockchain) executeTransaction(
+	transaction *pendingTransaction,
+) (
+	outcome transactionOutcome,
+	err error,
+) {
+
+	for _, authorizer := range transaction.authorizers {
+		if _, ok := b.state.accounts[authorizer]; !ok {
+			return outcome, errors.NewDefaultUserError("authorizer %s does not exist", authorizer.HexWithPrefix())
+		}
+	}
+
+	proposalKey, err := b.state.checkSignatures(transaction)
+	if err != nil {
+		return outcome, err
+	}
+
+	// Like on Flow, the sequence number of the proposal key is incremented
+	// even if the transaction fails
+	b.state.accounts[proposalKey.Address].sequenceNumbers[proposalKey.KeyIndex]++
+
+	previousState := b.state.clone()
+
+	runtimeInterface := b.newExecutionInterface(
+		b.state,
+		fixedBlock(b.pendingBlock().runtimeBlock()),
+	)
+	runtimeInterface.signers = transaction.authorizers
+
+	err = b.runtime.ExecuteTransaction(
+		runtime.Script{
+			Source:    []byte(transaction.code),
+			Arguments: transaction.arguments,
+		},
+		runtime.Context{
+			Interface:      runtimeInterface,
+			Location:       common.TransactionLocation(b.nextLocationID()),
+			CoverageReport: b.coverageReport,
+		},
+	)
+
+	b.logs = append(b.logs, runtimeInterface.logs...)
+
+	outcome.logs = runtimeInterface.logs
+	outcome.computationUsed = runtimeInterface.computationUsed
+	outcome.memoryUsed = runtimeInterface.memoryUsed
+
+	if err != nil {
+		b.state.restore(previousState)
+		return outcome, err
+	}
+
+	b.pendingEvents = append(b.pendingEvents, runtimeInterface.events...)
+
+	outcome.events = runtimeInterface.events
+
+	return outcome, nil
+}
+
+// checkSignatures checks the signatures of the transaction, like Flow does,
+// and returns the proposal key of the transaction:
+//   - The proposal key must sign, and must have the current sequence number of the key.
+//   - The payer and each authorizer must sign with keys of a total weight of at least 1000.
+//   - Revoked keys cannot sign.
+//
+// The signers sign with all their keys which are not revoked.
+// The service account always signs with all its keys.
+func (s *chainState) checkSignatures(transaction *pendingTransaction) (*stdlib.ProposalKey, error) {
+	signatures := map[stdlib.KeySignature]struct{}{}
+	weights := map[common.Address]int{}
+
+	sign := func(signature stdlib.KeySignature) error {
+		account, ok := s.accounts[signature.Address]
+		if !ok {
+			return errors.NewDefaultUserError(
+				"signer %s does not exist",
+				signature.Address.HexWithPrefix(),
+			)
+		}
+
+		if signature.KeyIndex < 0 || signature.KeyIndex >= len(account.keys) {
+			return errors.NewDefaultUserError(
+				"key %d of signer %s does not exist",
+				signature.KeyIndex,
+				signature.Address.HexWithPrefix(),
+			)
+		}
+
+		key := account.keys[signature.KeyIndex]
+		if key.IsRevoked {
+			return errors.NewDefaultUserError(
+				"key %d of signer %s is revoked",
+				signature.KeyIndex,
+				signature.Address.HexWithPrefix(),
+			)
+		}
+
+		if _, ok := signatures[signature]; ok {
+			return nil
+		}
+		signatures[signature] = struct{}{}
+		weights[signature.Address] += key.Weight
+
+		return nil
+	}
+
+	signWithAllKeys := func(address common.Address) error {
+		account, ok := s.accounts[address]
+		if !ok {
+			return errors.NewDefaultUserError("signer %s does not exist", address.HexWithPrefix())
+		}
+
+		for index, key := range account.keys {
+			if key.IsRevoked {
+				continue
+			}
+
+			err := sign(stdlib.KeySignature{
+				Address:  address,
+				KeyIndex: index,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	signerAddresses := append([]common.Address{serviceAddress}, transaction.signers...)
+	for _, address := range signerAddresses {
+		err := signWithAllKeys(address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, signature := range transaction.keySignatures {
+		err := sign(signature)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Check the proposal key
+
+	proposalKey := transaction.proposalKey
+	if proposalKey == nil {
+		if _, ok := s.accounts[transaction.payer]; !ok {
+			return nil, errors.NewDefaultUserError(
+				"payer %s does not exist",
+				transaction.payer.HexWithPrefix(),
+			)
+		}
+
+		proposalKey = &stdlib.ProposalKey{
+			Address:        transaction.payer,
+			KeyIndex:       0,
+			SequenceNumber: s.accounts[transaction.payer].sequenceNumbers[0],
+		}
+	}
+
+	proposalSignature := stdlib.KeySignature{
+		Address:  proposalKey.Address,
+		KeyIndex: proposalKey.KeyIndex,
+	}
+	if _, ok := signatures[proposalSignature]; !ok {
+		return nil, errors.NewDefaultUserError(
+			"proposal key %d of account %s did not sign the transaction",
+			proposalKey.KeyIndex,
+			proposalKey.Address.HexWithPrefix(),
+		)
+	}
+
+	sequenceNumber := s.accounts[proposalKey.Address].sequenceNumbers[proposalKey.KeyIndex]
+	if proposalKey.SequenceNumber != sequenceNumber {
+		return nil, errors.NewDefaultUserError(
+			"invalid sequence number %d of proposal key %d of account %s: expected %d",
+			proposalKey.SequenceNumber,
+			proposalKey.KeyIndex,
+			proposalKey.Address.HexWithPrefix(),
+			sequenceNumber,
+		)
+	}
+
+	// Check the weights of the payer and the authorizers
+
+	checkWeight := func(role string, address common.Address) error {
+		weight := weights[address]
+
+		if weight == 0 {
+			return errors.NewDefaultUserError(
+				"%s %s did not sign the transaction",
+				role,
+				address.HexWithPrefix(),
+			)
+		}
+
+		if weight < accountKeyWeight {
+			return errors.NewDefaultUserError(
+				"%s %s signed the transaction with keys of total weight %d, but at least %d is required",
+				role,
+				address.HexWithPrefix(),
+				weight,
+				accountKeyWeight,
+			)
+		}
+
+		return nil
+	}
+
+	err := checkWeight("payer", transaction.payer)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, authorizer := range transaction.authorizers {
+		err := checkWeight("authorizer", authorizer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return proposalKey, nil
+}
+
+func (b *Blockchain) CommitBlock() error {
+	if len(b.pendingTransactions) > 0 {
+		return errors.NewDefaultUserError(
+			"cannot commit block: %d transaction(s) not executed",
+			len(b.pendingTransactions),
+		)
+	}
+
+	committedBlock := b.pendingBlock()
+	committedBlock.state = b.state.clone()
+	committedBlock.events = b.pendingEvents
+
+	b.blocks = append(b.blocks, committedBlock)
+	b.pendingEvents = nil
+	b.resetPendingBlock()
+
+	return nil
+}
+
+const deployContractTransactionTemplate = `
+transaction(name: String, code: String%[1]s) {
+    prepare(signer: AuthAccount) {
+        signer.contracts.add(name: name, code: code.utf8%[2]s)
+    }
+}
+`
+
+// DeployContract deploys the contract in the given file to the service account,
+// and commits the block.
+func (b *Blockchain) DeployContract(
+	inter *interpreter.Interpreter,
+	name string,
+	path string,
+	arguments []interpreter.Value,
+) error {
+
+	code, err := b.readFile(path)
+	if err != nil {
+		return err
+	}
+
+	err = b.deployContract(inter, serviceAddress, name, code, arguments)
+	if err != nil {
+		return err
+	}
+
+	return b.CommitBlock()
+}
+
+// MockContract deploys the given code of a test double for the contract with the given name
+// to a new account, and commits the block.
+// From then on, all imports of the contract resolve to the test double.
+func (b *Blockchain) MockContract(
+	inter *interpreter.Interpreter,
+	name string,
+	code string,
+	arguments []interpreter.Value,
+) error {
+
+	previousState := b.state.clone()
+
+	address := b.state.createAccount(defaultAccountKeys)
+
+	// Test doubles are not covered
+	b.coverageReport.ExcludeLocation(common.AddressLocation{
+		Address: address,
+		Name:    name,
+	})
+
+	err := b.deployContract(inter, address, name, code, arguments)
+	if err != nil {
+		b.state.restore(previousState)
+		return err
+	}
+
+	b.state.mocks[name] = address
+
+	return b.CommitBlock()
+}
+
+// deployContract deploys the given contract code to the given account,
+// by executing a transaction authorized by the account.
+func (b *Blockchain) deployContract(
+	inter *interpreter.Interpreter,
+	address common.Address,
+	name string,
+	code string,
+	arguments []interpreter.Value,
+) error {
+
+	// The contract initializer arguments are passed as additional transaction parameters
+
+	var parameters, parameterArguments strings.Builder
+
+	for i, argument := range arguments {
+		exportedArgument, err := runtime.ExportValue(
+			argument,
+			inter,
+			interpreter.EmptyLocationRange,
+		)
+		if err != nil {
+			return err
+		}
+
+		argumentType, err := runtime.EncodeLiteralType(exportedArgument.Type())
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(&parameters, ", arg%d: %s", i, argumentType)
+		fmt.Fprintf(&parameterArguments, ", arg%d", i)
+	}
+
+	transactionArguments := []interpreter.Value{
+		interpreter.NewUnmeteredStringValue(name),
+		interpreter.NewUnmeteredStringValue(code),
+	}
+	transactionArguments = append(transactionArguments, arguments...)
+
+	encodedArguments, err := encodeArguments(inter, transactionArguments)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.executeTransaction(&pendingTransaction{
+		code: fmt.Sprintf(
+			deployContractTransactionTemplate,
+			parameters.String(),
+			parameterArguments.String(),
+		),
+		authorizers: []common.Address{address},
+		signers:     []common.Address{address},
+		payer:       serviceAddress,
+		arguments:   encodedArguments,
+	})
+	return err
+}
+
+// Coverage returns the coverage of the contracts
+// by all scripts and transactions executed so far.
+func (b *Blockchain) Coverage() *stdlib.Coverage {
+	summary := b.coverageReport.Summary()
+	return &stdlib.Coverage{
+		Locations:  summary.Locations,
+		Statements: summary.Statements,
+		Hits:       summary.Hits,
+		Misses:     summary.Misses,
+	}
+}
+
+func (b *Blockchain) StandardLibraryHandler() stdlib.StandardLibraryHandler {
+	return b.stdlibHandler
+}
+
+func (b *Blockchain) Logs() []string {
+	return b.logs
+}
+
+func (b *Blockchain) ServiceAccount() (*stdlib.Account, error) {
+	return b.account(serviceAddress)
+}
+
+// Events returns all events of the committed blocks and of the pending block,
+// optionally filtered by the given event type.
+func (b *Blockchain) Events(
+	inter *interpreter.Interpreter,
+	eventType interpreter.StaticType,
+) interpreter.Value {
+
+	var events []cadence.Event
+	for _, block := range b.blocks {
+		events = append(events, block.events...)
+	}
+	events = append(events, b.pendingEvents...)
+
+	return interpreter.NewArrayValue(
+		inter,
+		interpreter.EmptyLocationRange,
+		interpreter.NewVariableSizedStaticType(
+			inter,
+			interpreter.PrimitiveStaticTypeAnyStruct,
+		),
+		common.ZeroAddress,
+		b.importEvents(inter, events, eventType)...,
+	)
+}
+
+// importEvents imports the given events into the given interpreter,
+// optionally filtered by the given event type.
+func (b *Blockchain) importEvents(
+	inter *interpreter.Interpreter,
+	events []cadence.Event,
+	eventType interpreter.StaticType,
+) []interpreter.Value {
+
+	values := make([]interpreter.Value, 0, len(events))
+
+	for _, event := range events {
+		if eventType != nil && event.EventType.ID() != string(eventType.ID()) {
+			continue
+		}
+
+		value, err := b.ImportEvent(inter, event)
+		if err != nil {
+			panic(err)
+		}
+
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// ImportEvent imports the given event into the given interpreter.
+func (b *Blockchain) ImportEvent(
+	inter *interpreter.Interpreter,
+	event cadence.Event,
+) (interpreter.Value, error) {
+	return runtime.ImportValue(
+		inter,
+		interpreter.EmptyLocationRange,
+		b.stdlibHandler,
+		event,
+		nil,
+	)
+}
+
+// Reset resets the blockchain to the block with the given height.
+// All later blocks, and all pending transactions and events are discarded.
+func (b *Blockchain) Reset(height uint64) {
+	if height > b.latestBlock().height {
+		return
+	}
+
+	// Keep all blocks up to the given height
+	count := sort.Search(len(b.blocks), func(index int) bool {
+		return b.blocks[index].height > height
+	})
+
+	b.blocks = b.blocks[:count]
+	b.state.restore(b.latestBlock().state)
+	b.pendingTransactions = nil
+	b.pendingEvents = nil
+	b.resetPendingBlock()
+}
+
+// MoveTime moves the time of the blockchain by the given number of seconds.
+// The time of already committed blocks is not changed.
+func (b *Blockchain) MoveTime(delta int64) {
+	b.timeOffset += time.Duration(delta) * time.Second
+}
+
+func (b *Blockchain) CreateSnapshot(name string) error {
+	blocks := make([]*block, len(b.blocks))
+	copy(blocks, b.blocks)
+
+	pendingEvents := make([]cadence.Event, len(b.pendingEvents))
+	copy(pendingEvents, b.pendingEvents)
+
+	b.snapshots[name] = &blockchainSnapshot{
+		state:         b.state.clone(),
+		blocks:        blocks,
+		pendingEvents: pendingEvents,
+	}
+
+	return nil
+}
+
+func (b *Blockchain) LoadSnapshot(name string) error {
+	snapshot, ok := b.snapshots[name]
+	if !ok {
+		return errors.NewDefaultUserError("snapshot %s does not exist", name)
+	}
+
+	blocks := make([]*block, len(snapshot.blocks))
+	copy(blocks, snapshot.blocks)
+
+	pendingEvents := make([]cadence.Event, len(snapshot.pendingEvents))
+	copy(pendingEvents, snapshot.pendingEvents)
+
+	b.state.restore(snapshot.state)
+	b.blocks = blocks
+	b.pendingEvents = pendingEvents
+	b.pendingTransactions = nil
+	b.resetPendingBlock()
+
+	return nil
+}
+
+// SetRandomSeed seeds the generator of random values,
+// so the same random values are generated in every run.
+func (b *Blockchain) SetRandomSeed(seed uint64) {
+	b.random = rand.New(rand.NewSource(int64(seed)))
+}
+
+// QueueRandomValues queues the given values,
+// which are returned before any other random values.
+func (b *Blockchain) QueueRandomValues(values []uint64) {
+	b.queuedRandomValues = append(b.queuedRandomValues, values...)
+}
+
+// SetBlockHeight sets the height of the pending block.
+// The heights of later blocks follow the given height.
+func (b *Blockchain) SetBlockHeight(height uint64) error {
+	latestHeight := b.latestBlock().height
+	if height <= latestHeight {
+		return errors.NewDefaultUserError(
+			"invalid block height %d: must be greater than the height of the latest block, %d",
+			height,
+			latestHeight,
+		)
+	}
+
+	b.pendingBlockHeight = height
+	return nil
+}
+
+// SetBlockID sets the ID of the pending block.
+func (b *Blockchain) SetBlockID(id []byte) error {
+	if len(id) != stdlib.BlockHashLength {
+		return errors.NewDefaultUserError(
+			"invalid block ID: must be %d bytes long, got %d",
+			stdlib.BlockHashLength,
+			len(id),
+		)
+	}
+
+	var blockID stdlib.BlockHash
+	copy(blockID[:], id)
+	b.pendingBlockID = &blockID
+	return nil
+}
+
+// SetBlockTimestamp sets the timestamp of the pending block.
+// Later blocks have the time of the blockchain again.
+func (b *Blockchain) SetBlockTimestamp(timestamp time.Time) {
+	b.pendingBlockTimestamp = &timestamp
+}
+
+// readRandom fills the given buffer with random bytes.
+// Queued values are used first, then values of the seeded generator, if any.
+// Values are encoded in little-endian byte order, like they are decoded by `revertibleRandom`.
+func (b *Blockchain) readRandom(buffer []byte) error {
+	for len(buffer) > 0 {
+		var value [8]byte
+
+		switch {
+		case len(b.queuedRandomValues) > 0:
+			binary.LittleEndian.PutUint64(value[:], b.queuedRandomValues[0])
+			b.queuedRandomValues = b.queuedRandomValues[1:]
+
+		case b.random != nil:
+			binary.LittleEndian.PutUint64(value[:], b.random.Uint64())
+
+		default:
+			_, err := cryptorand.Read(buffer)
+			return err
+		}
+
+		n := copy(buffer, value[:])
+		buffer = buffer[n:]
+	}
+
+	return nil
+}
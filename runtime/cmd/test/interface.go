@@ -0,0 +1,573 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/onflow/atree"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
+)
+
+// executionInterface is the runtime interface for a single execution
+// of a script or transaction on the blockchain,
+// or for the interpreter of a test file.
+type executionInterface struct {
+	blockchain *Blockchain
+	state      *chainState
+	// block returns the current block
+	block       func() runtime.Block
+	signers     []common.Address
+	programs    map[common.Location]*interpreter.Program
+	sharedState *interpreter.SharedState
+	logs        []string
+	events      []cadence.Event
+	// onLog, if set, is called for each log message instead of collecting it
+	onLog func(message string)
+	// computationUsed is the sum of all metered computation intensities
+	computationUsed uint64
+	// memoryUsed is the sum of all metered memory amounts
+	memoryUsed uint64
+}
+
+var _ runtime.Interface = &executionInterface{}
+
+func (b *Blockchain) newExecutionInterface(state *chainState, block func() runtime.Block) *executionInterface {
+	return &executionInterface{
+		blockchain: b,
+		state:      state,
+		block:      block,
+		programs:   map[common.Location]*interpreter.Program{},
+	}
+}
+
+func (i *executionInterface) account(address common.Address) (*account, error) {
+	account, ok := i.state.accounts[address]
+	if !ok {
+		return nil, errors.NewDefaultUserError("account %s does not exist", address.HexWithPrefix())
+	}
+	return account, nil
+}
+
+func (i *executionInterface) MeterMemory(usage common.MemoryUsage) error {
+	i.memoryUsed += usage.Amount
+	return nil
+}
+
+func (i *executionInterface) MeterComputation(_ common.ComputationKind, intensity uint) error {
+	i.computationUsed += uint64(intensity)
+	return nil
+}
+
+func (i *executionInterface) ComputationUsed() (uint64, error) {
+	return i.computationUsed, nil
+}
+
+func (i *executionInterface) MemoryUsed() (uint64, error) {
+	return i.memoryUsed, nil
+}
+
+func (i *executionInterface) InteractionUsed() (uint64, error) {
+	return 0, nil
+}
+
+func (i *executionInterface) ResolveLocation(
+	identifiers []runtime.Identifier,
+	location runtime.Location,
+) (
+	[]runtime.ResolvedLocation,
+	error,
+) {
+	switch location := location.(type) {
+	case common.AddressLocation:
+		// Without identifiers, all contracts of the account are imported
+		if len(identifiers) == 0 {
+			account, err := i.account(location.Address)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, name := range account.contractNames() {
+				identifiers = append(identifiers, runtime.Identifier{
+					Identifier: name,
+				})
+			}
+		}
+
+		resolvedLocations := make([]runtime.ResolvedLocation, 0, len(identifiers))
+		for _, identifier := range identifiers {
+			// Imports of mocked contracts resolve to the test double
+			address, ok := i.state.mocks[identifier.Identifier]
+			if !ok {
+				address = location.Address
+			}
+
+			resolvedLocations = append(resolvedLocations, runtime.ResolvedLocation{
+				Location: common.AddressLocation{
+					Address: address,
+					Name:    identifier.Identifier,
+				},
+				Identifiers: []runtime.Identifier{identifier},
+			})
+		}
+		return resolvedLocations, nil
+
+	case common.StringLocation:
+		// A string import imports the deployed contract with the given name
+		name := string(location)
+		address, ok := i.state.mocks[name]
+		if !ok {
+			address, ok = i.contractAddress(name)
+		}
+		if !ok {
+			return nil, errors.NewDefaultUserError("cannot find deployed contract %s", name)
+		}
+
+		return []runtime.ResolvedLocation{
+			{
+				Location: common.AddressLocation{
+					Address: address,
+					Name:    name,
+				},
+				Identifiers: []runtime.Identifier{
+					{
+						Identifier: name,
+					},
+				},
+			},
+		}, nil
+
+	default:
+		return []runtime.ResolvedLocation{
+			{
+				Location:    location,
+				Identifiers: identifiers,
+			},
+		}, nil
+	}
+}
+
+// contractAddress returns the address of the account with the lowest address
+// which has a contract with the given name deployed.
+func (i *executionInterface) contractAddress(name string) (common.Address, bool) {
+	addresses := make([]common.Address, 0, len(i.state.accounts))
+	for address := range i.state.accounts {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(a, b int) bool {
+		return bytes.Compare(addresses[a][:], addresses[b][:]) < 0
+	})
+
+	for _, address := range addresses {
+		if _, ok := i.state.accounts[address].contracts[name]; ok {
+			return address, true
+		}
+	}
+
+	return common.ZeroAddress, false
+}
+
+func (i *executionInterface) GetCode(location runtime.Location) ([]byte, error) {
+	return nil, errors.NewDefaultUserError("cannot import %s", location)
+}
+
+func (i *executionInterface) GetOrLoadProgram(
+	location runtime.Location,
+	load func() (*interpreter.Program, error),
+) (
+	program *interpreter.Program,
+	err error,
+) {
+	program, ok := i.programs[location]
+	if ok {
+		return program, nil
+	}
+
+	program, err = load()
+
+	// NOTE: important: still set empty program,
+	// even if error occurred
+
+	i.programs[location] = program
+
+	return program, err
+}
+
+func (i *executionInterface) SetInterpreterSharedState(state *interpreter.SharedState) {
+	i.sharedState = state
+}
+
+func (i *executionInterface) GetInterpreterSharedState() *interpreter.SharedState {
+	return i.sharedState
+}
+
+func (i *executionInterface) GetValue(owner, key []byte) ([]byte, error) {
+	return i.state.registers[registerKey{string(owner), string(key)}], nil
+}
+
+func (i *executionInterface) SetValue(owner, key, value []byte) error {
+	registerKey := registerKey{string(owner), string(key)}
+	if len(value) == 0 {
+		delete(i.state.registers, registerKey)
+	} else {
+		i.state.registers[registerKey] = append([]byte(nil), value...)
+	}
+	return nil
+}
+
+func (i *executionInterface) ValueExists(owner, key []byte) (bool, error) {
+	_, ok := i.state.registers[registerKey{string(owner), string(key)}]
+	return ok, nil
+}
+
+func (i *executionInterface) AllocateStorageIndex(owner []byte) (atree.StorageIndex, error) {
+	index := i.state.storageIndices[string(owner)].Next()
+	i.state.storageIndices[string(owner)] = index
+	return index, nil
+}
+
+func (i *executionInterface) CreateAccount(_ runtime.Address) (runtime.Address, error) {
+	return i.state.createAccount(defaultAccountKeys), nil
+}
+
+func (i *executionInterface) AddEncodedAccountKey(_ runtime.Address, _ []byte) error {
+	return errors.NewDefaultUserError("encoded account keys are not supported")
+}
+
+func (i *executionInterface) RevokeEncodedAccountKey(_ runtime.Address, _ int) ([]byte, error) {
+	return nil, errors.NewDefaultUserError("encoded account keys are not supported")
+}
+
+func (i *executionInterface) AddAccountKey(
+	address runtime.Address,
+	publicKey *runtime.PublicKey,
+	hashAlgo runtime.HashAlgorithm,
+	weight int,
+) (*runtime.AccountKey, error) {
+	account, err := i.account(address)
+	if err != nil {
+		return nil, err
+	}
+
+	key := runtime.AccountKey{
+		PublicKey: publicKey,
+		KeyIndex:  len(account.keys),
+		Weight:    weight,
+		HashAlgo:  hashAlgo,
+	}
+	account.keys = append(account.keys, key)
+
+	return &key, nil
+}
+
+func (i *executionInterface) GetAccountKey(address runtime.Address, index int) (*runtime.AccountKey, error) {
+	account, err := i.account(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if index < 0 || index >= len(account.keys) {
+		return nil, nil
+	}
+
+	key := account.keys[index]
+	return &key, nil
+}
+
+func (i *executionInterface) AccountKeysCount(address runtime.Address) (uint64, error) {
+	account, err := i.account(address)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(len(account.keys)), nil
+}
+
+func (i *executionInterface) RevokeAccountKey(address runtime.Address, index int) (*runtime.AccountKey, error) {
+	account, err := i.account(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if index < 0 || index >= len(account.keys) {
+		return nil, nil
+	}
+
+	account.keys[index].IsRevoked = true
+
+	key := account.keys[index]
+	return &key, nil
+}
+
+func (i *executionInterface) UpdateAccountContractCode(location common.AddressLocation, code []byte) error {
+	account, err := i.account(location.Address)
+	if err != nil {
+		return err
+	}
+
+	account.contracts[location.Name] = code
+	return nil
+}
+
+func (i *executionInterface) GetAccountContractCode(location common.AddressLocation) ([]byte, error) {
+	account, err := i.account(location.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	return account.contracts[location.Name], nil
+}
+
+func (i *executionInterface) RemoveAccountContractCode(location common.AddressLocation) error {
+	account, err := i.account(location.Address)
+	if err != nil {
+		return err
+	}
+
+	delete(account.contracts, location.Name)
+	return nil
+}
+
+func (i *executionInterface) GetSigningAccounts() ([]runtime.Address, error) {
+	return i.signers, nil
+}
+
+func (i *executionInterface) ProgramLog(message string) error {
+	if i.onLog != nil {
+		i.onLog(message)
+		return nil
+	}
+
+	i.logs = append(i.logs, message)
+	return nil
+}
+
+func (i *executionInterface) EmitEvent(event cadence.Event) error {
+	i.events = append(i.events, event)
+	return nil
+}
+
+func (i *executionInterface) GenerateUUID() (uint64, error) {
+	i.state.uuid++
+	return i.state.uuid, nil
+}
+
+func (i *executionInterface) DecodeArgument(argument []byte, _ cadence.Type) (cadence.Value, error) {
+	return jsoncdc.Decode(nil, argument)
+}
+
+func (i *executionInterface) GetCurrentBlockHeight() (uint64, error) {
+	return i.block().Height, nil
+}
+
+func (i *executionInterface) GetBlockAtHeight(height uint64) (runtime.Block, bool, error) {
+	currentBlock := i.block()
+	if height == currentBlock.Height {
+		return currentBlock, true, nil
+	}
+
+	block, ok := i.blockchain.blockAtHeight(height)
+	if !ok {
+		return runtime.Block{}, false, nil
+	}
+
+	return block.runtimeBlock(), true, nil
+}
+
+func (i *executionInterface) ReadRandom(buffer []byte) error {
+	return i.blockchain.readRandom(buffer)
+}
+
+func (i *executionInterface) VerifySignature(
+	signature []byte,
+	tag string,
+	signedData []byte,
+	publicKey []byte,
+	signatureAlgorithm runtime.SignatureAlgorithm,
+	hashAlgorithm runtime.HashAlgorithm,
+) (bool, error) {
+	if signatureAlgorithm != runtime.SignatureAlgorithmECDSA_P256 {
+		return false, errors.NewDefaultUserError(
+			"signature algorithm %s is not supported",
+			signatureAlgorithm.Name(),
+		)
+	}
+
+	digest, err := i.Hash(signedData, tag, hashAlgorithm)
+	if err != nil {
+		return false, err
+	}
+
+	if len(signature) != 64 || len(publicKey) != 64 {
+		return false, nil
+	}
+
+	key := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(publicKey[:32]),
+		Y:     new(big.Int).SetBytes(publicKey[32:]),
+	}
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+
+	return ecdsa.Verify(key, digest, r, s), nil
+}
+
+// hashTagLength is the length to which non-empty hash tags are padded.
+const hashTagLength = 32
+
+func (i *executionInterface) Hash(data []byte, tag string, hashAlgorithm runtime.HashAlgorithm) ([]byte, error) {
+	var hasher hash.Hash
+
+	switch hashAlgorithm {
+	case runtime.HashAlgorithmSHA2_256:
+		hasher = sha256.New()
+	case runtime.HashAlgorithmSHA2_384:
+		hasher = sha512.New384()
+	case runtime.HashAlgorithmSHA3_256:
+		hasher = sha3.New256()
+	case runtime.HashAlgorithmSHA3_384:
+		hasher = sha3.New384()
+	case runtime.HashAlgorithmKECCAK_256:
+		hasher = sha3.NewLegacyKeccak256()
+	default:
+		return nil, errors.NewDefaultUserError(
+			"hash algorithm %s is not supported",
+			hashAlgorithm.Name(),
+		)
+	}
+
+	if tag != "" {
+		if len(tag) > hashTagLength {
+			return nil, errors.NewDefaultUserError(
+				"hash tag is longer than %d bytes",
+				hashTagLength,
+			)
+		}
+
+		var paddedTag [hashTagLength]byte
+		copy(paddedTag[:], tag)
+		hasher.Write(paddedTag[:])
+	}
+
+	hasher.Write(data)
+
+	return hasher.Sum(nil), nil
+}
+
+func (i *executionInterface) GetAccountBalance(_ common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (i *executionInterface) GetAccountAvailableBalance(_ common.Address) (uint64, error) {
+	return 0, nil
+}
+
+func (i *executionInterface) GetStorageUsed(address runtime.Address) (uint64, error) {
+	owner := string(address[:])
+
+	var used uint64
+	for key, value := range i.state.registers {
+		if key.owner == owner {
+			used += uint64(len(key.key) + len(value))
+		}
+	}
+
+	return used, nil
+}
+
+func (i *executionInterface) GetStorageCapacity(_ runtime.Address) (uint64, error) {
+	return accountStorageCapacity, nil
+}
+
+func (i *executionInterface) ImplementationDebugLog(_ string) error {
+	return nil
+}
+
+func (i *executionInterface) ValidatePublicKey(key *runtime.PublicKey) error {
+	if key.SignAlgo != runtime.SignatureAlgorithmECDSA_P256 {
+		// Other algorithms are not validated
+		return nil
+	}
+
+	// Add the uncompressed point prefix
+	_, err := ecdh.P256().NewPublicKey(append([]byte{0x04}, key.PublicKey...))
+	return err
+}
+
+func (i *executionInterface) GetAccountContractNames(address runtime.Address) ([]string, error) {
+	account, err := i.account(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return account.contractNames(), nil
+}
+
+func (i *executionInterface) RecordTrace(_ string, _ runtime.Location, _ time.Duration, _ []attribute.KeyValue) {
+	// NO-OP
+}
+
+func (i *executionInterface) BLSVerifyPOP(_ *runtime.PublicKey, _ []byte) (bool, error) {
+	return false, errors.NewDefaultUserError("BLS is not supported")
+}
+
+func (i *executionInterface) BLSAggregateSignatures(_ [][]byte) ([]byte, error) {
+	return nil, errors.NewDefaultUserError("BLS is not supported")
+}
+
+func (i *executionInterface) BLSAggregatePublicKeys(_ []*runtime.PublicKey) (*runtime.PublicKey, error) {
+	return nil, errors.NewDefaultUserError("BLS is not supported")
+}
+
+func (i *executionInterface) ResourceOwnerChanged(
+	_ *interpreter.Interpreter,
+	_ *interpreter.CompositeValue,
+	_ common.Address,
+	_ common.Address,
+) {
+	// NO-OP
+}
+
+func (i *executionInterface) GenerateAccountID(address common.Address) (uint64, error) {
+	account, err := i.account(address)
+	if err != nil {
+		return 0, err
+	}
+
+	account.lastID++
+	return account.lastID, nil
+}
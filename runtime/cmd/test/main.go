@@ -0,0 +1,188 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// A utility program that runs Cadence test files.
+//
+// Test files import the `Test` contract, which is backed by an in-memory blockchain,
+// executing scripts and transactions directly with this runtime.
+//
+// All functions of a test file whose name starts with `test` are run.
+// Each test function is run in isolation: the test file is interpreted anew
+// with a new blockchain, then the `setup` function is called, if any,
+// then the test function, and finally the `tearDown` function, if any.
+//
+// Test functions may have parameters, if their docstring declares rows of literal arguments
+// with `pragma arguments <argument-list>` lines. Each row is run as a separate test,
+// named after the function and the index of the row, e.g. `testTransfer[0]`.
+//
+// Contracts deployed with `Test.deployContract` are deployed to the service account,
+// which has the address 0x1, and can be imported with `import Name from 0x1` or `import "Name"`.
+//
+// The service account pays for transactions by default, and always signs them.
+// Like on Flow, the payer and the authorizers of a transaction must sign with keys of a total weight of 1000,
+// and the sequence number of the proposal key must match.
+//
+// Random values are cryptographically secure, unless `Test.setRandomSeed` seeds them,
+// or `Test.queueRandomValues` queues the next values.
+// The height, ID, and timestamp of the pending block can be set,
+// e.g. with `Test.setBlockTimestamp`. Scripts see the latest committed block.
+//
+// The coverage of contracts by each test can be queried with `Test.coverage`,
+// and written to a directory with the `-coverdir` flag.
+//
+// Snapshots of `Test.matchSnapshot` are written next to the test file on the first run,
+// and compared against on later runs. The `-update` flag regenerates them.
+//
+// Arguments are test files, or directories in which all files ending in `_test.cdc` are run.
+// The default is the current directory.
+
+package main
+
+import (
+	"flag"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const testFileSuffix = "_test.cdc"
+
+var runFlag = flag.String("run", "", "run only the tests whose name matches the regular expression")
+var verboseFlag = flag.Bool("v", false, "print the logs and results of all tests")
+var junitFlag = flag.String("junit", "", "write a JUnit XML report to the given file")
+var updateFlag = flag.Bool("update", false, "regenerate the snapshots of Test.matchSnapshot, instead of comparing against them")
+var coverDirFlag = flag.String("coverdir", "", "write a JSON coverage report for each test to the given directory")
+
+func main() {
+	flag.Parse()
+
+	var filter *regexp.Regexp
+	if *runFlag != "" {
+		var err error
+		filter, err = regexp.Compile(*runFlag)
+		if err != nil {
+			log.Fatalf("Invalid -run pattern: %s", err)
+		}
+	}
+
+	paths, err := findTestFiles(flag.Args())
+	if err != nil {
+		log.Fatalf("Failed to find test files: %s", err)
+	}
+
+	if len(paths) == 0 {
+		log.Fatal("no test files")
+	}
+
+	reporter := reporter{
+		writer:  os.Stdout,
+		verbose: *verboseFlag,
+	}
+
+	var results []*FileResult
+	failed := false
+
+	for _, path := range paths {
+		result := runFile(path, runOptions{
+			filter:          filter,
+			updateSnapshots: *updateFlag,
+		})
+		reporter.reportFile(result)
+
+		if result.Failed() {
+			failed = true
+		}
+
+		results = append(results, result)
+	}
+
+	if *junitFlag != "" {
+		err := writeJUnitReportFile(*junitFlag, results)
+		if err != nil {
+			log.Fatalf("Failed to write JUnit report: %s", err)
+		}
+	}
+
+	if *coverDirFlag != "" {
+		err := writeCoverageReports(*coverDirFlag, results)
+		if err != nil {
+			log.Fatalf("Failed to write coverage reports: %s", err)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func writeJUnitReportFile(path string, results []*FileResult) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeErr := file.Close()
+		if err == nil {
+			err = closeErr
+		}
+	}()
+
+	return writeJUnitReport(file, results)
+}
+
+// findTestFiles returns the given test files,
+// and the test files in the given directories, in lexical order.
+func findTestFiles(arguments []string) ([]string, error) {
+	if len(arguments) == 0 {
+		arguments = []string{"."}
+	}
+
+	var paths []string
+
+	for _, argument := range arguments {
+		info, err := os.Stat(argument)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			paths = append(paths, argument)
+			continue
+		}
+
+		err = filepath.WalkDir(argument, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !entry.IsDir() && strings.HasSuffix(path, testFileSuffix) {
+				paths = append(paths, path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return paths, nil
+}
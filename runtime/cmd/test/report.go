@@ -0,0 +1,280 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/onflow/cadence/runtime/pretty"
+)
+
+// reporter prints test results in the format of `go test`.
+type reporter struct {
+	writer  io.Writer
+	verbose bool
+}
+
+func (r reporter) reportFile(result *FileResult) {
+	if result.Error != nil {
+		r.printError(result, result.Error, "")
+		fmt.Fprintf(r.writer, "FAIL\t%s [setup failed]\n", result.Path)
+		return
+	}
+
+	for _, test := range result.Tests {
+		r.reportTest(result, test)
+	}
+
+	if result.Failed() {
+		fmt.Fprintf(r.writer, "FAIL\t%s\t%s\n", result.Path, formatSeconds(result.Duration, 3))
+		return
+	}
+
+	var suffix string
+	if len(result.Tests) == 0 {
+		suffix = " [no tests to run]"
+	}
+
+	fmt.Fprintf(r.writer, "ok  \t%s\t%s%s\n", result.Path, formatSeconds(result.Duration, 3), suffix)
+}
+
+func (r reporter) reportTest(file *FileResult, test TestResult) {
+	if r.verbose {
+		fmt.Fprintf(r.writer, "=== RUN   %s\n", test.Name)
+	}
+
+	if test.Error == nil {
+		if r.verbose {
+			r.printLogs(test)
+			fmt.Fprintf(r.writer, "--- PASS: %s (%s)\n", test.Name, formatSeconds(test.Duration, 2))
+		}
+		return
+	}
+
+	fmt.Fprintf(r.writer, "--- FAIL: %s (%s)\n", test.Name, formatSeconds(test.Duration, 2))
+	r.printLogs(test)
+	r.printLines(formatTestError(file, test), "    ")
+}
+
+func (r reporter) printLogs(test TestResult) {
+	for _, message := range test.Logs {
+		fmt.Fprintf(r.writer, "    %s\n", message)
+	}
+}
+
+func (r reporter) printError(file *FileResult, err error, indent string) {
+	r.printLines(formatError(file, err), indent)
+}
+
+func (r reporter) printLines(text string, indent string) {
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			continue
+		}
+		fmt.Fprintf(r.writer, "%s%s\n", indent, line)
+	}
+}
+
+// formatError pretty-prints the given error, including the relevant code of the test file.
+func formatError(file *FileResult, err error) string {
+	var builder strings.Builder
+	printErr := pretty.NewErrorPrettyPrinter(&builder, false).
+		PrettyPrintError(err, file.location, file.codes)
+	if printErr != nil {
+		return err.Error()
+	}
+	return builder.String()
+}
+
+// formatTestError formats the error of the given test,
+// including the arguments of the test, if it is parameterized.
+func formatTestError(file *FileResult, test TestResult) string {
+	message := formatError(file, test.Error)
+	if test.Arguments == "" {
+		return message
+	}
+	return fmt.Sprintf("with arguments %s: %s", test.Arguments, message)
+}
+
+func formatSeconds(duration time.Duration, precision int) string {
+	return fmt.Sprintf("%.*fs", precision, duration.Seconds())
+}
+
+func formatJUnitTime(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
+
+// JUnit XML report, as understood by common CI systems
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func newJUnitMessage(contents string) *junitMessage {
+	message, _, _ := strings.Cut(contents, "\n")
+
+	return &junitMessage{
+		Message:  message,
+		Contents: contents,
+	}
+}
+
+// writeJUnitReport writes the given results as a JUnit XML report.
+// Each test file is a test suite.
+// A test file which could not be run is reported as a suite with a single erroneous test case.
+func writeJUnitReport(writer io.Writer, results []*FileResult) error {
+	report := junitTestSuites{}
+
+	var duration time.Duration
+
+	for _, result := range results {
+		suite := junitTestSuite{
+			Name: result.Path,
+			Time: formatJUnitTime(result.Duration),
+		}
+
+		if result.Error != nil {
+			suite.Tests = 1
+			suite.Errors = 1
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				Name:      setupFunctionName,
+				ClassName: result.Path,
+				Time:      suite.Time,
+				Error:     newJUnitMessage(formatError(result, result.Error)),
+			})
+		}
+
+		for _, test := range result.Tests {
+			testCase := junitTestCase{
+				Name:      test.Name,
+				ClassName: result.Path,
+				Time:      formatJUnitTime(test.Duration),
+				SystemOut: strings.Join(test.Logs, "\n"),
+			}
+
+			if test.Error != nil {
+				testCase.Failure = newJUnitMessage(formatTestError(result, test))
+				suite.Failures++
+			}
+
+			suite.Tests++
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Suites = append(report.Suites, suite)
+
+		duration += result.Duration
+	}
+
+	report.Time = formatJUnitTime(duration)
+
+	_, err := io.WriteString(writer, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(report)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(writer, "\n")
+	return err
+}
+
+// writeCoverageReports writes the coverage of each test as a JSON file to the given directory.
+// The file of a test is named after the escaped path of the test file and the name of the test,
+// e.g. `contracts%2Fcounter_test.cdc.testIncrement.json`.
+func writeCoverageReports(directory string, results []*FileResult) error {
+	err := os.MkdirAll(directory, 0o755)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		for _, test := range result.Tests {
+			if test.Coverage == nil {
+				continue
+			}
+
+			data, err := json.MarshalIndent(test.Coverage, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			path := filepath.Join(directory, coverageFileName(result.Path, test.Name))
+
+			err = os.WriteFile(path, data, 0o644)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// coverageFileName returns the name of the coverage report file
+// of the test with the given name in the test file with the given path.
+// The path is escaped, so the name is unique for each test file, and contains no separators.
+func coverageFileName(path string, testName string) string {
+	name := url.PathEscape(filepath.ToSlash(filepath.Clean(path)))
+	return name + "." + testName + ".json"
+}
@@ -0,0 +1,564 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
+)
+
+const testFunctionPrefix = "test"
+const setupFunctionName = "setup"
+const tearDownFunctionName = "tearDown"
+
+// TestResult is the result of a single test function,
+// or of a single row of arguments of a parameterized test function.
+type TestResult struct {
+	Name string
+	// Arguments is the argument list the test function was called with, if it is parameterized
+	Arguments string
+	Error     error
+	Logs      []string
+	Duration  time.Duration
+	// Coverage is the coverage of the contracts by the test
+	Coverage *runtime.CoverageReport
+}
+
+// FileResult is the result of all test functions of a test file.
+// Error is set if the file could not be run at all, e.g. because checking failed.
+type FileResult struct {
+	Path     string
+	Tests    []TestResult
+	Error    error
+	Duration time.Duration
+	location common.Location
+	codes    map[common.Location][]byte
+}
+
+func (r *FileResult) Failed() bool {
+	if r.Error != nil {
+		return true
+	}
+	for _, test := range r.Tests {
+		if test.Error != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// runOptions configures how test files are run.
+type runOptions struct {
+	// filter, if set, selects the test functions which are run
+	filter *regexp.Regexp
+	// updateSnapshots regenerates snapshots, instead of comparing against them
+	updateSnapshots bool
+}
+
+// testFramework provides the blockchain of a test instance,
+// and reads and writes files relative to the directory of the test file.
+type testFramework struct {
+	blockchain      *Blockchain
+	directory       string
+	updateSnapshots bool
+}
+
+var _ stdlib.TestFramework = testFramework{}
+var _ stdlib.ValueExporter = testFramework{}
+var _ stdlib.SnapshotUpdater = testFramework{}
+
+func (f testFramework) EmulatorBackend() stdlib.Blockchain {
+	return f.blockchain
+}
+
+func (f testFramework) ReadFile(path string) (string, error) {
+	content, err := os.ReadFile(f.path(path))
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+func (f testFramework) WriteFile(path string, content string) error {
+	path = f.path(path)
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, []byte(content), 0o644)
+}
+
+func (f testFramework) path(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(f.directory, path)
+}
+
+func (f testFramework) UpdateSnapshots() bool {
+	return f.updateSnapshots
+}
+
+// ExportValue exports the given value like the runtime does.
+// Event values only exist in tests, where events are imported,
+// and the runtime does not export them, so they are exported like emitted events,
+// also when they are nested in optionals, arrays, or dictionaries.
+func (f testFramework) ExportValue(
+	inter *interpreter.Interpreter,
+	value interpreter.Value,
+) (cadence.Value, error) {
+	locationRange := interpreter.EmptyLocationRange
+
+	switch value := value.(type) {
+	case *interpreter.CompositeValue:
+		if value.Kind == common.CompositeKindEvent {
+			return f.exportEvent(inter, value)
+		}
+
+	case *interpreter.SomeValue:
+		innerValue, err := f.ExportValue(inter, value.InnerValue(inter, locationRange))
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewOptional(innerValue), nil
+
+	case *interpreter.ArrayValue:
+		var values []cadence.Value
+		var err error
+		value.Iterate(inter, func(element interpreter.Value) (resume bool) {
+			var exported cadence.Value
+			exported, err = f.ExportValue(inter, element)
+			if err != nil {
+				return false
+			}
+			values = append(values, exported)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		arrayType := runtime.ExportType(
+			value.SemaType(inter),
+			map[sema.TypeID]cadence.Type{},
+		).(cadence.ArrayType)
+
+		return cadence.NewArray(values).WithType(arrayType), nil
+
+	case *interpreter.DictionaryValue:
+		var pairs []cadence.KeyValuePair
+		var err error
+		value.Iterate(inter, func(key, element interpreter.Value) (resume bool) {
+			var exportedKey, exportedValue cadence.Value
+			exportedKey, err = f.ExportValue(inter, key)
+			if err != nil {
+				return false
+			}
+			exportedValue, err = f.ExportValue(inter, element)
+			if err != nil {
+				return false
+			}
+			pairs = append(pairs, cadence.KeyValuePair{
+				Key:   exportedKey,
+				Value: exportedValue,
+			})
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		dictionaryType := runtime.ExportType(
+			value.SemaType(inter),
+			map[sema.TypeID]cadence.Type{},
+		).(*cadence.DictionaryType)
+
+		return cadence.NewDictionary(pairs).WithType(dictionaryType), nil
+	}
+
+	return runtime.ExportValue(value, inter, locationRange)
+}
+
+// exportEvent exports the given event value like an emitted event.
+func (f testFramework) exportEvent(
+	inter *interpreter.Interpreter,
+	event *interpreter.CompositeValue,
+) (cadence.Value, error) {
+	semaType, err := inter.ConvertStaticToSemaType(event.StaticType(inter))
+	if err != nil {
+		return nil, err
+	}
+
+	eventType, ok := runtime.ExportType(
+		semaType,
+		map[sema.TypeID]cadence.Type{},
+	).(*cadence.EventType)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	fields := make([]cadence.Value, len(eventType.Fields))
+	for i, field := range eventType.Fields {
+		fieldValue := event.GetField(inter, interpreter.EmptyLocationRange, field.Identifier)
+		fields[i], err = f.ExportValue(inter, fieldValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cadence.NewEvent(fields).WithType(eventType), nil
+}
+
+// testInstance is an isolated instance of a test file:
+// the test file is interpreted anew, with a new blockchain.
+type testInstance struct {
+	blockchain *Blockchain
+	program    *interpreter.Program
+	inter      *interpreter.Interpreter
+	logs       []string
+}
+
+func newTestInstance(
+	path string,
+	location common.Location,
+	code []byte,
+	options runOptions,
+) (*testInstance, error) {
+	instance := &testInstance{}
+
+	framework := testFramework{
+		directory:       filepath.Dir(path),
+		updateSnapshots: options.updateSnapshots,
+	}
+
+	blockchain := NewBlockchain(framework.ReadFile)
+	framework.blockchain = blockchain
+	instance.blockchain = blockchain
+
+	// The test file sees the current state of the blockchain,
+	// but never commits its own changes to it
+
+	runtimeInterface := blockchain.newExecutionInterface(
+		blockchain.state,
+		func() runtime.Block {
+			return blockchain.pendingBlock().runtimeBlock()
+		},
+	)
+	runtimeInterface.onLog = func(message string) {
+		instance.logs = append(instance.logs, message)
+	}
+
+	env := runtime.NewBaseInterpreterEnvironment(runtime.Config{})
+	env.Configure(
+		runtimeInterface,
+		runtime.NewCodesAndPrograms(),
+		runtime.NewStorage(runtimeInterface, nil),
+		nil,
+	)
+	blockchain.stdlibHandler = env
+
+	// Provide the Test contract
+
+	importHandler := env.CheckerConfig.ImportHandler
+	env.CheckerConfig.ImportHandler = func(
+		checker *sema.Checker,
+		importedLocation common.Location,
+		importRange ast.Range,
+	) (sema.Import, error) {
+		if importedLocation == stdlib.TestContractLocation {
+			return sema.ElaborationImport{
+				Elaboration: stdlib.GetTestContractType().Checker.Elaboration,
+			}, nil
+		}
+
+		return importHandler(checker, importedLocation, importRange)
+	}
+
+	importLocationHandler := env.InterpreterConfig.ImportLocationHandler
+	env.InterpreterConfig.ImportLocationHandler = func(
+		inter *interpreter.Interpreter,
+		location common.Location,
+	) interpreter.Import {
+		if location == stdlib.TestContractLocation {
+			program := interpreter.ProgramFromChecker(stdlib.GetTestContractType().Checker)
+			subInterpreter, err := inter.NewSubInterpreter(program, location)
+			if err != nil {
+				panic(err)
+			}
+			return interpreter.InterpreterImport{
+				Interpreter: subInterpreter,
+			}
+		}
+
+		return importLocationHandler(inter, location)
+	}
+
+	contractValueHandler := env.InterpreterConfig.ContractValueHandler
+	testContractValueHandler := stdlib.NewTestInterpreterContractValueHandler(framework)
+	env.InterpreterConfig.ContractValueHandler = func(
+		inter *interpreter.Interpreter,
+		compositeType *sema.CompositeType,
+		constructorGenerator func(common.Address) *interpreter.HostFunctionValue,
+		invocationRange ast.Range,
+	) interpreter.ContractValue {
+		handler := contractValueHandler
+		if compositeType.Location == stdlib.TestContractLocation {
+			handler = testContractValueHandler
+		}
+
+		return handler(inter, compositeType, constructorGenerator, invocationRange)
+	}
+
+	program, err := env.ParseAndCheckProgram(code, location, false)
+	if err != nil {
+		return instance, err
+	}
+	instance.program = program
+
+	_, inter, err := env.Interpret(location, program, nil)
+	if err != nil {
+		return instance, err
+	}
+	instance.inter = inter
+
+	return instance, nil
+}
+
+func (t *testInstance) functionDeclaration(name string) *ast.FunctionDeclaration {
+	for _, declaration := range t.program.Program.FunctionDeclarations() {
+		if declaration.Identifier.Identifier == name {
+			return declaration
+		}
+	}
+	return nil
+}
+
+func (t *testInstance) hasFunction(name string) bool {
+	return t.functionDeclaration(name) != nil
+}
+
+// arguments parses the given argument list of literals,
+// and imports them as the arguments of the given function.
+func (t *testInstance) arguments(name string, argumentList string) ([]interpreter.Value, error) {
+	functionType := t.program.Elaboration.FunctionDeclarationFunctionType(t.functionDeclaration(name))
+
+	parameterTypes := make([]sema.Type, len(functionType.Parameters))
+	for i, parameter := range functionType.Parameters {
+		parameterTypes[i] = parameter.TypeAnnotation.Type
+	}
+
+	values, err := runtime.ParseLiteralArgumentList(argumentList, parameterTypes, t.inter)
+	if err != nil {
+		return nil, err
+	}
+
+	arguments := make([]interpreter.Value, len(values))
+	for i, value := range values {
+		arguments[i], err = runtime.ImportValue(
+			t.inter,
+			interpreter.EmptyLocationRange,
+			t.blockchain.stdlibHandler,
+			value,
+			parameterTypes[i],
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return arguments, nil
+}
+
+// run runs the given test function with the given arguments,
+// preceded by the setup function and followed by the tearDown function, if they are declared.
+// The tearDown function is also run if the test function failed.
+func (t *testInstance) run(name string, arguments []interpreter.Value) error {
+	if t.hasFunction(setupFunctionName) {
+		_, err := t.inter.Invoke(setupFunctionName)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := t.inter.Invoke(name, arguments...)
+
+	if t.hasFunction(tearDownFunctionName) {
+		_, tearDownErr := t.inter.Invoke(tearDownFunctionName)
+		if err == nil {
+			err = tearDownErr
+		}
+	}
+
+	return err
+}
+
+// testFunctions returns the names of the test functions of the program, in declaration order.
+// Test functions are the functions whose name starts with "test".
+func testFunctions(program *ast.Program) []*ast.FunctionDeclaration {
+	var declarations []*ast.FunctionDeclaration
+	for _, declaration := range program.FunctionDeclarations() {
+		if strings.HasPrefix(declaration.Identifier.Identifier, testFunctionPrefix) {
+			declarations = append(declarations, declaration)
+		}
+	}
+	return declarations
+}
+
+// testCase is a single run of a test function.
+type testCase struct {
+	name string
+	// arguments is the argument list of the run, if the test function is parameterized
+	arguments string
+}
+
+// testCases returns the runs of the given test function.
+//
+// A test function is parameterized by declaring rows of arguments in its docstring,
+// with `pragma arguments <argument-list>` lines, where the arguments are literals.
+// Each row is run as a separate test, named after the function and the index of the row.
+func testCases(declaration *ast.FunctionDeclaration) []testCase {
+	name := declaration.Identifier.Identifier
+
+	rows := parser.ParseDocstringPragmaArguments(declaration.DocString)
+	if len(rows) == 0 {
+		return []testCase{{name: name}}
+	}
+
+	cases := make([]testCase, len(rows))
+	for i, row := range rows {
+		cases[i] = testCase{
+			name:      fmt.Sprintf("%s[%d]", name, i),
+			arguments: strings.TrimSpace(row),
+		}
+	}
+	return cases
+}
+
+// runFile runs all test functions of the given test file which match the given filter, if any.
+//
+// Each test function is run in a new test instance,
+// so state changes of one test, and of its setup, are not visible to other tests.
+func runFile(path string, options runOptions) *FileResult {
+	start := time.Now()
+
+	location := common.NewStringLocation(nil, path)
+
+	result := &FileResult{
+		Path:     path,
+		location: location,
+		codes:    map[common.Location][]byte{},
+	}
+
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	code, err := os.ReadFile(path)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+	result.codes[location] = code
+
+	// Interpret the file once, to report errors only once,
+	// and to find the test functions
+
+	instance, err := newTestInstance(path, location, code, options)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	for _, declaration := range testFunctions(instance.program.Program) {
+		for _, test := range testCases(declaration) {
+			if options.filter != nil && !options.filter.MatchString(test.name) {
+				continue
+			}
+
+			result.Tests = append(result.Tests, runTest(path, location, code, declaration, test, options))
+		}
+	}
+
+	return result
+}
+
+func runTest(
+	path string,
+	location common.Location,
+	code []byte,
+	declaration *ast.FunctionDeclaration,
+	test testCase,
+	options runOptions,
+) (result TestResult) {
+	start := time.Now()
+
+	name := declaration.Identifier.Identifier
+
+	result.Name = test.name
+	result.Arguments = test.arguments
+
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
+
+	if test.arguments == "" &&
+		declaration.ParameterList != nil &&
+		len(declaration.ParameterList.Parameters) > 0 {
+
+		result.Error = errors.NewDefaultUserError(
+			"test function %s must not have parameters, unless it declares pragma arguments",
+			name,
+		)
+		return result
+	}
+
+	instance, err := newTestInstance(path, location, code, options)
+	if err == nil {
+		var arguments []interpreter.Value
+		if test.arguments != "" {
+			arguments, err = instance.arguments(name, test.arguments)
+		}
+		if err == nil {
+			err = instance.run(name, arguments)
+		}
+	}
+
+	result.Error = err
+	result.Logs = instance.logs
+	result.Coverage = instance.blockchain.coverageReport
+
+	return result
+}
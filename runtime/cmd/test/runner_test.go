@@ -0,0 +1,1227 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCounterContract = `
+  pub contract Counter {
+
+      pub event Incremented(count: Int)
+
+      pub var count: Int
+
+      init(count: Int) {
+          self.count = count
+      }
+
+      pub fun increment() {
+          self.count = self.count + 1
+          emit Incremented(count: self.count)
+      }
+  }
+`
+
+// writeTestFiles writes the given files to a new directory,
+// and returns the path of the test file "test.cdc".
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	directory := t.TempDir()
+
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(directory, name), []byte(content), 0644)
+		require.NoError(t, err)
+	}
+
+	return filepath.Join(directory, "test.cdc")
+}
+
+func requireTestResults(t *testing.T, result *FileResult, expected map[string]bool) {
+	require.NoError(t, result.Error)
+
+	actual := map[string]bool{}
+	for _, test := range result.Tests {
+		actual[test.Name] = test.Error == nil
+		if test.Error != nil && expected[test.Name] {
+			t.Errorf("%s failed: %s", test.Name, formatError(result, test.Error))
+		}
+	}
+
+	require.Equal(t, expected, actual)
+}
+
+func TestRunFile(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestFiles(t, map[string]string{
+		"Counter.cdc": testCounterContract,
+		"test.cdc": `
+          import Test
+
+          pub var account: Test.Account? = nil
+
+          pub var setupCount = 0
+
+          pub var modified = false
+
+          pub fun setup() {
+              setupCount = setupCount + 1
+
+              let err = Test.deployContract(
+                  name: "Counter",
+                  path: "Counter.cdc",
+                  arguments: [10]
+              )
+              Test.expect(err, Test.beNil())
+
+              account = Test.createAccount()
+          }
+
+          pub fun testIncrement() {
+              let result = Test.executeTransaction(
+                  Test.Transaction(
+                      code: "import Counter from 0x1 transaction { prepare(signer: AuthAccount) { Counter.increment(); log(signer.address) } }",
+                      authorizers: [account!.address],
+                      signers: [account!],
+                      arguments: []
+                  )
+              )
+              Test.expect(result, Test.beSucceeded())
+
+              let scriptResult = Test.executeScript(
+                  "import \"Counter\" pub fun main(): Int { return Counter.count }",
+                  []
+              )
+              Test.expect(scriptResult, Test.beSucceeded())
+              Test.assertEqual(11, scriptResult.returnValue! as! Int)
+
+              Test.assertEqual(["0x0000000000000002"], Test.logs())
+
+              let events = Test.eventsOfType(CompositeType("A.0000000000000001.Counter.Incremented")!)
+              Test.assertEqual(1, events.length)
+
+              log("incremented")
+              modified = true
+          }
+
+          pub fun testIsolation() {
+              Test.assertEqual(1, setupCount)
+              Test.assert(!modified)
+
+              let scriptResult = Test.executeScript(
+                  "import Counter from 0x1 pub fun main(): Int { return Counter.count }",
+                  []
+              )
+              Test.assertEqual(10, scriptResult.returnValue! as! Int)
+          }
+
+          pub fun testFailure() {
+              log("failing")
+              Test.assertEqual(1, 2)
+          }
+
+          pub fun helper() {
+              panic("not a test")
+          }
+        `,
+	})
+
+	result := runFile(path, runOptions{})
+
+	requireTestResults(t, result, map[string]bool{
+		"testIncrement": true,
+		"testIsolation": true,
+		"testFailure":   false,
+	})
+
+	assert.Equal(t, []string{"testIncrement", "testIsolation", "testFailure"},
+		[]string{result.Tests[0].Name, result.Tests[1].Name, result.Tests[2].Name},
+	)
+	assert.Equal(t, []string{`"incremented"`}, result.Tests[0].Logs)
+	assert.Equal(t, []string{`"failing"`}, result.Tests[2].Logs)
+	assert.ErrorContains(t, result.Tests[2].Error, "not equal: expected: 1, actual: 2")
+	assert.True(t, result.Failed())
+}
+
+func TestRunFileFilter(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestFiles(t, map[string]string{
+		"test.cdc": `
+          pub fun testA() {}
+
+          pub fun testB() {
+              panic("B")
+          }
+
+          pub fun testAB() {}
+        `,
+	})
+
+	result := runFile(path, runOptions{filter: regexp.MustCompile("A")})
+
+	requireTestResults(t, result, map[string]bool{
+		"testA":  true,
+		"testAB": true,
+	})
+	assert.False(t, result.Failed())
+}
+
+func TestRunFileParameterized(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestFiles(t, map[string]string{
+		"test.cdc": `
+          import Test
+
+          /// pragma arguments (amount: 10.0, valid: true)
+          /// pragma arguments (amount: 0.0, valid: false)
+          /// pragma arguments (amount: 0.5, valid: false)
+          pub fun testAmount(amount: UFix64, valid: Bool) {
+              Test.assertEqual(valid, amount > 0.0)
+          }
+
+          /// pragma arguments ("a")
+          pub fun testInvalidArguments(x: Int) {}
+        `,
+	})
+
+	result := runFile(path, runOptions{})
+	require.NoError(t, result.Error)
+
+	requireTestResults(t, result, map[string]bool{
+		"testAmount[0]":           true,
+		"testAmount[1]":           true,
+		"testAmount[2]":           false,
+		"testInvalidArguments[0]": false,
+	})
+
+	failure := result.Tests[2]
+	assert.Equal(t, "(amount: 0.5, valid: false)", failure.Arguments)
+	assert.Contains(t,
+		formatTestError(result, failure),
+		"with arguments (amount: 0.5, valid: false): error: assertion failed",
+	)
+
+	assert.ErrorContains(t, result.Tests[3].Error, "invalid argument at index 0")
+
+	t.Run("filter", func(t *testing.T) {
+		t.Parallel()
+
+		result := runFile(path, runOptions{filter: regexp.MustCompile(`testAmount\[1\]`)})
+		requireTestResults(t, result, map[string]bool{"testAmount[1]": true})
+	})
+}
+
+func TestRunFileErrors(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("checking", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTestFiles(t, map[string]string{
+			"test.cdc": `
+              pub fun testA() {
+                  let x: Int = "a"
+              }
+            `,
+		})
+
+		result := runFile(path, runOptions{})
+		require.Error(t, result.Error)
+		assert.Contains(t, formatError(result, result.Error), "mismatched types")
+		assert.Empty(t, result.Tests)
+		assert.True(t, result.Failed())
+	})
+
+	t.Run("setup", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTestFiles(t, map[string]string{
+			"test.cdc": `
+              pub fun setup() {
+                  panic("setup failed")
+              }
+
+              pub fun testA() {}
+            `,
+		})
+
+		result := runFile(path, runOptions{})
+		require.NoError(t, result.Error)
+		require.Len(t, result.Tests, 1)
+		assert.ErrorContains(t, result.Tests[0].Error, "setup failed")
+	})
+
+	t.Run("tearDown", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTestFiles(t, map[string]string{
+			"test.cdc": `
+              pub fun testA() {
+                  log("test")
+              }
+
+              pub fun tearDown() {
+                  log("tearDown")
+                  panic("tearDown failed")
+              }
+            `,
+		})
+
+		result := runFile(path, runOptions{})
+		require.NoError(t, result.Error)
+		require.Len(t, result.Tests, 1)
+		assert.ErrorContains(t, result.Tests[0].Error, "tearDown failed")
+		assert.Equal(t, []string{`"test"`, `"tearDown"`}, result.Tests[0].Logs)
+	})
+
+	t.Run("parameters", func(t *testing.T) {
+		t.Parallel()
+
+		path := writeTestFiles(t, map[string]string{
+			"test.cdc": `
+              pub fun testA(x: Int) {}
+            `,
+		})
+
+		result := runFile(path, runOptions{})
+		require.NoError(t, result.Error)
+		require.Len(t, result.Tests, 1)
+		assert.ErrorContains(t, result.Tests[0].Error, "must not have parameters")
+	})
+}
+
+func TestRunFileTransactionResult(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestFiles(t, map[string]string{
+		"Counter.cdc": testCounterContract,
+		"test.cdc": `
+          import Test
+
+          // The type can only be loaded once the contract is deployed
+          pub fun incrementedType(): Type {
+              return CompositeType("A.0000000000000001.Counter.Incremented")!
+          }
+
+          pub fun setup() {
+              let err = Test.deployContract(
+                  name: "Counter",
+                  path: "Counter.cdc",
+                  arguments: [10]
+              )
+              Test.expect(err, Test.beNil())
+          }
+
+          pub fun increment(times: Int, fail: Bool): Test.TransactionResult {
+              let account = Test.createAccount()
+              return Test.executeTransaction(
+                  Test.Transaction(
+                      code: "import Counter from 0x1 transaction(times: Int, fail: Bool) { prepare(signer: AuthAccount) { var i = 0; while i < times { Counter.increment(); i = i + 1 }; log(Counter.count); if fail { panic(\"failed\") } } }",
+                      authorizers: [account.address],
+                      signers: [account],
+                      arguments: [times, fail]
+                  )
+              )
+          }
+
+          pub fun testEvents() {
+              let incremented = incrementedType()
+              let txResult = increment(times: 2, fail: false)
+
+              Test.expect(txResult, Test.emit(incremented))
+              Test.expect(txResult, Test.emit(incremented, where: fun (event: AnyStruct): Bool {
+                  return event.getType() == incremented
+              }))
+              Test.expect(txResult, Test.emitCount(incremented, count: 2))
+              Test.expect(txResult, Test.emitInOrder([incremented, incremented]))
+              Test.assertEqual(2, txResult.eventsOfType(incremented).length)
+              Test.assertEqual(["12"], txResult.logs)
+
+              // Only the events of this transaction are part of the result
+              let secondResult = increment(times: 1, fail: false)
+              Test.expect(secondResult, Test.emitCount(incremented, count: 1))
+          }
+
+          pub fun testFailedTransaction() {
+              let incremented = incrementedType()
+              let txResult = increment(times: 1, fail: true)
+
+              Test.expect(txResult, Test.beFailed())
+              Test.expect(txResult, Test.notEmit(incremented))
+              Test.assertEqual(["11"], txResult.logs)
+          }
+        `,
+	})
+
+	result := runFile(path, runOptions{})
+
+	requireTestResults(t, result, map[string]bool{
+		"testEvents":            true,
+		"testFailedTransaction": true,
+	})
+}
+
+func TestRunFileMocks(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestFiles(t, map[string]string{
+		"Oracle.cdc": `
+          pub contract Oracle {
+              pub fun price(_ symbol: String): UFix64 {
+                  panic("no price feed")
+              }
+          }
+        `,
+		"Exchange.cdc": `
+          import Oracle from 0x1
+
+          pub contract Exchange {
+              pub fun quote(_ symbol: String, amount: UFix64): UFix64 {
+                  return Oracle.price(symbol) * amount
+              }
+          }
+        `,
+		"FixedOracle.cdc": `
+          pub contract Oracle {
+              pub let fixedPrice: UFix64
+
+              init(price: UFix64) {
+                  self.fixedPrice = price
+              }
+
+              pub fun price(_ symbol: String): UFix64 {
+                  return self.fixedPrice
+              }
+          }
+        `,
+		"test.cdc": `
+          import Test
+
+          pub fun setup() {
+              let err = Test.deployContract(name: "Oracle", path: "Oracle.cdc", arguments: [])
+              Test.expect(err, Test.beNil())
+          }
+
+          pub fun deployExchange() {
+              let err = Test.deployContract(name: "Exchange", path: "Exchange.cdc", arguments: [])
+              Test.expect(err, Test.beNil())
+          }
+
+          pub fun quote(_ symbol: String, amount: UFix64): UFix64 {
+              let scriptResult = Test.executeScript(
+                  "import \"Exchange\" pub fun main(symbol: String, amount: UFix64): UFix64 { return Exchange.quote(symbol, amount: amount) }",
+                  [symbol, amount]
+              )
+              Test.expect(scriptResult, Test.beSucceeded())
+              return scriptResult.returnValue! as! UFix64
+          }
+
+          pub fun testMockContract() {
+              let err = Test.mockContract(name: "Oracle", path: "FixedOracle.cdc", arguments: [2.0])
+              Test.expect(err, Test.beNil())
+              deployExchange()
+
+              Test.assertEqual(6.0, quote("FLOW", amount: 3.0))
+          }
+
+          pub fun testStubContract() {
+              let err = Test.stubContract(
+                  name: "Oracle",
+                  stubs: [
+                      Test.Stub(function: "fun price(_ symbol: String): UFix64", returns: 1.5)
+                  ]
+              )
+              Test.expect(err, Test.beNil())
+              deployExchange()
+
+              Test.assertEqual(3.0, quote("FLOW", amount: 2.0))
+
+              let account = Test.createAccount()
+              let txResult = Test.executeTransaction(
+                  Test.Transaction(
+                      code: "import Exchange from 0x1 transaction { prepare(signer: AuthAccount) { Exchange.quote(\"BTC\", amount: 1.0); Exchange.quote(\"ETH\", amount: 1.0) } }",
+                      authorizers: [account.address],
+                      signers: [account],
+                      arguments: []
+                  )
+              )
+              Test.expect(txResult, Test.beSucceeded())
+
+              let calls = Test.calls("Oracle", function: "price")
+              Test.assertEqual(2, calls.length)
+              Test.assertEqual("BTC", calls[0][0] as! String)
+              Test.assertEqual("ETH", calls[1][0] as! String)
+          }
+
+          pub fun testCallsOfUnknownFunction() {
+              let err = Test.stubContract(
+                  name: "Oracle",
+                  stubs: [
+                      Test.Stub(function: "fun price(_ symbol: String): UFix64", returns: 1.5)
+                  ]
+              )
+              Test.expect(err, Test.beNil())
+
+              Test.calls("Oracle", function: "volume")
+          }
+
+          pub fun testCallsOfUnstubbedContract() {
+              Test.calls("Oracle", function: "price")
+          }
+
+          pub fun testInvalidMock() {
+              let err = Test.mockContract(name: "Oracle", path: "Oracle.cdc", arguments: [1])
+              Test.expect(err, Test.not(Test.beNil()))
+
+              // The original contract is still used
+              deployExchange()
+              let scriptResult = Test.executeScript(
+                  "import \"Exchange\" pub fun main(): UFix64 { return Exchange.quote(\"FLOW\", amount: 1.0) }",
+                  []
+              )
+              Test.expect(scriptResult, Test.beFailed())
+          }
+        `,
+	})
+
+	result := runFile(path, runOptions{})
+
+	requireTestResults(t, result, map[string]bool{
+		"testMockContract":             true,
+		"testStubContract":             true,
+		"testCallsOfUnknownFunction":   false,
+		"testCallsOfUnstubbedContract": false,
+		"testInvalidMock":              true,
+	})
+
+	assert.ErrorContains(t,
+		result.Tests[2].Error,
+		"function `volume` is not a stubbed function of contract `Oracle`",
+	)
+	assert.ErrorContains(t,
+		result.Tests[3].Error,
+		"contract `Oracle` is not stubbed using `stubContract`",
+	)
+}
+
+func TestRunFileCoverageAndUsage(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestFiles(t, map[string]string{
+		"Counter.cdc": testCounterContract,
+		"test.cdc": `
+          import Test
+
+          pub fun setup() {
+              let err = Test.deployContract(
+                  name: "Counter",
+                  path: "Counter.cdc",
+                  arguments: [10]
+              )
+              Test.expect(err, Test.beNil())
+          }
+
+          pub fun increment(times: Int): Test.TransactionResult {
+              let account = Test.createAccount()
+              return Test.executeTransaction(
+                  Test.Transaction(
+                      code: "import Counter from 0x1 transaction(times: Int) { prepare(signer: AuthAccount) { var i = 0; while i < times { Counter.increment(); i = i + 1 } } }",
+                      authorizers: [account.address],
+                      signers: [account],
+                      arguments: [times]
+                  )
+              )
+          }
+
+          pub fun testUsage() {
+              let once = increment(times: 1)
+              let often = increment(times: 10)
+
+              Test.expect(once, Test.beSucceeded())
+              Test.expect(often, Test.beSucceeded())
+              Test.assert(Test.computationUsed(once) > 0)
+              Test.assert(Test.computationUsed(often) > Test.computationUsed(once))
+              Test.assert(Test.memoryUsed(often) > Test.memoryUsed(once))
+
+              let scriptResult = Test.executeScript("pub fun main(): Int { return 1 }", [])
+              Test.assert(Test.computationUsed(scriptResult) > 0)
+          }
+
+          pub fun testCoverage() {
+              let initial = Test.coverage()!
+              Test.assertEqual(1, initial.locations)
+              Test.assert(initial.misses > 0)
+
+              increment(times: 1)
+
+              let coverage = Test.coverage()!
+              Test.assertEqual(initial.statements, coverage.statements)
+              Test.assert(coverage.hits > initial.hits)
+              Test.assertEqual(coverage.statements, coverage.hits + coverage.misses)
+          }
+        `,
+	})
+
+	result := runFile(path, runOptions{})
+
+	requireTestResults(t, result, map[string]bool{
+		"testUsage":    true,
+		"testCoverage": true,
+	})
+
+	directory := t.TempDir()
+	err := writeCoverageReports(directory, []*FileResult{result})
+	require.NoError(t, err)
+
+	for _, test := range result.Tests {
+		data, err := os.ReadFile(filepath.Join(directory, coverageFileName(path, test.Name)))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "A.0000000000000001.Counter")
+	}
+}
+
+func TestCoverageFileName(t *testing.T) {
+
+	t.Parallel()
+
+	assert.Equal(t,
+		"contracts%2Fcounter_test.cdc.testIncrement.json",
+		coverageFileName("./contracts/counter_test.cdc", "testIncrement"),
+	)
+
+	// Different test files have different names
+
+	assert.NotEqual(t,
+		coverageFileName("a/b_c_test.cdc", "test"),
+		coverageFileName("a_b/c_test.cdc", "test"),
+	)
+	assert.NotEqual(t,
+		coverageFileName("test.cdc", "test"),
+		coverageFileName("../test.cdc", "test"),
+	)
+	assert.NotEqual(t,
+		coverageFileName("test.cdc", "test"),
+		coverageFileName(".test.cdc", "test"),
+	)
+
+	// Names never refer to another directory
+
+	for _, path := range []string{"../test.cdc", "/tmp/test.cdc", "a/../../test.cdc"} {
+		name := coverageFileName(path, "test")
+		assert.Equal(t, name, filepath.Base(name))
+	}
+}
+
+func TestRunFileSnapshots(t *testing.T) {
+
+	t.Parallel()
+
+	const testFile = `
+      import Test
+
+      pub fun setup() {
+          let err = Test.deployContract(
+              name: "Counter",
+              path: "Counter.cdc",
+              arguments: [10]
+          )
+          Test.expect(err, Test.beNil())
+      }
+
+      pub fun testSnapshot() {
+          let account = Test.createAccount()
+          let txResult = Test.executeTransaction(
+              Test.Transaction(
+                  code: "import Counter from 0x1 transaction { prepare(signer: AuthAccount) { Counter.increment() } }",
+                  authorizers: [account.address],
+                  signers: [account],
+                  arguments: []
+              )
+          )
+          Test.matchSnapshot(txResult.events, "events")
+
+          let scriptResult = Test.executeScript(
+              "import Counter from 0x1 pub fun main(): {String: Int} { return {\"count\": Counter.count, \"double\": Counter.count * 2} }",
+              []
+          )
+          Test.matchSnapshot(scriptResult.returnValue, "count")
+      }
+    `
+
+	path := writeTestFiles(t, map[string]string{
+		"Counter.cdc": testCounterContract,
+		"test.cdc":    testFile,
+	})
+	directory := filepath.Dir(path)
+
+	// The first run writes the snapshots
+
+	result := runFile(path, runOptions{})
+	requireTestResults(t, result, map[string]bool{"testSnapshot": true})
+
+	events, err := os.ReadFile(filepath.Join(directory, "events.snapshot.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(events), `"A.0000000000000001.Counter.Incremented"`)
+
+	count, err := os.ReadFile(filepath.Join(directory, "count.snapshot.json"))
+	require.NoError(t, err)
+
+	// Later runs compare against the snapshots
+
+	result = runFile(path, runOptions{})
+	requireTestResults(t, result, map[string]bool{"testSnapshot": true})
+
+	// A changed value does not match the snapshot
+
+	err = os.WriteFile(
+		filepath.Join(directory, "Counter.cdc"),
+		[]byte(strings.Replace(testCounterContract, "self.count = self.count + 1", "self.count = self.count + 2", 1)),
+		0o644,
+	)
+	require.NoError(t, err)
+
+	result = runFile(path, runOptions{})
+	requireTestResults(t, result, map[string]bool{"testSnapshot": false})
+	require.ErrorContains(t, result.Tests[0].Error, "value does not match snapshot events")
+
+	// Updating regenerates the snapshots
+
+	result = runFile(path, runOptions{updateSnapshots: true})
+	requireTestResults(t, result, map[string]bool{"testSnapshot": true})
+
+	updatedCount, err := os.ReadFile(filepath.Join(directory, "count.snapshot.json"))
+	require.NoError(t, err)
+	assert.NotEqual(t, string(count), string(updatedCount))
+
+	result = runFile(path, runOptions{})
+	requireTestResults(t, result, map[string]bool{"testSnapshot": true})
+}
+
+func TestRunFileBlockchain(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestFiles(t, map[string]string{
+		"test.cdc": `
+          import Test
+
+          pub let saveTransaction = "transaction(value: Int) { prepare(signer: AuthAccount) { signer.save(value, to: /storage/value) } }"
+
+          pub let failingTransaction = "transaction { prepare(signer: AuthAccount) { signer.save(1, to: /storage/value); panic(\"failed\") } }"
+
+          pub fun storedValue(_ account: Test.Account): Int? {
+              let scriptResult = Test.executeScript(
+                  "pub fun main(address: Address): Int? { return getAuthAccount(address).copy<Int>(from: /storage/value) }",
+                  [account.address]
+              )
+              Test.expect(scriptResult, Test.beSucceeded())
+              return scriptResult.returnValue as! Int?
+          }
+
+          pub fun save(_ account: Test.Account, _ value: Int) {
+              let result = Test.executeTransaction(
+                  Test.Transaction(
+                      code: saveTransaction,
+                      authorizers: [account.address],
+                      signers: [account],
+                      arguments: [value]
+                  )
+              )
+              Test.expect(result, Test.beSucceeded())
+          }
+
+          pub fun testFailedTransactionIsReverted() {
+              let account = Test.createAccount()
+
+              let result = Test.executeTransaction(
+                  Test.Transaction(
+                      code: failingTransaction,
+                      authorizers: [account.address],
+                      signers: [account],
+                      arguments: []
+                  )
+              )
+              Test.assertError(result, errorMessage: "failed")
+              Test.assertEqual(nil, storedValue(account))
+          }
+
+          pub fun testMissingSignature() {
+              let account = Test.createAccount()
+
+              let result = Test.executeTransaction(
+                  Test.Transaction(
+                      code: saveTransaction,
+                      authorizers: [account.address],
+                      signers: [],
+                      arguments: [1]
+                  )
+              )
+              Test.assertError(result, errorMessage: "did not sign the transaction")
+          }
+
+          pub fun testScriptsSeeCommittedState() {
+              let account = Test.createAccount()
+
+              Test.addTransaction(
+                  Test.Transaction(
+                      code: saveTransaction,
+                      authorizers: [account.address],
+                      signers: [account],
+                      arguments: [1]
+                  )
+              )
+
+              Test.expectFailure(fun () {
+                  Test.commitBlock()
+              }, errorMessageSubstring: "not executed")
+
+              Test.expect(Test.executeNextTransaction(), Test.not(Test.beNil()))
+              Test.assertEqual(nil, storedValue(account))
+
+              Test.commitBlock()
+              Test.assertEqual(1, storedValue(account)!)
+          }
+
+          pub fun testReset() {
+              let account = Test.createAccount()
+              let height = getCurrentBlock().height - 1
+
+              save(account, 1)
+              Test.assertEqual(1, storedValue(account)!)
+
+              Test.reset(to: height)
+              Test.assertEqual(nil, storedValue(account))
+              Test.assertEqual(height + 1, getCurrentBlock().height)
+          }
+
+          pub fun testSnapshots() {
+              let account = Test.createAccount()
+
+              Test.createSnapshot(name: "empty")
+              save(account, 1)
+              Test.createSnapshot(name: "saved")
+
+              Test.loadSnapshot(name: "empty")
+              Test.assertEqual(nil, storedValue(account))
+
+              Test.loadSnapshot(name: "saved")
+              Test.assertEqual(1, storedValue(account)!)
+
+              Test.expectFailure(fun () {
+                  Test.loadSnapshot(name: "missing")
+              }, errorMessageSubstring: "does not exist")
+          }
+
+          pub fun testMoveTime() {
+              let before = getCurrentBlock().timestamp
+              Test.moveTime(by: 3600.0)
+              Test.assert(getCurrentBlock().timestamp >= before + 3600.0)
+          }
+
+          pub fun testAccounts() {
+              let serviceAccount = Test.serviceAccount()
+              Test.assertEqual(0x1 as Address, serviceAccount.address)
+
+              let account = Test.createAccount()
+              Test.assertEqual(0x2 as Address, account.address)
+              Test.assertEqual(account.publicKey.publicKey, Test.getAccount(0x2).publicKey.publicKey)
+          }
+        `,
+	})
+
+	result := runFile(path, runOptions{})
+
+	requireTestResults(t, result, map[string]bool{
+		"testFailedTransactionIsReverted": true,
+		"testMissingSignature":            true,
+		"testScriptsSeeCommittedState":    true,
+		"testReset":                       true,
+		"testSnapshots":                   true,
+		"testMoveTime":                    true,
+		"testAccounts":                    true,
+	})
+}
+
+func TestRunFileRandomnessAndBlocks(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestFiles(t, map[string]string{
+		"test.cdc": `
+          import Test
+
+          pub let timeLockTransaction = "transaction { prepare(signer: AuthAccount) { assert(getCurrentBlock().timestamp >= 2000.0, message: \"locked\") } }"
+
+          pub fun executeTimeLockTransaction(): Test.TransactionResult {
+              let serviceAccount = Test.serviceAccount()
+              return Test.executeTransaction(
+                  Test.Transaction(
+                      code: timeLockTransaction,
+                      authorizers: [serviceAccount.address],
+                      signers: [serviceAccount],
+                      arguments: []
+                  )
+              )
+          }
+
+          pub fun testRandomSeed() {
+              Test.setRandomSeed(42)
+              let first = [revertibleRandom(), unsafeRandom()]
+
+              Test.setRandomSeed(42)
+              let second = [revertibleRandom(), unsafeRandom()]
+
+              Test.assertEqual(first, second)
+          }
+
+          pub fun testQueuedRandomValues() {
+              Test.queueRandomValues([7, 3])
+              Test.assertEqual(7 as UInt64, revertibleRandom())
+
+              let scriptResult = Test.executeScript(
+                  "pub fun main(): UInt64 { return revertibleRandom() }",
+                  []
+              )
+              Test.assertEqual(3 as UInt64, scriptResult.returnValue! as! UInt64)
+          }
+
+          pub fun testBlockHeight() {
+              Test.setBlockHeight(100)
+              Test.assertEqual(100 as UInt64, getCurrentBlock().height)
+
+              Test.commitBlock()
+              Test.assertEqual(101 as UInt64, getCurrentBlock().height)
+              Test.assertEqual(100 as UInt64, getBlock(at: 100)!.height)
+              Test.assert(getBlock(at: 99) == nil)
+
+              Test.expectFailure(fun () {
+                  Test.setBlockHeight(50)
+              }, errorMessageSubstring: "must be greater than the height of the latest block")
+
+              Test.reset(to: 0)
+              Test.assertEqual(1 as UInt64, getCurrentBlock().height)
+          }
+
+          pub fun testBlockID() {
+              Test.setBlockID("0101010101010101010101010101010101010101010101010101010101010101".decodeHex())
+              Test.assertEqual(1 as UInt8, getCurrentBlock().id[0])
+
+              Test.commitBlock()
+              Test.assertEqual(1 as UInt8, getBlock(at: getCurrentBlock().height - 1)!.id[31])
+              Test.assert(getCurrentBlock().id[31] != 1)
+
+              Test.expectFailure(fun () {
+                  Test.setBlockID([1, 2, 3])
+              }, errorMessageSubstring: "must be 32 bytes long")
+          }
+
+          pub fun testBlockTimestamp() {
+              Test.setBlockTimestamp(1999.0)
+              Test.assertEqual(1999.0, getCurrentBlock().timestamp)
+              Test.assertError(executeTimeLockTransaction(), errorMessage: "locked")
+
+              Test.setBlockTimestamp(2000.0)
+              Test.expect(executeTimeLockTransaction(), Test.beSucceeded())
+
+              Test.commitBlock()
+              Test.assert(getCurrentBlock().timestamp > 2000.0)
+          }
+        `,
+	})
+
+	result := runFile(path, runOptions{})
+
+	requireTestResults(t, result, map[string]bool{
+		"testRandomSeed":         true,
+		"testQueuedRandomValues": true,
+		"testBlockHeight":        true,
+		"testBlockID":            true,
+		"testBlockTimestamp":     true,
+	})
+}
+
+func TestRunFileSignatures(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestFiles(t, map[string]string{
+		"test.cdc": `
+          import Test
+
+          pub let emptyTransaction = "transaction { prepare(signer: AuthAccount) {} }"
+
+          pub let revokeTransaction = "transaction(keyIndex: Int) { prepare(signer: AuthAccount) { signer.keys.revoke(keyIndex: keyIndex) } }"
+
+          pub fun key(weight: UFix64): Test.Key {
+              return Test.Key(
+                  signatureAlgorithm: SignatureAlgorithm.ECDSA_P256,
+                  hashAlgorithm: HashAlgorithm.SHA3_256,
+                  weight: weight
+              )
+          }
+
+          pub fun transaction(_ account: Test.Account, signers: [Test.Account]): Test.Transaction {
+              return Test.Transaction(
+                  code: emptyTransaction,
+                  authorizers: [account.address],
+                  signers: signers,
+                  arguments: []
+              )
+          }
+
+          pub fun testKeyWeights() {
+              let account = Test.createAccountWithKeys([key(weight: 500.0), key(weight: 500.0)])
+
+              let halfSigned = transaction(account, signers: [])
+                  .withKeySignatures([Test.KeySignature(address: account.address, keyIndex: 0)])
+              Test.assertError(
+                  Test.executeTransaction(halfSigned),
+                  errorMessage: "signed the transaction with keys of total weight 500, but at least 1000 is required"
+              )
+
+              let signed = halfSigned
+                  .withKeySignatures([Test.KeySignature(address: account.address, keyIndex: 1)])
+              Test.expect(Test.executeTransaction(signed), Test.beSucceeded())
+
+              Test.expect(Test.executeTransaction(transaction(account, signers: [account])), Test.beSucceeded())
+          }
+
+          pub fun testKeyAlgorithms() {
+              let account = Test.createAccountWithKeys([
+                  Test.Key(
+                      signatureAlgorithm: SignatureAlgorithm.ECDSA_secp256k1,
+                      hashAlgorithm: HashAlgorithm.KECCAK_256,
+                      weight: 1000.0
+                  )
+              ])
+              Test.assertEqual(SignatureAlgorithm.ECDSA_secp256k1.rawValue, account.publicKey.signatureAlgorithm.rawValue)
+              Test.assertEqual(64, account.publicKey.publicKey.length)
+
+              Test.expectFailure(fun () {
+                  Test.createAccountWithKeys([key(weight: 1001.0)])
+              }, errorMessageSubstring: "invalid weight 1001 of key 0")
+          }
+
+          pub fun testRevokedKey() {
+              let account = Test.createAccountWithKeys([key(weight: 1000.0), key(weight: 1000.0)])
+
+              let revoke = Test.Transaction(
+                  code: revokeTransaction,
+                  authorizers: [account.address],
+                  signers: [account],
+                  arguments: [0]
+              )
+              Test.expect(Test.executeTransaction(revoke), Test.beSucceeded())
+
+              let signedWithRevokedKey = transaction(account, signers: [])
+                  .withKeySignatures([Test.KeySignature(address: account.address, keyIndex: 0)])
+              Test.assertError(
+                  Test.executeTransaction(signedWithRevokedKey),
+                  errorMessage: "key 0 of signer 0x0000000000000002 is revoked"
+              )
+
+              // The signer only signs with the key which is not revoked
+              Test.expect(Test.executeTransaction(transaction(account, signers: [account])), Test.beSucceeded())
+          }
+
+          pub fun testPayer() {
+              let account = Test.createAccount()
+              let payer = Test.createAccount()
+
+              let unsigned = transaction(account, signers: [account]).withPayer(payer.address)
+              Test.assertError(
+                  Test.executeTransaction(unsigned),
+                  errorMessage: "proposal key 0 of account 0x0000000000000003 did not sign the transaction"
+              )
+
+              let signed = transaction(account, signers: [account, payer]).withPayer(payer.address)
+              Test.expect(Test.executeTransaction(signed), Test.beSucceeded())
+
+              // The first key of the payer proposed the transaction
+              Test.assertEqual(1 as UInt64, Test.sequenceNumber(payer.address, keyIndex: 0))
+              Test.assertEqual(0 as UInt64, Test.sequenceNumber(account.address, keyIndex: 0))
+          }
+
+          pub fun testProposalKey() {
+              let account = Test.createAccount()
+
+              let invalid = transaction(account, signers: [account])
+                  .withProposalKey(Test.ProposalKey(address: account.address, keyIndex: 0, sequenceNumber: 1))
+              Test.assertError(
+                  Test.executeTransaction(invalid),
+                  errorMessage: "invalid sequence number 1 of proposal key 0 of account 0x0000000000000002: expected 0"
+              )
+              Test.assertEqual(0 as UInt64, Test.sequenceNumber(account.address, keyIndex: 0))
+
+              let failing = Test.Transaction(
+                  code: "transaction { prepare(signer: AuthAccount) { panic(\"failed\") } }",
+                  authorizers: [account.address],
+                  signers: [account],
+                  arguments: []
+              ).withProposalKey(Test.ProposalKey(address: account.address, keyIndex: 0, sequenceNumber: 0))
+              Test.assertError(Test.executeTransaction(failing), errorMessage: "failed")
+
+              // The sequence number is incremented even if the transaction fails
+              Test.assertEqual(1 as UInt64, Test.sequenceNumber(account.address, keyIndex: 0))
+
+              let valid = transaction(account, signers: [account])
+                  .withProposalKey(Test.ProposalKey(address: account.address, keyIndex: 0, sequenceNumber: 1))
+              Test.expect(Test.executeTransaction(valid), Test.beSucceeded())
+              Test.assertEqual(2 as UInt64, Test.sequenceNumber(account.address, keyIndex: 0))
+          }
+        `,
+	})
+
+	result := runFile(path, runOptions{})
+
+	requireTestResults(t, result, map[string]bool{
+		"testKeyWeights":    true,
+		"testKeyAlgorithms": true,
+		"testRevokedKey":    true,
+		"testPayer":         true,
+		"testProposalKey":   true,
+	})
+}
+
+func TestFindTestFiles(t *testing.T) {
+
+	t.Parallel()
+
+	directory := t.TempDir()
+
+	for _, name := range []string{
+		"a_test.cdc",
+		"contract.cdc",
+		"nested/b_test.cdc",
+	} {
+		path := filepath.Join(directory, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+
+	paths, err := findTestFiles([]string{
+		directory,
+		filepath.Join(directory, "contract.cdc"),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		[]string{
+			filepath.Join(directory, "a_test.cdc"),
+			filepath.Join(directory, "nested/b_test.cdc"),
+			filepath.Join(directory, "contract.cdc"),
+		},
+		paths,
+	)
+}
+
+func TestReport(t *testing.T) {
+
+	t.Parallel()
+
+	path := writeTestFiles(t, map[string]string{
+		"test.cdc": `
+          pub fun testPass() {
+              log("pass")
+          }
+
+          pub fun testFail() {
+              log("fail")
+              panic("failed")
+          }
+        `,
+	})
+
+	results := []*FileResult{runFile(path, runOptions{})}
+
+	// Durations vary, so report them as zero
+	for _, result := range results {
+		result.Duration = 0
+		for i := range result.Tests {
+			result.Tests[i].Duration = 0
+		}
+	}
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		var output bytes.Buffer
+		reporter{writer: &output}.reportFile(results[0])
+
+		assert.Equal(t,
+			"--- FAIL: testFail (0.00s)\n"+
+				"    \"fail\"\n"+
+				"    error: panic: failed\n"+
+				"     --> "+path+":8:14\n"+
+				"      |\n"+
+				"    8 |               panic(\"failed\")\n"+
+				"      |               ^^^^^^^^^^^^^^^\n"+
+				"FAIL\t"+path+"\t0.000s\n",
+			output.String(),
+		)
+	})
+
+	t.Run("verbose", func(t *testing.T) {
+		t.Parallel()
+
+		var output bytes.Buffer
+		reporter{writer: &output, verbose: true}.reportFile(results[0])
+
+		assert.Contains(t,
+			output.String(),
+			"=== RUN   testPass\n"+
+				"    \"pass\"\n"+
+				"--- PASS: testPass (0.00s)\n"+
+				"=== RUN   testFail\n"+
+				"--- FAIL: testFail (0.00s)\n",
+		)
+	})
+
+	t.Run("JUnit", func(t *testing.T) {
+		t.Parallel()
+
+		var output bytes.Buffer
+		err := writeJUnitReport(&output, results)
+		require.NoError(t, err)
+
+		assert.Contains(t,
+			output.String(),
+			`<testsuites tests="2" failures="1" errors="0" time="0.000">`+"\n"+
+				`  <testsuite name="`+path+`" tests="2" failures="1" errors="0" time="0.000">`,
+		)
+	})
+}
@@ -27,6 +27,9 @@ type Context struct {
 	Location       Location
 	Environment    Environment
 	CoverageReport *CoverageReport
+	// ResourceAuditor, if set, audits the resources of the execution.
+	// It requires the Environment to implement ResourceAuditingEnvironment
+	ResourceAuditor *ResourceAuditor
 }
 
 // CodesAndPrograms collects the source code and AST for each location.
@@ -118,6 +118,7 @@ func (executor *interpreterContractFunctionExecutor) preprocess() (err error) {
 		storage,
 		context.CoverageReport,
 	)
+	configureResourceAuditor(environment, context.ResourceAuditor)
 	executor.environment = environment
 
 	return nil
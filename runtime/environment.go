@@ -78,12 +78,23 @@ type Environment interface {
 	NewPublicAccountValue(address interpreter.AddressValue) interpreter.Value
 }
 
+// ResourceAuditingEnvironment is an Environment which can audit resources.
+// It is separate from Environment, so existing environments do not have to implement it.
+type ResourceAuditingEnvironment interface {
+	// SetResourceAuditor sets the auditor for the next execution.
+	// It must be called after Configure, which removes the auditor.
+	SetResourceAuditor(resourceAuditor *ResourceAuditor)
+}
+
+var _ ResourceAuditingEnvironment = &interpreterEnvironment{}
+
 // interpreterEnvironmentReconfigured is the portion of interpreterEnvironment
 // that gets reconfigured by interpreterEnvironment.Configure
 type interpreterEnvironmentReconfigured struct {
 	runtimeInterface Interface
 	storage          *Storage
 	coverageReport   *CoverageReport
+	resourceAuditor  *ResourceAuditor
 	codesAndPrograms CodesAndPrograms
 }
 
@@ -242,9 +253,28 @@ func (e *interpreterEnvironment) Configure(
 	e.storage = storage
 	e.InterpreterConfig.Storage = storage
 	e.coverageReport = coverageReport
+	e.SetResourceAuditor(nil)
 	e.stackDepthLimiter.depth = 0
 }
 
+func (e *interpreterEnvironment) SetResourceAuditor(resourceAuditor *ResourceAuditor) {
+	e.resourceAuditor = resourceAuditor
+
+	// The resource handlers are only installed when resources are audited,
+	// so resource operations do not pay for them otherwise
+
+	config := e.InterpreterConfig
+	if resourceAuditor == nil {
+		config.OnResourceCreated = nil
+		config.OnResourceMoved = nil
+		config.OnResourceDestroyed = nil
+	} else {
+		config.OnResourceCreated = resourceAuditor.resourceCreated
+		config.OnResourceMoved = resourceAuditor.resourceMoved
+		config.OnResourceDestroyed = resourceAuditor.resourceDestroyed
+	}
+}
+
 func (e *interpreterEnvironment) DeclareValue(valueDeclaration stdlib.StandardLibraryValue, location common.Location) {
 	e.semaBaseActivationFor(
 		location,
@@ -360,6 +390,19 @@ func (e *interpreterEnvironment) GetStorageCapacity(address common.Address) (uin
 	return e.runtimeInterface.GetStorageCapacity(address)
 }
 
+func (e *interpreterEnvironment) GetStorageUsage(
+	inter *interpreter.Interpreter,
+	address common.Address,
+	path interpreter.PathValue,
+) *interpreter.StorageUsage {
+	return e.storage.StorageUsage(
+		inter,
+		address,
+		path.Domain.Identifier(),
+		path.Identifier,
+	)
+}
+
 func (e *interpreterEnvironment) GetAccountKey(address common.Address, index int) (*stdlib.AccountKey, error) {
 	return e.runtimeInterface.GetAccountKey(address, index)
 }
@@ -1220,6 +1263,11 @@ func (e *interpreterEnvironment) CommitStorage(inter *interpreter.Interpreter) e
 		return err
 	}
 
+	// NOTE: audit after the commit, so that new contract values are in storage
+	if e.resourceAuditor != nil {
+		e.resourceAuditor.audit(inter, e.storage)
+	}
+
 	if e.config.AtreeValidationEnabled {
 		err = e.storage.CheckHealth()
 		if err != nil {
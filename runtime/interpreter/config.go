@@ -35,6 +35,12 @@ type Config struct {
 	OnRecordTrace OnRecordTraceFunc
 	// OnResourceOwnerChange is triggered when the owner of a resource changes
 	OnResourceOwnerChange OnResourceOwnerChangeFunc
+	// OnResourceCreated is triggered when a resource is created
+	OnResourceCreated OnResourceCreatedFunc
+	// OnResourceMoved is triggered when a resource is moved
+	OnResourceMoved OnResourceMovedFunc
+	// OnResourceDestroyed is triggered when a resource is about to be destroyed
+	OnResourceDestroyed OnResourceDestroyedFunc
 	// OnMeterComputation is triggered when a computation is about to happen
 	OnMeterComputation OnMeterComputationFunc
 	// InjectedCompositeFieldsHandler is used to initialize new composite values' fields
@@ -869,6 +869,23 @@ func (StorageMutatedDuringIterationError) Error() string {
 	return "storage iteration continued after modifying storage"
 }
 
+// InvalidStorageIterationLimitError
+type InvalidStorageIterationLimitError struct {
+	LocationRange
+	Limit int
+}
+
+var _ errors.UserError = InvalidStorageIterationLimitError{}
+
+func (InvalidStorageIterationLimitError) IsUserError() {}
+
+func (e InvalidStorageIterationLimitError) Error() string {
+	return fmt.Sprintf(
+		"invalid storage iteration limit: expected non-negative limit, got %d",
+		e.Limit,
+	)
+}
+
 // ContainerMutatedDuringIterationError
 type ContainerMutatedDuringIterationError struct {
 	LocationRange
@@ -105,6 +105,30 @@ type OnResourceOwnerChangeFunc func(
 	newOwner common.Address,
 )
 
+// OnResourceCreatedFunc is a function that is triggered when a resource is created.
+type OnResourceCreatedFunc func(
+	inter *Interpreter,
+	resource *CompositeValue,
+	locationRange LocationRange,
+)
+
+// OnResourceMovedFunc is a function that is triggered when a resource is moved,
+// independent of whether its owner changes.
+type OnResourceMovedFunc func(
+	inter *Interpreter,
+	resource *CompositeValue,
+	locationRange LocationRange,
+	oldOwner common.Address,
+	newOwner common.Address,
+)
+
+// OnResourceDestroyedFunc is a function that is triggered when a resource is about to be destroyed.
+type OnResourceDestroyedFunc func(
+	inter *Interpreter,
+	resource *CompositeValue,
+	locationRange LocationRange,
+)
+
 // OnMeterComputationFunc is a function that is called when some computation is about to happen.
 // intensity captures the intensity of the computation and can be set using input sizes
 // complexity of computation given input sizes, or any other factors that could help the upper levels
@@ -1291,6 +1315,12 @@ func (interpreter *Interpreter) declareNonEnumCompositeValue(
 				value.Functions = functions
 				value.Destructor = destructorFunction
 
+				if declaration.Kind() == common.CompositeKindResource &&
+					config.OnResourceCreated != nil {
+
+					config.OnResourceCreated(interpreter, value, locationRange)
+				}
+
 				var self MemberAccessibleValue = value
 				if declaration.Kind() == common.CompositeKindAttachment {
 					self = NewEphemeralReferenceValue(interpreter, false, value, interpreter.MustSemaTypeOfValue(value))
@@ -3729,6 +3759,167 @@ func (interpreter *Interpreter) storageAccountPaths(addressValue AddressValue, l
 	return interpreter.accountPaths(addressValue, locationRange, common.PathDomainStorage, PrimitiveStaticTypeStoragePath)
 }
 
+func (interpreter *Interpreter) storedCount(addressValue AddressValue) UInt64Value {
+	address := addressValue.ToAddress()
+	storageMap := interpreter.Storage().GetStorageMap(address, common.PathDomainStorage.Identifier(), false)
+
+	return NewUInt64Value(
+		interpreter,
+		func() uint64 {
+			if storageMap == nil {
+				return 0
+			}
+			return storageMap.Count()
+		},
+	)
+}
+
+// newStoragePathsPageFunction returns a function which returns a page of the storage paths of an account.
+// Pages start after a given cursor path, and are limited to a given number of paths.
+// If filtered, the function has a type parameter,
+// and only paths of values which are a subtype of the type argument are returned.
+// The iteration of a page seeks the cursor path in the storage map,
+// so the computation of a page does not depend on the position of the cursor path.
+func (interpreter *Interpreter) newStoragePathsPageFunction(
+	functionType *sema.FunctionType,
+	addressValue AddressValue,
+	filtered bool,
+) *HostFunctionValue {
+
+	// Converted addresses can be cached and don't have to be recomputed on each function invocation
+	address := addressValue.ToAddress()
+
+	domain := common.PathDomainStorage
+
+	return NewHostFunctionValue(
+		interpreter,
+		functionType,
+		func(invocation Invocation) Value {
+			inter := invocation.Interpreter
+			locationRange := invocation.LocationRange
+
+			var after StorageMapKey
+			switch cursor := invocation.Arguments[0].(type) {
+			case NilValue:
+				break
+			case *SomeValue:
+				path, ok := cursor.InnerValue(inter, locationRange).(PathValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+				after = StringStorageMapKey(path.Identifier)
+			default:
+				panic(errors.NewUnreachableError())
+			}
+
+			limitValue, ok := invocation.Arguments[1].(IntValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+			limit := limitValue.ToInt(locationRange)
+			if limit < 0 {
+				panic(InvalidStorageIterationLimitError{
+					LocationRange: locationRange,
+					Limit:         limit,
+				})
+			}
+
+			var filterType sema.Type
+			if filtered {
+				typeParameterPair := invocation.TypeParameterTypes.Oldest()
+				if typeParameterPair == nil {
+					panic(errors.NewUnreachableError())
+				}
+				filterType = typeParameterPair.Value
+			}
+
+			var paths []Value
+
+			storageMap := inter.Storage().GetStorageMap(address, domain.Identifier(), false)
+			if storageMap != nil && limit > 0 {
+
+				// If the cursor path does not store a value anymore,
+				// the page starts at the path which follows it
+				iterator := storageMap.IteratorAfter(inter, after)
+
+				for len(paths) < limit {
+					inter.ReportComputation(common.ComputationKindLoop, 1)
+
+					var key atree.Value
+					if filterType == nil {
+						key = iterator.NextKey()
+					} else {
+						var value Value
+						key, value = iterator.Next()
+						if key != nil && !inter.storedValueHasType(value, filterType) {
+							continue
+						}
+					}
+
+					if key == nil {
+						break
+					}
+
+					// TODO: unfortunately, the iterator only returns an atree.Value, not a StorageMapKey
+					identifier := string(key.(StringAtreeValue))
+					paths = append(paths, NewPathValue(inter, domain, identifier))
+				}
+			}
+
+			return NewArrayValue(
+				inter,
+				locationRange,
+				NewVariableSizedStaticType(inter, PrimitiveStaticTypeStoragePath),
+				common.ZeroAddress,
+				paths...,
+			)
+		},
+	)
+}
+
+// storedValueHasType returns true if the type of the given stored value is a subtype of the given type.
+// Values with a type that cannot be loaded are never a subtype.
+func (interpreter *Interpreter) storedValueHasType(value Value, ty sema.Type) (result bool) {
+
+	// Loading the type may load the program of its location,
+	// which fails if the program cannot be parsed or checked anymore.
+	// Only recover such loading errors, all other errors are not expected
+
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(error)
+			if !ok || !isTypeLoadingFailure(err) {
+				panic(r)
+			}
+			result = false
+		}
+	}()
+
+	semaType, err := interpreter.ConvertStaticToSemaType(value.StaticType(interpreter))
+	if err != nil {
+		if !isTypeLoadingFailure(err) {
+			panic(err)
+		}
+		return false
+	}
+
+	return sema.IsSubType(semaType, ty)
+}
+
+// isTypeLoadingFailure returns true if the given error is a type loading error,
+// or an error of a program which failed to be imported.
+func isTypeLoadingFailure(err error) bool {
+	if goErrors.As(err, &TypeLoadingError{}) {
+		return true
+	}
+
+	var importError interface {
+		errors.UserError
+		ImportLocation() common.Location
+	}
+	return goErrors.As(err, &importError)
+}
+
 func (interpreter *Interpreter) recordStorageMutation() {
 	if interpreter.SharedState.inStorageIteration {
 		interpreter.SharedState.storageMutatedDuringIteration = true
@@ -20,6 +20,7 @@ package interpreter
 
 import (
 	goerrors "errors"
+	"sort"
 
 	"github.com/onflow/atree"
 
@@ -98,6 +99,25 @@ func (s StorageMap) ReadValue(gauge common.MemoryGauge, key StorageMapKey) Value
 	return StoredValue(gauge, storable, s.orderedMap.Storage)
 }
 
+// ReadStorable returns the storable stored with the given key, if any,
+// without converting it to a value.
+func (s StorageMap) ReadStorable(key StorageMapKey) atree.Storable {
+	storable, err := s.orderedMap.Get(
+		key.AtreeValueCompare,
+		key.AtreeValueHashInput,
+		key.AtreeValue(),
+	)
+	if err != nil {
+		var keyNotFoundError *atree.KeyNotFoundError
+		if goerrors.As(err, &keyNotFoundError) {
+			return nil
+		}
+		panic(errors.NewExternalError(err))
+	}
+
+	return storable
+}
+
 // WriteValue sets or removes a value in the storage map.
 // If the given value is nil, the key is removed.
 // If the given value is non-nil, the key is added/updated.
@@ -186,6 +206,40 @@ func (s StorageMap) Iterator(gauge common.MemoryGauge) StorageMapIterator {
 	}
 }
 
+// IteratorAfter returns an iterator (StorageMapSeekIterator),
+// which allows iterating over the keys and values of the storage map
+// that follow the given key in iteration order, whether the key exists or not.
+// If the given key is nil, the iteration starts at the first key.
+//
+// The iteration order is the order of the digests of the keys.
+// The iterator seeks the given key through the slabs on the path to it,
+// so its cost does not depend on the position of the key.
+func (s StorageMap) IteratorAfter(gauge common.MemoryGauge, key StorageMapKey) *StorageMapSeekIterator {
+	iterator := &StorageMapSeekIterator{
+		gauge:   gauge,
+		storage: s.orderedMap.Storage,
+	}
+
+	rootSlab := iterator.retrieveSlab(s.orderedMap.StorageID())
+
+	if key == nil {
+		iterator.pushSlab(rootSlab)
+		return iterator
+	}
+
+	digesterBuilder := atree.NewDefaultDigesterBuilder()
+	// NOTE: only the first seed is used to digest keys
+	digesterBuilder.SetSeed(s.orderedMap.Seed(), 0)
+
+	iterator.digesterBuilder = digesterBuilder
+	iterator.hashInput = key.AtreeValueHashInput
+	iterator.after = iterator.digester(key.AtreeValue())
+
+	iterator.seek(rootSlab)
+
+	return iterator
+}
+
 func (s StorageMap) StorageID() atree.StorageID {
 	return s.orderedMap.StorageID()
 }
@@ -246,3 +300,245 @@ func (i StorageMapIterator) NextValue() Value {
 
 	return MustConvertStoredValue(i.gauge, v)
 }
+
+// StorageMapSeekIterator is an iterator over StorageMap,
+// which starts after a given key.
+//
+// It walks the slabs of the storage map directly,
+// in the same order as StorageMapIterator.
+type StorageMapSeekIterator struct {
+	gauge   common.MemoryGauge
+	storage atree.SlabStorage
+	// frames are the storables of the slabs which are currently iterated,
+	// from the root slab to the current data slab
+	frames          []*storageMapSeekIteratorFrame
+	digesterBuilder atree.DigesterBuilder
+	hashInput       atree.HashInputProvider
+	// after is the digester of the key after which the iteration starts.
+	// It is nil once a following key has been found
+	after atree.Digester
+}
+
+type storageMapSeekIteratorFrame struct {
+	// storables are the storage IDs of the child slabs of a metadata slab,
+	// or the keys and values, and the storage IDs of collision groups, of a data slab
+	storables []atree.Storable
+	index     int
+	children  bool
+}
+
+// Next returns the next key and value of the storage map iterator.
+// If there is no further key-value pair, (nil, nil) is returned.
+func (i *StorageMapSeekIterator) Next() (atree.Value, Value) {
+	key, valueStorable := i.next()
+	if key == nil {
+		return nil, nil
+	}
+
+	return key, StoredValue(i.gauge, valueStorable, i.storage)
+}
+
+// NextKey returns the next key of the storage map iterator.
+// If there is no further key, nil is returned.
+func (i *StorageMapSeekIterator) NextKey() atree.Value {
+	key, _ := i.next()
+	return key
+}
+
+func (i *StorageMapSeekIterator) next() (atree.Value, atree.Storable) {
+	for len(i.frames) > 0 {
+		frame := i.frames[len(i.frames)-1]
+
+		if frame.index >= len(frame.storables) {
+			i.frames = i.frames[:len(i.frames)-1]
+			continue
+		}
+
+		storable := frame.storables[frame.index]
+		frame.index++
+
+		if frame.children {
+			i.pushSlab(i.retrieveSlab(storageIDOfStorable(storable)))
+			continue
+		}
+
+		// In a data slab, an element is either a key followed by its value,
+		// or the storage ID of a collision group, which is a map slab
+
+		if collisionGroup, ok := i.collisionGroupSlab(storable); ok {
+			i.pushSlab(collisionGroup)
+			continue
+		}
+
+		if frame.index >= len(frame.storables) {
+			panic(errors.NewUnexpectedError("missing value of storage map key"))
+		}
+		valueStorable := frame.storables[frame.index]
+		frame.index++
+
+		key := i.storedKey(storable)
+
+		if i.after != nil {
+			if !i.isAfter(key) {
+				continue
+			}
+			i.after = nil
+		}
+
+		return key, valueStorable
+	}
+
+	return nil, nil
+}
+
+// seek positions the iterator in the data slab which contains the key after which the iteration starts.
+// The keys of the data slab up to and including that key are skipped by next.
+func (i *StorageMapSeekIterator) seek(slab atree.Slab) {
+	for {
+		metaDataSlab, ok := slab.(*atree.MapMetaDataSlab)
+		if !ok {
+			i.pushSlab(slab)
+			return
+		}
+
+		children := metaDataSlab.ChildStorables()
+
+		// Find the last child slab whose first key is not after the key,
+		// the iteration continues with the following child slabs
+		index := sort.Search(len(children), func(index int) bool {
+			child := i.retrieveSlab(storageIDOfStorable(children[index]))
+			return i.isAfter(i.firstKey(child))
+		}) - 1
+		if index < 0 {
+			index = 0
+		}
+
+		i.frames = append(i.frames, &storageMapSeekIteratorFrame{
+			storables: children,
+			index:     index + 1,
+			children:  true,
+		})
+
+		slab = i.retrieveSlab(storageIDOfStorable(children[index]))
+	}
+}
+
+func (i *StorageMapSeekIterator) pushSlab(slab atree.Slab) {
+	var children bool
+	switch slab.(type) {
+	case *atree.MapMetaDataSlab:
+		children = true
+	case *atree.MapDataSlab:
+		children = false
+	default:
+		panic(errors.NewUnexpectedError("invalid storage map slab: %T", slab))
+	}
+
+	i.frames = append(i.frames, &storageMapSeekIteratorFrame{
+		storables: slab.ChildStorables(),
+		children:  children,
+	})
+}
+
+// firstKey returns the first key of the given map slab
+func (i *StorageMapSeekIterator) firstKey(slab atree.Slab) atree.Value {
+	for {
+		storables := slab.ChildStorables()
+		if len(storables) == 0 {
+			panic(errors.NewUnexpectedError("empty storage map slab"))
+		}
+
+		switch slab.(type) {
+		case *atree.MapMetaDataSlab:
+			slab = i.retrieveSlab(storageIDOfStorable(storables[0]))
+
+		case *atree.MapDataSlab:
+			collisionGroup, ok := i.collisionGroupSlab(storables[0])
+			if !ok {
+				return i.storedKey(storables[0])
+			}
+			slab = collisionGroup
+
+		default:
+			panic(errors.NewUnexpectedError("invalid storage map slab: %T", slab))
+		}
+	}
+}
+
+// collisionGroupSlab returns the slab of the collision group with the given storable, if any.
+// Large keys are also stored in separate slabs, which are not map slabs.
+func (i *StorageMapSeekIterator) collisionGroupSlab(storable atree.Storable) (atree.Slab, bool) {
+	storageIDStorable, ok := storable.(atree.StorageIDStorable)
+	if !ok {
+		return nil, false
+	}
+
+	slab := i.retrieveSlab(atree.StorageID(storageIDStorable))
+	switch slab.(type) {
+	case *atree.MapMetaDataSlab, *atree.MapDataSlab:
+		return slab, true
+	default:
+		return nil, false
+	}
+}
+
+func (i *StorageMapSeekIterator) storedKey(storable atree.Storable) atree.Value {
+	key, err := storable.StoredValue(i.storage)
+	if err != nil {
+		panic(errors.NewExternalError(err))
+	}
+	return key
+}
+
+func (i *StorageMapSeekIterator) retrieveSlab(storageID atree.StorageID) atree.Slab {
+	slab, found, err := i.storage.Retrieve(storageID)
+	if err != nil {
+		panic(errors.NewExternalError(err))
+	}
+	if !found {
+		panic(errors.NewUnexpectedError("missing storage map slab %s", storageID))
+	}
+	return slab
+}
+
+func (i *StorageMapSeekIterator) digester(key atree.Value) atree.Digester {
+	digester, err := i.digesterBuilder.Digest(i.hashInput, key)
+	if err != nil {
+		panic(errors.NewExternalError(err))
+	}
+	return digester
+}
+
+// isAfter returns true if the given key follows the key after which the iteration starts.
+// Keys are ordered by their digests, the first level of digests orders all keys,
+// further levels order the keys of collision groups.
+func (i *StorageMapSeekIterator) isAfter(key atree.Value) bool {
+	digester := i.digester(key)
+
+	for level := uint(0); level < digester.Levels(); level++ {
+		digest, err := digester.Digest(level)
+		if err != nil {
+			panic(errors.NewExternalError(err))
+		}
+
+		afterDigest, err := i.after.Digest(level)
+		if err != nil {
+			panic(errors.NewExternalError(err))
+		}
+
+		if digest != afterDigest {
+			return digest > afterDigest
+		}
+	}
+
+	// All digests are equal, so the key is the key after which the iteration starts
+	return false
+}
+
+func storageIDOfStorable(storable atree.Storable) atree.StorageID {
+	storageIDStorable, ok := storable.(atree.StorageIDStorable)
+	if !ok {
+		panic(errors.NewUnexpectedError("invalid storage map child slab storable: %T", storable))
+	}
+	return atree.StorageID(storageIDStorable)
+}
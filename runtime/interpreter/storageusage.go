@@ -0,0 +1,233 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package interpreter
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/onflow/atree"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+)
+
+// StorageUsage is the amount of storage used by a stored value, in bytes.
+//
+// The size includes the sizes of all slabs of the value,
+// i.e. the sizes of all nested fields, collections, and their elements.
+// The usage of composite values is further broken down into the usage of their fields,
+// and the usage of arrays and dictionaries into the usage of their elements.
+type StorageUsage struct {
+	// Type is the static type of the value
+	Type StaticType
+	// Name is the path identifier of a stored value, the field name of a nested value,
+	// or the index or key of an element in brackets, e.g. `[0]` or `["a"]`
+	Name string
+	// Members is the storage usage of the fields of a composite value,
+	// or the elements of an array or dictionary value
+	Members []*StorageUsage
+	// Size is the total size of the value, in bytes
+	Size uint64
+}
+
+// ForEach calls the given function for the usage and each nested member usage, in pre-order.
+// The path of a nested field is the path of its parent, followed by a dot and the name of the field.
+// The path of an element is the path of its parent, followed by the bracketed index or key of the element.
+func (u *StorageUsage) ForEach(path string, f func(path string, usage *StorageUsage)) {
+	f(path, u)
+	for _, member := range u.Members {
+		memberPath := path
+		if !strings.HasPrefix(member.Name, "[") {
+			memberPath += "."
+		}
+		member.ForEach(memberPath+member.Name, f)
+	}
+}
+
+// StorageUsage returns the amount of storage used by the value
+// stored under the given identifier in the given domain of the given account,
+// or nil if no value is stored.
+func (interpreter *Interpreter) StorageUsage(
+	address common.Address,
+	domain string,
+	identifier string,
+) *StorageUsage {
+	storageMap := interpreter.Storage().GetStorageMap(address, domain, false)
+	if storageMap == nil {
+		return nil
+	}
+
+	storable := storageMap.ReadStorable(StringStorageMapKey(identifier))
+	if storable == nil {
+		return nil
+	}
+
+	return interpreter.storableStorageUsage(identifier, storable)
+}
+
+func (interpreter *Interpreter) storableStorageUsage(name string, storable atree.Storable) *StorageUsage {
+	// Meter each entry, as a single slab may contain many elements,
+	// each of which results in an entry
+	interpreter.ReportComputation(common.ComputationKindLoop, 1)
+
+	storage := interpreter.Storage()
+
+	value := StoredValue(interpreter, storable, storage)
+
+	usage := &StorageUsage{
+		Name: name,
+		Type: value.StaticType(interpreter),
+		Size: uint64(storable.ByteSize()) +
+			interpreter.referencedSlabsSize(storable),
+	}
+
+	switch value := value.(type) {
+	case *CompositeValue:
+		usage.Members = interpreter.compositeStorageUsages(value)
+	case *ArrayValue:
+		usage.Members = interpreter.arrayStorageUsages(value)
+	case *DictionaryValue:
+		usage.Members = interpreter.dictionaryStorageUsages(value)
+	}
+
+	return usage
+}
+
+func (interpreter *Interpreter) compositeStorageUsages(composite *CompositeValue) []*StorageUsage {
+	var fieldNames []string
+	composite.ForEachField(interpreter, func(fieldName string, _ Value) (resume bool) {
+		fieldNames = append(fieldNames, fieldName)
+		return true
+	})
+
+	usages := make([]*StorageUsage, 0, len(fieldNames))
+
+	for _, fieldName := range fieldNames {
+		fieldStorable, err := composite.dictionary.Get(
+			StringAtreeValueComparator,
+			StringAtreeValueHashInput,
+			StringAtreeValue(fieldName),
+		)
+		if err != nil {
+			panic(errors.NewExternalError(err))
+		}
+
+		usages = append(
+			usages,
+			interpreter.storableStorageUsage(fieldName, fieldStorable),
+		)
+	}
+
+	return usages
+}
+
+func (interpreter *Interpreter) arrayStorageUsages(array *ArrayValue) []*StorageUsage {
+	count := array.array.Count()
+
+	usages := make([]*StorageUsage, 0, count)
+
+	for index := uint64(0); index < count; index++ {
+		elementStorable, err := array.array.Get(index)
+		if err != nil {
+			panic(errors.NewExternalError(err))
+		}
+
+		usages = append(
+			usages,
+			interpreter.storableStorageUsage(
+				"["+strconv.FormatUint(index, 10)+"]",
+				elementStorable,
+			),
+		)
+	}
+
+	return usages
+}
+
+func (interpreter *Interpreter) dictionaryStorageUsages(dictionary *DictionaryValue) []*StorageUsage {
+	var keys []Value
+	err := dictionary.dictionary.IterateKeys(func(key atree.Value) (resume bool, err error) {
+		keys = append(keys, MustConvertStoredValue(interpreter, key))
+		return true, nil
+	})
+	if err != nil {
+		panic(errors.NewExternalError(err))
+	}
+
+	valueComparator := newValueComparator(interpreter, EmptyLocationRange)
+	hashInputProvider := newHashInputProvider(interpreter, EmptyLocationRange)
+
+	usages := make([]*StorageUsage, 0, len(keys))
+
+	for _, key := range keys {
+		valueStorable, err := dictionary.dictionary.Get(
+			valueComparator,
+			hashInputProvider,
+			key,
+		)
+		if err != nil {
+			panic(errors.NewExternalError(err))
+		}
+
+		usages = append(
+			usages,
+			interpreter.storableStorageUsage(
+				"["+key.String()+"]",
+				valueStorable,
+			),
+		)
+	}
+
+	return usages
+}
+
+// referencedSlabsSize returns the total size of all slabs
+// which are (transitively) referenced by the given storable.
+func (interpreter *Interpreter) referencedSlabsSize(storable atree.Storable) uint64 {
+	if storageIDStorable, ok := storable.(atree.StorageIDStorable); ok {
+		return interpreter.slabSize(atree.StorageID(storageIDStorable))
+	}
+
+	var size uint64
+	for _, childStorable := range storable.ChildStorables() {
+		size += interpreter.referencedSlabsSize(childStorable)
+	}
+	return size
+}
+
+// slabSize returns the total size of the slab with the given ID,
+// including the sizes of all slabs it (transitively) references.
+func (interpreter *Interpreter) slabSize(storageID atree.StorageID) uint64 {
+	interpreter.ReportComputation(common.ComputationKindLoop, 1)
+
+	slab, found, err := interpreter.Storage().Retrieve(storageID)
+	if err != nil {
+		panic(errors.NewExternalError(err))
+	}
+	if !found {
+		panic(errors.NewUnexpectedError("missing slab: %s", storageID))
+	}
+
+	size := uint64(slab.ByteSize())
+	for _, childStorable := range slab.ChildStorables() {
+		size += interpreter.referencedSlabsSize(childStorable)
+	}
+	return size
+}
@@ -16475,6 +16475,12 @@ func (v *CompositeValue) Destroy(interpreter *Interpreter, locationRange Locatio
 		v.checkInvalidatedResourceUse(locationRange)
 	}
 
+	if v.Kind == common.CompositeKindResource &&
+		config.OnResourceDestroyed != nil {
+
+		config.OnResourceDestroyed(interpreter, v, locationRange)
+	}
+
 	if config.TracingEnabled {
 		startTime := time.Now()
 
@@ -17384,6 +17390,21 @@ func (v *CompositeValue) Transfer(
 		)
 	}
 
+	onResourceMoved := config.OnResourceMoved
+
+	if isResourceKinded &&
+		res.Kind == common.CompositeKindResource &&
+		onResourceMoved != nil {
+
+		onResourceMoved(
+			interpreter,
+			res,
+			locationRange,
+			common.Address(currentAddress),
+			common.Address(address),
+		)
+	}
+
 	return res
 }
 
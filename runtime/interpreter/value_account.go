@@ -48,6 +48,7 @@ func NewAuthAccountValue(
 	storageCapacityGet func(interpreter *Interpreter) UInt64Value,
 	addPublicKeyFunction FunctionValue,
 	removePublicKeyFunction FunctionValue,
+	storageUsageFunctionConstructor func() FunctionValue,
 	contractsConstructor func() Value,
 	keysConstructor func() Value,
 	inboxConstructor func() Value,
@@ -64,9 +65,12 @@ func NewAuthAccountValue(
 	var keys Value
 	var inbox Value
 	var capabilities Value
+	var storageUsageFunction FunctionValue
 	var forEachStoredFunction *HostFunctionValue
 	var forEachPublicFunction *HostFunctionValue
 	var forEachPrivateFunction *HostFunctionValue
+	var getStoragePathsFunction *HostFunctionValue
+	var getStoragePathsOfTypeFunction *HostFunctionValue
 	var typeFunction *HostFunctionValue
 	var loadFunction *HostFunctionValue
 	var copyFunction *HostFunctionValue
@@ -114,6 +118,29 @@ func NewAuthAccountValue(
 		case sema.AuthAccountTypeStoragePathsFieldName:
 			return inter.storageAccountPaths(address, locationRange)
 
+		case sema.AuthAccountTypeStoredCountFieldName:
+			return inter.storedCount(address)
+
+		case sema.AuthAccountTypeGetStoragePathsFunctionName:
+			if getStoragePathsFunction == nil {
+				getStoragePathsFunction = inter.newStoragePathsPageFunction(
+					sema.AuthAccountTypeGetStoragePathsFunctionType,
+					address,
+					false,
+				)
+			}
+			return getStoragePathsFunction
+
+		case sema.AuthAccountTypeGetStoragePathsOfTypeFunctionName:
+			if getStoragePathsOfTypeFunction == nil {
+				getStoragePathsOfTypeFunction = inter.newStoragePathsPageFunction(
+					sema.AuthAccountTypeGetStoragePathsOfTypeFunctionType,
+					address,
+					true,
+				)
+			}
+			return getStoragePathsOfTypeFunction
+
 		case sema.AuthAccountTypeForEachPublicFunctionName:
 			if forEachPublicFunction == nil {
 				forEachPublicFunction = inter.newStorageIterationFunction(
@@ -159,6 +186,12 @@ func NewAuthAccountValue(
 		case sema.AuthAccountTypeStorageCapacityFieldName:
 			return storageCapacityGet(inter)
 
+		case sema.AuthAccountTypeStorageUsageFunctionName:
+			if storageUsageFunction == nil {
+				storageUsageFunction = storageUsageFunctionConstructor()
+			}
+			return storageUsageFunction
+
 		case sema.AuthAccountTypeTypeFunctionName:
 			if typeFunction == nil {
 				typeFunction = inter.authAccountTypeFunction(address)
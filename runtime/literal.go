@@ -20,6 +20,7 @@ package runtime
 
 import (
 	"math/big"
+	"strings"
 
 	"github.com/onflow/cadence"
 	"github.com/onflow/cadence/fixedpoint"
@@ -34,6 +35,19 @@ var InvalidLiteralError = parser.NewUnpositionedSyntaxError("invalid literal")
 var UnsupportedLiteralError = parser.NewUnpositionedSyntaxError("unsupported literal")
 var LiteralExpressionTypeError = parser.NewUnpositionedSyntaxError("input is not a literal")
 
+// LiteralResolver resolves the types referred to by literals,
+// e.g. against the contracts imported by the transaction or script the literals are arguments of.
+//
+// Composite constructors and type value literals use ResolveType,
+// enum case literals (e.g. `C.Color.red`) also use ResolveEnumCases.
+// Either function may be nil.
+type LiteralResolver struct {
+	// ResolveType returns the type with the given qualified identifier, e.g. `C.S`
+	ResolveType func(qualifiedIdentifier string) (sema.Type, error)
+	// ResolveEnumCases returns the names of the cases of the given enum type, in declaration order
+	ResolveEnumCases func(enumType *sema.CompositeType) ([]string, error)
+}
+
 // ParseLiteral parses a single literal string, that should have the given type.
 //
 // Returns an error if the literal string is not a literal (e.g. it does not have valid syntax,
@@ -45,6 +59,23 @@ func ParseLiteral(
 ) (
 	cadence.Value,
 	error,
+) {
+	return ParseLiteralWithResolver(literal, ty, inter, nil)
+}
+
+// ParseLiteralWithResolver parses a single literal string, that should have the given type.
+// Types referred to by the literal, e.g. the type of a composite constructor, are resolved using the given resolver.
+//
+// Returns an error if the literal string is not a literal (e.g. it does not have valid syntax,
+// or does not parse to a literal).
+func ParseLiteralWithResolver(
+	literal string,
+	ty sema.Type,
+	inter *interpreter.Interpreter,
+	resolver *LiteralResolver,
+) (
+	cadence.Value,
+	error,
 ) {
 	code := []byte(literal)
 
@@ -56,7 +87,7 @@ func ParseLiteral(
 		}
 	}
 
-	return LiteralValue(inter, expression, ty)
+	return LiteralValueWithResolver(inter, expression, ty, resolver)
 }
 
 // ParseLiteralArgumentList parses an argument list with literals, that should have the given types.
@@ -71,6 +102,21 @@ func ParseLiteralArgumentList(
 ) (
 	[]cadence.Value,
 	error,
+) {
+	return ParseLiteralArgumentListWithResolver(argumentList, parameterTypes, inter, nil)
+}
+
+// ParseLiteralArgumentListWithResolver parses an argument list with literals, that should have the given types.
+// Types referred to by the literals are resolved using the given resolver.
+// Returns an error if the code is not a valid argument list, or the arguments are not literals.
+func ParseLiteralArgumentListWithResolver(
+	argumentList string,
+	parameterTypes []sema.Type,
+	inter *interpreter.Interpreter,
+	resolver *LiteralResolver,
+) (
+	[]cadence.Value,
+	error,
 ) {
 	code := []byte(argumentList)
 	arguments, errs := parser.ParseArgumentList(inter, code, parser.Config{})
@@ -95,7 +141,7 @@ func ParseLiteralArgumentList(
 
 	for i, argument := range arguments {
 		parameterType := parameterTypes[i]
-		value, err := LiteralValue(inter, argument.Expression, parameterType)
+		value, err := LiteralValueWithResolver(inter, argument.Expression, parameterType, resolver)
 		if err != nil {
 			return nil, parser.NewSyntaxError(
 				argument.Expression.StartPosition(),
@@ -108,7 +154,12 @@ func ParseLiteralArgumentList(
 	return result, nil
 }
 
-func arrayLiteralValue(inter *interpreter.Interpreter, elements []ast.Expression, elementType sema.Type) (cadence.Value, error) {
+func arrayLiteralValue(
+	inter *interpreter.Interpreter,
+	elements []ast.Expression,
+	elementType sema.Type,
+	resolver *LiteralResolver,
+) (cadence.Value, error) {
 	return cadence.NewMeteredArray(
 		inter,
 		len(elements),
@@ -116,7 +167,7 @@ func arrayLiteralValue(inter *interpreter.Interpreter, elements []ast.Expression
 			values := make([]cadence.Value, len(elements))
 
 			for i, element := range elements {
-				convertedElement, err := LiteralValue(inter, element, elementType)
+				convertedElement, err := LiteralValueWithResolver(inter, element, elementType, resolver)
 				if err != nil {
 					return nil, err
 				}
@@ -297,6 +348,17 @@ func fixedPointLiteralValue(memoryGauge common.MemoryGauge, expression ast.Expre
 }
 
 func LiteralValue(inter *interpreter.Interpreter, expression ast.Expression, ty sema.Type) (cadence.Value, error) {
+	return LiteralValueWithResolver(inter, expression, ty, nil)
+}
+
+// LiteralValueWithResolver converts the given literal expression to a value of the given type.
+// Types referred to by the expression are resolved using the given resolver.
+func LiteralValueWithResolver(
+	inter *interpreter.Interpreter,
+	expression ast.Expression,
+	ty sema.Type,
+	resolver *LiteralResolver,
+) (cadence.Value, error) {
 	switch ty := ty.(type) {
 	case *sema.VariableSizedType:
 		expression, ok := expression.(*ast.ArrayExpression)
@@ -304,7 +366,7 @@ func LiteralValue(inter *interpreter.Interpreter, expression ast.Expression, ty
 			return nil, LiteralExpressionTypeError
 		}
 
-		return arrayLiteralValue(inter, expression.Values, ty.Type)
+		return arrayLiteralValue(inter, expression.Values, ty.Type, resolver)
 
 	case *sema.ConstantSizedType:
 		expression, ok := expression.(*ast.ArrayExpression)
@@ -312,7 +374,7 @@ func LiteralValue(inter *interpreter.Interpreter, expression ast.Expression, ty
 			return nil, LiteralExpressionTypeError
 		}
 
-		return arrayLiteralValue(inter, expression.Values, ty.Type)
+		return arrayLiteralValue(inter, expression.Values, ty.Type, resolver)
 
 	case *sema.OptionalType:
 		if _, ok := expression.(*ast.NilExpression); ok {
@@ -326,7 +388,7 @@ func LiteralValue(inter *interpreter.Interpreter, expression ast.Expression, ty
 			}
 		}
 
-		converted, err := LiteralValue(inter, expression, ty.Type)
+		converted, err := LiteralValueWithResolver(inter, expression, ty.Type, resolver)
 		if err != nil {
 			return nil, err
 		}
@@ -348,12 +410,12 @@ func LiteralValue(inter *interpreter.Interpreter, expression ast.Expression, ty
 				for i, entry := range expression.Entries {
 					var err error
 
-					pairs[i].Key, err = LiteralValue(inter, entry.Key, ty.KeyType)
+					pairs[i].Key, err = LiteralValueWithResolver(inter, entry.Key, ty.KeyType, resolver)
 					if err != nil {
 						return nil, err
 					}
 
-					pairs[i].Value, err = LiteralValue(inter, entry.Value, ty.ValueType)
+					pairs[i].Value, err = LiteralValueWithResolver(inter, entry.Value, ty.ValueType, resolver)
 					if err != nil {
 						return nil, err
 					}
@@ -374,6 +436,9 @@ func LiteralValue(inter *interpreter.Interpreter, expression ast.Expression, ty
 		}
 
 		return cadence.BytesToAddress(expression.Value.Bytes()), nil
+
+	case *sema.CompositeType:
+		return compositeLiteralValue(inter, expression, ty, resolver)
 	}
 
 	switch ty {
@@ -398,6 +463,27 @@ func LiteralValue(inter *interpreter.Interpreter, expression ast.Expression, ty
 				return expression.Value
 			},
 		)
+
+	case sema.CharacterType:
+		expression, ok := expression.(*ast.StringExpression)
+		if !ok {
+			return nil, LiteralExpressionTypeError
+		}
+
+		if !sema.IsValidCharacter(expression.Value) {
+			return nil, InvalidLiteralError
+		}
+
+		return cadence.NewMeteredCharacter(
+			inter,
+			common.NewCadenceCharacterMemoryUsage(len(expression.Value)),
+			func() string {
+				return expression.Value
+			},
+		)
+
+	case sema.MetaType:
+		return typeLiteralValue(inter, expression, resolver)
 	}
 
 	switch {
@@ -411,5 +497,522 @@ func LiteralValue(inter *interpreter.Interpreter, expression ast.Expression, ty
 		return pathLiteralValue(inter, expression, ty)
 	}
 
+	// Composite constructors and enum cases may also be passed for a supertype,
+	// e.g. for a parameter of type `AnyStruct`, if the resolver can resolve their type
+
+	compositeType, err := resolveLiteralCompositeType(expression, resolver)
+	if err == nil && sema.IsSubType(compositeType, ty) {
+		return compositeLiteralValue(inter, expression, compositeType, resolver)
+	}
+
+	return nil, UnsupportedLiteralError
+}
+
+// literalCompositeTypeName returns the qualified identifier of the composite type
+// of the given composite literal, which is either a struct constructor invocation (`C.S(a: 1)`),
+// a forced enum constructor invocation (`C.E(rawValue: 1)!`), or an enum case (`C.E.a`).
+func literalCompositeTypeName(expression ast.Expression) (string, bool) {
+	switch expression := expression.(type) {
+	case *ast.InvocationExpression:
+		return literalQualifiedIdentifier(expression.InvokedExpression)
+
+	case *ast.ForceExpression:
+		invocation, ok := expression.Expression.(*ast.InvocationExpression)
+		if !ok {
+			return "", false
+		}
+		return literalQualifiedIdentifier(invocation.InvokedExpression)
+
+	case *ast.MemberExpression:
+		if expression.Optional {
+			return "", false
+		}
+		return literalQualifiedIdentifier(expression.Expression)
+	}
+
+	return "", false
+}
+
+// literalQualifiedIdentifier returns the qualified identifier
+// of the given identifier expression (`S`) or nested member expression (`C.S`).
+func literalQualifiedIdentifier(expression ast.Expression) (string, bool) {
+	switch expression := expression.(type) {
+	case *ast.IdentifierExpression:
+		return expression.Identifier.Identifier, true
+
+	case *ast.MemberExpression:
+		if expression.Optional {
+			return "", false
+		}
+		prefix, ok := literalQualifiedIdentifier(expression.Expression)
+		if !ok {
+			return "", false
+		}
+		return prefix + "." + expression.Identifier.Identifier, true
+	}
+
+	return "", false
+}
+
+func resolveLiteralType(qualifiedIdentifier string, resolver *LiteralResolver) (sema.Type, error) {
+	if resolver == nil || resolver.ResolveType == nil {
+		return nil, parser.NewUnpositionedSyntaxError(
+			"cannot resolve type %s",
+			qualifiedIdentifier,
+		)
+	}
+
+	ty, err := resolver.ResolveType(qualifiedIdentifier)
+	if err != nil {
+		return nil, parser.NewUnpositionedSyntaxError(
+			"cannot resolve type %s: %s",
+			qualifiedIdentifier,
+			err,
+		)
+	}
+	if ty == nil {
+		return nil, parser.NewUnpositionedSyntaxError(
+			"cannot resolve type %s",
+			qualifiedIdentifier,
+		)
+	}
+
+	return ty, nil
+}
+
+func resolveLiteralCompositeType(expression ast.Expression, resolver *LiteralResolver) (*sema.CompositeType, error) {
+	name, ok := literalCompositeTypeName(expression)
+	if !ok {
+		return nil, LiteralExpressionTypeError
+	}
+
+	ty, err := resolveLiteralType(name, resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	compositeType, ok := ty.(*sema.CompositeType)
+	if !ok {
+		return nil, parser.NewUnpositionedSyntaxError(
+			"type %s is not a composite type",
+			name,
+		)
+	}
+
+	return compositeType, nil
+}
+
+func compositeLiteralValue(
+	inter *interpreter.Interpreter,
+	expression ast.Expression,
+	ty *sema.CompositeType,
+	resolver *LiteralResolver,
+) (
+	cadence.Value,
+	error,
+) {
+	name, ok := literalCompositeTypeName(expression)
+	if !ok {
+		return nil, LiteralExpressionTypeError
+	}
+
+	// The requested type does not need to be resolved
+
+	if name != ty.QualifiedIdentifier() {
+		literalType, err := resolveLiteralType(name, resolver)
+		if err != nil {
+			return nil, err
+		}
+
+		if literalType.ID() != ty.ID() {
+			return nil, parser.NewUnpositionedSyntaxError(
+				"literal type %s is not requested type %s",
+				literalType.QualifiedString(),
+				ty.QualifiedString(),
+			)
+		}
+	}
+
+	switch ty.Kind {
+	case common.CompositeKindStructure:
+		invocation, ok := expression.(*ast.InvocationExpression)
+		if !ok {
+			return nil, LiteralExpressionTypeError
+		}
+		return structLiteralValue(inter, invocation, ty, resolver)
+
+	case common.CompositeKindEnum:
+		return enumLiteralValue(inter, expression, ty, resolver)
+	}
+
+	return nil, UnsupportedLiteralError
+}
+
+// structLiteralValue converts a struct constructor invocation to a struct value.
+//
+// The arguments of the invocation are the values of the fields, labeled with the field names.
+// The initializer of the struct is not executed.
+func structLiteralValue(
+	inter *interpreter.Interpreter,
+	invocation *ast.InvocationExpression,
+	ty *sema.CompositeType,
+	resolver *LiteralResolver,
+) (
+	cadence.Value,
+	error,
+) {
+	if len(invocation.TypeArguments) > 0 {
+		return nil, InvalidLiteralError
+	}
+
+	fieldMembers := make([]*sema.Member, 0, len(ty.Fields))
+	for _, identifier := range ty.Fields {
+		member, ok := ty.Members.Get(identifier)
+		if !ok || member.IgnoreInSerialization {
+			continue
+		}
+		fieldMembers = append(fieldMembers, member)
+	}
+
+	fieldArguments := make(map[string]ast.Expression, len(invocation.Arguments))
+	for _, argument := range invocation.Arguments {
+		label := argument.Label
+		if label == "" {
+			return nil, parser.NewUnpositionedSyntaxError(
+				"missing field name label for argument of %s",
+				ty.QualifiedString(),
+			)
+		}
+
+		member, ok := ty.Members.Get(label)
+		if !ok ||
+			member.DeclarationKind != common.DeclarationKindField ||
+			member.IgnoreInSerialization {
+
+			return nil, parser.NewUnpositionedSyntaxError(
+				"unknown field %s of %s",
+				label,
+				ty.QualifiedString(),
+			)
+		}
+
+		if _, ok := fieldArguments[label]; ok {
+			return nil, parser.NewUnpositionedSyntaxError(
+				"duplicate field %s of %s",
+				label,
+				ty.QualifiedString(),
+			)
+		}
+
+		fieldArguments[label] = argument.Expression
+	}
+
+	for _, member := range fieldMembers {
+		if _, ok := fieldArguments[member.Identifier.Identifier]; !ok {
+			return nil, parser.NewUnpositionedSyntaxError(
+				"missing field %s of %s",
+				member.Identifier.Identifier,
+				ty.QualifiedString(),
+			)
+		}
+	}
+
+	structType, ok := ExportMeteredType(inter, ty, map[sema.TypeID]cadence.Type{}).(*cadence.StructType)
+	if !ok {
+		return nil, UnsupportedLiteralError
+	}
+
+	structValue, err := cadence.NewMeteredStruct(
+		inter,
+		len(fieldMembers),
+		func() ([]cadence.Value, error) {
+			fields := make([]cadence.Value, len(fieldMembers))
+
+			for i, member := range fieldMembers {
+				field, err := LiteralValueWithResolver(
+					inter,
+					fieldArguments[member.Identifier.Identifier],
+					member.TypeAnnotation.Type,
+					resolver,
+				)
+				if err != nil {
+					return nil, err
+				}
+				fields[i] = field
+			}
+
+			return fields, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return structValue.WithType(structType), nil
+}
+
+// enumLiteralValue converts a forced enum constructor invocation or an enum case to an enum value.
+func enumLiteralValue(
+	inter *interpreter.Interpreter,
+	expression ast.Expression,
+	ty *sema.CompositeType,
+	resolver *LiteralResolver,
+) (
+	cadence.Value,
+	error,
+) {
+	var rawValue cadence.Value
+	var err error
+
+	switch expression := expression.(type) {
+	case *ast.ForceExpression:
+		invocation, ok := expression.Expression.(*ast.InvocationExpression)
+		if !ok ||
+			len(invocation.TypeArguments) > 0 ||
+			len(invocation.Arguments) != 1 ||
+			invocation.Arguments[0].Label != sema.EnumRawValueFieldName {
+
+			return nil, InvalidLiteralError
+		}
+
+		rawValue, err = LiteralValueWithResolver(
+			inter,
+			invocation.Arguments[0].Expression,
+			ty.EnumRawType,
+			resolver,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+	case *ast.MemberExpression:
+		if resolver == nil || resolver.ResolveEnumCases == nil {
+			return nil, parser.NewUnpositionedSyntaxError(
+				"cannot resolve cases of enum %s",
+				ty.QualifiedString(),
+			)
+		}
+
+		cases, err := resolver.ResolveEnumCases(ty)
+		if err != nil {
+			return nil, parser.NewUnpositionedSyntaxError(
+				"cannot resolve cases of enum %s: %s",
+				ty.QualifiedString(),
+				err,
+			)
+		}
+
+		caseName := expression.Identifier.Identifier
+
+		index := -1
+		for i, name := range cases {
+			if name == caseName {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, parser.NewUnpositionedSyntaxError(
+				"enum %s has no case %s",
+				ty.QualifiedString(),
+				caseName,
+			)
+		}
+
+		// The raw value of an enum case is its index
+
+		convertedValue, err := convertIntValue(
+			inter,
+			interpreter.NewIntValueFromInt64(inter, int64(index)),
+			ty.EnumRawType,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		rawValue, err = ExportValue(convertedValue, inter, interpreter.EmptyLocationRange)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, LiteralExpressionTypeError
+	}
+
+	enumType, ok := ExportMeteredType(inter, ty, map[sema.TypeID]cadence.Type{}).(*cadence.EnumType)
+	if !ok {
+		return nil, UnsupportedLiteralError
+	}
+
+	enumValue, err := cadence.NewMeteredEnum(
+		inter,
+		1,
+		func() ([]cadence.Value, error) {
+			return []cadence.Value{rawValue}, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return enumValue.WithType(enumType), nil
+}
+
+// typeLiteralValue converts a type value literal (`Type<T>()`) to a type value.
+func typeLiteralValue(
+	inter *interpreter.Interpreter,
+	expression ast.Expression,
+	resolver *LiteralResolver,
+) (
+	cadence.Value,
+	error,
+) {
+	invocation, ok := expression.(*ast.InvocationExpression)
+	if !ok {
+		return nil, LiteralExpressionTypeError
+	}
+
+	identifier, ok := invocation.InvokedExpression.(*ast.IdentifierExpression)
+	if !ok || identifier.Identifier.Identifier != sema.MetaTypeName {
+		return nil, LiteralExpressionTypeError
+	}
+
+	if len(invocation.Arguments) > 0 || len(invocation.TypeArguments) != 1 {
+		return nil, InvalidLiteralError
+	}
+
+	ty, err := literalType(inter, invocation.TypeArguments[0].Type, resolver)
+	if err != nil {
+		return nil, err
+	}
+
+	return cadence.NewMeteredTypeValue(
+		inter,
+		ExportMeteredType(inter, ty, map[sema.TypeID]cadence.Type{}),
+	), nil
+}
+
+// literalType converts the type of a type value literal to a sema type.
+// Built-in types are found by name, all other nominal types are resolved using the given resolver.
+func literalType(
+	inter *interpreter.Interpreter,
+	t ast.Type,
+	resolver *LiteralResolver,
+) (
+	sema.Type,
+	error,
+) {
+	switch t := t.(type) {
+	case *ast.NominalType:
+		if len(t.NestedIdentifiers) == 0 {
+			variable := sema.BaseTypeActivation.Find(t.Identifier.Identifier)
+			if variable != nil {
+				return variable.Type, nil
+			}
+		}
+
+		identifiers := make([]string, 0, 1+len(t.NestedIdentifiers))
+		identifiers = append(identifiers, t.Identifier.Identifier)
+		for _, nestedIdentifier := range t.NestedIdentifiers {
+			identifiers = append(identifiers, nestedIdentifier.Identifier)
+		}
+
+		return resolveLiteralType(strings.Join(identifiers, "."), resolver)
+
+	case *ast.OptionalType:
+		innerType, err := literalType(inter, t.Type, resolver)
+		if err != nil {
+			return nil, err
+		}
+		return sema.NewOptionalType(inter, innerType), nil
+
+	case *ast.VariableSizedType:
+		elementType, err := literalType(inter, t.Type, resolver)
+		if err != nil {
+			return nil, err
+		}
+		return sema.NewVariableSizedType(inter, elementType), nil
+
+	case *ast.ConstantSizedType:
+		elementType, err := literalType(inter, t.Type, resolver)
+		if err != nil {
+			return nil, err
+		}
+		size := t.Size.Value
+		if !size.IsInt64() || size.Sign() < 0 {
+			return nil, InvalidLiteralError
+		}
+		return sema.NewConstantSizedType(inter, elementType, size.Int64()), nil
+
+	case *ast.DictionaryType:
+		keyType, err := literalType(inter, t.KeyType, resolver)
+		if err != nil {
+			return nil, err
+		}
+		valueType, err := literalType(inter, t.ValueType, resolver)
+		if err != nil {
+			return nil, err
+		}
+		if !sema.IsSubType(keyType, sema.HashableStructType) {
+			return nil, InvalidLiteralError
+		}
+		return sema.NewDictionaryType(inter, keyType, valueType), nil
+
+	case *ast.ReferenceType:
+		referencedType, err := literalType(inter, t.Type, resolver)
+		if err != nil {
+			return nil, err
+		}
+		return sema.NewReferenceType(inter, referencedType, t.Authorized), nil
+
+	case *ast.RestrictedType:
+		restrictions := make([]*sema.InterfaceType, 0, len(t.Restrictions))
+		for _, restriction := range t.Restrictions {
+			restrictionType, err := literalType(inter, restriction, resolver)
+			if err != nil {
+				return nil, err
+			}
+			interfaceType, ok := restrictionType.(*sema.InterfaceType)
+			if !ok {
+				return nil, InvalidLiteralError
+			}
+			restrictions = append(restrictions, interfaceType)
+		}
+
+		var restrictedType sema.Type
+		if t.Type != nil {
+			var err error
+			restrictedType, err = literalType(inter, t.Type, resolver)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			// Like in the checker, the restricted type defaults
+			// to the least specific type of the restrictions' kind
+			restrictedType = sema.AnyStructType
+			if len(restrictions) > 0 && restrictions[0].CompositeKind == common.CompositeKindResource {
+				restrictedType = sema.AnyResourceType
+			}
+		}
+
+		return sema.NewRestrictedType(inter, restrictedType, restrictions), nil
+
+	case *ast.InstantiationType:
+		instantiatedType, err := literalType(inter, t.Type, resolver)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := instantiatedType.(*sema.CapabilityType); !ok || len(t.TypeArguments) != 1 {
+			return nil, UnsupportedLiteralError
+		}
+
+		borrowType, err := literalType(inter, t.TypeArguments[0].Type, resolver)
+		if err != nil {
+			return nil, err
+		}
+		return sema.NewCapabilityType(inter, borrowType), nil
+	}
+
 	return nil, UnsupportedLiteralError
 }
@@ -0,0 +1,365 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/format"
+)
+
+// UnsupportedLiteralValueError is returned by EncodeLiteral
+// when a value has no representation in Cadence literal syntax,
+// e.g. a resource, a capability, or a function.
+type UnsupportedLiteralValueError struct {
+	Value cadence.Value
+}
+
+func (e UnsupportedLiteralValueError) Error() string {
+	return fmt.Sprintf("cannot encode value as literal: %s", literalValueKind(e.Value))
+}
+
+func literalValueKind(value cadence.Value) string {
+	if value == nil {
+		return "nil"
+	}
+	ty := value.Type()
+	if ty == nil {
+		return fmt.Sprintf("%T", value)
+	}
+	return ty.ID()
+}
+
+// EncodeLiteral encodes the given value in Cadence literal syntax.
+// It is the inverse of ParseLiteral: the result parses back to the given value
+// when parsed with the value's type.
+//
+// Composite values are encoded as constructor invocations,
+// which use the field names as argument labels:
+// structs as `C.S(a: 1)`, and enums as `C.E(rawValue: 1)!`.
+// Like types in type values, they are referred to by qualified identifier,
+// see ParseLiteralWithResolver.
+//
+// Nested optionals are encoded as `nil` if any of the nested optionals is nil.
+func EncodeLiteral(value cadence.Value) (string, error) {
+	var builder strings.Builder
+	err := encodeLiteral(&builder, value)
+	if err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
+
+// EncodeLiteralArgumentList encodes the given values as an argument list in Cadence literal syntax.
+// It is the inverse of ParseLiteralArgumentList.
+func EncodeLiteralArgumentList(values []cadence.Value) (string, error) {
+	var builder strings.Builder
+	builder.WriteByte('(')
+	for i, value := range values {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		err := encodeLiteral(&builder, value)
+		if err != nil {
+			return "", err
+		}
+	}
+	builder.WriteByte(')')
+	return builder.String(), nil
+}
+
+func encodeLiteral(builder *strings.Builder, value cadence.Value) error {
+	switch value := value.(type) {
+	case cadence.Optional:
+		if value.Value == nil {
+			builder.WriteString("nil")
+			return nil
+		}
+		return encodeLiteral(builder, value.Value)
+
+	case cadence.Bool,
+		cadence.Address,
+		cadence.Path,
+		cadence.Int,
+		cadence.Int8,
+		cadence.Int16,
+		cadence.Int32,
+		cadence.Int64,
+		cadence.Int128,
+		cadence.Int256,
+		cadence.UInt,
+		cadence.UInt8,
+		cadence.UInt16,
+		cadence.UInt32,
+		cadence.UInt64,
+		cadence.UInt128,
+		cadence.UInt256,
+		cadence.Word8,
+		cadence.Word16,
+		cadence.Word32,
+		cadence.Word64,
+		cadence.Word128,
+		cadence.Word256,
+		cadence.Fix64,
+		cadence.UFix64:
+
+		// The string representation of these values is already in literal syntax
+		builder.WriteString(value.String())
+		return nil
+
+	case cadence.String:
+		builder.WriteString(ast.QuoteString(string(value)))
+		return nil
+
+	case cadence.Character:
+		builder.WriteString(ast.QuoteString(string(value)))
+		return nil
+
+	case cadence.Array:
+		builder.WriteByte('[')
+		for i, element := range value.Values {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			err := encodeLiteral(builder, element)
+			if err != nil {
+				return err
+			}
+		}
+		builder.WriteByte(']')
+		return nil
+
+	case cadence.Dictionary:
+		builder.WriteByte('{')
+		for i, pair := range value.Pairs {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			err := encodeLiteral(builder, pair.Key)
+			if err != nil {
+				return err
+			}
+			builder.WriteString(": ")
+			err = encodeLiteral(builder, pair.Value)
+			if err != nil {
+				return err
+			}
+		}
+		builder.WriteByte('}')
+		return nil
+
+	case cadence.TypeValue:
+		if value.StaticType == nil {
+			return UnsupportedLiteralValueError{Value: value}
+		}
+		typeString, err := EncodeLiteralType(value.StaticType)
+		if err != nil {
+			return err
+		}
+		builder.WriteString(format.TypeValue(typeString))
+		return nil
+
+	case cadence.Struct:
+		if value.StructType == nil {
+			return UnsupportedLiteralValueError{Value: value}
+		}
+		return encodeCompositeLiteral(
+			builder,
+			value.StructType.QualifiedIdentifier,
+			value.StructType.Fields,
+			value.Fields,
+		)
+
+	case cadence.Enum:
+		if value.EnumType == nil {
+			return UnsupportedLiteralValueError{Value: value}
+		}
+		err := encodeCompositeLiteral(
+			builder,
+			value.EnumType.QualifiedIdentifier,
+			value.EnumType.Fields,
+			value.Fields,
+		)
+		if err != nil {
+			return err
+		}
+		// The enum constructor returns an optional
+		builder.WriteByte('!')
+		return nil
+	}
+
+	return UnsupportedLiteralValueError{Value: value}
+}
+
+func encodeCompositeLiteral(
+	builder *strings.Builder,
+	qualifiedIdentifier string,
+	fields []cadence.Field,
+	values []cadence.Value,
+) error {
+	if len(fields) != len(values) {
+		return fmt.Errorf(
+			"cannot encode value of type %s as literal: got %d fields, expected %d",
+			qualifiedIdentifier,
+			len(values),
+			len(fields),
+		)
+	}
+
+	builder.WriteString(qualifiedIdentifier)
+	builder.WriteByte('(')
+	for i, field := range fields {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		builder.WriteString(field.Identifier)
+		builder.WriteString(": ")
+		err := encodeLiteral(builder, values[i])
+		if err != nil {
+			return err
+		}
+	}
+	builder.WriteByte(')')
+	return nil
+}
+
+// EncodeLiteralType encodes the given type in Cadence type syntax,
+// e.g. for the type argument of a type value literal.
+//
+// Composite and interface types are encoded using their qualified identifier,
+// so the result must be resolved in a program which imports their contracts.
+func EncodeLiteralType(ty cadence.Type) (string, error) {
+	switch ty := ty.(type) {
+	case nil:
+		return "", fmt.Errorf("cannot encode missing type as literal")
+
+	case *cadence.OptionalType:
+		innerType, err := EncodeLiteralType(ty.Type)
+		if err != nil {
+			return "", err
+		}
+		return innerType + "?", nil
+
+	case *cadence.VariableSizedArrayType:
+		elementType, err := EncodeLiteralType(ty.ElementType)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("[%s]", elementType), nil
+
+	case *cadence.ConstantSizedArrayType:
+		elementType, err := EncodeLiteralType(ty.ElementType)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("[%s; %d]", elementType, ty.Size), nil
+
+	case *cadence.DictionaryType:
+		keyType, err := EncodeLiteralType(ty.KeyType)
+		if err != nil {
+			return "", err
+		}
+		valueType, err := EncodeLiteralType(ty.ElementType)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("{%s: %s}", keyType, valueType), nil
+
+	case cadence.CompositeType:
+		return ty.CompositeTypeQualifiedIdentifier(), nil
+
+	case cadence.InterfaceType:
+		return ty.InterfaceTypeQualifiedIdentifier(), nil
+
+	case *cadence.ReferenceType:
+		referencedType, err := EncodeLiteralType(ty.Type)
+		if err != nil {
+			return "", err
+		}
+		if ty.Authorized {
+			return "auth &" + referencedType, nil
+		}
+		return "&" + referencedType, nil
+
+	case *cadence.RestrictedType:
+		var builder strings.Builder
+		if ty.Type != nil {
+			restrictedType, err := EncodeLiteralType(ty.Type)
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(restrictedType)
+		}
+		builder.WriteByte('{')
+		for i, restriction := range ty.Restrictions {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			restrictionType, err := EncodeLiteralType(restriction)
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(restrictionType)
+		}
+		builder.WriteByte('}')
+		return builder.String(), nil
+
+	case *cadence.CapabilityType:
+		if ty.BorrowType == nil {
+			return "Capability", nil
+		}
+		borrowType, err := EncodeLiteralType(ty.BorrowType)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Capability<%s>", borrowType), nil
+
+	case *cadence.FunctionType:
+		if len(ty.TypeParameters) > 0 {
+			return "", fmt.Errorf("cannot encode generic function type as literal: %s", ty.ID())
+		}
+		var builder strings.Builder
+		builder.WriteString("((")
+		for i, parameter := range ty.Parameters {
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			parameterType, err := EncodeLiteralType(parameter.Type)
+			if err != nil {
+				return "", err
+			}
+			builder.WriteString(parameterType)
+		}
+		builder.WriteString("): ")
+		returnType, err := EncodeLiteralType(ty.ReturnType)
+		if err != nil {
+			return "", err
+		}
+		builder.WriteString(returnType)
+		builder.WriteByte(')')
+		return builder.String(), nil
+	}
+
+	// All other types are simple types,
+	// and their type ID is their name
+	return ty.ID(), nil
+}
@@ -0,0 +1,589 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/leanovate/gopter"
+	"github.com/leanovate/gopter/gen"
+	"github.com/leanovate/gopter/prop"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+func TestEncodeLiteral(t *testing.T) {
+
+	t.Parallel()
+
+	location := common.NewAddressLocation(nil, common.MustBytesToAddress([]byte{0x1}), "C")
+
+	structType := cadence.NewStructType(
+		location,
+		"C.S",
+		[]cadence.Field{
+			{Identifier: "a", Type: cadence.IntType{}},
+			{Identifier: "b", Type: cadence.StringType{}},
+		},
+		nil,
+	)
+
+	enumType := cadence.NewEnumType(
+		location,
+		"C.E",
+		cadence.UInt8Type{},
+		[]cadence.Field{
+			{Identifier: sema.EnumRawValueFieldName, Type: cadence.UInt8Type{}},
+		},
+		nil,
+	)
+
+	resourceType := cadence.NewResourceType(
+		location,
+		"C.R",
+		[]cadence.Field{},
+		nil,
+	)
+
+	type testCase struct {
+		name     string
+		value    cadence.Value
+		expected string
+	}
+
+	for _, test := range []testCase{
+		{
+			name:     "Bool",
+			value:    cadence.NewBool(true),
+			expected: `true`,
+		},
+		{
+			name:     "String",
+			value:    cadence.String("a \"b\"\n"),
+			expected: `"a \"b\"\n"`,
+		},
+		{
+			name:     "Character",
+			value:    cadence.Character("é"),
+			expected: `"\u{e9}"`,
+		},
+		{
+			name:     "Address",
+			value:    cadence.BytesToAddress([]byte{0x1}),
+			expected: `0x0000000000000001`,
+		},
+		{
+			name:     "Int8, negative",
+			value:    cadence.Int8(-128),
+			expected: `-128`,
+		},
+		{
+			name:     "UInt256",
+			value:    cadence.NewUInt256(42),
+			expected: `42`,
+		},
+		{
+			name:     "Fix64, negative",
+			value:    cadence.Fix64(-150000000),
+			expected: `-1.50000000`,
+		},
+		{
+			name:     "UFix64",
+			value:    cadence.UFix64(1),
+			expected: `0.00000001`,
+		},
+		{
+			name: "Path",
+			value: cadence.Path{
+				Domain:     common.PathDomainStorage,
+				Identifier: "foo",
+			},
+			expected: `/storage/foo`,
+		},
+		{
+			name:     "Optional, nil",
+			value:    cadence.NewOptional(nil),
+			expected: `nil`,
+		},
+		{
+			name:     "Optional, nested",
+			value:    cadence.NewOptional(cadence.NewOptional(cadence.NewInt(1))),
+			expected: `1`,
+		},
+		{
+			name: "Array",
+			value: cadence.NewArray([]cadence.Value{
+				cadence.NewArray([]cadence.Value{cadence.NewInt(1)}),
+				cadence.NewArray([]cadence.Value{}),
+			}),
+			expected: `[[1], []]`,
+		},
+		{
+			name: "Dictionary",
+			value: cadence.NewDictionary([]cadence.KeyValuePair{
+				{Key: cadence.String("a"), Value: cadence.NewInt(1)},
+				{Key: cadence.String("b"), Value: cadence.NewInt(2)},
+			}),
+			expected: `{"a": 1, "b": 2}`,
+		},
+		{
+			name: "Struct",
+			value: cadence.NewStruct([]cadence.Value{
+				cadence.NewInt(1),
+				cadence.String("x"),
+			}).WithType(structType),
+			expected: `C.S(a: 1, b: "x")`,
+		},
+		{
+			name: "Enum",
+			value: cadence.NewEnum([]cadence.Value{
+				cadence.UInt8(2),
+			}).WithType(enumType),
+			expected: `C.E(rawValue: 2)!`,
+		},
+		{
+			name: "Type, simple",
+			value: cadence.TypeValue{
+				StaticType: cadence.IntType{},
+			},
+			expected: `Type<Int>()`,
+		},
+		{
+			name: "Type, composite",
+			value: cadence.TypeValue{
+				StaticType: &cadence.OptionalType{Type: structType},
+			},
+			expected: `Type<C.S?>()`,
+		},
+		{
+			name: "Type, nested",
+			value: cadence.TypeValue{
+				StaticType: &cadence.DictionaryType{
+					KeyType: cadence.StringType{},
+					ElementType: &cadence.ConstantSizedArrayType{
+						ElementType: cadence.IntType{},
+						Size:        2,
+					},
+				},
+			},
+			expected: `Type<{String: [Int; 2]}>()`,
+		},
+		{
+			name: "Type, capability",
+			value: cadence.TypeValue{
+				StaticType: &cadence.CapabilityType{
+					BorrowType: &cadence.ReferenceType{
+						Authorized: true,
+						Type: &cadence.RestrictedType{
+							Type:         resourceType,
+							Restrictions: []cadence.Type{cadence.AnyResourceType{}},
+						},
+					},
+				},
+			},
+			expected: `Type<Capability<auth &C.R{AnyResource}>>()`,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			literal, err := EncodeLiteral(test.value)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, literal)
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		t.Parallel()
+
+		for _, value := range []cadence.Value{
+			cadence.NewVoid(),
+			cadence.NewResource([]cadence.Value{}).WithType(resourceType),
+			cadence.NewArray([]cadence.Value{
+				cadence.NewIDCapability(1, cadence.BytesToAddress([]byte{0x1}), nil),
+			}),
+			cadence.TypeValue{},
+		} {
+			_, err := EncodeLiteral(value)
+			require.Error(t, err)
+			require.ErrorAs(t, err, &UnsupportedLiteralValueError{})
+		}
+	})
+}
+
+func TestEncodeLiteralArgumentList(t *testing.T) {
+
+	t.Parallel()
+
+	values := []cadence.Value{
+		cadence.NewInt(1),
+		cadence.String("a"),
+		cadence.NewOptional(nil),
+	}
+
+	argumentList, err := EncodeLiteralArgumentList(values)
+	require.NoError(t, err)
+	require.Equal(t, `(1, "a", nil)`, argumentList)
+
+	parsed, err := ParseLiteralArgumentList(
+		argumentList,
+		[]sema.Type{
+			sema.IntType,
+			sema.StringType,
+			&sema.OptionalType{Type: sema.BoolType},
+		},
+		newTestInterpreter(t),
+	)
+	require.NoError(t, err)
+	require.Equal(t, values, parsed)
+}
+
+func TestEncodeLiteralRoundTrip(t *testing.T) {
+
+	t.Parallel()
+
+	properties := gopter.NewProperties(nil)
+
+	properties.Property("ParseLiteral(EncodeLiteral(value)) == value", prop.ForAll(
+		func(seed int64) (bool, error) {
+			random := rand.New(rand.NewSource(seed))
+			ty, value := generateLiteralValue(random, 3)
+
+			literal, err := EncodeLiteral(value)
+			if err != nil {
+				return false, err
+			}
+
+			parsed, err := ParseLiteral(literal, ty, newTestInterpreter(t))
+			if err != nil {
+				return false, fmt.Errorf("failed to parse %s as %s: %w", literal, ty, err)
+			}
+
+			// Compare the JSON encodings, which are independent
+			// of e.g. the internal representation of big integers
+
+			expected, err := json.Encode(value)
+			if err != nil {
+				return false, err
+			}
+
+			actual, err := json.Encode(parsed)
+			if err != nil {
+				return false, err
+			}
+
+			if string(expected) != string(actual) {
+				return false, fmt.Errorf("%s parsed as %s, expected %s", literal, actual, expected)
+			}
+
+			return true, nil
+		},
+		gen.Int64(),
+	))
+
+	properties.TestingRun(t)
+}
+
+var literalTestIntegerValues = map[sema.Type]func(*big.Int) (cadence.Value, error){
+	sema.IntType: func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewIntFromBig(i), nil
+	},
+	sema.Int8Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.Int8(i.Int64()), nil
+	},
+	sema.Int16Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.Int16(i.Int64()), nil
+	},
+	sema.Int32Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.Int32(i.Int64()), nil
+	},
+	sema.Int64Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.Int64(i.Int64()), nil
+	},
+	sema.Int128Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewInt128FromBig(i)
+	},
+	sema.Int256Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewInt256FromBig(i)
+	},
+	sema.UIntType: func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUIntFromBig(i)
+	},
+	sema.UInt8Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.UInt8(i.Uint64()), nil
+	},
+	sema.UInt16Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.UInt16(i.Uint64()), nil
+	},
+	sema.UInt32Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.UInt32(i.Uint64()), nil
+	},
+	sema.UInt64Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.UInt64(i.Uint64()), nil
+	},
+	sema.UInt128Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUInt128FromBig(i)
+	},
+	sema.UInt256Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewUInt256FromBig(i)
+	},
+	sema.Word8Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.Word8(i.Uint64()), nil
+	},
+	sema.Word16Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.Word16(i.Uint64()), nil
+	},
+	sema.Word32Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.Word32(i.Uint64()), nil
+	},
+	sema.Word64Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.Word64(i.Uint64()), nil
+	},
+	sema.Word128Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewWord128FromBig(i)
+	},
+	sema.Word256Type: func(i *big.Int) (cadence.Value, error) {
+		return cadence.NewWord256FromBig(i)
+	},
+}
+
+var literalTestStringRunes = []rune("aZ09 _\"\\\n\t\u0000é日\U0001F600")
+
+var literalTestKeyTypes = []sema.Type{
+	sema.StringType,
+	sema.IntType,
+	sema.UInt8Type,
+	&sema.AddressType{},
+}
+
+// generateLiteralValue generates a random value which can be expressed as a literal,
+// and the type it should be parsed with.
+func generateLiteralValue(random *rand.Rand, depth int) (sema.Type, cadence.Value) {
+	const kindCount = 11
+
+	kinds := kindCount
+	if depth <= 0 {
+		// Only generate simple values
+		kinds = 6
+	}
+
+	switch random.Intn(kinds) {
+	case 0:
+		return sema.BoolType, cadence.NewBool(random.Intn(2) == 1)
+
+	case 1:
+		return sema.StringType, generateLiteralString(random)
+
+	case 2:
+		var address cadence.Address
+		random.Read(address[:])
+		return &sema.AddressType{}, address
+
+	case 3:
+		return generateLiteralInteger(random)
+
+	case 4:
+		if random.Intn(2) == 0 {
+			return sema.Fix64Type, cadence.Fix64(random.Int63() - random.Int63())
+		}
+		return sema.UFix64Type, cadence.UFix64(random.Uint64())
+
+	case 5:
+		ty := []sema.Type{
+			sema.StoragePathType,
+			sema.PublicPathType,
+			sema.PrivatePathType,
+		}[random.Intn(3)]
+		domain := common.PathDomainFromIdentifier(
+			strings.TrimSuffix(strings.ToLower(ty.String()), "path"),
+		)
+		identifier := fmt.Sprintf("path%d", random.Intn(100))
+		return ty, cadence.Path{
+			Domain:     domain,
+			Identifier: identifier,
+		}
+
+	case 6:
+		innerType, innerValue := generateLiteralValue(random, 0)
+		ty := &sema.OptionalType{Type: innerType}
+		if random.Intn(2) == 0 {
+			return ty, cadence.NewOptional(nil)
+		}
+		return ty, cadence.NewOptional(innerValue)
+
+	case 7, 8:
+		elementType, _ := generateLiteralValue(random, depth-1)
+		count := random.Intn(4)
+		values := make([]cadence.Value, count)
+		for i := range values {
+			values[i] = generateLiteralValueOfType(random, elementType, depth-1)
+		}
+
+		var ty sema.Type
+		if random.Intn(2) == 0 {
+			ty = &sema.VariableSizedType{Type: elementType}
+		} else {
+			ty = &sema.ConstantSizedType{Type: elementType, Size: int64(count)}
+		}
+		return ty, cadence.NewArray(values)
+
+	default:
+		keyType := literalTestKeyTypes[random.Intn(len(literalTestKeyTypes))]
+		valueType, _ := generateLiteralValue(random, depth-1)
+		count := random.Intn(4)
+		pairs := make([]cadence.KeyValuePair, count)
+		for i := range pairs {
+			pairs[i] = cadence.KeyValuePair{
+				Key:   generateLiteralValueOfType(random, keyType, 0),
+				Value: generateLiteralValueOfType(random, valueType, depth-1),
+			}
+		}
+		ty := &sema.DictionaryType{
+			KeyType:   keyType,
+			ValueType: valueType,
+		}
+		return ty, cadence.NewDictionary(pairs)
+	}
+}
+
+// generateLiteralValueOfType generates a random value of the given type.
+func generateLiteralValueOfType(random *rand.Rand, ty sema.Type, depth int) cadence.Value {
+	switch ty := ty.(type) {
+	case *sema.VariableSizedType:
+		count := random.Intn(4)
+		values := make([]cadence.Value, count)
+		for i := range values {
+			values[i] = generateLiteralValueOfType(random, ty.Type, depth-1)
+		}
+		return cadence.NewArray(values)
+
+	case *sema.ConstantSizedType:
+		values := make([]cadence.Value, ty.Size)
+		for i := range values {
+			values[i] = generateLiteralValueOfType(random, ty.Type, depth-1)
+		}
+		return cadence.NewArray(values)
+
+	case *sema.DictionaryType:
+		count := random.Intn(4)
+		pairs := make([]cadence.KeyValuePair, count)
+		for i := range pairs {
+			pairs[i] = cadence.KeyValuePair{
+				Key:   generateLiteralValueOfType(random, ty.KeyType, 0),
+				Value: generateLiteralValueOfType(random, ty.ValueType, depth-1),
+			}
+		}
+		return cadence.NewDictionary(pairs)
+
+	case *sema.OptionalType:
+		if random.Intn(2) == 0 {
+			return cadence.NewOptional(nil)
+		}
+		return cadence.NewOptional(generateLiteralValueOfType(random, ty.Type, depth-1))
+
+	case *sema.AddressType:
+		var address cadence.Address
+		random.Read(address[:])
+		return address
+	}
+
+	switch ty {
+	case sema.BoolType:
+		return cadence.NewBool(random.Intn(2) == 1)
+	case sema.StringType:
+		return generateLiteralString(random)
+	case sema.Fix64Type:
+		return cadence.Fix64(random.Int63() - random.Int63())
+	case sema.UFix64Type:
+		return cadence.UFix64(random.Uint64())
+	case sema.StoragePathType:
+		return cadence.Path{Domain: common.PathDomainStorage, Identifier: "foo"}
+	case sema.PublicPathType:
+		return cadence.Path{Domain: common.PathDomainPublic, Identifier: "foo"}
+	case sema.PrivatePathType:
+		return cadence.Path{Domain: common.PathDomainPrivate, Identifier: "foo"}
+	}
+
+	if _, ok := literalTestIntegerValues[ty]; ok {
+		return generateLiteralIntegerOfType(random, ty)
+	}
+
+	panic(fmt.Errorf("cannot generate value of type %s", ty))
+}
+
+func generateLiteralString(random *rand.Rand) cadence.String {
+	var builder strings.Builder
+	length := random.Intn(8)
+	for i := 0; i < length; i++ {
+		builder.WriteRune(literalTestStringRunes[random.Intn(len(literalTestStringRunes))])
+	}
+	return cadence.String(builder.String())
+}
+
+func generateLiteralInteger(random *rand.Rand) (sema.Type, cadence.Value) {
+	integerTypes := common.Concat(sema.AllSignedIntegerTypes, sema.AllUnsignedIntegerTypes)
+	ty := integerTypes[random.Intn(len(integerTypes))]
+	return ty, generateLiteralIntegerOfType(random, ty)
+}
+
+func generateLiteralIntegerOfType(random *rand.Rand, ty sema.Type) cadence.Value {
+	rangedType := ty.(sema.IntegerRangedType)
+
+	min := rangedType.MinInt()
+	if min == nil {
+		// Int has no minimum
+		min = new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 100))
+	}
+	max := rangedType.MaxInt()
+	if max == nil {
+		// Int and UInt have no maximum
+		max = new(big.Int).Lsh(big.NewInt(1), 100)
+	}
+
+	// Prefer the bounds, which are the most likely to be encoded incorrectly
+	var i *big.Int
+	switch random.Intn(4) {
+	case 0:
+		i = min
+	case 1:
+		i = max
+	default:
+		span := new(big.Int).Sub(max, min)
+		span.Add(span, big.NewInt(1))
+		i = new(big.Int).Rand(random, span)
+		i.Add(i, min)
+	}
+
+	value, err := literalTestIntegerValues[ty](i)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
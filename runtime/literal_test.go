@@ -26,8 +26,10 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/sema"
+	. "github.com/onflow/cadence/runtime/tests/checker"
 	. "github.com/onflow/cadence/runtime/tests/utils"
 )
 
@@ -806,3 +808,433 @@ func TestParseLiteralArgumentList(t *testing.T) {
 		RequireError(t, err)
 	})
 }
+
+func TestParseLiteralWithResolver(t *testing.T) {
+
+	t.Parallel()
+
+	const code = `
+      pub struct S {
+          pub let a: Int
+          pub let b: String?
+
+          init(a: Int, b: String?) {
+              self.a = a
+              self.b = b
+          }
+      }
+
+      pub enum E: UInt8 {
+          pub case red
+          pub case green
+      }
+
+      pub struct interface I {}
+    `
+
+	checker, err := ParseAndCheckWithOptions(t,
+		code,
+		ParseAndCheckOptions{
+			Location: common.ScriptLocation{},
+		},
+	)
+	require.NoError(t, err)
+
+	elaboration := checker.Elaboration
+
+	resolver := &LiteralResolver{
+		ResolveType: func(qualifiedIdentifier string) (sema.Type, error) {
+			typeID := common.ScriptLocation{}.TypeID(nil, qualifiedIdentifier)
+			if compositeType := elaboration.CompositeType(typeID); compositeType != nil {
+				return compositeType, nil
+			}
+			if interfaceType := elaboration.InterfaceType(typeID); interfaceType != nil {
+				return interfaceType, nil
+			}
+			return nil, fmt.Errorf("unknown type %s", qualifiedIdentifier)
+		},
+		ResolveEnumCases: func(enumType *sema.CompositeType) ([]string, error) {
+			declaration, ok := elaboration.CompositeTypeDeclaration(enumType)
+			if !ok {
+				return nil, fmt.Errorf("unknown enum %s", enumType)
+			}
+			enumCases := declaration.DeclarationMembers().EnumCases()
+			names := make([]string, len(enumCases))
+			for i, enumCase := range enumCases {
+				names[i] = enumCase.Identifier.Identifier
+			}
+			return names, nil
+		},
+	}
+
+	structType := RequireGlobalType(t, elaboration, "S").(*sema.CompositeType)
+	enumType := RequireGlobalType(t, elaboration, "E").(*sema.CompositeType)
+
+	// Export the types for each use, as the exported types cache their type ID
+
+	exportedStructType := func() *cadence.StructType {
+		return ExportType(structType, map[sema.TypeID]cadence.Type{}).(*cadence.StructType)
+	}
+	exportedEnumType := func() *cadence.EnumType {
+		return ExportType(enumType, map[sema.TypeID]cadence.Type{}).(*cadence.EnumType)
+	}
+
+	t.Run("Struct", func(t *testing.T) {
+		t.Parallel()
+
+		for _, literal := range []string{
+			`S(a: 1, b: "x")`,
+			`S(b: "x", a: 1)`,
+		} {
+			value, err := ParseLiteralWithResolver(literal, structType, newTestInterpreter(t), nil)
+			require.NoError(t, err)
+			require.Equal(t,
+				cadence.NewStruct([]cadence.Value{
+					cadence.NewInt(1),
+					cadence.NewOptional(cadence.String("x")),
+				}).WithType(exportedStructType()),
+				value,
+			)
+		}
+	})
+
+	t.Run("Struct, invalid fields", func(t *testing.T) {
+		t.Parallel()
+
+		for _, literal := range []string{
+			`S(a: 1)`,
+			`S(a: 1, b: nil, c: 2)`,
+			`S(a: 1, a: 2, b: nil)`,
+			`S(1, nil)`,
+			`S(a: "x", b: nil)`,
+			`S<Int>(a: 1, b: nil)`,
+		} {
+			value, err := ParseLiteralWithResolver(literal, structType, newTestInterpreter(t), resolver)
+			RequireError(t, err)
+
+			require.Nil(t, value)
+		}
+	})
+
+	t.Run("Struct, unresolved type", func(t *testing.T) {
+		t.Parallel()
+
+		value, err := ParseLiteralWithResolver(`T(a: 1, b: nil)`, structType, newTestInterpreter(t), resolver)
+		RequireError(t, err)
+
+		require.Nil(t, value)
+	})
+
+	t.Run("Struct, AnyStruct", func(t *testing.T) {
+		t.Parallel()
+
+		value, err := ParseLiteralWithResolver(`S(a: 1, b: nil)`, sema.AnyStructType, newTestInterpreter(t), resolver)
+		require.NoError(t, err)
+		require.Equal(t,
+			cadence.NewStruct([]cadence.Value{
+				cadence.NewInt(1),
+				cadence.NewOptional(nil),
+			}).WithType(exportedStructType()),
+			value,
+		)
+
+		// Without a resolver, the type of the constructor is unknown
+
+		value, err = ParseLiteral(`S(a: 1, b: nil)`, sema.AnyStructType, newTestInterpreter(t))
+		RequireError(t, err)
+
+		require.Nil(t, value)
+	})
+
+	t.Run("Enum", func(t *testing.T) {
+		t.Parallel()
+
+		for _, literal := range []string{
+			`E.green`,
+			`E(rawValue: 1)!`,
+		} {
+			value, err := ParseLiteralWithResolver(literal, enumType, newTestInterpreter(t), resolver)
+			require.NoError(t, err)
+			require.Equal(t,
+				cadence.NewEnum([]cadence.Value{
+					cadence.UInt8(1),
+				}).WithType(exportedEnumType()),
+				value,
+			)
+		}
+	})
+
+	t.Run("Enum, invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, literal := range []string{
+			`E.blue`,
+			`E?.red`,
+			`E(rawValue: 1)`,
+			`E(1)!`,
+			`S.red`,
+		} {
+			value, err := ParseLiteralWithResolver(literal, enumType, newTestInterpreter(t), resolver)
+			RequireError(t, err)
+
+			require.Nil(t, value)
+		}
+
+		// Without a resolver, enum cases are unknown
+
+		value, err := ParseLiteral(`E.red`, enumType, newTestInterpreter(t))
+		RequireError(t, err)
+
+		require.Nil(t, value)
+	})
+
+	t.Run("nested Optional, Enum", func(t *testing.T) {
+		t.Parallel()
+
+		value, err := ParseLiteralWithResolver(
+			`E.red`,
+			&sema.OptionalType{
+				Type: &sema.OptionalType{
+					Type: enumType,
+				},
+			},
+			newTestInterpreter(t),
+			resolver,
+		)
+		require.NoError(t, err)
+		require.Equal(t,
+			cadence.NewOptional(
+				cadence.NewOptional(
+					cadence.NewEnum([]cadence.Value{
+						cadence.UInt8(0),
+					}).WithType(exportedEnumType()),
+				),
+			),
+			value,
+		)
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		t.Parallel()
+
+		type testCase struct {
+			literal  string
+			expected cadence.Type
+		}
+
+		for _, test := range []testCase{
+			{
+				literal:  `Type<Int>()`,
+				expected: cadence.IntType{},
+			},
+			{
+				literal: `Type<{String: [UInt8; 2]}?>()`,
+				expected: &cadence.OptionalType{
+					Type: &cadence.DictionaryType{
+						KeyType: cadence.StringType{},
+						ElementType: &cadence.ConstantSizedArrayType{
+							ElementType: cadence.UInt8Type{},
+							Size:        2,
+						},
+					},
+				},
+			},
+			{
+				literal:  `Type<[S]>()`,
+				expected: &cadence.VariableSizedArrayType{ElementType: exportedStructType()},
+			},
+			{
+				literal: `Type<Capability<&AnyStruct{I}>>()`,
+				expected: &cadence.CapabilityType{
+					BorrowType: &cadence.ReferenceType{
+						Type: &cadence.RestrictedType{
+							Type: cadence.AnyStructType{},
+							Restrictions: []cadence.Type{
+								ExportType(
+									RequireGlobalType(t, elaboration, "I"),
+									map[sema.TypeID]cadence.Type{},
+								),
+							},
+						},
+					},
+				},
+			},
+		} {
+			value, err := ParseLiteralWithResolver(test.literal, sema.MetaType, newTestInterpreter(t), resolver)
+			require.NoError(t, err)
+			require.IsType(t, cadence.TypeValue{}, value)
+			require.Equal(t,
+				test.expected.ID(),
+				value.(cadence.TypeValue).StaticType.ID(),
+			)
+		}
+	})
+
+	t.Run("Type, invalid", func(t *testing.T) {
+		t.Parallel()
+
+		for _, literal := range []string{
+			`Type<T>()`,
+			`Type<Int>(1)`,
+			`Type()`,
+			`Type<{[Int]: Int}>()`,
+			`Int`,
+		} {
+			value, err := ParseLiteralWithResolver(literal, sema.MetaType, newTestInterpreter(t), resolver)
+			RequireError(t, err)
+
+			require.Nil(t, value)
+		}
+	})
+
+	t.Run("EncodeLiteral round trip", func(t *testing.T) {
+		t.Parallel()
+
+		type testCase struct {
+			literal string
+			ty      sema.Type
+		}
+
+		for _, test := range []testCase{
+			{literal: `S(a: 1, b: "x")`, ty: structType},
+			{literal: `E(rawValue: 1)!`, ty: enumType},
+			{literal: `Type<{String: [S]}>()`, ty: sema.MetaType},
+		} {
+			value, err := ParseLiteralWithResolver(test.literal, test.ty, newTestInterpreter(t), resolver)
+			require.NoError(t, err)
+
+			literal, err := EncodeLiteral(value)
+			require.NoError(t, err)
+			require.Equal(t, test.literal, literal)
+		}
+	})
+
+	t.Run("Character", func(t *testing.T) {
+		t.Parallel()
+
+		value, err := ParseLiteral(`"\u{65}\u{301}"`, sema.CharacterType, newTestInterpreter(t))
+		require.NoError(t, err)
+		require.Equal(t, cadence.Character("é"), value)
+
+		for _, literal := range []string{`"ab"`, `""`, `1`} {
+			value, err := ParseLiteral(literal, sema.CharacterType, newTestInterpreter(t))
+			RequireError(t, err)
+
+			require.Nil(t, value)
+		}
+	})
+}
+
+func TestParseLiteralWithResolverImport(t *testing.T) {
+
+	t.Parallel()
+
+	// The parsed arguments should be accepted by the argument decoder of the runtime
+
+	const declarations = `
+      pub struct S {
+          pub let a: Int
+          pub let b: [E]
+
+          init(a: Int, b: [E]) {
+              self.a = a
+              self.b = b
+          }
+      }
+
+      pub enum E: UInt8 {
+          pub case red
+          pub case green
+      }
+    `
+
+	const script = declarations + `
+      pub fun main(s: S, e: E??, t: Type, c: Character) {
+          log(s.a)
+          log(s.b[0].rawValue)
+          log(e!!.rawValue)
+          log(t == Type<{String: S}>())
+          log(c)
+      }
+    `
+
+	// Only the declarations are needed to resolve the types
+
+	checker, err := ParseAndCheckWithOptions(t,
+		declarations,
+		ParseAndCheckOptions{
+			Location: common.ScriptLocation{},
+		},
+	)
+	require.NoError(t, err)
+
+	elaboration := checker.Elaboration
+
+	resolver := &LiteralResolver{
+		ResolveType: func(qualifiedIdentifier string) (sema.Type, error) {
+			typeID := common.ScriptLocation{}.TypeID(nil, qualifiedIdentifier)
+			if compositeType := elaboration.CompositeType(typeID); compositeType != nil {
+				return compositeType, nil
+			}
+			return nil, fmt.Errorf("unknown type %s", qualifiedIdentifier)
+		},
+		ResolveEnumCases: func(enumType *sema.CompositeType) ([]string, error) {
+			return []string{"red", "green"}, nil
+		},
+	}
+
+	structType := RequireGlobalType(t, elaboration, "S")
+	enumType := RequireGlobalType(t, elaboration, "E")
+
+	arguments, err := ParseLiteralArgumentListWithResolver(
+		`(s: S(a: 1, b: [E.green]), e: E(rawValue: 1)!, t: Type<{String: S}>(), c: "x")`,
+		[]sema.Type{
+			structType,
+			&sema.OptionalType{
+				Type: &sema.OptionalType{
+					Type: enumType,
+				},
+			},
+			sema.MetaType,
+			sema.CharacterType,
+		},
+		newTestInterpreter(t),
+		resolver,
+	)
+	require.NoError(t, err)
+
+	encodedArguments := make([][]byte, len(arguments))
+	for i, argument := range arguments {
+		encodedArguments[i], err = json.Encode(argument)
+		require.NoError(t, err)
+	}
+
+	var loggedMessages []string
+
+	runtimeInterface := &testRuntimeInterface{
+		storage: newTestLedger(nil, nil),
+		log: func(message string) {
+			loggedMessages = append(loggedMessages, message)
+		},
+	}
+	runtimeInterface.decodeArgument = func(b []byte, t cadence.Type) (value cadence.Value, err error) {
+		return json.Decode(runtimeInterface, b)
+	}
+
+	_, err = newTestInterpreterRuntime().ExecuteScript(
+		Script{
+			Source:    []byte(script),
+			Arguments: encodedArguments,
+		},
+		Context{
+			Interface: runtimeInterface,
+			Location:  common.ScriptLocation{},
+		},
+	)
+	require.NoError(t, err)
+
+	require.Equal(t,
+		[]string{"1", "1", "1", "true", `"x"`},
+		loggedMessages,
+	)
+}
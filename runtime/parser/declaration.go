@@ -615,13 +615,20 @@ func parseImportDeclaration(p *parser) (*ast.ImportDeclaration, error) {
 		return nil
 	}
 
-	maybeParseFromIdentifier := func(identifier ast.Identifier) error {
+	maybeParseFromIdentifier := func(
+		identifier ast.Identifier,
+		afterIdentifier lexer.Token,
+		afterIdentifierCursor int,
+	) error {
 		// The current identifier is maybe the `from` keyword,
 		// in which case the given (previous) identifier was
 		// an imported identifier and not the import location.
 		//
 		// If it is not the `from` keyword,
 		// the given (previous) identifier is the import location.
+		// The current identifier then starts the next declaration,
+		// so go back to the trivia after the location,
+		// which may contain the docstring of the next declaration.
 
 		if string(p.currentTokenSource()) == keywordFrom {
 			identifiers = append(identifiers, identifier)
@@ -634,6 +641,9 @@ func parseImportDeclaration(p *parser) (*ast.ImportDeclaration, error) {
 			}
 		} else {
 			setIdentifierLocation(identifier)
+
+			p.current = afterIdentifier
+			p.tokens.Revert(afterIdentifierCursor)
 		}
 
 		return nil
@@ -649,7 +659,10 @@ func parseImportDeclaration(p *parser) (*ast.ImportDeclaration, error) {
 	case lexer.TokenIdentifier:
 		identifier := p.tokenToIdentifier(p.current)
 		// Skip the identifier
-		p.nextSemanticToken()
+		p.next()
+		afterIdentifier := p.current
+		afterIdentifierCursor := p.tokens.Cursor()
+		p.skipSpaceAndComments()
 
 		switch p.current.Type {
 		case lexer.TokenComma:
@@ -661,7 +674,7 @@ func parseImportDeclaration(p *parser) (*ast.ImportDeclaration, error) {
 				return nil, err
 			}
 		case lexer.TokenIdentifier:
-			err := maybeParseFromIdentifier(identifier)
+			err := maybeParseFromIdentifier(identifier, afterIdentifier, afterIdentifierCursor)
 			if err != nil {
 				return nil, err
 			}
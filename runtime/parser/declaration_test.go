@@ -1984,6 +1984,29 @@ func TestParseImportDeclaration(t *testing.T) {
 		)
 	})
 
+	t.Run("identifier location, docstring of next declaration", func(t *testing.T) {
+
+		t.Parallel()
+
+		result, errs := testParseDeclarations(`
+			import foo
+
+			/// bar
+			fun bar() {}
+		`)
+		require.Empty(t, errs)
+		require.Len(t, result, 2)
+
+		require.Equal(t,
+			common.IdentifierLocation("foo"),
+			result[0].(*ast.ImportDeclaration).Location,
+		)
+		require.Equal(t,
+			" bar",
+			result[1].(*ast.FunctionDeclaration).DocString,
+		)
+	})
+
 	t.Run("from keyword as second identifier", func(t *testing.T) {
 
 		t.Parallel()
@@ -0,0 +1,760 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/onflow/atree"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+)
+
+// ReplayResult is the result of a replayed execution
+type ReplayResult struct {
+	// Value is the result of a script
+	Value cadence.Value
+	// Err is the error of the execution, which is identical to the recorded error
+	Err error
+}
+
+// Replay re-executes the program recorded in the given replay log,
+// using only the recorded host function results.
+//
+// Replay checks that the execution performs exactly the same host function calls
+// with the same arguments, e.g. the same writes and events, and that it has the same result.
+// If the execution diverges, an error is returned.
+//
+// If a debugger is given, the execution can be stepped through.
+func Replay(log *ReplayLog, debugger *interpreter.Debugger) (*ReplayResult, error) {
+	if log.Version != ReplayLogVersion {
+		return nil, fmt.Errorf(
+			"unsupported replay log version: expected %d, got %d",
+			ReplayLogVersion,
+			log.Version,
+		)
+	}
+
+	location, err := decodeReplayLocation(log.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	config := log.Config.runtimeConfig()
+	config.Debugger = debugger
+
+	runtime := NewInterpreterRuntime(config)
+
+	replayer := newReplayingInterface(log)
+
+	script := Script{
+		Source:    []byte(log.Source),
+		Arguments: log.Arguments,
+	}
+
+	context := Context{
+		Interface: replayer,
+		Location:  location,
+	}
+
+	result := &ReplayResult{}
+
+	switch log.Kind {
+	case ReplayKindTransaction:
+		result.Err = runtime.ExecuteTransaction(script, context)
+
+	case ReplayKindScript:
+		result.Value, result.Err = runtime.ExecuteScript(script, context)
+
+	default:
+		return nil, fmt.Errorf("unsupported replay kind: %s", log.Kind)
+	}
+
+	if replayer.mismatch != nil {
+		return result, replayer.mismatch
+	}
+
+	err = checkReplayResult(log, result)
+	if err != nil {
+		return result, err
+	}
+
+	if replayer.nextCall < len(log.Calls) {
+		return result, ReplayIncompleteError{
+			Performed: replayer.nextCall,
+			Expected:  len(log.Calls),
+		}
+	}
+
+	return result, nil
+}
+
+func checkReplayResult(log *ReplayLog, result *ReplayResult) error {
+	expected := describeReplayResult(log.Result, log.Error)
+
+	var actualError *string
+	if result.Err != nil {
+		message := result.Err.Error()
+		actualError = &message
+	}
+
+	var actualValue json.RawMessage
+	if result.Value != nil {
+		var err error
+		actualValue, err = jsoncdc.Encode(result.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	actual := describeReplayResult(actualValue, actualError)
+
+	if expected != actual {
+		return ReplayResultMismatchError{
+			Expected: expected,
+			Actual:   actual,
+		}
+	}
+
+	return nil
+}
+
+func describeReplayResult(value json.RawMessage, err *string) string {
+	if err != nil {
+		return fmt.Sprintf("error: %s", *err)
+	}
+	if len(value) == 0 {
+		return "success"
+	}
+	return fmt.Sprintf("value: %s", compactReplayJSON(value))
+}
+
+func compactReplayJSON(data json.RawMessage) string {
+	var buffer bytes.Buffer
+	err := json.Compact(&buffer, data)
+	if err != nil {
+		return string(data)
+	}
+	return buffer.String()
+}
+
+// replayingInterface is an Interface which returns the results recorded in a replay log,
+// and checks that the calls are identical to the recorded calls.
+type replayingInterface struct {
+	log               *ReplayLog
+	nextCall          int
+	programs          map[Location]*interpreter.Program
+	sharedState       *interpreter.SharedState
+	meteringCalls     uint64
+	nextMeteringError int
+	// mismatch is the first divergence from the replay log
+	mismatch error
+}
+
+var _ Interface = &replayingInterface{}
+
+func newReplayingInterface(log *ReplayLog) *replayingInterface {
+	return &replayingInterface{
+		log:      log,
+		programs: map[Location]*interpreter.Program{},
+	}
+}
+
+// replay checks that the next call in the log is a call to the given function with the given arguments,
+// and decodes the recorded results into the given results pointer.
+// If the recorded call failed, the recorded error is returned.
+func (i *replayingInterface) replay(function string, arguments any, results any) error {
+	if i.mismatch != nil {
+		return i.mismatch
+	}
+
+	encodedArguments := compactReplayJSON(mustEncodeReplayJSON(arguments))
+
+	index := i.nextCall
+	if index >= len(i.log.Calls) {
+		i.mismatch = ReplayCallMismatchError{
+			Index:           index,
+			ActualFunction:  function,
+			ActualArguments: encodedArguments,
+		}
+		return i.mismatch
+	}
+
+	call := i.log.Calls[index]
+	expectedArguments := compactReplayJSON(call.Arguments)
+
+	if call.Function != function || expectedArguments != encodedArguments {
+		i.mismatch = ReplayCallMismatchError{
+			Index:             index,
+			ExpectedFunction:  call.Function,
+			ExpectedArguments: expectedArguments,
+			ActualFunction:    function,
+			ActualArguments:   encodedArguments,
+		}
+		return i.mismatch
+	}
+
+	i.nextCall++
+
+	if call.Error != nil {
+		return ReplayedError{
+			Message: *call.Error,
+		}
+	}
+
+	if results != nil && len(call.Results) > 0 {
+		err := json.Unmarshal(call.Results, results)
+		if err != nil {
+			return fmt.Errorf("failed to decode results of call %d to %s: %w", index, function, err)
+		}
+	}
+
+	return nil
+}
+
+func (i *replayingInterface) replayMetering() error {
+	index := i.meteringCalls
+	i.meteringCalls++
+
+	meteringErrors := i.log.MeteringErrors
+	if i.nextMeteringError < len(meteringErrors) &&
+		meteringErrors[i.nextMeteringError].Index == index {
+
+		meteringError := meteringErrors[i.nextMeteringError]
+		i.nextMeteringError++
+
+		return ReplayedError{
+			Message: meteringError.Error,
+		}
+	}
+
+	return nil
+}
+
+func (i *replayingInterface) MeterMemory(_ common.MemoryUsage) error {
+	return i.replayMetering()
+}
+
+func (i *replayingInterface) MeterComputation(_ common.ComputationKind, _ uint) error {
+	return i.replayMetering()
+}
+
+func (i *replayingInterface) ComputationUsed() (used uint64, err error) {
+	err = i.replay("ComputationUsed", nil, &used)
+	return
+}
+
+func (i *replayingInterface) MemoryUsed() (used uint64, err error) {
+	err = i.replay("MemoryUsed", nil, &used)
+	return
+}
+
+func (i *replayingInterface) InteractionUsed() (used uint64, err error) {
+	err = i.replay("InteractionUsed", nil, &used)
+	return
+}
+
+func (i *replayingInterface) ResolveLocation(identifiers []Identifier, location Location) ([]ResolvedLocation, error) {
+	var results []replayResolvedLocation
+	err := i.replay(
+		"ResolveLocation",
+		replayResolveLocationArguments{
+			Identifiers: encodeReplayIdentifiers(identifiers),
+			Location:    encodeReplayLocation(location),
+		},
+		&results,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedLocations := make([]ResolvedLocation, 0, len(results))
+	for _, result := range results {
+		resolvedLocation, err := decodeReplayLocation(result.Location)
+		if err != nil {
+			return nil, err
+		}
+		resolvedLocations = append(resolvedLocations, ResolvedLocation{
+			Location:    resolvedLocation,
+			Identifiers: decodeReplayIdentifiers(result.Identifiers),
+		})
+	}
+	return resolvedLocations, nil
+}
+
+func (i *replayingInterface) GetCode(location Location) (code []byte, err error) {
+	err = i.replay(
+		"GetCode",
+		replayLocationArguments{
+			Location: encodeReplayLocation(location),
+		},
+		&code,
+	)
+	return
+}
+
+func (i *replayingInterface) GetOrLoadProgram(
+	location Location,
+	load func() (*interpreter.Program, error),
+) (
+	program *interpreter.Program,
+	err error,
+) {
+	program, ok := i.programs[location]
+	if ok {
+		return program, nil
+	}
+
+	program, err = load()
+
+	// NOTE: important: still set empty program,
+	// even if error occurred
+
+	i.programs[location] = program
+
+	return program, err
+}
+
+func (i *replayingInterface) SetInterpreterSharedState(state *interpreter.SharedState) {
+	i.sharedState = state
+}
+
+func (i *replayingInterface) GetInterpreterSharedState() *interpreter.SharedState {
+	return i.sharedState
+}
+
+func (i *replayingInterface) GetValue(owner, key []byte) (value []byte, err error) {
+	err = i.replay(
+		"GetValue",
+		replayRegisterArguments{
+			Owner: owner,
+			Key:   key,
+		},
+		&value,
+	)
+	return
+}
+
+func (i *replayingInterface) SetValue(owner, key, value []byte) (err error) {
+	return i.replay(
+		"SetValue",
+		replayRegisterArguments{
+			Owner: owner,
+			Key:   key,
+			Value: value,
+		},
+		nil,
+	)
+}
+
+func (i *replayingInterface) ValueExists(owner, key []byte) (exists bool, err error) {
+	err = i.replay(
+		"ValueExists",
+		replayRegisterArguments{
+			Owner: owner,
+			Key:   key,
+		},
+		&exists,
+	)
+	return
+}
+
+func (i *replayingInterface) AllocateStorageIndex(owner []byte) (index atree.StorageIndex, err error) {
+	err = i.replay(
+		"AllocateStorageIndex",
+		replayRegisterArguments{
+			Owner: owner,
+		},
+		&index,
+	)
+	return
+}
+
+func (i *replayingInterface) CreateAccount(payer Address) (address Address, err error) {
+	err = i.replay(
+		"CreateAccount",
+		replayAccountArguments{
+			Address: payer,
+		},
+		&address,
+	)
+	return
+}
+
+func (i *replayingInterface) AddEncodedAccountKey(address Address, publicKey []byte) error {
+	return i.replay(
+		"AddEncodedAccountKey",
+		replayAccountKeyArguments{
+			Address:   address,
+			PublicKey: publicKey,
+		},
+		nil,
+	)
+}
+
+func (i *replayingInterface) RevokeEncodedAccountKey(address Address, index int) (publicKey []byte, err error) {
+	err = i.replay(
+		"RevokeEncodedAccountKey",
+		replayAccountKeyArguments{
+			Address: address,
+			Index:   index,
+		},
+		&publicKey,
+	)
+	return
+}
+
+func (i *replayingInterface) AddAccountKey(
+	address Address,
+	publicKey *PublicKey,
+	hashAlgo HashAlgorithm,
+	weight int,
+) (accountKey *AccountKey, err error) {
+	err = i.replay(
+		"AddAccountKey",
+		replayAccountKeyArguments{
+			Address:  address,
+			Key:      publicKey,
+			HashAlgo: hashAlgo,
+			Weight:   weight,
+		},
+		&accountKey,
+	)
+	return
+}
+
+func (i *replayingInterface) GetAccountKey(address Address, index int) (accountKey *AccountKey, err error) {
+	err = i.replay(
+		"GetAccountKey",
+		replayAccountKeyArguments{
+			Address: address,
+			Index:   index,
+		},
+		&accountKey,
+	)
+	return
+}
+
+func (i *replayingInterface) AccountKeysCount(address Address) (count uint64, err error) {
+	err = i.replay(
+		"AccountKeysCount",
+		replayAccountArguments{
+			Address: address,
+		},
+		&count,
+	)
+	return
+}
+
+func (i *replayingInterface) RevokeAccountKey(address Address, index int) (accountKey *AccountKey, err error) {
+	err = i.replay(
+		"RevokeAccountKey",
+		replayAccountKeyArguments{
+			Address: address,
+			Index:   index,
+		},
+		&accountKey,
+	)
+	return
+}
+
+func (i *replayingInterface) UpdateAccountContractCode(location common.AddressLocation, code []byte) (err error) {
+	return i.replay(
+		"UpdateAccountContractCode",
+		replayContractCodeArguments{
+			Address: location.Address,
+			Name:    location.Name,
+			Code:    code,
+		},
+		nil,
+	)
+}
+
+func (i *replayingInterface) GetAccountContractCode(location common.AddressLocation) (code []byte, err error) {
+	err = i.replay(
+		"GetAccountContractCode",
+		replayContractCodeArguments{
+			Address: location.Address,
+			Name:    location.Name,
+		},
+		&code,
+	)
+	return
+}
+
+func (i *replayingInterface) RemoveAccountContractCode(location common.AddressLocation) (err error) {
+	return i.replay(
+		"RemoveAccountContractCode",
+		replayContractCodeArguments{
+			Address: location.Address,
+			Name:    location.Name,
+		},
+		nil,
+	)
+}
+
+func (i *replayingInterface) GetSigningAccounts() (accounts []Address, err error) {
+	err = i.replay("GetSigningAccounts", nil, &accounts)
+	return
+}
+
+func (i *replayingInterface) ProgramLog(message string) error {
+	return i.replay(
+		"ProgramLog",
+		replayMessageArguments{
+			Message: message,
+		},
+		nil,
+	)
+}
+
+func (i *replayingInterface) EmitEvent(event cadence.Event) error {
+	encodedEvent, err := jsoncdc.Encode(event)
+	if err != nil {
+		return err
+	}
+
+	return i.replay(
+		"EmitEvent",
+		replayEventArguments{
+			Event: encodedEvent,
+		},
+		nil,
+	)
+}
+
+func (i *replayingInterface) GenerateUUID() (uuid uint64, err error) {
+	err = i.replay("GenerateUUID", nil, &uuid)
+	return
+}
+
+func (i *replayingInterface) DecodeArgument(argument []byte, argumentType cadence.Type) (cadence.Value, error) {
+	var results replayValueResults
+	err := i.replay(
+		"DecodeArgument",
+		replayDecodeArgumentArguments{
+			Argument: argument,
+			Type:     argumentType.ID(),
+		},
+		&results,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsoncdc.Decode(nil, results.Value)
+}
+
+func (i *replayingInterface) GetCurrentBlockHeight() (height uint64, err error) {
+	err = i.replay("GetCurrentBlockHeight", nil, &height)
+	return
+}
+
+func (i *replayingInterface) GetBlockAtHeight(height uint64) (block Block, exists bool, err error) {
+	var results replayBlockAtHeightResults
+	err = i.replay("GetBlockAtHeight", height, &results)
+	return results.Block, results.Exists, err
+}
+
+func (i *replayingInterface) ReadRandom(buffer []byte) error {
+	var random []byte
+	err := i.replay(
+		"ReadRandom",
+		replayLengthArguments{
+			Length: len(buffer),
+		},
+		&random,
+	)
+	if err != nil {
+		return err
+	}
+
+	copy(buffer, random)
+	return nil
+}
+
+func (i *replayingInterface) VerifySignature(
+	signature []byte,
+	tag string,
+	signedData []byte,
+	publicKey []byte,
+	signatureAlgorithm SignatureAlgorithm,
+	hashAlgorithm HashAlgorithm,
+) (valid bool, err error) {
+	err = i.replay(
+		"VerifySignature",
+		replayVerifySignatureArguments{
+			Signature:          signature,
+			Tag:                tag,
+			SignedData:         signedData,
+			PublicKey:          publicKey,
+			SignatureAlgorithm: signatureAlgorithm,
+			HashAlgorithm:      hashAlgorithm,
+		},
+		&valid,
+	)
+	return
+}
+
+func (i *replayingInterface) Hash(data []byte, tag string, hashAlgorithm HashAlgorithm) (digest []byte, err error) {
+	err = i.replay(
+		"Hash",
+		replayHashArguments{
+			Data:          data,
+			Tag:           tag,
+			HashAlgorithm: hashAlgorithm,
+		},
+		&digest,
+	)
+	return
+}
+
+func (i *replayingInterface) GetAccountBalance(address common.Address) (value uint64, err error) {
+	err = i.replay(
+		"GetAccountBalance",
+		replayAccountArguments{
+			Address: address,
+		},
+		&value,
+	)
+	return
+}
+
+func (i *replayingInterface) GetAccountAvailableBalance(address common.Address) (value uint64, err error) {
+	err = i.replay(
+		"GetAccountAvailableBalance",
+		replayAccountArguments{
+			Address: address,
+		},
+		&value,
+	)
+	return
+}
+
+func (i *replayingInterface) GetStorageUsed(address Address) (value uint64, err error) {
+	err = i.replay(
+		"GetStorageUsed",
+		replayAccountArguments{
+			Address: address,
+		},
+		&value,
+	)
+	return
+}
+
+func (i *replayingInterface) GetStorageCapacity(address Address) (value uint64, err error) {
+	err = i.replay(
+		"GetStorageCapacity",
+		replayAccountArguments{
+			Address: address,
+		},
+		&value,
+	)
+	return
+}
+
+func (i *replayingInterface) ImplementationDebugLog(_ string) error {
+	return nil
+}
+
+func (i *replayingInterface) ValidatePublicKey(key *PublicKey) error {
+	return i.replay(
+		"ValidatePublicKey",
+		replayPublicKeyArguments{
+			PublicKey: key,
+		},
+		nil,
+	)
+}
+
+func (i *replayingInterface) GetAccountContractNames(address Address) (names []string, err error) {
+	err = i.replay(
+		"GetAccountContractNames",
+		replayAccountArguments{
+			Address: address,
+		},
+		&names,
+	)
+	return
+}
+
+func (i *replayingInterface) RecordTrace(_ string, _ Location, _ time.Duration, _ []attribute.KeyValue) {
+	// NO-OP
+}
+
+func (i *replayingInterface) BLSVerifyPOP(publicKey *PublicKey, signature []byte) (valid bool, err error) {
+	err = i.replay(
+		"BLSVerifyPOP",
+		replayPublicKeyArguments{
+			PublicKey: publicKey,
+			Signature: signature,
+		},
+		&valid,
+	)
+	return
+}
+
+func (i *replayingInterface) BLSAggregateSignatures(signatures [][]byte) (signature []byte, err error) {
+	err = i.replay(
+		"BLSAggregateSignatures",
+		replaySignaturesArguments{
+			Signatures: signatures,
+		},
+		&signature,
+	)
+	return
+}
+
+func (i *replayingInterface) BLSAggregatePublicKeys(publicKeys []*PublicKey) (publicKey *PublicKey, err error) {
+	err = i.replay(
+		"BLSAggregatePublicKeys",
+		replayPublicKeysArguments{
+			PublicKeys: publicKeys,
+		},
+		&publicKey,
+	)
+	return
+}
+
+func (i *replayingInterface) ResourceOwnerChanged(
+	_ *interpreter.Interpreter,
+	_ *interpreter.CompositeValue,
+	_ common.Address,
+	_ common.Address,
+) {
+	// NO-OP
+}
+
+func (i *replayingInterface) GenerateAccountID(address common.Address) (id uint64, err error) {
+	err = i.replay(
+		"GenerateAccountID",
+		replayAccountArguments{
+			Address: address,
+		},
+		&id,
+	)
+	return
+}
@@ -0,0 +1,339 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/errors"
+)
+
+// ReplayLogVersion is the version of the replay log format
+const ReplayLogVersion = 1
+
+// ReplayKind is the kind of program recorded in a replay log
+type ReplayKind string
+
+const (
+	ReplayKindTransaction ReplayKind = "transaction"
+	ReplayKindScript      ReplayKind = "script"
+)
+
+// ReplayLog is a portable record of the execution of a transaction or script.
+// It contains the program, its arguments, and every host function call and its result,
+// so the execution can be replayed without access to the host, e.g. the ledger.
+type ReplayLog struct {
+	Version   int          `json:"version"`
+	Kind      ReplayKind   `json:"kind"`
+	Config    ReplayConfig `json:"config"`
+	Location  string       `json:"location"`
+	Source    string       `json:"source"`
+	Arguments [][]byte     `json:"arguments,omitempty"`
+	// Calls are the host function calls, in the order they were performed
+	Calls []ReplayCall `json:"calls"`
+	// MeteringErrors are the metering calls which failed, e.g. because a limit was exceeded.
+	// Successful metering calls are only counted
+	MeteringErrors []ReplayMeteringError `json:"meteringErrors,omitempty"`
+	// Result is the JSON-Cadence encoded result of a script
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error is the error message of a failed execution
+	Error *string `json:"error,omitempty"`
+}
+
+// ReplayConfig is the subset of the runtime configuration which influences execution
+type ReplayConfig struct {
+	StackDepthLimit                   uint64 `json:"stackDepthLimit,omitempty"`
+	AtreeValidationEnabled            bool   `json:"atreeValidationEnabled,omitempty"`
+	ResourceOwnerChangeHandlerEnabled bool   `json:"resourceOwnerChangeHandlerEnabled,omitempty"`
+	AccountLinkingEnabled             bool   `json:"accountLinkingEnabled,omitempty"`
+	AttachmentsEnabled                bool   `json:"attachmentsEnabled,omitempty"`
+	CapabilityControllersEnabled      bool   `json:"capabilityControllersEnabled,omitempty"`
+}
+
+func newReplayConfig(config Config) ReplayConfig {
+	return ReplayConfig{
+		StackDepthLimit:                   config.StackDepthLimit,
+		AtreeValidationEnabled:            config.AtreeValidationEnabled,
+		ResourceOwnerChangeHandlerEnabled: config.ResourceOwnerChangeHandlerEnabled,
+		AccountLinkingEnabled:             config.AccountLinkingEnabled,
+		AttachmentsEnabled:                config.AttachmentsEnabled,
+		CapabilityControllersEnabled:      config.CapabilityControllersEnabled,
+	}
+}
+
+func (c ReplayConfig) runtimeConfig() Config {
+	return Config{
+		StackDepthLimit:                   c.StackDepthLimit,
+		AtreeValidationEnabled:            c.AtreeValidationEnabled,
+		ResourceOwnerChangeHandlerEnabled: c.ResourceOwnerChangeHandlerEnabled,
+		AccountLinkingEnabled:             c.AccountLinkingEnabled,
+		AttachmentsEnabled:                c.AttachmentsEnabled,
+		CapabilityControllersEnabled:      c.CapabilityControllersEnabled,
+	}
+}
+
+// ReplayCall is a host function call
+type ReplayCall struct {
+	Function  string          `json:"function"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Results   json.RawMessage `json:"results,omitempty"`
+	// Error is the error message, if the call failed
+	Error *string `json:"error,omitempty"`
+}
+
+// ReplayMeteringError is a failed metering call.
+// Index is the number of metering calls performed before the failed call
+type ReplayMeteringError struct {
+	Index uint64 `json:"index"`
+	Error string `json:"error"`
+}
+
+// ReplayedError is an error returned by a host function during the recording,
+// which is returned again during the replay
+type ReplayedError struct {
+	Message string
+}
+
+var _ error = ReplayedError{}
+
+func (e ReplayedError) Error() string {
+	return e.Message
+}
+
+// ReplayCallMismatchError indicates that a replayed execution performed a host function call
+// which is different from the call in the replay log
+type ReplayCallMismatchError struct {
+	Index             int
+	ExpectedFunction  string
+	ExpectedArguments string
+	ActualFunction    string
+	ActualArguments   string
+}
+
+var _ error = ReplayCallMismatchError{}
+
+func (e ReplayCallMismatchError) Error() string {
+	if e.ExpectedFunction == "" {
+		return fmt.Sprintf(
+			"replay mismatch: unexpected call %d to %s(%s), log has no more calls",
+			e.Index,
+			e.ActualFunction,
+			e.ActualArguments,
+		)
+	}
+
+	return fmt.Sprintf(
+		"replay mismatch: expected call %d to be %s(%s), got %s(%s)",
+		e.Index,
+		e.ExpectedFunction,
+		e.ExpectedArguments,
+		e.ActualFunction,
+		e.ActualArguments,
+	)
+}
+
+// ReplayIncompleteError indicates that a replayed execution did not perform all calls of the replay log
+type ReplayIncompleteError struct {
+	Performed int
+	Expected  int
+}
+
+var _ error = ReplayIncompleteError{}
+
+func (e ReplayIncompleteError) Error() string {
+	return fmt.Sprintf(
+		"replay mismatch: only %d of %d calls were performed",
+		e.Performed,
+		e.Expected,
+	)
+}
+
+// ReplayResultMismatchError indicates that a replayed execution had a different result
+// than the recorded execution
+type ReplayResultMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+var _ error = ReplayResultMismatchError{}
+
+func (e ReplayResultMismatchError) Error() string {
+	return fmt.Sprintf(
+		"replay mismatch: expected result %s, got %s",
+		e.Expected,
+		e.Actual,
+	)
+}
+
+// Encoding of host function arguments and results
+
+func encodeReplayLocation(location Location) string {
+	if location == nil {
+		return ""
+	}
+	return location.ID()
+}
+
+func decodeReplayLocation(id string) (Location, error) {
+	if id == "" {
+		return nil, nil
+	}
+	location, _, err := common.DecodeTypeID(nil, id)
+	if err != nil {
+		return nil, err
+	}
+	if location == nil {
+		return nil, errors.NewDefaultUserError("invalid location: %s", id)
+	}
+	return location, nil
+}
+
+type replayIdentifier struct {
+	Identifier string       `json:"identifier"`
+	Pos        ast.Position `json:"pos"`
+}
+
+func encodeReplayIdentifiers(identifiers []Identifier) []replayIdentifier {
+	result := make([]replayIdentifier, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		result = append(result, replayIdentifier{
+			Identifier: identifier.Identifier,
+			Pos:        identifier.Pos,
+		})
+	}
+	return result
+}
+
+func decodeReplayIdentifiers(identifiers []replayIdentifier) []Identifier {
+	result := make([]Identifier, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		result = append(result, Identifier{
+			Identifier: identifier.Identifier,
+			Pos:        identifier.Pos,
+		})
+	}
+	return result
+}
+
+type replayResolvedLocation struct {
+	Location    string             `json:"location"`
+	Identifiers []replayIdentifier `json:"identifiers"`
+}
+
+type replayLocationArguments struct {
+	Location string `json:"location"`
+}
+
+type replayResolveLocationArguments struct {
+	Identifiers []replayIdentifier `json:"identifiers"`
+	Location    string             `json:"location"`
+}
+
+type replayRegisterArguments struct {
+	Owner []byte `json:"owner"`
+	Key   []byte `json:"key"`
+	Value []byte `json:"value,omitempty"`
+}
+
+type replayAccountArguments struct {
+	Address Address `json:"address"`
+}
+
+type replayAccountKeyArguments struct {
+	Address   Address       `json:"address"`
+	Index     int           `json:"index,omitempty"`
+	PublicKey []byte        `json:"publicKey,omitempty"`
+	Key       *PublicKey    `json:"key,omitempty"`
+	HashAlgo  HashAlgorithm `json:"hashAlgo,omitempty"`
+	Weight    int           `json:"weight,omitempty"`
+}
+
+type replayContractCodeArguments struct {
+	Address Address `json:"address"`
+	Name    string  `json:"name"`
+	Code    []byte  `json:"code,omitempty"`
+}
+
+type replayMessageArguments struct {
+	Message string `json:"message"`
+}
+
+type replayEventArguments struct {
+	Event json.RawMessage `json:"event"`
+}
+
+type replayDecodeArgumentArguments struct {
+	Argument []byte `json:"argument"`
+	Type     string `json:"type"`
+}
+
+type replayValueResults struct {
+	Value json.RawMessage `json:"value"`
+}
+
+type replayBlockAtHeightResults struct {
+	Block  Block `json:"block"`
+	Exists bool  `json:"exists"`
+}
+
+type replayLengthArguments struct {
+	Length int `json:"length"`
+}
+
+type replayVerifySignatureArguments struct {
+	Signature          []byte             `json:"signature"`
+	Tag                string             `json:"tag"`
+	SignedData         []byte             `json:"signedData"`
+	PublicKey          []byte             `json:"publicKey"`
+	SignatureAlgorithm SignatureAlgorithm `json:"signatureAlgorithm"`
+	HashAlgorithm      HashAlgorithm      `json:"hashAlgorithm"`
+}
+
+type replayHashArguments struct {
+	Data          []byte        `json:"data"`
+	Tag           string        `json:"tag"`
+	HashAlgorithm HashAlgorithm `json:"hashAlgorithm"`
+}
+
+type replayPublicKeyArguments struct {
+	PublicKey *PublicKey `json:"publicKey"`
+	Signature []byte     `json:"signature,omitempty"`
+}
+
+type replaySignaturesArguments struct {
+	Signatures [][]byte `json:"signatures"`
+}
+
+type replayPublicKeysArguments struct {
+	PublicKeys []*PublicKey `json:"publicKeys"`
+}
+
+func mustEncodeReplayJSON(value any) json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		panic(errors.NewUnexpectedErrorFromCause(err))
+	}
+	return encoded
+}
@@ -0,0 +1,727 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"time"
+
+	"github.com/onflow/atree"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+)
+
+// RecordTransaction executes the given transaction, like Runtime.ExecuteTransaction,
+// and records the execution into a replay log.
+//
+// The error returned is the error of the execution, which is also recorded in the log.
+func RecordTransaction(runtime Runtime, script Script, context Context) (*ReplayLog, error) {
+	recorder := newRecordingInterface(runtime, ReplayKindTransaction, script, context)
+	context.Interface = recorder
+
+	err := runtime.ExecuteTransaction(script, context)
+	recorder.recordError(err)
+
+	return recorder.log, err
+}
+
+// RecordScript executes the given script, like Runtime.ExecuteScript,
+// and records the execution into a replay log.
+//
+// The value and error returned are the result of the execution, which is also recorded in the log.
+func RecordScript(runtime Runtime, script Script, context Context) (*ReplayLog, cadence.Value, error) {
+	recorder := newRecordingInterface(runtime, ReplayKindScript, script, context)
+	context.Interface = recorder
+
+	value, err := runtime.ExecuteScript(script, context)
+	recorder.recordError(err)
+	if err == nil {
+		recorder.log.Result, err = jsoncdc.Encode(value)
+		if err != nil {
+			return recorder.log, nil, err
+		}
+	}
+
+	return recorder.log, value, err
+}
+
+// recordingInterface is an Interface which forwards all calls to the host interface,
+// and records the calls and their results.
+//
+// Programs are always loaded, instead of being provided by the host,
+// so that the code of all programs is recorded and the log is self-contained.
+type recordingInterface struct {
+	inner         Interface
+	log           *ReplayLog
+	programs      map[Location]*interpreter.Program
+	sharedState   *interpreter.SharedState
+	meteringCalls uint64
+}
+
+var _ Interface = &recordingInterface{}
+
+func newRecordingInterface(
+	runtime Runtime,
+	kind ReplayKind,
+	script Script,
+	context Context,
+) *recordingInterface {
+	return &recordingInterface{
+		inner: context.Interface,
+		log: &ReplayLog{
+			Version:   ReplayLogVersion,
+			Kind:      kind,
+			Config:    newReplayConfig(runtime.Config()),
+			Location:  encodeReplayLocation(context.Location),
+			Source:    string(script.Source),
+			Arguments: script.Arguments,
+		},
+		programs: map[Location]*interpreter.Program{},
+	}
+}
+
+func (i *recordingInterface) record(function string, arguments any, results any, err error) {
+	call := ReplayCall{
+		Function:  function,
+		Arguments: mustEncodeReplayJSON(arguments),
+	}
+	if err != nil {
+		message := err.Error()
+		call.Error = &message
+	} else {
+		call.Results = mustEncodeReplayJSON(results)
+	}
+	i.log.Calls = append(i.log.Calls, call)
+}
+
+func (i *recordingInterface) recordError(err error) {
+	if err == nil {
+		return
+	}
+	message := err.Error()
+	i.log.Error = &message
+}
+
+func (i *recordingInterface) recordMetering(err error) error {
+	if err != nil {
+		i.log.MeteringErrors = append(
+			i.log.MeteringErrors,
+			ReplayMeteringError{
+				Index: i.meteringCalls,
+				Error: err.Error(),
+			},
+		)
+	}
+	i.meteringCalls++
+	return err
+}
+
+func (i *recordingInterface) MeterMemory(usage common.MemoryUsage) error {
+	return i.recordMetering(i.inner.MeterMemory(usage))
+}
+
+func (i *recordingInterface) MeterComputation(operationType common.ComputationKind, intensity uint) error {
+	return i.recordMetering(i.inner.MeterComputation(operationType, intensity))
+}
+
+func (i *recordingInterface) ComputationUsed() (uint64, error) {
+	used, err := i.inner.ComputationUsed()
+	i.record("ComputationUsed", nil, used, err)
+	return used, err
+}
+
+func (i *recordingInterface) MemoryUsed() (uint64, error) {
+	used, err := i.inner.MemoryUsed()
+	i.record("MemoryUsed", nil, used, err)
+	return used, err
+}
+
+func (i *recordingInterface) InteractionUsed() (uint64, error) {
+	used, err := i.inner.InteractionUsed()
+	i.record("InteractionUsed", nil, used, err)
+	return used, err
+}
+
+func (i *recordingInterface) ResolveLocation(identifiers []Identifier, location Location) ([]ResolvedLocation, error) {
+	resolvedLocations, err := i.inner.ResolveLocation(identifiers, location)
+
+	results := make([]replayResolvedLocation, 0, len(resolvedLocations))
+	for _, resolvedLocation := range resolvedLocations {
+		results = append(results, replayResolvedLocation{
+			Location:    encodeReplayLocation(resolvedLocation.Location),
+			Identifiers: encodeReplayIdentifiers(resolvedLocation.Identifiers),
+		})
+	}
+
+	i.record(
+		"ResolveLocation",
+		replayResolveLocationArguments{
+			Identifiers: encodeReplayIdentifiers(identifiers),
+			Location:    encodeReplayLocation(location),
+		},
+		results,
+		err,
+	)
+	return resolvedLocations, err
+}
+
+func (i *recordingInterface) GetCode(location Location) ([]byte, error) {
+	code, err := i.inner.GetCode(location)
+	i.record(
+		"GetCode",
+		replayLocationArguments{
+			Location: encodeReplayLocation(location),
+		},
+		code,
+		err,
+	)
+	return code, err
+}
+
+func (i *recordingInterface) GetOrLoadProgram(
+	location Location,
+	load func() (*interpreter.Program, error),
+) (
+	program *interpreter.Program,
+	err error,
+) {
+	program, ok := i.programs[location]
+	if ok {
+		return program, nil
+	}
+
+	program, err = load()
+
+	// NOTE: important: still set empty program,
+	// even if error occurred
+
+	i.programs[location] = program
+
+	return program, err
+}
+
+func (i *recordingInterface) SetInterpreterSharedState(state *interpreter.SharedState) {
+	i.sharedState = state
+}
+
+func (i *recordingInterface) GetInterpreterSharedState() *interpreter.SharedState {
+	return i.sharedState
+}
+
+func (i *recordingInterface) GetValue(owner, key []byte) (value []byte, err error) {
+	value, err = i.inner.GetValue(owner, key)
+	i.record(
+		"GetValue",
+		replayRegisterArguments{
+			Owner: owner,
+			Key:   key,
+		},
+		value,
+		err,
+	)
+	return value, err
+}
+
+func (i *recordingInterface) SetValue(owner, key, value []byte) (err error) {
+	err = i.inner.SetValue(owner, key, value)
+	i.record(
+		"SetValue",
+		replayRegisterArguments{
+			Owner: owner,
+			Key:   key,
+			Value: value,
+		},
+		nil,
+		err,
+	)
+	return err
+}
+
+func (i *recordingInterface) ValueExists(owner, key []byte) (exists bool, err error) {
+	exists, err = i.inner.ValueExists(owner, key)
+	i.record(
+		"ValueExists",
+		replayRegisterArguments{
+			Owner: owner,
+			Key:   key,
+		},
+		exists,
+		err,
+	)
+	return exists, err
+}
+
+func (i *recordingInterface) AllocateStorageIndex(owner []byte) (atree.StorageIndex, error) {
+	index, err := i.inner.AllocateStorageIndex(owner)
+	i.record(
+		"AllocateStorageIndex",
+		replayRegisterArguments{
+			Owner: owner,
+		},
+		index,
+		err,
+	)
+	return index, err
+}
+
+func (i *recordingInterface) CreateAccount(payer Address) (address Address, err error) {
+	address, err = i.inner.CreateAccount(payer)
+	i.record(
+		"CreateAccount",
+		replayAccountArguments{
+			Address: payer,
+		},
+		address,
+		err,
+	)
+	return address, err
+}
+
+func (i *recordingInterface) AddEncodedAccountKey(address Address, publicKey []byte) error {
+	err := i.inner.AddEncodedAccountKey(address, publicKey)
+	i.record(
+		"AddEncodedAccountKey",
+		replayAccountKeyArguments{
+			Address:   address,
+			PublicKey: publicKey,
+		},
+		nil,
+		err,
+	)
+	return err
+}
+
+func (i *recordingInterface) RevokeEncodedAccountKey(address Address, index int) (publicKey []byte, err error) {
+	publicKey, err = i.inner.RevokeEncodedAccountKey(address, index)
+	i.record(
+		"RevokeEncodedAccountKey",
+		replayAccountKeyArguments{
+			Address: address,
+			Index:   index,
+		},
+		publicKey,
+		err,
+	)
+	return publicKey, err
+}
+
+func (i *recordingInterface) AddAccountKey(
+	address Address,
+	publicKey *PublicKey,
+	hashAlgo HashAlgorithm,
+	weight int,
+) (*AccountKey, error) {
+	accountKey, err := i.inner.AddAccountKey(address, publicKey, hashAlgo, weight)
+	i.record(
+		"AddAccountKey",
+		replayAccountKeyArguments{
+			Address:  address,
+			Key:      publicKey,
+			HashAlgo: hashAlgo,
+			Weight:   weight,
+		},
+		accountKey,
+		err,
+	)
+	return accountKey, err
+}
+
+func (i *recordingInterface) GetAccountKey(address Address, index int) (*AccountKey, error) {
+	accountKey, err := i.inner.GetAccountKey(address, index)
+	i.record(
+		"GetAccountKey",
+		replayAccountKeyArguments{
+			Address: address,
+			Index:   index,
+		},
+		accountKey,
+		err,
+	)
+	return accountKey, err
+}
+
+func (i *recordingInterface) AccountKeysCount(address Address) (uint64, error) {
+	count, err := i.inner.AccountKeysCount(address)
+	i.record(
+		"AccountKeysCount",
+		replayAccountArguments{
+			Address: address,
+		},
+		count,
+		err,
+	)
+	return count, err
+}
+
+func (i *recordingInterface) RevokeAccountKey(address Address, index int) (*AccountKey, error) {
+	accountKey, err := i.inner.RevokeAccountKey(address, index)
+	i.record(
+		"RevokeAccountKey",
+		replayAccountKeyArguments{
+			Address: address,
+			Index:   index,
+		},
+		accountKey,
+		err,
+	)
+	return accountKey, err
+}
+
+func (i *recordingInterface) UpdateAccountContractCode(location common.AddressLocation, code []byte) (err error) {
+	err = i.inner.UpdateAccountContractCode(location, code)
+	i.record(
+		"UpdateAccountContractCode",
+		replayContractCodeArguments{
+			Address: location.Address,
+			Name:    location.Name,
+			Code:    code,
+		},
+		nil,
+		err,
+	)
+	return err
+}
+
+func (i *recordingInterface) GetAccountContractCode(location common.AddressLocation) (code []byte, err error) {
+	code, err = i.inner.GetAccountContractCode(location)
+	i.record(
+		"GetAccountContractCode",
+		replayContractCodeArguments{
+			Address: location.Address,
+			Name:    location.Name,
+		},
+		code,
+		err,
+	)
+	return code, err
+}
+
+func (i *recordingInterface) RemoveAccountContractCode(location common.AddressLocation) (err error) {
+	err = i.inner.RemoveAccountContractCode(location)
+	i.record(
+		"RemoveAccountContractCode",
+		replayContractCodeArguments{
+			Address: location.Address,
+			Name:    location.Name,
+		},
+		nil,
+		err,
+	)
+	return err
+}
+
+func (i *recordingInterface) GetSigningAccounts() ([]Address, error) {
+	accounts, err := i.inner.GetSigningAccounts()
+	i.record("GetSigningAccounts", nil, accounts, err)
+	return accounts, err
+}
+
+func (i *recordingInterface) ProgramLog(message string) error {
+	err := i.inner.ProgramLog(message)
+	i.record(
+		"ProgramLog",
+		replayMessageArguments{
+			Message: message,
+		},
+		nil,
+		err,
+	)
+	return err
+}
+
+func (i *recordingInterface) EmitEvent(event cadence.Event) error {
+	encodedEvent, err := jsoncdc.Encode(event)
+	if err != nil {
+		return err
+	}
+
+	err = i.inner.EmitEvent(event)
+	i.record(
+		"EmitEvent",
+		replayEventArguments{
+			Event: encodedEvent,
+		},
+		nil,
+		err,
+	)
+	return err
+}
+
+func (i *recordingInterface) GenerateUUID() (uint64, error) {
+	uuid, err := i.inner.GenerateUUID()
+	i.record("GenerateUUID", nil, uuid, err)
+	return uuid, err
+}
+
+func (i *recordingInterface) DecodeArgument(argument []byte, argumentType cadence.Type) (cadence.Value, error) {
+	value, err := i.inner.DecodeArgument(argument, argumentType)
+
+	var results replayValueResults
+	if err == nil {
+		results.Value, err = jsoncdc.Encode(value)
+	}
+
+	i.record(
+		"DecodeArgument",
+		replayDecodeArgumentArguments{
+			Argument: argument,
+			Type:     argumentType.ID(),
+		},
+		results,
+		err,
+	)
+	return value, err
+}
+
+func (i *recordingInterface) GetCurrentBlockHeight() (uint64, error) {
+	height, err := i.inner.GetCurrentBlockHeight()
+	i.record("GetCurrentBlockHeight", nil, height, err)
+	return height, err
+}
+
+func (i *recordingInterface) GetBlockAtHeight(height uint64) (block Block, exists bool, err error) {
+	block, exists, err = i.inner.GetBlockAtHeight(height)
+	i.record(
+		"GetBlockAtHeight",
+		height,
+		replayBlockAtHeightResults{
+			Block:  block,
+			Exists: exists,
+		},
+		err,
+	)
+	return block, exists, err
+}
+
+func (i *recordingInterface) ReadRandom(buffer []byte) error {
+	err := i.inner.ReadRandom(buffer)
+	i.record(
+		"ReadRandom",
+		replayLengthArguments{
+			Length: len(buffer),
+		},
+		buffer,
+		err,
+	)
+	return err
+}
+
+func (i *recordingInterface) VerifySignature(
+	signature []byte,
+	tag string,
+	signedData []byte,
+	publicKey []byte,
+	signatureAlgorithm SignatureAlgorithm,
+	hashAlgorithm HashAlgorithm,
+) (bool, error) {
+	valid, err := i.inner.VerifySignature(
+		signature,
+		tag,
+		signedData,
+		publicKey,
+		signatureAlgorithm,
+		hashAlgorithm,
+	)
+	i.record(
+		"VerifySignature",
+		replayVerifySignatureArguments{
+			Signature:          signature,
+			Tag:                tag,
+			SignedData:         signedData,
+			PublicKey:          publicKey,
+			SignatureAlgorithm: signatureAlgorithm,
+			HashAlgorithm:      hashAlgorithm,
+		},
+		valid,
+		err,
+	)
+	return valid, err
+}
+
+func (i *recordingInterface) Hash(data []byte, tag string, hashAlgorithm HashAlgorithm) ([]byte, error) {
+	digest, err := i.inner.Hash(data, tag, hashAlgorithm)
+	i.record(
+		"Hash",
+		replayHashArguments{
+			Data:          data,
+			Tag:           tag,
+			HashAlgorithm: hashAlgorithm,
+		},
+		digest,
+		err,
+	)
+	return digest, err
+}
+
+func (i *recordingInterface) GetAccountBalance(address common.Address) (value uint64, err error) {
+	value, err = i.inner.GetAccountBalance(address)
+	i.record(
+		"GetAccountBalance",
+		replayAccountArguments{
+			Address: address,
+		},
+		value,
+		err,
+	)
+	return value, err
+}
+
+func (i *recordingInterface) GetAccountAvailableBalance(address common.Address) (value uint64, err error) {
+	value, err = i.inner.GetAccountAvailableBalance(address)
+	i.record(
+		"GetAccountAvailableBalance",
+		replayAccountArguments{
+			Address: address,
+		},
+		value,
+		err,
+	)
+	return value, err
+}
+
+func (i *recordingInterface) GetStorageUsed(address Address) (value uint64, err error) {
+	value, err = i.inner.GetStorageUsed(address)
+	i.record(
+		"GetStorageUsed",
+		replayAccountArguments{
+			Address: address,
+		},
+		value,
+		err,
+	)
+	return value, err
+}
+
+func (i *recordingInterface) GetStorageCapacity(address Address) (value uint64, err error) {
+	value, err = i.inner.GetStorageCapacity(address)
+	i.record(
+		"GetStorageCapacity",
+		replayAccountArguments{
+			Address: address,
+		},
+		value,
+		err,
+	)
+	return value, err
+}
+
+func (i *recordingInterface) ImplementationDebugLog(message string) error {
+	// Debug logs do not influence the execution, so they are not recorded
+	return i.inner.ImplementationDebugLog(message)
+}
+
+func (i *recordingInterface) ValidatePublicKey(key *PublicKey) error {
+	err := i.inner.ValidatePublicKey(key)
+	i.record(
+		"ValidatePublicKey",
+		replayPublicKeyArguments{
+			PublicKey: key,
+		},
+		nil,
+		err,
+	)
+	return err
+}
+
+func (i *recordingInterface) GetAccountContractNames(address Address) ([]string, error) {
+	names, err := i.inner.GetAccountContractNames(address)
+	i.record(
+		"GetAccountContractNames",
+		replayAccountArguments{
+			Address: address,
+		},
+		names,
+		err,
+	)
+	return names, err
+}
+
+func (i *recordingInterface) RecordTrace(
+	operation string,
+	location Location,
+	duration time.Duration,
+	attrs []attribute.KeyValue,
+) {
+	i.inner.RecordTrace(operation, location, duration, attrs)
+}
+
+func (i *recordingInterface) BLSVerifyPOP(publicKey *PublicKey, signature []byte) (bool, error) {
+	valid, err := i.inner.BLSVerifyPOP(publicKey, signature)
+	i.record(
+		"BLSVerifyPOP",
+		replayPublicKeyArguments{
+			PublicKey: publicKey,
+			Signature: signature,
+		},
+		valid,
+		err,
+	)
+	return valid, err
+}
+
+func (i *recordingInterface) BLSAggregateSignatures(signatures [][]byte) ([]byte, error) {
+	signature, err := i.inner.BLSAggregateSignatures(signatures)
+	i.record(
+		"BLSAggregateSignatures",
+		replaySignaturesArguments{
+			Signatures: signatures,
+		},
+		signature,
+		err,
+	)
+	return signature, err
+}
+
+func (i *recordingInterface) BLSAggregatePublicKeys(publicKeys []*PublicKey) (*PublicKey, error) {
+	publicKey, err := i.inner.BLSAggregatePublicKeys(publicKeys)
+	i.record(
+		"BLSAggregatePublicKeys",
+		replayPublicKeysArguments{
+			PublicKeys: publicKeys,
+		},
+		publicKey,
+		err,
+	)
+	return publicKey, err
+}
+
+func (i *recordingInterface) ResourceOwnerChanged(
+	interpreter *interpreter.Interpreter,
+	resource *interpreter.CompositeValue,
+	oldOwner common.Address,
+	newOwner common.Address,
+) {
+	i.inner.ResourceOwnerChanged(interpreter, resource, oldOwner, newOwner)
+}
+
+func (i *recordingInterface) GenerateAccountID(address common.Address) (uint64, error) {
+	id, err := i.inner.GenerateAccountID(address)
+	i.record(
+		"GenerateAccountID",
+		replayAccountArguments{
+			Address: address,
+		},
+		id,
+		err,
+	)
+	return id, err
+}
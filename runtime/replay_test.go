@@ -0,0 +1,317 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
+	. "github.com/onflow/cadence/runtime/tests/utils"
+)
+
+func TestRuntimeReplay(t *testing.T) {
+
+	t.Parallel()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+
+	const contract = `
+      pub contract Test {
+
+          pub event Created(id: UInt64, random: UInt64, height: UInt64)
+
+          pub resource R {}
+
+          pub fun createR(): @R {
+              let r <- create R()
+              emit Created(id: r.uuid, random: unsafeRandom(), height: getCurrentBlock().height)
+              return <-r
+          }
+      }
+    `
+
+	const transaction = `
+      import Test from 0x1
+
+      transaction(message: String) {
+          prepare(signer: AuthAccount) {
+              log(message)
+              signer.save(<-Test.createR(), to: /storage/r)
+          }
+      }
+    `
+
+	const script = `
+      import Test from 0x1
+
+      pub fun main(): UInt64 {
+          return getAuthAccount(0x1).borrow<&Test.R>(from: /storage/r)!.uuid
+      }
+    `
+
+	setup := func(t *testing.T) func() *testRuntimeInterface {
+
+		ledger := newTestLedger(nil, nil)
+		accountCodes := map[Location][]byte{}
+
+		var uuid uint64
+		var random uint64
+
+		newRuntimeInterface := func() *testRuntimeInterface {
+			return &testRuntimeInterface{
+				storage: ledger,
+				getSigningAccounts: func() ([]Address, error) {
+					return []Address{address}, nil
+				},
+				resolveLocation: singleIdentifierLocationResolver(t),
+				updateAccountContractCode: func(location common.AddressLocation, code []byte) error {
+					accountCodes[location] = code
+					return nil
+				},
+				getAccountContractCode: func(location common.AddressLocation) ([]byte, error) {
+					return accountCodes[location], nil
+				},
+				emitEvent: func(event cadence.Event) error {
+					return nil
+				},
+				log: func(message string) {},
+				generateUUID: func() (uint64, error) {
+					uuid++
+					return uuid, nil
+				},
+				readRandom: func(buffer []byte) error {
+					random++
+					binary.LittleEndian.PutUint64(buffer, random)
+					return nil
+				},
+				decodeArgument: func(b []byte, t cadence.Type) (cadence.Value, error) {
+					return jsoncdc.Decode(nil, b)
+				},
+			}
+		}
+
+		runtime := newTestInterpreterRuntime()
+
+		err := runtime.ExecuteTransaction(
+			Script{
+				Source: DeploymentTransaction("Test", []byte(contract)),
+			},
+			Context{
+				Interface: newRuntimeInterface(),
+				Location:  common.TransactionLocation{},
+			},
+		)
+		require.NoError(t, err)
+
+		return newRuntimeInterface
+	}
+
+	record := func(t *testing.T, newRuntimeInterface func() *testRuntimeInterface) *ReplayLog {
+		runtime := NewInterpreterRuntime(Config{
+			AtreeValidationEnabled: true,
+		})
+
+		log, err := RecordTransaction(
+			runtime,
+			Script{
+				Source: []byte(transaction),
+				Arguments: [][]byte{
+					jsoncdc.MustEncode(cadence.String("hello")),
+				},
+			},
+			Context{
+				Interface: newRuntimeInterface(),
+				Location:  common.TransactionLocation{0x1},
+			},
+		)
+		require.NoError(t, err)
+
+		return log
+	}
+
+	findCall := func(log *ReplayLog, function string) *ReplayCall {
+		for i, call := range log.Calls {
+			if call.Function == function {
+				return &log.Calls[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run("transaction", func(t *testing.T) {
+
+		t.Parallel()
+
+		log := record(t, setup(t))
+
+		assert.Equal(t, ReplayKindTransaction, log.Kind)
+		assert.Nil(t, log.Error)
+
+		for _, function := range []string{
+			"GetSigningAccounts",
+			"DecodeArgument",
+			"ResolveLocation",
+			"GetAccountContractCode",
+			"GenerateUUID",
+			"ReadRandom",
+			"GetCurrentBlockHeight",
+			"EmitEvent",
+			"ProgramLog",
+			"GetValue",
+			"SetValue",
+		} {
+			assert.NotNil(t, findCall(log, function), function)
+		}
+
+		result, err := Replay(log, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.Err)
+	})
+
+	t.Run("portable", func(t *testing.T) {
+
+		t.Parallel()
+
+		log := record(t, setup(t))
+
+		encoded, err := json.MarshalIndent(log, "", "  ")
+		require.NoError(t, err)
+
+		var decoded ReplayLog
+		err = json.Unmarshal(encoded, &decoded)
+		require.NoError(t, err)
+
+		result, err := Replay(&decoded, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.Err)
+	})
+
+	t.Run("diverging result", func(t *testing.T) {
+
+		t.Parallel()
+
+		log := record(t, setup(t))
+
+		// Change the generated UUID,
+		// which changes the emitted event
+
+		call := findCall(log, "GenerateUUID")
+		require.NotNil(t, call)
+		call.Results = json.RawMessage(`42`)
+
+		_, err := Replay(log, nil)
+		var mismatchErr ReplayCallMismatchError
+		require.ErrorAs(t, err, &mismatchErr)
+		assert.Equal(t, "EmitEvent", mismatchErr.ExpectedFunction)
+		assert.Equal(t, "EmitEvent", mismatchErr.ActualFunction)
+	})
+
+	t.Run("missing call", func(t *testing.T) {
+
+		t.Parallel()
+
+		log := record(t, setup(t))
+
+		log.Calls = log.Calls[:len(log.Calls)-1]
+
+		_, err := Replay(log, nil)
+		var mismatchErr ReplayCallMismatchError
+		require.ErrorAs(t, err, &mismatchErr)
+		assert.Equal(t, "", mismatchErr.ExpectedFunction)
+	})
+
+	t.Run("failed transaction", func(t *testing.T) {
+
+		t.Parallel()
+
+		newRuntimeInterface := setup(t)
+
+		// Saving a second time fails, as the storage path is already used
+
+		record(t, newRuntimeInterface)
+
+		runtime := NewInterpreterRuntime(Config{})
+
+		log, err := RecordTransaction(
+			runtime,
+			Script{
+				Source: []byte(transaction),
+				Arguments: [][]byte{
+					jsoncdc.MustEncode(cadence.String("hello")),
+				},
+			},
+			Context{
+				Interface: newRuntimeInterface(),
+				Location:  common.TransactionLocation{0x2},
+			},
+		)
+		RequireError(t, err)
+		require.NotNil(t, log.Error)
+
+		result, err := Replay(log, nil)
+		require.NoError(t, err)
+		require.Error(t, result.Err)
+		assert.Equal(t, *log.Error, result.Err.Error())
+
+		// Replaying a failed execution as a successful execution is a mismatch
+
+		log.Error = nil
+
+		_, err = Replay(log, nil)
+		var resultMismatchErr ReplayResultMismatchError
+		require.ErrorAs(t, err, &resultMismatchErr)
+	})
+
+	t.Run("script", func(t *testing.T) {
+
+		t.Parallel()
+
+		newRuntimeInterface := setup(t)
+
+		record(t, newRuntimeInterface)
+
+		runtime := NewInterpreterRuntime(Config{})
+
+		log, value, err := RecordScript(
+			runtime,
+			Script{
+				Source: []byte(script),
+			},
+			Context{
+				Interface: newRuntimeInterface(),
+				Location:  common.ScriptLocation{0x1},
+			},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.UInt64(1), value)
+		assert.Equal(t, ReplayKindScript, log.Kind)
+
+		result, err := Replay(log, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.Err)
+		assert.Equal(t, cadence.UInt64(1), result.Value)
+	})
+}
@@ -0,0 +1,397 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"sort"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/stdlib"
+)
+
+// AuditedResource is a resource observed by the resource auditor.
+type AuditedResource struct {
+	UUID  uint64
+	Type  common.TypeID
+	Owner common.Address
+	// Location and Line are the position in the program
+	// where the resource was created, moved, or destroyed.
+	Location common.Location
+	Line     int
+}
+
+// ResourceMove is a move of a resource observed by the resource auditor.
+type ResourceMove struct {
+	AuditedResource
+	OldOwner common.Address
+}
+
+// UnlinkedResource is a stored resource observed by the resource auditor,
+// which is stored under a storage path that no link or capability targets.
+type UnlinkedResource struct {
+	AuditedResource
+	// Path is the storage path under which the resource is stored
+	Path interpreter.PathValue
+}
+
+// ResourceAuditReport is the result of auditing the resources of an execution.
+type ResourceAuditReport struct {
+	// Created are the resources which were created
+	Created []AuditedResource
+	// Moved are the moves of resources.
+	// A resource may be moved multiple times
+	Moved []ResourceMove
+	// Destroyed are the resources which were destroyed
+	Destroyed []AuditedResource
+	// Lost are the resources which were created or moved and were not destroyed,
+	// but are not reachable from storage at the end of the execution.
+	// The owner and position are the ones of the last observed move, or the creation
+	Lost []AuditedResource
+	// Unlinked are the resources which were created or moved and were not destroyed,
+	// and which are stored under a storage path that no link or capability controller targets,
+	// so nobody but the owning account can borrow them
+	Unlinked []UnlinkedResource
+}
+
+// ResourceAuditor tracks the life cycle of resources during the execution of a program.
+// It is opt-in: pass a ResourceAuditor in the Context of an execution to enable it.
+//
+// At the end of the execution, when storage is committed,
+// all resources which are still alive are checked to be reachable from storage,
+// and to be stored under a storage path which can be borrowed through a link or capability.
+// A ResourceAuditor should only be used for a single execution.
+type ResourceAuditor struct {
+	report ResourceAuditReport
+	// alive are the resources which were created or moved, and not destroyed yet
+	alive map[uint64]AuditedResource
+}
+
+func NewResourceAuditor() *ResourceAuditor {
+	return &ResourceAuditor{
+		alive: map[uint64]AuditedResource{},
+	}
+}
+
+// Report returns the report of the audit
+func (a *ResourceAuditor) Report() *ResourceAuditReport {
+	return &a.report
+}
+
+// configureResourceAuditor sets the given auditor, which may be nil, for the next execution in the given environment.
+// Environments which do not implement ResourceAuditingEnvironment do not audit resources.
+func configureResourceAuditor(environment Environment, resourceAuditor *ResourceAuditor) {
+	auditingEnvironment, ok := environment.(ResourceAuditingEnvironment)
+	if !ok {
+		return
+	}
+	auditingEnvironment.SetResourceAuditor(resourceAuditor)
+}
+
+func newAuditedResource(
+	inter *interpreter.Interpreter,
+	resource *interpreter.CompositeValue,
+	locationRange interpreter.LocationRange,
+	owner common.Address,
+) (AuditedResource, bool) {
+	uuid := resource.ResourceUUID(inter, locationRange)
+	if uuid == nil {
+		return AuditedResource{}, false
+	}
+
+	var line int
+	if locationRange.HasPosition != nil {
+		line = locationRange.StartPosition().Line
+	}
+
+	return AuditedResource{
+		UUID:     uint64(*uuid),
+		Type:     resource.TypeID(),
+		Owner:    owner,
+		Location: locationRange.Location,
+		Line:     line,
+	}, true
+}
+
+func (a *ResourceAuditor) resourceCreated(
+	inter *interpreter.Interpreter,
+	resource *interpreter.CompositeValue,
+	locationRange interpreter.LocationRange,
+) {
+	audited, ok := newAuditedResource(inter, resource, locationRange, resource.GetOwner())
+	if !ok {
+		return
+	}
+
+	a.report.Created = append(a.report.Created, audited)
+	a.alive[audited.UUID] = audited
+}
+
+func (a *ResourceAuditor) resourceMoved(
+	inter *interpreter.Interpreter,
+	resource *interpreter.CompositeValue,
+	locationRange interpreter.LocationRange,
+	oldOwner common.Address,
+	newOwner common.Address,
+) {
+	audited, ok := newAuditedResource(inter, resource, locationRange, newOwner)
+	if !ok {
+		return
+	}
+
+	a.report.Moved = append(
+		a.report.Moved,
+		ResourceMove{
+			AuditedResource: audited,
+			OldOwner:        oldOwner,
+		},
+	)
+	a.alive[audited.UUID] = audited
+}
+
+func (a *ResourceAuditor) resourceDestroyed(
+	inter *interpreter.Interpreter,
+	resource *interpreter.CompositeValue,
+	locationRange interpreter.LocationRange,
+) {
+	audited, ok := newAuditedResource(inter, resource, locationRange, resource.GetOwner())
+	if !ok {
+		return
+	}
+
+	a.report.Destroyed = append(a.report.Destroyed, audited)
+	delete(a.alive, audited.UUID)
+}
+
+// resourceAuditStorageDomains are the storage domains which may contain resources
+var resourceAuditStorageDomains = []string{
+	common.PathDomainStorage.Identifier(),
+	StorageDomainContract,
+}
+
+// audit determines which of the alive resources are not reachable from storage,
+// and reports them as lost.
+// It also determines which of the reachable resources are stored under a storage path
+// that is not targeted by any link or capability controller, and reports them as unlinked.
+func (a *ResourceAuditor) audit(inter *interpreter.Interpreter, storage *Storage) {
+
+	// Only the accounts which own an alive resource need to be inspected:
+	// If a lost resource is reachable from storage,
+	// then it is reachable from the storage of its last owner
+
+	accounts := map[common.Address]struct{}{}
+
+	// NOTE: iteration over map is safe,
+	// as only a set is built
+
+	for _, audited := range a.alive { //nolint:maprange
+		if audited.Owner == common.ZeroAddress {
+			continue
+		}
+		accounts[audited.Owner] = struct{}{}
+	}
+
+	reachable := map[uint64]struct{}{}
+
+	// unlinked are the reachable resources which are stored under storage paths
+	// that are not targeted by any link or capability controller, by UUID
+	unlinked := map[uint64]interpreter.PathValue{}
+
+	// NOTE: iteration over map is safe,
+	// as only sets are built
+
+	for address := range accounts { //nolint:maprange
+		linkedIdentifiers := linkedStorageIdentifiers(inter, storage, address)
+
+		for _, domain := range resourceAuditStorageDomains {
+			storageMap := storage.GetStorageMap(address, domain, false)
+			if storageMap == nil {
+				continue
+			}
+
+			iterator := storageMap.Iterator(inter)
+			for {
+				key, value := iterator.Next()
+				if key == nil {
+					break
+				}
+
+				// Only the resources stored in the storage domain are accessed through paths,
+				// the resources in the contract domain are accessed through the contract
+
+				var unlinkedPath *interpreter.PathValue
+				if domain == common.PathDomainStorage.Identifier() {
+					// TODO: unfortunately, the iterator only returns an atree.Value, not a StorageMapKey
+					identifier := string(key.(interpreter.StringAtreeValue))
+					if _, ok := linkedIdentifiers[identifier]; !ok {
+						unlinkedPath = &interpreter.PathValue{
+							Domain:     common.PathDomainStorage,
+							Identifier: identifier,
+						}
+					}
+				}
+
+				interpreter.InspectValue(
+					inter,
+					value,
+					func(value interpreter.Value) bool {
+						composite, ok := value.(*interpreter.CompositeValue)
+						if !ok || composite.Kind != common.CompositeKindResource {
+							return true
+						}
+
+						uuid := composite.ResourceUUID(inter, interpreter.EmptyLocationRange)
+						if uuid != nil {
+							reachable[uint64(*uuid)] = struct{}{}
+							if unlinkedPath != nil {
+								unlinked[uint64(*uuid)] = *unlinkedPath
+							}
+						}
+
+						return true
+					},
+				)
+			}
+		}
+	}
+
+	var lost []AuditedResource
+	var unlinkedResources []UnlinkedResource
+
+	// NOTE: iteration over map is safe,
+	// as results are sorted below
+
+	for uuid, audited := range a.alive { //nolint:maprange
+		if _, ok := reachable[uuid]; !ok {
+			lost = append(lost, audited)
+			continue
+		}
+
+		if path, ok := unlinked[uuid]; ok {
+			unlinkedResources = append(
+				unlinkedResources,
+				UnlinkedResource{
+					AuditedResource: audited,
+					Path:            path,
+				},
+			)
+		}
+	}
+
+	sort.Slice(lost, func(i, j int) bool {
+		return lost[i].UUID < lost[j].UUID
+	})
+
+	sort.Slice(unlinkedResources, func(i, j int) bool {
+		return unlinkedResources[i].UUID < unlinkedResources[j].UUID
+	})
+
+	a.report.Lost = append(a.report.Lost, lost...)
+	a.report.Unlinked = append(a.report.Unlinked, unlinkedResources...)
+}
+
+// linkedStorageIdentifiers returns the identifiers of the storage paths of the given account
+// which are targeted by a link, directly or through other links,
+// or by a storage capability controller.
+func linkedStorageIdentifiers(
+	inter *interpreter.Interpreter,
+	storage *Storage,
+	address common.Address,
+) map[string]struct{} {
+
+	result := map[string]struct{}{}
+
+	// Storage capability controllers are recorded by target storage path identifier
+
+	pathCapabilityStorageMap := storage.GetStorageMap(address, stdlib.PathCapabilityStorageDomain, false)
+	if pathCapabilityStorageMap != nil {
+		iterator := pathCapabilityStorageMap.Iterator(inter)
+		for {
+			key, value := iterator.Next()
+			if key == nil {
+				break
+			}
+
+			capabilityIDSet, ok := value.(*interpreter.DictionaryValue)
+			if !ok || capabilityIDSet.Count() == 0 {
+				continue
+			}
+
+			// TODO: unfortunately, the iterator only returns an atree.Value, not a StorageMapKey
+			result[string(key.(interpreter.StringAtreeValue))] = struct{}{}
+		}
+	}
+
+	// Links may target storage paths directly, or through other links
+
+	links := map[interpreter.PathValue]interpreter.PathValue{}
+
+	for _, domain := range []common.PathDomain{
+		common.PathDomainPublic,
+		common.PathDomainPrivate,
+	} {
+		storageMap := storage.GetStorageMap(address, domain.Identifier(), false)
+		if storageMap == nil {
+			continue
+		}
+
+		iterator := storageMap.Iterator(inter)
+		for {
+			key, value := iterator.Next()
+			if key == nil {
+				break
+			}
+
+			link, ok := value.(interpreter.PathLinkValue)
+			if !ok {
+				continue
+			}
+
+			// TODO: unfortunately, the iterator only returns an atree.Value, not a StorageMapKey
+			path := interpreter.PathValue{
+				Domain:     domain,
+				Identifier: string(key.(interpreter.StringAtreeValue)),
+			}
+			links[path] = link.TargetPath
+		}
+	}
+
+	// NOTE: iteration over map is safe,
+	// as only a set is built
+
+	for _, target := range links { //nolint:maprange
+
+		// Follow the links until a storage path is reached.
+		// The number of steps is limited by the number of links, to avoid cycles
+
+		for i := 0; i <= len(links) && target.Domain != common.PathDomainStorage; i++ {
+			next, ok := links[target]
+			if !ok {
+				break
+			}
+			target = next
+		}
+
+		if target.Domain == common.PathDomainStorage {
+			result[target.Identifier] = struct{}{}
+		}
+	}
+
+	return result
+}
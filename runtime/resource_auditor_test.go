@@ -0,0 +1,371 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
+	. "github.com/onflow/cadence/runtime/tests/utils"
+)
+
+func TestRuntimeResourceAuditor(t *testing.T) {
+
+	t.Parallel()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+
+	const contract = `
+      pub contract Test {
+
+          pub resource R {}
+
+          pub fun createR(): @R {
+              return <-create R()
+          }
+      }
+    `
+
+	// The transaction creates three resources:
+	// The first is stored, the second is destroyed,
+	// and the third is stored, but then overwritten by a host function
+
+	const transaction = `
+      import Test from 0x1
+
+      transaction {
+          prepare(signer: AuthAccount) {
+              signer.save(<-Test.createR(), to: /storage/r1)
+
+              destroy Test.createR()
+
+              signer.save(<-Test.createR(), to: /storage/r2)
+              overwrite()
+          }
+      }
+    `
+
+	ledger := newTestLedger(nil, nil)
+	accountCodes := map[Location][]byte{}
+
+	var uuid uint64
+
+	newRuntimeInterface := func() *testRuntimeInterface {
+		return &testRuntimeInterface{
+			storage: ledger,
+			getSigningAccounts: func() ([]Address, error) {
+				return []Address{address}, nil
+			},
+			resolveLocation: singleIdentifierLocationResolver(t),
+			updateAccountContractCode: func(location common.AddressLocation, code []byte) error {
+				accountCodes[location] = code
+				return nil
+			},
+			getAccountContractCode: func(location common.AddressLocation) ([]byte, error) {
+				return accountCodes[location], nil
+			},
+			emitEvent: func(event cadence.Event) error {
+				return nil
+			},
+			generateUUID: func() (uint64, error) {
+				uuid++
+				return uuid, nil
+			},
+		}
+	}
+
+	runtime := NewInterpreterRuntime(Config{})
+
+	nextTransactionLocation := newTransactionLocationGenerator()
+
+	err := runtime.ExecuteTransaction(
+		Script{
+			Source: DeploymentTransaction("Test", []byte(contract)),
+		},
+		Context{
+			Interface: newRuntimeInterface(),
+			Location:  nextTransactionLocation(),
+		},
+	)
+	require.NoError(t, err)
+
+	overwriteFunction := stdlib.NewStandardLibraryFunction(
+		"overwrite",
+		&sema.FunctionType{
+			ReturnTypeAnnotation: sema.NewTypeAnnotation(sema.VoidType),
+		},
+		"",
+		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+			storageMap := inter.Storage().GetStorageMap(
+				address,
+				common.PathDomainStorage.Identifier(),
+				false,
+			)
+			storageMap.WriteValue(
+				inter,
+				interpreter.StringStorageMapKey("r2"),
+				interpreter.NewUnmeteredStringValue("overwritten"),
+			)
+			return interpreter.Void
+		},
+	)
+
+	environment := NewBaseInterpreterEnvironment(Config{})
+	environment.DeclareValue(overwriteFunction, nil)
+
+	auditor := NewResourceAuditor()
+
+	transactionLocation := nextTransactionLocation()
+
+	err = runtime.ExecuteTransaction(
+		Script{
+			Source: []byte(transaction),
+		},
+		Context{
+			Interface:       newRuntimeInterface(),
+			Location:        transactionLocation,
+			Environment:     environment,
+			ResourceAuditor: auditor,
+		},
+	)
+	require.NoError(t, err)
+
+	report := auditor.Report()
+
+	uuids := func(resources []AuditedResource) []uint64 {
+		result := make([]uint64, 0, len(resources))
+		for _, resource := range resources {
+			result = append(result, resource.UUID)
+		}
+		return result
+	}
+
+	const resourceTypeID = common.TypeID("A.0000000000000001.Test.R")
+
+	require.Len(t, report.Created, 3)
+	for _, created := range report.Created {
+		assert.Equal(t, resourceTypeID, created.Type)
+		assert.Equal(t, common.ZeroAddress, created.Owner)
+	}
+	firstUUID := report.Created[0].UUID
+	secondUUID := report.Created[1].UUID
+	thirdUUID := report.Created[2].UUID
+
+	assert.Equal(t, []uint64{secondUUID}, uuids(report.Destroyed))
+
+	// The stored resources were moved into the account
+
+	var movedIntoAccount []uint64
+	for _, move := range report.Moved {
+		if move.Owner == address {
+			assert.Equal(t, common.ZeroAddress, move.OldOwner)
+			assert.Equal(t, transactionLocation, move.Location)
+			movedIntoAccount = append(movedIntoAccount, move.UUID)
+		}
+	}
+	assert.Equal(t, []uint64{firstUUID, thirdUUID}, movedIntoAccount)
+
+	// The overwritten resource is lost
+
+	require.Len(t, report.Lost, 1)
+	lost := report.Lost[0]
+	assert.Equal(t, thirdUUID, lost.UUID)
+	assert.Equal(t, address, lost.Owner)
+	assert.Equal(t, 10, lost.Line)
+	// The stored resource is not targeted by a link or capability
+
+	require.Len(t, report.Unlinked, 1)
+	unlinked := report.Unlinked[0]
+	assert.Equal(t, firstUUID, unlinked.UUID)
+	assert.Equal(t,
+		interpreter.PathValue{
+			Domain:     common.PathDomainStorage,
+			Identifier: "r1",
+		},
+		unlinked.Path,
+	)
+}
+
+func TestRuntimeResourceAuditorUnlinked(t *testing.T) {
+
+	t.Parallel()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+
+	const contract = `
+      pub contract Test {
+
+          pub resource R {}
+
+          pub fun createR(): @R {
+              return <-create R()
+          }
+      }
+    `
+
+	// The transaction stores resources under paths which are
+	// not linked, linked directly, linked through another link,
+	// targeted by a capability controller, and linked from a dangling link
+
+	const transaction = `
+      import Test from 0x1
+
+      transaction {
+          prepare(signer: AuthAccount) {
+              signer.save(<-Test.createR(), to: /storage/unlinked)
+
+              signer.save(<-Test.createR(), to: /storage/linked)
+              signer.link<&Test.R>(/public/linked, target: /storage/linked)
+
+              signer.save(<-Test.createR(), to: /storage/linkedIndirectly)
+              signer.link<&Test.R>(/private/linkedIndirectly, target: /storage/linkedIndirectly)
+              signer.link<&Test.R>(/public/linkedIndirectly, target: /private/linkedIndirectly)
+
+              signer.save(<-Test.createR(), to: /storage/controlled)
+              signer.capabilities.storage.issue<&Test.R>(/storage/controlled)
+
+              signer.save(<-[<-Test.createR()], to: /storage/collection)
+              signer.link<&Test.R>(/public/cycle1, target: /public/cycle2)
+              signer.link<&Test.R>(/public/cycle2, target: /public/cycle1)
+          }
+      }
+    `
+
+	ledger := newTestLedger(nil, nil)
+	accountCodes := map[Location][]byte{}
+
+	var uuid uint64
+	var accountID uint64
+
+	newRuntimeInterface := func() *testRuntimeInterface {
+		return &testRuntimeInterface{
+			storage: ledger,
+			getSigningAccounts: func() ([]Address, error) {
+				return []Address{address}, nil
+			},
+			resolveLocation: singleIdentifierLocationResolver(t),
+			updateAccountContractCode: func(location common.AddressLocation, code []byte) error {
+				accountCodes[location] = code
+				return nil
+			},
+			getAccountContractCode: func(location common.AddressLocation) ([]byte, error) {
+				return accountCodes[location], nil
+			},
+			emitEvent: func(event cadence.Event) error {
+				return nil
+			},
+			generateUUID: func() (uint64, error) {
+				uuid++
+				return uuid, nil
+			},
+			generateAccountID: func(_ common.Address) (uint64, error) {
+				accountID++
+				return accountID, nil
+			},
+		}
+	}
+
+	runtime := NewInterpreterRuntime(Config{
+		CapabilityControllersEnabled: true,
+	})
+
+	nextTransactionLocation := newTransactionLocationGenerator()
+
+	err := runtime.ExecuteTransaction(
+		Script{
+			Source: DeploymentTransaction("Test", []byte(contract)),
+		},
+		Context{
+			Interface: newRuntimeInterface(),
+			Location:  nextTransactionLocation(),
+		},
+	)
+	require.NoError(t, err)
+
+	auditor := NewResourceAuditor()
+
+	err = runtime.ExecuteTransaction(
+		Script{
+			Source: []byte(transaction),
+		},
+		Context{
+			Interface:       newRuntimeInterface(),
+			Location:        nextTransactionLocation(),
+			ResourceAuditor: auditor,
+		},
+	)
+	require.NoError(t, err)
+
+	report := auditor.Report()
+
+	require.Len(t, report.Created, 5)
+	assert.Empty(t, report.Lost)
+
+	unlinkedIdentifiers := map[uint64]string{}
+	for _, unlinked := range report.Unlinked {
+		assert.Equal(t, address, unlinked.Owner)
+		assert.Equal(t, common.PathDomainStorage, unlinked.Path.Domain)
+		unlinkedIdentifiers[unlinked.UUID] = unlinked.Path.Identifier
+	}
+
+	assert.Equal(t,
+		map[uint64]string{
+			report.Created[0].UUID: "unlinked",
+			report.Created[4].UUID: "collection",
+		},
+		unlinkedIdentifiers,
+	)
+}
+
+func TestRuntimeResourceAuditorHandlers(t *testing.T) {
+
+	t.Parallel()
+
+	environment := NewBaseInterpreterEnvironment(Config{})
+	config := environment.InterpreterConfig
+
+	environment.Configure(&testRuntimeInterface{}, NewCodesAndPrograms(), nil, nil)
+
+	assert.Nil(t, config.OnResourceCreated)
+	assert.Nil(t, config.OnResourceMoved)
+	assert.Nil(t, config.OnResourceDestroyed)
+
+	configureResourceAuditor(environment, NewResourceAuditor())
+
+	assert.NotNil(t, config.OnResourceCreated)
+	assert.NotNil(t, config.OnResourceMoved)
+	assert.NotNil(t, config.OnResourceDestroyed)
+
+	// Reconfiguring the environment for the next execution removes the auditor
+
+	environment.Configure(&testRuntimeInterface{}, NewCodesAndPrograms(), nil, nil)
+
+	assert.Nil(t, environment.resourceAuditor)
+	assert.Nil(t, config.OnResourceCreated)
+	assert.Nil(t, config.OnResourceMoved)
+	assert.Nil(t, config.OnResourceDestroyed)
+}
@@ -151,6 +151,11 @@ type Runtime interface {
 	//
 	ReadLinked(address common.Address, path cadence.Path, context Context) (cadence.Value, error)
 
+	// StorageUsage returns the amount of storage used by each value stored in the storage domain of the account,
+	// broken down by the nested fields of the values.
+	//
+	StorageUsage(address common.Address, context Context) ([]*interpreter.StorageUsage, error)
+
 	// Storage returns the storage system and an interpreter which can be used for
 	// accessing values in storage.
 	//
@@ -566,6 +571,7 @@ func (r *interpreterRuntime) Storage(context Context) (*Storage, *interpreter.In
 		storage,
 		context.CoverageReport,
 	)
+	configureResourceAuditor(environment, context.ResourceAuditor)
 
 	_, inter, err := environment.Interpret(
 		location,
@@ -579,6 +585,51 @@ func (r *interpreterRuntime) Storage(context Context) (*Storage, *interpreter.In
 	return storage, inter, nil
 }
 
+func (r *interpreterRuntime) StorageUsage(
+	address common.Address,
+	context Context,
+) (
+	usages []*interpreter.StorageUsage,
+	err error,
+) {
+	location := context.Location
+
+	var codesAndPrograms CodesAndPrograms
+
+	defer r.Recover(
+		func(internalErr Error) {
+			err = internalErr
+		},
+		location,
+		codesAndPrograms,
+	)
+
+	storage, inter, err := r.Storage(context)
+	if err != nil {
+		// error is already wrapped as Error in Storage
+		return nil, err
+	}
+
+	domain := common.PathDomainStorage.Identifier()
+
+	storageMap := storage.GetStorageMap(address, domain, false)
+	if storageMap == nil {
+		return nil, nil
+	}
+
+	iterator := storageMap.Iterator(inter)
+	for key := iterator.NextKey(); key != nil; key = iterator.NextKey() {
+		// TODO: unfortunately, the iterator only returns an atree.Value, not a StorageMapKey
+		identifier := string(key.(interpreter.StringAtreeValue))
+		usages = append(
+			usages,
+			storage.StorageUsage(inter, address, domain, identifier),
+		)
+	}
+
+	return usages, nil
+}
+
 func (r *interpreterRuntime) ReadStored(
 	address common.Address,
 	path cadence.Path,
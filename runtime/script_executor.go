@@ -120,6 +120,7 @@ func (executor *interpreterScriptExecutor) preprocess() (err error) {
 		storage,
 		context.CoverageReport,
 	)
+	configureResourceAuditor(environment, context.ResourceAuditor)
 	executor.environment = environment
 
 	program, err := environment.ParseAndCheckProgram(
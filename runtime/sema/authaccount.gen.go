@@ -126,6 +126,14 @@ const AuthAccountTypeStoragePathsFieldDocString = `
 All storage paths of this account.
 `
 
+const AuthAccountTypeStoredCountFieldName = "storedCount"
+
+var AuthAccountTypeStoredCountFieldType = UInt64Type
+
+const AuthAccountTypeStoredCountFieldDocString = `
+The number of objects stored in the storage of this account.
+`
+
 const AuthAccountTypeAddPublicKeyFunctionName = "addPublicKey"
 
 var AuthAccountTypeAddPublicKeyFunctionType = &FunctionType{
@@ -237,6 +245,39 @@ If there is an object stored, the type of the object is returned without modifyi
 The path must be a storage path, i.e., only the domain ` + "`storage`" + ` is allowed.
 `
 
+const AuthAccountTypeStorageUsageFunctionName = "storageUsage"
+
+var AuthAccountTypeStorageUsageFunctionType = &FunctionType{
+	Parameters: []Parameter{
+		{
+			Label:          "at",
+			Identifier:     "path",
+			TypeAnnotation: NewTypeAnnotation(StoragePathType),
+		},
+	},
+	ReturnTypeAnnotation: NewTypeAnnotation(
+		&OptionalType{
+			Type: &DictionaryType{
+				KeyType:   StringType,
+				ValueType: UInt64Type,
+			},
+		},
+	),
+}
+
+const AuthAccountTypeStorageUsageFunctionDocString = `
+Returns the amount of storage used by the object stored under the given path, in bytes,
+or nil if no object is stored under the given path.
+
+The usage is broken down by the nested fields and collections of the stored object.
+The keys of the returned dictionary are the path of the stored object itself (e.g. ` + "`/storage/collection`" + `),
+the paths of its nested fields (e.g. ` + "`/storage/collection.ownedNFTs`" + `),
+and the paths of the elements of its nested arrays and dictionaries (e.g. ` + "`/storage/collection.ownedNFTs[42]`" + `).
+The usage of an object includes the usage of all its nested fields and collections.
+
+The path must be a storage path, i.e., only the domain ` + "`storage`" + ` is allowed.
+`
+
 const AuthAccountTypeLoadFunctionName = "load"
 
 var AuthAccountTypeLoadFunctionTypeParameterT = &TypeParameter{
@@ -687,6 +728,92 @@ then the callback must stop iteration by returning false.
 Otherwise, iteration aborts.
 `
 
+const AuthAccountTypeGetStoragePathsFunctionName = "getStoragePaths"
+
+var AuthAccountTypeGetStoragePathsFunctionType = &FunctionType{
+	Parameters: []Parameter{
+		{
+			Identifier: "after",
+			TypeAnnotation: NewTypeAnnotation(&OptionalType{
+				Type: StoragePathType,
+			}),
+		},
+		{
+			Identifier:     "limit",
+			TypeAnnotation: NewTypeAnnotation(IntType),
+		},
+	},
+	ReturnTypeAnnotation: NewTypeAnnotation(
+		&VariableSizedType{
+			Type: StoragePathType,
+		},
+	),
+}
+
+const AuthAccountTypeGetStoragePathsFunctionDocString = `
+Returns at most ` + "`limit`" + ` storage paths of this account,
+starting after the given path, or at the first stored path if no path is given.
+
+The paths are returned in a deterministic order,
+the same order in which ` + "`storagePaths`" + ` and ` + "`forEachStored`" + ` return them.
+
+To enumerate all storage paths page by page,
+pass the last path of the previous result as the ` + "`after`" + ` argument,
+until fewer than ` + "`limit`" + ` paths are returned.
+
+A page starts directly at the position of the ` + "`after`" + ` path,
+so the computation of a page does not grow with the position of the ` + "`after`" + ` path,
+even if the pages are requested by separate transactions or scripts.
+
+If the given ` + "`after`" + ` path does not store an object, for example because it was removed,
+the page starts at the path which follows it in the same order.
+The given limit must not be negative.
+`
+
+const AuthAccountTypeGetStoragePathsOfTypeFunctionName = "getStoragePathsOfType"
+
+var AuthAccountTypeGetStoragePathsOfTypeFunctionTypeParameterT = &TypeParameter{
+	Name:      "T",
+	TypeBound: AnyType,
+}
+
+var AuthAccountTypeGetStoragePathsOfTypeFunctionType = &FunctionType{
+	TypeParameters: []*TypeParameter{
+		AuthAccountTypeGetStoragePathsOfTypeFunctionTypeParameterT,
+	},
+	Parameters: []Parameter{
+		{
+			Identifier: "after",
+			TypeAnnotation: NewTypeAnnotation(&OptionalType{
+				Type: StoragePathType,
+			}),
+		},
+		{
+			Identifier:     "limit",
+			TypeAnnotation: NewTypeAnnotation(IntType),
+		},
+	},
+	ReturnTypeAnnotation: NewTypeAnnotation(
+		&VariableSizedType{
+			Type: StoragePathType,
+		},
+	),
+}
+
+const AuthAccountTypeGetStoragePathsOfTypeFunctionDocString = `
+Returns at most ` + "`limit`" + ` storage paths of this account
+which store an object that is a subtype of the given type,
+starting after the given path, or at the first stored path if no path is given.
+
+The paths are returned in the same deterministic order as ` + "`getStoragePaths`" + `.
+Objects which have a type that cannot be loaded are skipped.
+The computation of a page is the same as for ` + "`getStoragePaths`" + `.
+
+If the given ` + "`after`" + ` path does not store an object,
+the page starts at the path which follows it in the same order.
+The given limit must not be negative.
+`
+
 const AuthAccountContractsTypeNamesFieldName = "names"
 
 var AuthAccountContractsTypeNamesFieldType = &VariableSizedType{
@@ -1932,6 +2059,14 @@ func init() {
 			AuthAccountTypeStoragePathsFieldType,
 			AuthAccountTypeStoragePathsFieldDocString,
 		),
+		NewUnmeteredFieldMember(
+			AuthAccountType,
+			ast.AccessPublic,
+			ast.VariableKindConstant,
+			AuthAccountTypeStoredCountFieldName,
+			AuthAccountTypeStoredCountFieldType,
+			AuthAccountTypeStoredCountFieldDocString,
+		),
 		NewUnmeteredFunctionMember(
 			AuthAccountType,
 			ast.AccessPublic,
@@ -1960,6 +2095,13 @@ func init() {
 			AuthAccountTypeTypeFunctionType,
 			AuthAccountTypeTypeFunctionDocString,
 		),
+		NewUnmeteredFunctionMember(
+			AuthAccountType,
+			ast.AccessPublic,
+			AuthAccountTypeStorageUsageFunctionName,
+			AuthAccountTypeStorageUsageFunctionType,
+			AuthAccountTypeStorageUsageFunctionDocString,
+		),
 		NewUnmeteredFunctionMember(
 			AuthAccountType,
 			ast.AccessPublic,
@@ -2044,6 +2186,20 @@ func init() {
 			AuthAccountTypeForEachStoredFunctionType,
 			AuthAccountTypeForEachStoredFunctionDocString,
 		),
+		NewUnmeteredFunctionMember(
+			AuthAccountType,
+			ast.AccessPublic,
+			AuthAccountTypeGetStoragePathsFunctionName,
+			AuthAccountTypeGetStoragePathsFunctionType,
+			AuthAccountTypeGetStoragePathsFunctionDocString,
+		),
+		NewUnmeteredFunctionMember(
+			AuthAccountType,
+			ast.AccessPublic,
+			AuthAccountTypeGetStoragePathsOfTypeFunctionName,
+			AuthAccountTypeGetStoragePathsOfTypeFunctionType,
+			AuthAccountTypeGetStoragePathsOfTypeFunctionDocString,
+		),
 	}
 
 	AuthAccountType.Members = MembersAsMap(members)
@@ -0,0 +1,565 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+const (
+	cadenceImportPath = "github.com/onflow/cadence"
+	commonImportPath  = "github.com/onflow/cadence/runtime/common"
+	fmtImportPath     = "fmt"
+)
+
+// goSimpleTypes maps the IDs of simple Cadence types to the Go types of their values.
+// Go primitives are used where cadence.Marshal infers the same Cadence type,
+// otherwise the Cadence value types are used.
+var goSimpleTypes = map[sema.TypeID]string{
+	sema.BoolType.ID():           "bool",
+	sema.StringType.ID():         "string",
+	sema.CharacterType.ID():      "cadence.Character",
+	sema.TheAddressType.ID():     "cadence.Address",
+	sema.IntType.ID():            "cadence.Int",
+	sema.Int8Type.ID():           "int8",
+	sema.Int16Type.ID():          "int16",
+	sema.Int32Type.ID():          "int32",
+	sema.Int64Type.ID():          "int64",
+	sema.Int128Type.ID():         "cadence.Int128",
+	sema.Int256Type.ID():         "cadence.Int256",
+	sema.UIntType.ID():           "cadence.UInt",
+	sema.UInt8Type.ID():          "uint8",
+	sema.UInt16Type.ID():         "uint16",
+	sema.UInt32Type.ID():         "uint32",
+	sema.UInt64Type.ID():         "uint64",
+	sema.UInt128Type.ID():        "cadence.UInt128",
+	sema.UInt256Type.ID():        "cadence.UInt256",
+	sema.Word8Type.ID():          "cadence.Word8",
+	sema.Word16Type.ID():         "cadence.Word16",
+	sema.Word32Type.ID():         "cadence.Word32",
+	sema.Word64Type.ID():         "cadence.Word64",
+	sema.Word128Type.ID():        "cadence.Word128",
+	sema.Word256Type.ID():        "cadence.Word256",
+	sema.Fix64Type.ID():          "cadence.Fix64",
+	sema.UFix64Type.ID():         "cadence.UFix64",
+	sema.PathType.ID():           "cadence.Path",
+	sema.StoragePathType.ID():    "cadence.Path",
+	sema.CapabilityPathType.ID(): "cadence.Path",
+	sema.PublicPathType.ID():     "cadence.Path",
+	sema.PrivatePathType.ID():    "cadence.Path",
+}
+
+// goEnumRawTypes are the Go types which can be used as the underlying type of a generated enum type.
+var goEnumRawTypes = map[string]struct{}{
+	"int8":           {},
+	"int16":          {},
+	"int32":          {},
+	"int64":          {},
+	"uint8":          {},
+	"uint16":         {},
+	"uint32":         {},
+	"uint64":         {},
+	"cadence.Word8":  {},
+	"cadence.Word16": {},
+	"cadence.Word32": {},
+	"cadence.Word64": {},
+}
+
+const goValueType = "cadence.Value"
+
+// boundType is a composite type declared in a contract, for which a Go type is generated.
+type boundType struct {
+	compositeType *sema.CompositeType
+	declaration   *ast.CompositeDeclaration
+	goName        string
+}
+
+type generator struct {
+	inputs      []*input
+	boundTypes  map[sema.TypeID]*boundType
+	imports     map[string]struct{}
+	packageName string
+	buf         bytes.Buffer
+}
+
+func gen(inPaths []string, address common.Address, packageName string, out io.Writer) error {
+	for _, path := range inPaths {
+		if filepath.Ext(path) == ".json" {
+			return fmt.Errorf("type inputs are only supported for TypeScript: %s", path)
+		}
+	}
+
+	loader := newLoader(address)
+
+	err := loader.load(inPaths)
+	if err != nil {
+		return err
+	}
+
+	err = loader.check()
+	if err != nil {
+		return err
+	}
+
+	g := &generator{
+		inputs:      loader.inputs,
+		boundTypes:  map[sema.TypeID]*boundType{},
+		imports:     map[string]struct{}{},
+		packageName: packageName,
+	}
+
+	return g.generate(out)
+}
+
+func (g *generator) generate(out io.Writer) error {
+
+	// Bind all composite types declared in contracts first,
+	// so they can be referred to from any input
+
+	for _, input := range g.inputs {
+		if !input.isContract() {
+			continue
+		}
+		for _, boundType := range contractTypes(input) {
+			g.boundTypes[boundType.compositeType.ID()] = boundType
+		}
+	}
+
+	for _, input := range g.inputs {
+		if input.isContract() {
+			g.writeContract(input)
+		} else {
+			g.writeArguments(input)
+		}
+	}
+
+	source, err := format.Source(g.fileSource())
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(source)
+	return err
+}
+
+func (g *generator) fileSource() []byte {
+	var file bytes.Buffer
+
+	paths := make([]string, len(g.inputs))
+	for i, input := range g.inputs {
+		paths[i] = filepath.ToSlash(input.path)
+	}
+
+	fmt.Fprintf(&file, "// Code generated by bindgen from %s. DO NOT EDIT.\n\n", strings.Join(paths, ", "))
+	fmt.Fprintf(&file, "package %s\n\n", g.packageName)
+
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			imports = append(imports, path)
+		}
+		sort.Strings(imports)
+
+		file.WriteString("import (\n")
+		for _, path := range imports {
+			// Separate the standard library imports from the others
+			if path == cadenceImportPath {
+				if _, ok := g.imports[fmtImportPath]; ok {
+					file.WriteByte('\n')
+				}
+			}
+			fmt.Fprintf(&file, "%q\n", path)
+		}
+		file.WriteString(")\n\n")
+	}
+
+	file.Write(g.buf.Bytes())
+
+	return file.Bytes()
+}
+
+func (g *generator) use(importPath string) {
+	g.imports[importPath] = struct{}{}
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// contractTypes returns the composite types declared in the given contract input,
+// for which bindings are generated, in declaration order.
+func contractTypes(input *input) []*boundType {
+	contract := input.program.SoleContractDeclaration()
+	return nestedBoundTypes(input.checker.Elaboration, contract)
+}
+
+func nestedBoundTypes(elaboration *sema.Elaboration, declaration *ast.CompositeDeclaration) []*boundType {
+	var result []*boundType
+	for _, nestedDeclaration := range declaration.Members.Composites() {
+		compositeType := elaboration.CompositeDeclarationType(nestedDeclaration)
+
+		switch compositeType.Kind {
+		case common.CompositeKindStructure,
+			common.CompositeKindResource,
+			common.CompositeKindEvent,
+			common.CompositeKindEnum:
+
+			result = append(result, &boundType{
+				compositeType: compositeType,
+				declaration:   nestedDeclaration,
+				goName:        goIdentifier(compositeType.QualifiedIdentifier()),
+			})
+		}
+
+		result = append(result, nestedBoundTypes(elaboration, nestedDeclaration)...)
+	}
+	return result
+}
+
+func (g *generator) writeContract(input *input) {
+	contract := input.program.SoleContractDeclaration()
+	boundTypes := contractTypes(input)
+	if len(boundTypes) == 0 {
+		return
+	}
+
+	g.printf("// Type IDs of the types declared in the contract `%s`\n", contract.Identifier.Identifier)
+	g.printf("const (\n")
+	for _, boundType := range boundTypes {
+		g.printf("%sTypeID = %q\n", boundType.goName, boundType.compositeType.ID())
+	}
+	g.printf(")\n\n")
+
+	for _, boundType := range boundTypes {
+		if boundType.compositeType.Kind == common.CompositeKindEnum {
+			g.writeEnum(boundType)
+		} else {
+			g.writeComposite(boundType)
+		}
+	}
+}
+
+func (g *generator) writeComposite(boundType *boundType) {
+	g.use(cadenceImportPath)
+	g.use(commonImportPath)
+	g.use(fmtImportPath)
+
+	compositeType := boundType.compositeType
+	goName := boundType.goName
+	kind := compositeType.Kind.Name()
+
+	g.printf(
+		"// %s is the Go representation of the %s `%s`.\n",
+		goName,
+		kind,
+		compositeType.QualifiedIdentifier(),
+	)
+	g.printf("type %s struct {\n", goName)
+	for _, fieldName := range compositeType.Fields {
+		member, ok := compositeType.Members.Get(fieldName)
+		if !ok || member.IgnoreInSerialization {
+			continue
+		}
+		g.printf(
+			"%s %s `cadence:\"%s\"`\n",
+			goFieldName(fieldName),
+			g.goType(member.TypeAnnotation.Type),
+			fieldName,
+		)
+	}
+	g.printf("}\n\n")
+
+	g.printf("// CadenceCompositeType returns the Cadence type of %s.\n", goName)
+	g.printf("func (%s) CadenceCompositeType() cadence.CompositeType {\n", goName)
+	g.printf("return &cadence.%s{\n", cadenceCompositeTypeName(compositeType.Kind))
+	g.writeLocationField(compositeType.Location)
+	g.printf("QualifiedIdentifier: %q,\n", compositeType.QualifiedIdentifier())
+	g.printf("}\n")
+	g.printf("}\n\n")
+
+	g.printf("// ToCadence returns the Cadence value of v.\n")
+	g.printf("func (v %s) ToCadence() (cadence.Value, error) {\n", goName)
+	g.printf("return cadence.Marshal(v)\n")
+	g.printf("}\n\n")
+
+	g.printf("// FromCadence sets v to the given Cadence value of the %s `%s`.\n",
+		kind,
+		compositeType.QualifiedIdentifier(),
+	)
+	g.printf("func (v *%s) FromCadence(value cadence.Value) error {\n", goName)
+	g.printf("if typ := value.Type(); typ == nil || typ.ID() != %sTypeID {\n", goName)
+	g.printf("return fmt.Errorf(\"expected value of type %%s, got %%s\", %sTypeID, value)\n", goName)
+	g.printf("}\n")
+	g.printf("return cadence.Unmarshal(value, v)\n")
+	g.printf("}\n\n")
+}
+
+func (g *generator) writeEnum(boundType *boundType) {
+	g.use(cadenceImportPath)
+	g.use(commonImportPath)
+	g.use(fmtImportPath)
+
+	compositeType := boundType.compositeType
+	goName := boundType.goName
+
+	rawType := compositeType.EnumRawType
+	goRawType := g.goType(rawType)
+	if _, ok := goEnumRawTypes[goRawType]; !ok {
+		panic(fmt.Errorf(
+			"enum %s has unsupported raw type %s",
+			compositeType.QualifiedIdentifier(),
+			rawType,
+		))
+	}
+
+	g.printf(
+		"// %s is the Go representation of the enum `%s`.\n",
+		goName,
+		compositeType.QualifiedIdentifier(),
+	)
+	g.printf("type %s %s\n\n", goName, goRawType)
+
+	enumCases := boundType.declaration.Members.EnumCases()
+	if len(enumCases) > 0 {
+		g.printf("const (\n")
+		for i, enumCase := range enumCases {
+			g.printf(
+				"%s%s %s = %d\n",
+				goName,
+				initialUpper(enumCase.Identifier.Identifier),
+				goName,
+				i,
+			)
+		}
+		g.printf(")\n\n")
+	}
+
+	g.printf("// CadenceCompositeType returns the Cadence type of %s.\n", goName)
+	g.printf("func (%s) CadenceCompositeType() cadence.CompositeType {\n", goName)
+	g.printf("return &cadence.EnumType{\n")
+	g.writeLocationField(compositeType.Location)
+	g.printf("QualifiedIdentifier: %q,\n", compositeType.QualifiedIdentifier())
+	g.printf("RawType: cadence.The%sType,\n", rawType.ID())
+	g.printf("Fields: []cadence.Field{\n")
+	g.printf("{Identifier: %q, Type: cadence.The%sType},\n", sema.EnumRawValueFieldName, rawType.ID())
+	g.printf("},\n")
+	g.printf("}\n")
+	g.printf("}\n\n")
+
+	g.printf("// MarshalCadence returns the Cadence value of v.\n")
+	g.printf("func (v %s) MarshalCadence() (cadence.Value, error) {\n", goName)
+	g.printf("rawValue, err := cadence.Marshal(%s(v))\n", goRawType)
+	g.printf("if err != nil {\n")
+	g.printf("return nil, err\n")
+	g.printf("}\n")
+	g.printf("enumType := v.CadenceCompositeType().(*cadence.EnumType)\n")
+	g.printf("return cadence.NewEnum([]cadence.Value{rawValue}).WithType(enumType), nil\n")
+	g.printf("}\n\n")
+
+	g.printf("// UnmarshalCadence sets v to the given Cadence value of the enum `%s`.\n",
+		compositeType.QualifiedIdentifier(),
+	)
+	g.printf("func (v *%s) UnmarshalCadence(value cadence.Value) error {\n", goName)
+	g.printf("enum, ok := value.(cadence.Enum)\n")
+	g.printf("if !ok || enum.EnumType == nil || enum.EnumType.ID() != %sTypeID {\n", goName)
+	g.printf("return fmt.Errorf(\"expected value of type %%s, got %%s\", %sTypeID, value)\n", goName)
+	g.printf("}\n")
+	g.printf("rawValue := cadence.GetFieldByName(enum, %q)\n", sema.EnumRawValueFieldName)
+	g.printf("return cadence.Unmarshal(rawValue, (*%s)(v))\n", goRawType)
+	g.printf("}\n\n")
+}
+
+func (g *generator) writeLocationField(location common.Location) {
+	addressLocation, ok := location.(common.AddressLocation)
+	if !ok {
+		panic(fmt.Errorf("unsupported location %s", location))
+	}
+
+	g.printf("Location: common.AddressLocation{\n")
+	g.printf("Address: %#v,\n", addressLocation.Address)
+	g.printf("Name: %q,\n", addressLocation.Name)
+	g.printf("},\n")
+}
+
+func (g *generator) writeArguments(input *input) {
+	g.use(cadenceImportPath)
+
+	kind := "script"
+	if input.isTransaction() {
+		kind = "transaction"
+	}
+
+	_, fileName := filepath.Split(input.path)
+	functionName := goIdentifier(strings.TrimSuffix(fileName, filepath.Ext(fileName))) + "Arguments"
+
+	parameters := input.checker.EntryPointParameters()
+
+	goParameterNames := make([]string, len(parameters))
+	for i, parameter := range parameters {
+		goParameterNames[i] = goParameterName(parameter.Identifier)
+	}
+
+	g.printf("// %s returns the arguments for the %s `%s`.\n", functionName, kind, fileName)
+	g.printf("func %s(", functionName)
+	// Put each parameter on its own line if there are many
+	multiline := len(parameters) > 3
+	for i, parameter := range parameters {
+		if multiline {
+			g.printf("\n")
+		} else if i > 0 {
+			g.printf(", ")
+		}
+		g.printf("%s %s", goParameterNames[i], g.goType(parameter.TypeAnnotation.Type))
+		if multiline {
+			g.printf(",")
+		}
+	}
+	if multiline {
+		g.printf("\n")
+	}
+	g.printf(") ([]cadence.Value, error) {\n")
+
+	if len(parameters) == 0 {
+		g.printf("return []cadence.Value{}, nil\n")
+		g.printf("}\n\n")
+		return
+	}
+
+	g.use(fmtImportPath)
+
+	g.printf("arguments := make([]cadence.Value, %d)\n", len(parameters))
+	g.printf("var err error\n")
+	for i, parameter := range parameters {
+		g.printf("arguments[%d], err = cadence.Marshal(%s)\n", i, goParameterNames[i])
+		g.printf("if err != nil {\n")
+		g.printf(
+			"return nil, fmt.Errorf(\"invalid argument %s: %%w\", err)\n",
+			parameter.Identifier,
+		)
+		g.printf("}\n")
+	}
+	g.printf("return arguments, nil\n")
+	g.printf("}\n\n")
+}
+
+// goType returns the Go type for values of the given Cadence type.
+// Values of types which have no specific Go representation are cadence.Value.
+func (g *generator) goType(ty sema.Type) string {
+	switch ty := ty.(type) {
+	case *sema.OptionalType:
+		innerType := g.goType(ty.Type)
+		if innerType == goValueType {
+			return goValueType
+		}
+		return "*" + innerType
+
+	case *sema.VariableSizedType:
+		return "[]" + g.goType(ty.Type)
+
+	case *sema.ConstantSizedType:
+		return fmt.Sprintf("[%d]%s", ty.Size, g.goType(ty.Type))
+
+	case *sema.DictionaryType:
+		return fmt.Sprintf("map[%s]%s", g.goType(ty.KeyType), g.goType(ty.ValueType))
+
+	case *sema.CompositeType:
+		if boundType, ok := g.boundTypes[ty.ID()]; ok {
+			return boundType.goName
+		}
+	}
+
+	if goType, ok := goSimpleTypes[ty.ID()]; ok {
+		return goType
+	}
+
+	return goValueType
+}
+
+func cadenceCompositeTypeName(kind common.CompositeKind) string {
+	switch kind {
+	case common.CompositeKindStructure:
+		return "StructType"
+	case common.CompositeKindResource:
+		return "ResourceType"
+	case common.CompositeKindEvent:
+		return "EventType"
+	}
+	panic(fmt.Errorf("unsupported composite kind %s", kind))
+}
+
+func initialUpper(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(rune(s[0]))) + s[1:]
+}
+
+// goIdentifier turns the given qualified identifier or file name into an exported Go identifier,
+// e.g. `Token.Vault` into `TokenVault`, and `get_balance` into `GetBalance`.
+func goIdentifier(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, part := range parts {
+		b.WriteString(initialUpper(part))
+	}
+
+	identifier := b.String()
+	if identifier == "" || unicode.IsDigit(rune(identifier[0])) {
+		identifier = "X" + identifier
+	}
+	return identifier
+}
+
+func goFieldName(name string) string {
+	switch name {
+	case sema.ResourceUUIDFieldName:
+		return "UUID"
+	case "id":
+		return "ID"
+	}
+	return initialUpper(name)
+}
+
+func goParameterName(name string) string {
+	switch name {
+	case "arguments", "err", "cadence", "fmt":
+		return name + "_"
+	}
+	// Avoid shadowing predeclared identifiers, like the type `string`
+	if token.IsKeyword(name) || types.Universe.Lookup(name) != nil {
+		return name + "_"
+	}
+	return name
+}
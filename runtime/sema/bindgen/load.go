@@ -0,0 +1,245 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/sema"
+	"github.com/onflow/cadence/runtime/stdlib"
+)
+
+// programError is an error in a Cadence input file,
+// which is pretty-printed with the code of all input files.
+type programError struct {
+	err      error
+	location common.Location
+	codes    map[common.Location][]byte
+}
+
+func (e *programError) Error() string {
+	return e.err.Error()
+}
+
+func (e *programError) Unwrap() error {
+	return e.err
+}
+
+// input is a Cadence file the bindings are generated for.
+type input struct {
+	path     string
+	location common.Location
+	program  *ast.Program
+	checker  *sema.Checker
+	checking bool
+}
+
+func (i *input) isContract() bool {
+	return i.program.SoleContractDeclaration() != nil
+}
+
+func (i *input) isTransaction() bool {
+	return i.program.SoleTransactionDeclaration() != nil
+}
+
+type loader struct {
+	address    common.Address
+	inputs     []*input
+	byLocation map[common.Location]*input
+	codes      map[common.Location][]byte
+	config     *sema.Config
+}
+
+func newLoader(address common.Address) *loader {
+	l := &loader{
+		address:    address,
+		byLocation: map[common.Location]*input{},
+		codes:      map[common.Location][]byte{},
+	}
+
+	baseValueActivation := sema.NewVariableActivation(sema.BaseValueActivation)
+	for _, value := range stdlib.DefaultScriptStandardLibraryValues(nil) {
+		baseValueActivation.DeclareValue(value)
+	}
+
+	l.config = &sema.Config{
+		AccessCheckMode: sema.AccessCheckModeStrict,
+		BaseValueActivationHandler: func(_ common.Location) *sema.VariableActivation {
+			return baseValueActivation
+		},
+		LocationHandler: l.resolveLocation,
+		ImportHandler:   l.resolveImport,
+	}
+
+	return l
+}
+
+// load parses the Cadence files at the given paths.
+// Contracts are located at the address of the loader,
+// all other programs at their path.
+func (l *loader) load(paths []string) error {
+	for _, path := range paths {
+		code, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var location common.Location = common.StringLocation(path)
+		l.codes[location] = code
+
+		program, err := parser.ParseProgram(nil, code, parser.Config{})
+		if err != nil {
+			return &programError{
+				err:      err,
+				location: location,
+				codes:    l.codes,
+			}
+		}
+
+		if contract := program.SoleContractDeclaration(); contract != nil {
+			if l.address == common.ZeroAddress {
+				return fmt.Errorf("cannot generate bindings for contract in %s: missing address", path)
+			}
+			delete(l.codes, location)
+			location = common.AddressLocation{
+				Address: l.address,
+				Name:    contract.Identifier.Identifier,
+			}
+			l.codes[location] = code
+		}
+
+		if _, ok := l.byLocation[location]; ok {
+			return fmt.Errorf("duplicate input %s", location)
+		}
+
+		input := &input{
+			path:     path,
+			location: location,
+			program:  program,
+		}
+		l.inputs = append(l.inputs, input)
+		l.byLocation[location] = input
+	}
+
+	return nil
+}
+
+// check checks all inputs.
+// Imported inputs are checked before the importing input.
+func (l *loader) check() error {
+	for _, input := range l.inputs {
+		err := l.checkInput(input)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *loader) checkInput(input *input) error {
+	if input.checker != nil {
+		return nil
+	}
+
+	checker, err := sema.NewChecker(input.program, input.location, nil, l.config)
+	if err != nil {
+		return err
+	}
+
+	input.checking = true
+	err = checker.Check()
+	input.checking = false
+	if err != nil {
+		return &programError{
+			err:      err,
+			location: input.location,
+			codes:    l.codes,
+		}
+	}
+
+	input.checker = checker
+	return nil
+}
+
+func (l *loader) resolveLocation(
+	identifiers []ast.Identifier,
+	location common.Location,
+) ([]sema.ResolvedLocation, error) {
+
+	addressLocation, ok := location.(common.AddressLocation)
+	if !ok || len(identifiers) == 0 {
+		return []sema.ResolvedLocation{
+			{
+				Location:    location,
+				Identifiers: identifiers,
+			},
+		}, nil
+	}
+
+	// Each identifier imported from an address is a contract
+	resolvedLocations := make([]sema.ResolvedLocation, len(identifiers))
+	for i, identifier := range identifiers {
+		resolvedLocations[i] = sema.ResolvedLocation{
+			Location: common.AddressLocation{
+				Address: addressLocation.Address,
+				Name:    identifier.Identifier,
+			},
+			Identifiers: []ast.Identifier{identifier},
+		}
+	}
+	return resolvedLocations, nil
+}
+
+func (l *loader) resolveImport(
+	_ *sema.Checker,
+	importedLocation common.Location,
+	importRange ast.Range,
+) (sema.Import, error) {
+
+	if importedLocation == stdlib.CryptoCheckerLocation {
+		return sema.ElaborationImport{
+			Elaboration: stdlib.CryptoChecker().Elaboration,
+		}, nil
+	}
+
+	input, ok := l.byLocation[importedLocation]
+	if !ok {
+		return nil, fmt.Errorf("cannot import %s: not an input", importedLocation)
+	}
+
+	if input.checking {
+		return nil, &sema.CyclicImportsError{
+			Location: importedLocation,
+			Range:    importRange,
+		}
+	}
+
+	err := l.checkInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return sema.ElaborationImport{
+		Elaboration: input.checker.Elaboration,
+	}, nil
+}
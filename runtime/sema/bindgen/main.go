@@ -0,0 +1,96 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// bindgen generates typed Go or TypeScript bindings for Cadence contracts, scripts and transactions.
+//
+// Contracts are checked as if they were deployed to the given address.
+// For each struct, resource, event and enum declared in a contract,
+// a Go type with conversion methods and a type ID constant is generated.
+// For each script and transaction, a constructor for the arguments is generated.
+// Scripts and transactions may import the given contracts from the given address.
+//
+// With -lang typescript, TypeScript definitions for the JSON-Cadence encoding
+// of these types are generated instead, together with decoders and encoders.
+// In this mode, inputs may also be JSON-Cadence encoded type values (.json files),
+// for which a type alias is generated.
+// The generated TypeScript has no dependencies, but uses bigint, so it requires ES2020.
+//
+// Usage: bindgen -address 0x1 -package bindings output.go Contract.cdc script.cdc ...
+//
+//	bindgen -lang typescript -address 0x1 output.ts Contract.cdc script.cdc Type.json ...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/pretty"
+)
+
+var addressFlag = flag.String("address", "", "the address the contracts are deployed to")
+var packageFlag = flag.String("package", "bindings", "the package name of the generated Go file")
+var langFlag = flag.String("lang", "go", "the language of the generated file: go or typescript")
+
+func main() {
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		panic("Missing path to output file")
+	}
+	if len(args) < 2 {
+		panic("Missing paths to input files")
+	}
+	outPath := args[0]
+	inPaths := args[1:]
+
+	var address common.Address
+	if *addressFlag != "" {
+		var err error
+		address, err = common.HexToAddress(*addressFlag)
+		if err != nil {
+			panic(fmt.Errorf("invalid address: %w", err))
+		}
+	}
+
+	if *langFlag != "go" && *langFlag != "typescript" {
+		panic(fmt.Errorf("unsupported language: %s", *langFlag))
+	}
+
+	outFile, err := os.Create(outPath)
+	if err != nil {
+		panic(err)
+	}
+	defer outFile.Close()
+
+	if *langFlag == "typescript" {
+		err = genTypeScript(inPaths, address, outFile)
+	} else {
+		err = gen(inPaths, address, *packageFlag, outFile)
+	}
+	if err != nil {
+		if programErr, ok := err.(*programError); ok {
+			printer := pretty.NewErrorPrettyPrinter(os.Stderr, true)
+			_ = printer.PrettyPrintError(programErr.err, programErr.location, programErr.codes)
+			os.Exit(1)
+		}
+		panic(err)
+	}
+}
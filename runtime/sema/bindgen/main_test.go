@@ -0,0 +1,90 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/cadence/runtime/common"
+)
+
+// Go treats directories named "testdata" specially
+const testDataDirectory = "testdata"
+
+// TestFiles finds all directories in the `testdata` directory.
+// Each directory turns into a test case, with all `.cdc` and `.json` files in the directory as the inputs.
+// Each directory is expected to have a "golden output" file, with the same path,
+// with the `.golden.go` extension for Go bindings, and/or the `.golden.ts` extension for TypeScript bindings.
+func TestFiles(t *testing.T) {
+
+	t.Parallel()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+
+	test := func(directoryPath string) {
+		_, testname := filepath.Split(directoryPath)
+
+		t.Run(testname, func(t *testing.T) {
+			t.Parallel()
+
+			programPaths, err := filepath.Glob(filepath.Join(directoryPath, "*.cdc"))
+			require.NoError(t, err)
+
+			typePaths, err := filepath.Glob(filepath.Join(directoryPath, "*.json"))
+			require.NoError(t, err)
+
+			goldenPath := filepath.Join(testDataDirectory, testname+".golden.go")
+			if want, err := os.ReadFile(goldenPath); err == nil {
+				var got bytes.Buffer
+				err = gen(programPaths, address, "bindings", &got)
+				require.NoError(t, err)
+
+				require.Equal(t, string(want), got.String())
+			} else {
+				require.ErrorIs(t, err, os.ErrNotExist)
+			}
+
+			goldenPath = filepath.Join(testDataDirectory, testname+".golden.ts")
+			if want, err := os.ReadFile(goldenPath); err == nil {
+				var got bytes.Buffer
+				err = genTypeScript(append(programPaths, typePaths...), address, &got)
+				require.NoError(t, err)
+
+				require.Equal(t, string(want), got.String())
+			} else {
+				require.ErrorIs(t, err, os.ErrNotExist)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(testDataDirectory)
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		test(filepath.Join(testDataDirectory, entry.Name()))
+	}
+}
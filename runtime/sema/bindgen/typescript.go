@@ -0,0 +1,983 @@
+/*
+ * Cadence - The resource-oriented smart contract programming language
+ *
+ * Copyright Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+// typeScriptPrelude declares the JSON-Cadence value shapes,
+// and the decoders and encoders for them, used by the generated code.
+//
+// Integers which fit into a JavaScript number are decoded to numbers,
+// all other integers are decoded to bigints, and fixed-point numbers to decimal strings.
+// Values of types without a specific representation are kept as JSON-Cadence values.
+const typeScriptPrelude = `export interface JsonCadenceVoid {
+  type: "Void"
+}
+
+export interface JsonCadenceOptional<T = JsonCadenceValue> {
+  type: "Optional"
+  value: T | null
+}
+
+export interface JsonCadenceBool {
+  type: "Bool"
+  value: boolean
+}
+
+export interface JsonCadenceString {
+  type: "String"
+  value: string
+}
+
+export interface JsonCadenceCharacter {
+  type: "Character"
+  value: string
+}
+
+export interface JsonCadenceAddress {
+  type: "Address"
+  value: string
+}
+
+export type JsonCadenceNumberType =
+  | "Int" | "Int8" | "Int16" | "Int32" | "Int64" | "Int128" | "Int256"
+  | "UInt" | "UInt8" | "UInt16" | "UInt32" | "UInt64" | "UInt128" | "UInt256"
+  | "Word8" | "Word16" | "Word32" | "Word64" | "Word128" | "Word256"
+  | "Fix64" | "UFix64"
+
+export interface JsonCadenceNumber<T extends JsonCadenceNumberType = JsonCadenceNumberType> {
+  type: T
+  value: string
+}
+
+export interface JsonCadenceArray<T = JsonCadenceValue> {
+  type: "Array"
+  value: T[]
+}
+
+export interface JsonCadenceDictionary<K = JsonCadenceValue, V = JsonCadenceValue> {
+  type: "Dictionary"
+  value: { key: K; value: V }[]
+}
+
+export type JsonCadenceCompositeKind = "Struct" | "Resource" | "Event" | "Contract" | "Enum" | "Attachment"
+
+export interface JsonCadenceField<N extends string = string, T = JsonCadenceValue> {
+  name: N
+  value: T
+}
+
+export interface JsonCadenceComposite<K extends JsonCadenceCompositeKind = JsonCadenceCompositeKind> {
+  type: K
+  value: {
+    id: string
+    fields: JsonCadenceField[]
+  }
+}
+
+export interface CadencePath {
+  domain: "storage" | "public" | "private"
+  identifier: string
+}
+
+export interface JsonCadencePath {
+  type: "Path"
+  value: CadencePath
+}
+
+export interface JsonCadenceTypeValue {
+  type: "Type"
+  value: { staticType: unknown }
+}
+
+export interface JsonCadenceCapability {
+  type: "Capability"
+  value: { path?: JsonCadencePath; address: string; borrowType: unknown; id?: string }
+}
+
+export interface JsonCadenceLink {
+  type: "Link"
+  value: { targetPath: JsonCadencePath; borrowType: string }
+}
+
+export interface JsonCadenceAccountLink {
+  type: "AccountLink"
+}
+
+export interface JsonCadenceFunction {
+  type: "Function"
+  value: { functionType: unknown }
+}
+
+export type JsonCadenceValue =
+  | JsonCadenceVoid
+  | JsonCadenceOptional
+  | JsonCadenceBool
+  | JsonCadenceString
+  | JsonCadenceCharacter
+  | JsonCadenceAddress
+  | JsonCadenceNumber
+  | JsonCadenceArray
+  | JsonCadenceDictionary
+  | JsonCadenceComposite
+  | JsonCadencePath
+  | JsonCadenceTypeValue
+  | JsonCadenceCapability
+  | JsonCadenceLink
+  | JsonCadenceAccountLink
+  | JsonCadenceFunction
+
+export type Decoder<T> = (json: JsonCadenceValue) => T
+
+export type Encoder<T, J extends JsonCadenceValue = JsonCadenceValue> = (value: T) => J
+
+function expectType(json: JsonCadenceValue, type: JsonCadenceValue["type"]): JsonCadenceValue {
+  if (json.type !== type) {
+    throw new Error(` + "`expected JSON-Cadence value of type ${type}, got ${json.type}`" + `)
+  }
+  return json
+}
+
+export function decodeValue(json: JsonCadenceValue): JsonCadenceValue {
+  return json
+}
+
+export function encodeValue(value: JsonCadenceValue): JsonCadenceValue {
+  return value
+}
+
+export function decodeBool(json: JsonCadenceValue): boolean {
+  return (expectType(json, "Bool") as JsonCadenceBool).value
+}
+
+export function encodeBool(value: boolean): JsonCadenceBool {
+  return { type: "Bool", value }
+}
+
+export function decodeString(json: JsonCadenceValue): string {
+  return (expectType(json, "String") as JsonCadenceString).value
+}
+
+export function encodeString(value: string): JsonCadenceString {
+  return { type: "String", value }
+}
+
+export function decodeCharacter(json: JsonCadenceValue): string {
+  return (expectType(json, "Character") as JsonCadenceCharacter).value
+}
+
+export function encodeCharacter(value: string): JsonCadenceCharacter {
+  return { type: "Character", value }
+}
+
+export function decodeAddress(json: JsonCadenceValue): string {
+  return (expectType(json, "Address") as JsonCadenceAddress).value
+}
+
+export function encodeAddress(value: string): JsonCadenceAddress {
+  return { type: "Address", value }
+}
+
+export function decodePath(json: JsonCadenceValue): CadencePath {
+  return (expectType(json, "Path") as JsonCadencePath).value
+}
+
+export function encodePath(value: CadencePath): JsonCadencePath {
+  return { type: "Path", value }
+}
+
+export function numberDecoder(type: JsonCadenceNumberType): Decoder<number> {
+  return (json) => Number((expectType(json, type) as JsonCadenceNumber).value)
+}
+
+export function numberEncoder<T extends JsonCadenceNumberType>(type: T): Encoder<number, JsonCadenceNumber<T>> {
+  return (value) => ({ type, value: value.toString() })
+}
+
+export function bigintDecoder(type: JsonCadenceNumberType): Decoder<bigint> {
+  return (json) => BigInt((expectType(json, type) as JsonCadenceNumber).value)
+}
+
+export function bigintEncoder<T extends JsonCadenceNumberType>(type: T): Encoder<bigint, JsonCadenceNumber<T>> {
+  return (value) => ({ type, value: value.toString() })
+}
+
+export function fixedPointDecoder(type: "Fix64" | "UFix64"): Decoder<string> {
+  return (json) => (expectType(json, type) as JsonCadenceNumber).value
+}
+
+export function fixedPointEncoder<T extends "Fix64" | "UFix64">(type: T): Encoder<string, JsonCadenceNumber<T>> {
+  return (value) => ({ type, value })
+}
+
+export const decodeInt = bigintDecoder("Int")
+export const encodeInt = bigintEncoder("Int")
+export const decodeInt8 = numberDecoder("Int8")
+export const encodeInt8 = numberEncoder("Int8")
+export const decodeInt16 = numberDecoder("Int16")
+export const encodeInt16 = numberEncoder("Int16")
+export const decodeInt32 = numberDecoder("Int32")
+export const encodeInt32 = numberEncoder("Int32")
+export const decodeInt64 = bigintDecoder("Int64")
+export const encodeInt64 = bigintEncoder("Int64")
+export const decodeInt128 = bigintDecoder("Int128")
+export const encodeInt128 = bigintEncoder("Int128")
+export const decodeInt256 = bigintDecoder("Int256")
+export const encodeInt256 = bigintEncoder("Int256")
+export const decodeUInt = bigintDecoder("UInt")
+export const encodeUInt = bigintEncoder("UInt")
+export const decodeUInt8 = numberDecoder("UInt8")
+export const encodeUInt8 = numberEncoder("UInt8")
+export const decodeUInt16 = numberDecoder("UInt16")
+export const encodeUInt16 = numberEncoder("UInt16")
+export const decodeUInt32 = numberDecoder("UInt32")
+export const encodeUInt32 = numberEncoder("UInt32")
+export const decodeUInt64 = bigintDecoder("UInt64")
+export const encodeUInt64 = bigintEncoder("UInt64")
+export const decodeUInt128 = bigintDecoder("UInt128")
+export const encodeUInt128 = bigintEncoder("UInt128")
+export const decodeUInt256 = bigintDecoder("UInt256")
+export const encodeUInt256 = bigintEncoder("UInt256")
+export const decodeWord8 = numberDecoder("Word8")
+export const encodeWord8 = numberEncoder("Word8")
+export const decodeWord16 = numberDecoder("Word16")
+export const encodeWord16 = numberEncoder("Word16")
+export const decodeWord32 = numberDecoder("Word32")
+export const encodeWord32 = numberEncoder("Word32")
+export const decodeWord64 = bigintDecoder("Word64")
+export const encodeWord64 = bigintEncoder("Word64")
+export const decodeWord128 = bigintDecoder("Word128")
+export const encodeWord128 = bigintEncoder("Word128")
+export const decodeWord256 = bigintDecoder("Word256")
+export const encodeWord256 = bigintEncoder("Word256")
+export const decodeFix64 = fixedPointDecoder("Fix64")
+export const encodeFix64 = fixedPointEncoder("Fix64")
+export const decodeUFix64 = fixedPointDecoder("UFix64")
+export const encodeUFix64 = fixedPointEncoder("UFix64")
+
+export function optionalDecoder<T>(decode: Decoder<T>): Decoder<T | null> {
+  return (json) => {
+    const value = (expectType(json, "Optional") as JsonCadenceOptional).value
+    return value === null ? null : decode(value)
+  }
+}
+
+export function optionalEncoder<T, J extends JsonCadenceValue>(
+  encode: Encoder<T, J>,
+): Encoder<T | null, JsonCadenceOptional<J>> {
+  return (value) => ({ type: "Optional", value: value === null ? null : encode(value) })
+}
+
+export function arrayDecoder<T>(decode: Decoder<T>): Decoder<T[]> {
+  return (json) => (expectType(json, "Array") as JsonCadenceArray).value.map((element) => decode(element))
+}
+
+export function arrayEncoder<T, J extends JsonCadenceValue>(encode: Encoder<T, J>): Encoder<T[], JsonCadenceArray<J>> {
+  return (value) => ({ type: "Array", value: value.map((element) => encode(element)) })
+}
+
+export function dictionaryDecoder<K, V>(decodeKey: Decoder<K>, decodeValue: Decoder<V>): Decoder<Map<K, V>> {
+  return (json) =>
+    new Map(
+      (expectType(json, "Dictionary") as JsonCadenceDictionary).value.map(
+        ({ key, value }): [K, V] => [decodeKey(key), decodeValue(value)],
+      ),
+    )
+}
+
+export function dictionaryEncoder<K, V, JK extends JsonCadenceValue, JV extends JsonCadenceValue>(
+  encodeKey: Encoder<K, JK>,
+  encodeValue: Encoder<V, JV>,
+): Encoder<Map<K, V>, JsonCadenceDictionary<JK, JV>> {
+  return (value) => ({
+    type: "Dictionary",
+    value: Array.from(value, ([key, element]) => ({ key: encodeKey(key), value: encodeValue(element) })),
+  })
+}
+
+export function compositeFields(
+  json: JsonCadenceValue,
+  kind: JsonCadenceCompositeKind,
+  id: string,
+): (name: string) => JsonCadenceValue {
+  const composite = expectType(json, kind) as JsonCadenceComposite
+  if (composite.value.id !== id) {
+    throw new Error(` + "`expected JSON-Cadence value of type ${id}, got ${composite.value.id}`" + `)
+  }
+  return (name) => {
+    const field = composite.value.fields.find((field) => field.name === name)
+    if (field === undefined) {
+      throw new Error(` + "`missing field ${name} in JSON-Cadence value of type ${id}`" + `)
+    }
+    return field.value
+  }
+}
+`
+
+// typeScriptSimpleType is the TypeScript representation of a simple Cadence type
+type typeScriptSimpleType struct {
+	tsType   string
+	jsonType string
+	name     string
+}
+
+var typeScriptSimpleTypes = func() map[string]typeScriptSimpleType {
+	types := map[string]typeScriptSimpleType{
+		string(sema.BoolType.ID()):       {"boolean", "JsonCadenceBool", "Bool"},
+		string(sema.StringType.ID()):     {"string", "JsonCadenceString", "String"},
+		string(sema.CharacterType.ID()):  {"string", "JsonCadenceCharacter", "Character"},
+		string(sema.TheAddressType.ID()): {"string", "JsonCadenceAddress", "Address"},
+	}
+
+	for _, pathType := range []sema.Type{
+		sema.PathType,
+		sema.StoragePathType,
+		sema.CapabilityPathType,
+		sema.PublicPathType,
+		sema.PrivatePathType,
+	} {
+		types[string(pathType.ID())] = typeScriptSimpleType{"CadencePath", "JsonCadencePath", "Path"}
+	}
+
+	for _, numberType := range []sema.Type{
+		sema.Int8Type,
+		sema.Int16Type,
+		sema.Int32Type,
+		sema.UInt8Type,
+		sema.UInt16Type,
+		sema.UInt32Type,
+		sema.Word8Type,
+		sema.Word16Type,
+		sema.Word32Type,
+	} {
+		id := string(numberType.ID())
+		types[id] = typeScriptSimpleType{"number", jsonCadenceNumberType(id), id}
+	}
+
+	for _, numberType := range []sema.Type{
+		sema.IntType,
+		sema.Int64Type,
+		sema.Int128Type,
+		sema.Int256Type,
+		sema.UIntType,
+		sema.UInt64Type,
+		sema.UInt128Type,
+		sema.UInt256Type,
+		sema.Word64Type,
+		sema.Word128Type,
+		sema.Word256Type,
+	} {
+		id := string(numberType.ID())
+		types[id] = typeScriptSimpleType{"bigint", jsonCadenceNumberType(id), id}
+	}
+
+	for _, numberType := range []sema.Type{
+		sema.Fix64Type,
+		sema.UFix64Type,
+	} {
+		id := string(numberType.ID())
+		types[id] = typeScriptSimpleType{"string", jsonCadenceNumberType(id), id}
+	}
+
+	return types
+}()
+
+func jsonCadenceNumberType(id string) string {
+	return fmt.Sprintf("JsonCadenceNumber<%q>", id)
+}
+
+// typeScriptReservedWords are the words which cannot be used as parameter names
+var typeScriptReservedWords = map[string]struct{}{}
+
+func init() {
+	for _, word := range strings.Fields(`
+		break case catch class const continue debugger default delete do else enum export extends
+		false finally for function if import in instanceof new null return super switch this throw
+		true try typeof var void while with yield let static implements interface package private
+		protected public await
+	`) {
+		typeScriptReservedWords[word] = struct{}{}
+	}
+}
+
+// typeInput is a JSON-Cadence encoded type value,
+// for which a TypeScript type alias is generated.
+type typeInput struct {
+	path       string
+	staticType cadence.Type
+	name       string
+}
+
+type typeScriptComposite struct {
+	compositeType cadence.CompositeType
+	name          string
+	enumCases     []string
+}
+
+type typeScriptGenerator struct {
+	inputs         []*input
+	typeInputs     []*typeInput
+	paths          []string
+	composites     []*typeScriptComposite
+	compositesByID map[string]*typeScriptComposite
+	enumCases      map[string][]string
+	exportedTypes  map[sema.TypeID]cadence.Type
+	buf            bytes.Buffer
+}
+
+func genTypeScript(inPaths []string, address common.Address, out io.Writer) error {
+	var programPaths []string
+	var typeInputs []*typeInput
+
+	for _, path := range inPaths {
+		if filepath.Ext(path) != ".json" {
+			programPaths = append(programPaths, path)
+			continue
+		}
+
+		typeInput, err := loadTypeInput(path)
+		if err != nil {
+			return err
+		}
+		typeInputs = append(typeInputs, typeInput)
+	}
+
+	loader := newLoader(address)
+
+	err := loader.load(programPaths)
+	if err != nil {
+		return err
+	}
+
+	err = loader.check()
+	if err != nil {
+		return err
+	}
+
+	g := &typeScriptGenerator{
+		inputs:         loader.inputs,
+		typeInputs:     typeInputs,
+		paths:          inPaths,
+		compositesByID: map[string]*typeScriptComposite{},
+		enumCases:      map[string][]string{},
+		exportedTypes:  map[sema.TypeID]cadence.Type{},
+	}
+
+	return g.generate(out)
+}
+
+func loadTypeInput(path string) (*typeInput, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := jsoncdc.Decode(nil, data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON-Cadence in %s: %w", path, err)
+	}
+
+	typeValue, ok := value.(cadence.TypeValue)
+	if !ok {
+		return nil, fmt.Errorf("invalid JSON-Cadence in %s: expected type value, got %s", path, value.Type().ID())
+	}
+
+	_, fileName := filepath.Split(path)
+
+	return &typeInput{
+		path:       path,
+		staticType: typeValue.StaticType,
+		name:       goIdentifier(strings.TrimSuffix(fileName, filepath.Ext(fileName))),
+	}, nil
+}
+
+func (g *typeScriptGenerator) generate(out io.Writer) error {
+
+	// Register all composite types first, starting with the ones declared in contracts,
+	// so their declarations are generated in a deterministic order
+
+	for _, input := range g.inputs {
+		if !input.isContract() {
+			continue
+		}
+		for _, boundType := range contractTypes(input) {
+			if boundType.compositeType.Kind == common.CompositeKindEnum {
+				enumCases := boundType.declaration.Members.EnumCases()
+				names := make([]string, len(enumCases))
+				for i, enumCase := range enumCases {
+					names[i] = enumCase.Identifier.Identifier
+				}
+				g.enumCases[string(boundType.compositeType.ID())] = names
+			}
+			g.register(g.exportType(boundType.compositeType))
+		}
+	}
+
+	for _, input := range g.inputs {
+		if input.isContract() {
+			continue
+		}
+		for _, parameter := range input.checker.EntryPointParameters() {
+			g.register(g.exportType(parameter.TypeAnnotation.Type))
+		}
+	}
+
+	for _, typeInput := range g.typeInputs {
+		g.register(typeInput.staticType)
+	}
+
+	paths := make([]string, len(g.paths))
+	for i, path := range g.paths {
+		paths[i] = filepath.ToSlash(path)
+	}
+
+	g.printf("// Code generated by bindgen from %s. DO NOT EDIT.\n\n", strings.Join(paths, ", "))
+	g.printf("%s\n", typeScriptPrelude)
+
+	for _, composite := range g.composites {
+		if _, ok := composite.compositeType.(*cadence.EnumType); ok {
+			g.writeEnum(composite)
+		} else {
+			g.writeComposite(composite)
+		}
+	}
+
+	for _, typeInput := range g.typeInputs {
+		g.writeTypeInput(typeInput)
+	}
+
+	for _, input := range g.inputs {
+		if !input.isContract() {
+			g.writeArguments(input)
+		}
+	}
+
+	// Every declaration is followed by an empty line, drop the last one
+	source := append(bytes.TrimRight(g.buf.Bytes(), "\n"), '\n')
+
+	_, err := out.Write(source)
+	return err
+}
+
+func (g *typeScriptGenerator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *typeScriptGenerator) exportType(ty sema.Type) cadence.Type {
+	return runtime.ExportType(ty, g.exportedTypes)
+}
+
+// register registers all composite types in the given type, in depth-first order.
+func (g *typeScriptGenerator) register(ty cadence.Type) {
+	switch ty := ty.(type) {
+	case *cadence.OptionalType:
+		g.register(ty.Type)
+
+	case *cadence.VariableSizedArrayType:
+		g.register(ty.ElementType)
+
+	case *cadence.ConstantSizedArrayType:
+		g.register(ty.ElementType)
+
+	case *cadence.DictionaryType:
+		g.register(ty.KeyType)
+		g.register(ty.ElementType)
+
+	case *cadence.RestrictedType:
+		g.register(ty.Type)
+
+	case *cadence.StructType,
+		*cadence.ResourceType,
+		*cadence.EventType,
+		*cadence.ContractType,
+		*cadence.EnumType:
+
+		compositeType := ty.(cadence.CompositeType)
+		id := compositeType.ID()
+		if _, ok := g.compositesByID[id]; ok {
+			return
+		}
+
+		composite := &typeScriptComposite{
+			compositeType: compositeType,
+			name:          goIdentifier(compositeType.CompositeTypeQualifiedIdentifier()),
+			enumCases:     g.enumCases[id],
+		}
+		g.compositesByID[id] = composite
+		g.composites = append(g.composites, composite)
+
+		for _, field := range compositeType.CompositeFields() {
+			g.register(field.Type)
+		}
+	}
+}
+
+func (g *typeScriptGenerator) composite(ty cadence.Type) *typeScriptComposite {
+	switch ty := ty.(type) {
+	case cadence.CompositeType:
+		return g.compositesByID[ty.ID()]
+	case *cadence.RestrictedType:
+		if ty.Type != nil {
+			return g.composite(ty.Type)
+		}
+	}
+	return nil
+}
+
+// tsType returns the TypeScript type of decoded values of the given type
+func (g *typeScriptGenerator) tsType(ty cadence.Type) string {
+	switch ty := ty.(type) {
+	case *cadence.OptionalType:
+		return g.tsType(ty.Type) + " | null"
+	case *cadence.VariableSizedArrayType:
+		return arrayTypeScriptType(g.tsType(ty.ElementType))
+	case *cadence.ConstantSizedArrayType:
+		return arrayTypeScriptType(g.tsType(ty.ElementType))
+	case *cadence.DictionaryType:
+		return fmt.Sprintf("Map<%s, %s>", g.tsType(ty.KeyType), g.tsType(ty.ElementType))
+	}
+
+	if composite := g.composite(ty); composite != nil {
+		return composite.name
+	}
+
+	if simpleType, ok := typeScriptSimpleTypes[ty.ID()]; ok {
+		return simpleType.tsType
+	}
+
+	return "JsonCadenceValue"
+}
+
+func arrayTypeScriptType(elementType string) string {
+	if strings.Contains(elementType, " ") {
+		return fmt.Sprintf("(%s)[]", elementType)
+	}
+	return elementType + "[]"
+}
+
+// jsonType returns the TypeScript type of JSON-Cadence encoded values of the given type
+func (g *typeScriptGenerator) jsonType(ty cadence.Type) string {
+	switch ty := ty.(type) {
+	case *cadence.OptionalType:
+		return fmt.Sprintf("JsonCadenceOptional<%s>", g.jsonType(ty.Type))
+	case *cadence.VariableSizedArrayType:
+		return fmt.Sprintf("JsonCadenceArray<%s>", g.jsonType(ty.ElementType))
+	case *cadence.ConstantSizedArrayType:
+		return fmt.Sprintf("JsonCadenceArray<%s>", g.jsonType(ty.ElementType))
+	case *cadence.DictionaryType:
+		return fmt.Sprintf(
+			"JsonCadenceDictionary<%s, %s>",
+			g.jsonType(ty.KeyType),
+			g.jsonType(ty.ElementType),
+		)
+	}
+
+	if composite := g.composite(ty); composite != nil {
+		return "Json" + composite.name
+	}
+
+	if simpleType, ok := typeScriptSimpleTypes[ty.ID()]; ok {
+		return simpleType.jsonType
+	}
+
+	return "JsonCadenceValue"
+}
+
+// decoder returns a TypeScript expression for the decoder of the given type
+func (g *typeScriptGenerator) decoder(ty cadence.Type) string {
+	switch ty := ty.(type) {
+	case *cadence.OptionalType:
+		return fmt.Sprintf("optionalDecoder(%s)", g.decoder(ty.Type))
+	case *cadence.VariableSizedArrayType:
+		return fmt.Sprintf("arrayDecoder(%s)", g.decoder(ty.ElementType))
+	case *cadence.ConstantSizedArrayType:
+		return fmt.Sprintf("arrayDecoder(%s)", g.decoder(ty.ElementType))
+	case *cadence.DictionaryType:
+		return fmt.Sprintf(
+			"dictionaryDecoder(%s, %s)",
+			g.decoder(ty.KeyType),
+			g.decoder(ty.ElementType),
+		)
+	}
+
+	if composite := g.composite(ty); composite != nil {
+		return "decode" + composite.name
+	}
+
+	if simpleType, ok := typeScriptSimpleTypes[ty.ID()]; ok {
+		return "decode" + simpleType.name
+	}
+
+	return "decodeValue"
+}
+
+// encoder returns a TypeScript expression for the encoder of the given type
+func (g *typeScriptGenerator) encoder(ty cadence.Type) string {
+	switch ty := ty.(type) {
+	case *cadence.OptionalType:
+		return fmt.Sprintf("optionalEncoder(%s)", g.encoder(ty.Type))
+	case *cadence.VariableSizedArrayType:
+		return fmt.Sprintf("arrayEncoder(%s)", g.encoder(ty.ElementType))
+	case *cadence.ConstantSizedArrayType:
+		return fmt.Sprintf("arrayEncoder(%s)", g.encoder(ty.ElementType))
+	case *cadence.DictionaryType:
+		return fmt.Sprintf(
+			"dictionaryEncoder(%s, %s)",
+			g.encoder(ty.KeyType),
+			g.encoder(ty.ElementType),
+		)
+	}
+
+	if composite := g.composite(ty); composite != nil {
+		return "encode" + composite.name
+	}
+
+	if simpleType, ok := typeScriptSimpleTypes[ty.ID()]; ok {
+		return "encode" + simpleType.name
+	}
+
+	return "encodeValue"
+}
+
+// call returns a TypeScript expression which calls the given decoder or encoder expression
+func call(function string, argument string) string {
+	return fmt.Sprintf("%s(%s)", function, argument)
+}
+
+func jsonCompositeKind(compositeType cadence.CompositeType) string {
+	switch compositeType.(type) {
+	case *cadence.StructType:
+		return "Struct"
+	case *cadence.ResourceType:
+		return "Resource"
+	case *cadence.EventType:
+		return "Event"
+	case *cadence.ContractType:
+		return "Contract"
+	case *cadence.EnumType:
+		return "Enum"
+	}
+	panic(fmt.Errorf("unsupported composite type %s", compositeType.ID()))
+}
+
+func (g *typeScriptGenerator) writeCompositeShape(composite *typeScriptComposite) {
+	compositeType := composite.compositeType
+	kind := jsonCompositeKind(compositeType)
+
+	g.printf("/** JSON-Cadence encoding of `%s` */\n", compositeType.CompositeTypeQualifiedIdentifier())
+	g.printf("export interface Json%s {\n", composite.name)
+	g.printf("  type: %q\n", kind)
+	g.printf("  value: {\n")
+	g.printf("    id: typeof %sTypeID\n", composite.name)
+	fields := compositeType.CompositeFields()
+	if len(fields) == 0 {
+		g.printf("    fields: []\n")
+	} else {
+		g.printf("    fields: [\n")
+		for _, field := range fields {
+			g.printf("      JsonCadenceField<%q, %s>,\n", field.Identifier, g.jsonType(field.Type))
+		}
+		g.printf("    ]\n")
+	}
+	g.printf("  }\n")
+	g.printf("}\n\n")
+
+	g.printf("export const %sTypeID = %q\n\n", composite.name, compositeType.ID())
+}
+
+func (g *typeScriptGenerator) writeComposite(composite *typeScriptComposite) {
+	compositeType := composite.compositeType
+	name := composite.name
+	kind := jsonCompositeKind(compositeType)
+	fields := compositeType.CompositeFields()
+
+	g.printf("/** Decoded value of `%s` */\n", compositeType.CompositeTypeQualifiedIdentifier())
+	g.printf("export interface %s {\n", name)
+	for _, field := range fields {
+		g.printf("  %s: %s\n", field.Identifier, g.tsType(field.Type))
+	}
+	g.printf("}\n\n")
+
+	g.writeCompositeShape(composite)
+
+	g.printf("export function decode%s(json: JsonCadenceValue): %s {\n", name, name)
+	if len(fields) == 0 {
+		g.printf("  compositeFields(json, %q, %sTypeID)\n", kind, name)
+		g.printf("  return {}\n")
+	} else {
+		g.printf("  const field = compositeFields(json, %q, %sTypeID)\n", kind, name)
+		g.printf("  return {\n")
+		for _, field := range fields {
+			g.printf(
+				"    %s: %s,\n",
+				field.Identifier,
+				call(g.decoder(field.Type), fmt.Sprintf("field(%q)", field.Identifier)),
+			)
+		}
+		g.printf("  }\n")
+	}
+	g.printf("}\n\n")
+
+	g.writeCompositeEncoder(composite, func(field cadence.Field) string {
+		return call(g.encoder(field.Type), "value."+field.Identifier)
+	})
+}
+
+func (g *typeScriptGenerator) writeCompositeEncoder(
+	composite *typeScriptComposite,
+	fieldValue func(field cadence.Field) string,
+) {
+	compositeType := composite.compositeType
+	name := composite.name
+	fields := compositeType.CompositeFields()
+
+	g.printf("export function encode%s(value: %s): Json%s {\n", name, name, name)
+	g.printf("  return {\n")
+	g.printf("    type: %q,\n", jsonCompositeKind(compositeType))
+	g.printf("    value: {\n")
+	g.printf("      id: %sTypeID,\n", name)
+	if len(fields) == 0 {
+		g.printf("      fields: [],\n")
+	} else {
+		g.printf("      fields: [\n")
+		for _, field := range fields {
+			g.printf("        { name: %q, value: %s },\n", field.Identifier, fieldValue(field))
+		}
+		g.printf("      ],\n")
+	}
+	g.printf("    },\n")
+	g.printf("  }\n")
+	g.printf("}\n\n")
+}
+
+func (g *typeScriptGenerator) writeEnum(composite *typeScriptComposite) {
+	enumType := composite.compositeType.(*cadence.EnumType)
+	name := composite.name
+	rawType := enumType.RawType
+	tsRawType := g.tsType(rawType)
+
+	g.printf("/** Raw value of `%s` */\n", enumType.QualifiedIdentifier)
+	g.printf("export type %s = %s\n\n", name, tsRawType)
+
+	if len(composite.enumCases) > 0 {
+		g.printf("export const %s = {\n", name)
+		for i, enumCase := range composite.enumCases {
+			literal := fmt.Sprint(i)
+			if tsRawType == "bigint" {
+				literal += "n"
+			}
+			g.printf("  %s: %s,\n", enumCase, literal)
+		}
+		g.printf("} as const\n\n")
+	}
+
+	g.writeCompositeShape(composite)
+
+	g.printf("export function decode%s(json: JsonCadenceValue): %s {\n", name, name)
+	g.printf("  const field = compositeFields(json, \"Enum\", %sTypeID)\n", name)
+	g.printf("  return %s\n", call(g.decoder(rawType), fmt.Sprintf("field(%q)", sema.EnumRawValueFieldName)))
+	g.printf("}\n\n")
+
+	g.writeCompositeEncoder(composite, func(field cadence.Field) string {
+		return call(g.encoder(field.Type), "value")
+	})
+}
+
+func (g *typeScriptGenerator) writeTypeInput(typeInput *typeInput) {
+	staticType := typeInput.staticType
+	name := typeInput.name
+
+	_, fileName := filepath.Split(typeInput.path)
+
+	g.printf("/** Decoded value of the type in `%s` */\n", fileName)
+	g.printf("export type %s = %s\n\n", name, g.tsType(staticType))
+	g.printf("/** JSON-Cadence encoding of the type in `%s` */\n", fileName)
+	g.printf("export type Json%s = %s\n\n", name, g.jsonType(staticType))
+	g.printf("export const decode%s: Decoder<%s> = %s\n\n", name, name, g.decoder(staticType))
+	g.printf("export const encode%s: Encoder<%s, Json%s> = %s\n\n", name, name, name, g.encoder(staticType))
+}
+
+func (g *typeScriptGenerator) writeArguments(input *input) {
+	kind := "script"
+	if input.isTransaction() {
+		kind = "transaction"
+	}
+
+	_, fileName := filepath.Split(input.path)
+	functionName := "encode" + goIdentifier(strings.TrimSuffix(fileName, filepath.Ext(fileName))) + "Arguments"
+
+	parameters := input.checker.EntryPointParameters()
+
+	g.printf("/** Encodes the arguments for the %s `%s` */\n", kind, fileName)
+	g.printf("export function %s(", functionName)
+
+	// Put each parameter on its own line if there are many
+	multiline := len(parameters) > 3
+	for i, parameter := range parameters {
+		if multiline {
+			g.printf("\n  ")
+		} else if i > 0 {
+			g.printf(", ")
+		}
+		g.printf(
+			"%s: %s",
+			typeScriptParameterName(parameter.Identifier),
+			g.tsType(g.exportType(parameter.TypeAnnotation.Type)),
+		)
+		if multiline {
+			g.printf(",")
+		}
+	}
+	if multiline {
+		g.printf("\n")
+	}
+	g.printf("): JsonCadenceValue[] {\n")
+
+	if len(parameters) == 0 {
+		g.printf("  return []\n")
+	} else {
+		g.printf("  return [\n")
+		for _, parameter := range parameters {
+			parameterType := g.exportType(parameter.TypeAnnotation.Type)
+			g.printf("    %s,\n", call(g.encoder(parameterType), typeScriptParameterName(parameter.Identifier)))
+		}
+		g.printf("  ]\n")
+	}
+	g.printf("}\n\n")
+}
+
+func typeScriptParameterName(name string) string {
+	if _, ok := typeScriptReservedWords[name]; ok {
+		return name + "_"
+	}
+	return name
+}
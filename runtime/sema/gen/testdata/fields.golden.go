@@ -70,6 +70,17 @@ const TestTypeTestConstIntsFieldDocString = `
 This is a test constant-sized integer array.
 `
 
+const TestTypeTestIntDictFieldName = "testIntDict"
+
+var TestTypeTestIntDictFieldType = &DictionaryType{
+	KeyType:   UInt64Type,
+	ValueType: BoolType,
+}
+
+const TestTypeTestIntDictFieldDocString = `
+This is a test integer dictionary.
+`
+
 const TestTypeTestParamFieldName = "testParam"
 
 var TestTypeTestParamFieldType = MustInstantiate(
@@ -205,6 +216,14 @@ func init() {
 				TestTypeTestConstIntsFieldType,
 				TestTypeTestConstIntsFieldDocString,
 			),
+			NewUnmeteredFieldMember(
+				t,
+				ast.AccessPublic,
+				ast.VariableKindConstant,
+				TestTypeTestIntDictFieldName,
+				TestTypeTestIntDictFieldType,
+				TestTypeTestIntDictFieldDocString,
+			),
 			NewUnmeteredFieldMember(
 				t,
 				ast.AccessPublic,
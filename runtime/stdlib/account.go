@@ -70,6 +70,7 @@ type AuthAccountHandler interface {
 	AvailableBalanceProvider
 	StorageUsedProvider
 	StorageCapacityProvider
+	StorageUsageProvider
 	AccountEncodedKeyAdditionHandler
 	AccountEncodedKeyRevocationHandler
 	AuthAccountKeysHandler
@@ -200,6 +201,9 @@ func NewAuthAccountValue(
 		newStorageCapacityGetFunction(handler, addressValue),
 		newAddPublicKeyFunction(gauge, handler, addressValue),
 		newRemovePublicKeyFunction(gauge, handler, addressValue),
+		func() interpreter.FunctionValue {
+			return newStorageUsageFunction(gauge, handler, addressValue)
+		},
 		func() interpreter.Value {
 			return newAuthAccountContractsValue(
 				gauge,
@@ -421,6 +425,87 @@ func newStorageUsedGetFunction(
 	}
 }
 
+type StorageUsageProvider interface {
+	// GetStorageUsage returns the amount of storage used by the value stored under the given path,
+	// or nil if no value is stored under the given path.
+	GetStorageUsage(
+		inter *interpreter.Interpreter,
+		address common.Address,
+		path interpreter.PathValue,
+	) *interpreter.StorageUsage
+}
+
+var storageUsageDictionaryStaticType = interpreter.DictionaryStaticType{
+	KeyType:   interpreter.PrimitiveStaticTypeString,
+	ValueType: interpreter.PrimitiveStaticTypeUInt64,
+}
+
+func newStorageUsageFunction(
+	gauge common.MemoryGauge,
+	provider StorageUsageProvider,
+	addressValue interpreter.AddressValue,
+) *interpreter.HostFunctionValue {
+
+	// Converted addresses can be cached and don't have to be recomputed on each function invocation
+	address := addressValue.ToAddress()
+
+	return interpreter.NewHostFunctionValue(
+		gauge,
+		sema.AuthAccountTypeStorageUsageFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+			locationRange := invocation.LocationRange
+
+			path, ok := invocation.Arguments[0].(interpreter.PathValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			usage := provider.GetStorageUsage(inter, address, path)
+			if usage == nil {
+				return interpreter.Nil
+			}
+
+			var keysAndValues []interpreter.Value
+
+			usage.ForEach(
+				path.String(),
+				func(memberPath string, memberUsage *interpreter.StorageUsage) {
+					// Meter each entry, as the usage may be cached
+					inter.ReportComputation(common.ComputationKindLoop, 1)
+
+					keysAndValues = append(
+						keysAndValues,
+						interpreter.NewStringValue(
+							inter,
+							common.NewStringMemoryUsage(len(memberPath)),
+							func() string {
+								return memberPath
+							},
+						),
+						interpreter.NewUInt64Value(
+							inter,
+							func() uint64 {
+								return memberUsage.Size
+							},
+						),
+					)
+				},
+			)
+
+			return interpreter.NewSomeValueNonCopying(
+				inter,
+				interpreter.NewDictionaryValue(
+					inter,
+					locationRange,
+					storageUsageDictionaryStaticType,
+					keysAndValues...,
+				),
+			)
+		},
+	)
+}
+
 type StorageCapacityProvider interface {
 	CommitStorageTemporarily(inter *interpreter.Interpreter) error
 	// GetStorageCapacity gets storage capacity in bytes on the address.
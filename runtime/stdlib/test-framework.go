@@ -20,8 +20,12 @@
 package stdlib
 
 import (
+	"time"
+
+	"github.com/onflow/cadence"
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/sema"
 )
 
 // TestFramework & Blockchain are the interfaces to be implemented by
@@ -33,6 +37,23 @@ type TestFramework interface {
 	EmulatorBackend() Blockchain
 
 	ReadFile(string) (string, error)
+
+	// WriteFile writes the given content to the file at the given path.
+	// It is used to write snapshots.
+	WriteFile(path string, content string) error
+}
+
+// ValueExporter is an optional interface, which a TestFramework can implement
+// to export values, so they can be serialized.
+// Snapshots are only supported by test frameworks which implement it.
+type ValueExporter interface {
+	ExportValue(inter *interpreter.Interpreter, value interpreter.Value) (cadence.Value, error)
+}
+
+// SnapshotUpdater is an optional interface, which a TestFramework can implement
+// to request that snapshots are regenerated, instead of being compared against.
+type SnapshotUpdater interface {
+	UpdateSnapshots() bool
 }
 
 type Blockchain interface {
@@ -43,14 +64,18 @@ type Blockchain interface {
 
 	CreateAccount() (*Account, error)
 
+	// CreateAccountWithKeys creates an account with the given keys.
+	CreateAccountWithKeys(keys []Key) (*Account, error)
+
 	GetAccount(interpreter.AddressValue) (*Account, error)
 
+	// SequenceNumber returns the sequence number of the key with the given index
+	// of the account with the given address.
+	SequenceNumber(address common.Address, keyIndex int) (uint64, error)
+
 	AddTransaction(
 		inter *interpreter.Interpreter,
-		code string,
-		authorizers []common.Address,
-		signers []*Account,
-		arguments []interpreter.Value,
+		transaction *Transaction,
 	) error
 
 	ExecuteNextTransaction() *TransactionResult
@@ -64,10 +89,21 @@ type Blockchain interface {
 		arguments []interpreter.Value,
 	) error
 
+	MockContract(
+		inter *interpreter.Interpreter,
+		name string,
+		code string,
+		arguments []interpreter.Value,
+	) error
+
 	StandardLibraryHandler() StandardLibraryHandler
 
 	Logs() []string
 
+	// Coverage returns the coverage of the contracts,
+	// or nil if the blockchain does not collect coverage.
+	Coverage() *Coverage
+
 	ServiceAccount() (*Account, error)
 
 	Events(
@@ -79,21 +115,99 @@ type Blockchain interface {
 
 	MoveTime(int64)
 
+	// SetRandomSeed seeds the random number generator of the blockchain.
+	SetRandomSeed(seed uint64)
+
+	// QueueRandomValues queues the given values, which are returned in order
+	// by the next requests for random values, before any other random values.
+	QueueRandomValues(values []uint64)
+
+	// SetBlockHeight sets the height of the pending block.
+	SetBlockHeight(height uint64) error
+
+	// SetBlockID sets the ID of the pending block.
+	SetBlockID(id []byte) error
+
+	// SetBlockTimestamp sets the timestamp of the pending block.
+	SetBlockTimestamp(timestamp time.Time)
+
 	CreateSnapshot(string) error
 
 	LoadSnapshot(string) error
 }
 
+// EventImporter is an optional interface, which a Blockchain can implement
+// to import the events of transaction results into the interpreter.
+// The results of a blockchain which does not implement it have no events.
+type EventImporter interface {
+	ImportEvent(inter *interpreter.Interpreter, event cadence.Event) (interpreter.Value, error)
+}
+
 type ScriptResult struct {
 	Value interpreter.Value
 	Error error
+	// ComputationUsed is the computation used by the script
+	ComputationUsed uint64
+	// MemoryUsed is the memory (estimate) used by the script
+	MemoryUsed uint64
 }
 
 type TransactionResult struct {
 	Error error
+	// Events are the events emitted by the transaction
+	Events []cadence.Event
+	// Logs are the logs produced by the transaction
+	Logs []string
+	// ComputationUsed is the computation used by the transaction
+	ComputationUsed uint64
+	// MemoryUsed is the memory (estimate) used by the transaction
+	MemoryUsed uint64
+}
+
+// Coverage is the statement coverage of the contracts.
+type Coverage struct {
+	Locations  int
+	Statements int
+	Hits       int
+	Misses     int
 }
 
 type Account struct {
 	PublicKey *PublicKey
 	Address   common.Address
 }
+
+// Key is a key of an account to be created.
+type Key struct {
+	SignAlgo sema.SignatureAlgorithm
+	HashAlgo sema.HashAlgorithm
+	// Weight is the weight of the key, where 1000 is the full weight
+	Weight int
+}
+
+type Transaction struct {
+	Code        string
+	Authorizers []common.Address
+	// Signers sign the transaction with all their keys
+	Signers   []*Account
+	Arguments []interpreter.Value
+	// Payer is the account paying for the transaction.
+	// If nil, the service account pays.
+	Payer *common.Address
+	// ProposalKey is the key proposing the transaction.
+	// If nil, the first key of the payer proposes, with its current sequence number.
+	ProposalKey *ProposalKey
+	// KeySignatures are signatures with single keys, in addition to the signatures of the signers
+	KeySignatures []KeySignature
+}
+
+type KeySignature struct {
+	Address  common.Address
+	KeyIndex int
+}
+
+type ProposalKey struct {
+	Address        common.Address
+	KeyIndex       int
+	SequenceNumber uint64
+}
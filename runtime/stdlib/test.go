@@ -20,8 +20,10 @@ package stdlib
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
+	"github.com/onflow/cadence"
 	"github.com/onflow/cadence/runtime/ast"
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/errors"
@@ -37,16 +39,43 @@ const testContractTypeName = "Test"
 
 const testScriptResultTypeName = "ScriptResult"
 const testTransactionResultTypeName = "TransactionResult"
+const testTransactionResultQualifiedIdentifier = testContractTypeName + "." + testTransactionResultTypeName
 const testResultStatusTypeName = "ResultStatus"
 const testResultStatusTypeSucceededCaseName = "succeeded"
 const testResultStatusTypeFailedCaseName = "failed"
 const testAccountTypeName = "Account"
 const testErrorTypeName = "Error"
+const testCoverageTypeName = "Coverage"
 const testMatcherTypeName = "Matcher"
+const testGeneratorTypeName = "Generator"
+const testStubTypeName = "Stub"
 
 const accountAddressFieldName = "address"
 
+const keySignatureAlgorithmFieldName = "signatureAlgorithm"
+const keyHashAlgorithmFieldName = "hashAlgorithm"
+const keyWeightFieldName = "weight"
+
+const keySignatureAddressFieldName = "address"
+const keySignatureKeyIndexFieldName = "keyIndex"
+
+const proposalKeySequenceNumberFieldName = "sequenceNumber"
+
+const transactionResultEventsFieldName = "events"
+const transactionResultLogsFieldName = "logs"
+
+const resultComputationUsedFieldName = "computationUsed"
+const resultMemoryUsedFieldName = "memoryUsed"
+
 const matcherTestFunctionName = "test"
+const matcherDescriptionFieldName = "description"
+
+const generatorGenerateFieldName = "generate"
+const generatorShrinkFieldName = "shrink"
+const generatorTransformFieldName = "transform"
+
+const stubFunctionFieldName = "function"
+const stubReturnsFieldName = "returns"
 
 const TestContractLocation = common.IdentifierLocation(testContractTypeName)
 
@@ -170,9 +199,45 @@ func newScriptResult(
 		panic(err)
 	}
 
+	// The usage is not passed to the constructor,
+	// so results can also be created by test code
+	setResultUsage(
+		inter,
+		scriptResult,
+		result.ComputationUsed,
+		result.MemoryUsed,
+	)
+
 	return scriptResult
 }
 
+// setResultUsage sets the computation and memory usage fields of the given result value.
+func setResultUsage(
+	inter *interpreter.Interpreter,
+	resultValue interpreter.Value,
+	computationUsed uint64,
+	memoryUsed uint64,
+) {
+	result, ok := resultValue.(*interpreter.CompositeValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	result.SetMember(
+		inter,
+		interpreter.EmptyLocationRange,
+		resultComputationUsedFieldName,
+		interpreter.NewUnmeteredUInt64Value(computationUsed),
+	)
+
+	result.SetMember(
+		inter,
+		interpreter.EmptyLocationRange,
+		resultMemoryUsedFieldName,
+		interpreter.NewUnmeteredUInt64Value(memoryUsed),
+	)
+}
+
 func getConstructor(inter *interpreter.Interpreter, typeName string) *interpreter.HostFunctionValue {
 	resultStatusConstructorVar := inter.FindVariable(typeName)
 	resultStatusConstructor, ok := resultStatusConstructorVar.GetValue().(*interpreter.HostFunctionValue)
@@ -273,8 +338,196 @@ func accountFromValue(
 	}
 }
 
+func keysArrayValueToSlice(
+	inter *interpreter.Interpreter,
+	keysValue interpreter.Value,
+	locationRange interpreter.LocationRange,
+) []Key {
+
+	keysArray, ok := keysValue.(*interpreter.ArrayValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	keys := make([]Key, 0, keysArray.Count())
+
+	keysArray.Iterate(inter, func(element interpreter.Value) (resume bool) {
+		keyValue, ok := element.(interpreter.MemberAccessibleValue)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		signAlgoValue, ok := keyValue.GetMember(
+			inter,
+			locationRange,
+			keySignatureAlgorithmFieldName,
+		).(interpreter.MemberAccessibleValue)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		signAlgoRawValue, ok := signAlgoValue.GetMember(
+			inter,
+			locationRange,
+			sema.EnumRawValueFieldName,
+		).(interpreter.UInt8Value)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		hashAlgo := NewHashAlgorithmFromValue(
+			inter,
+			locationRange,
+			keyValue.GetMember(inter, locationRange, keyHashAlgorithmFieldName),
+		)
+
+		weight, ok := keyValue.GetMember(
+			inter,
+			locationRange,
+			keyWeightFieldName,
+		).(interpreter.UFix64Value)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		keys = append(keys, Key{
+			SignAlgo: sema.SignatureAlgorithm(signAlgoRawValue),
+			HashAlgo: hashAlgo,
+			Weight:   int(uint64(weight) / sema.Fix64Factor),
+		})
+
+		return true
+	})
+
+	return keys
+}
+
+// keySignatureFromValue returns the address and key index of the given key signature or proposal key value.
+func keySignatureFromValue(
+	inter *interpreter.Interpreter,
+	value interpreter.MemberAccessibleValue,
+	locationRange interpreter.LocationRange,
+) KeySignature {
+
+	address, ok := value.GetMember(
+		inter,
+		locationRange,
+		keySignatureAddressFieldName,
+	).(interpreter.AddressValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	keyIndex, ok := value.GetMember(
+		inter,
+		locationRange,
+		keySignatureKeyIndexFieldName,
+	).(interpreter.IntValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	return KeySignature{
+		Address:  common.Address(address),
+		KeyIndex: keyIndex.ToInt(locationRange),
+	}
+}
+
+func keySignaturesArrayValueToSlice(
+	inter *interpreter.Interpreter,
+	keySignaturesValue interpreter.Value,
+	locationRange interpreter.LocationRange,
+) []KeySignature {
+
+	keySignaturesArray, ok := keySignaturesValue.(*interpreter.ArrayValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	keySignatures := make([]KeySignature, 0, keySignaturesArray.Count())
+
+	keySignaturesArray.Iterate(inter, func(element interpreter.Value) (resume bool) {
+		keySignatureValue, ok := element.(interpreter.MemberAccessibleValue)
+		if !ok {
+			panic(errors.NewUnreachableError())
+		}
+
+		keySignatures = append(
+			keySignatures,
+			keySignatureFromValue(inter, keySignatureValue, locationRange),
+		)
+
+		return true
+	})
+
+	return keySignatures
+}
+
+// proposalKeyFromValue returns the proposal key of the given optional proposal key value,
+// or nil if the value is nil.
+func proposalKeyFromValue(
+	inter *interpreter.Interpreter,
+	value interpreter.Value,
+	locationRange interpreter.LocationRange,
+) *ProposalKey {
+
+	someValue, ok := value.(*interpreter.SomeValue)
+	if !ok {
+		return nil
+	}
+
+	proposalKeyValue, ok := someValue.InnerValue(inter, locationRange).(interpreter.MemberAccessibleValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	keySignature := keySignatureFromValue(inter, proposalKeyValue, locationRange)
+
+	sequenceNumber, ok := proposalKeyValue.GetMember(
+		inter,
+		locationRange,
+		proposalKeySequenceNumberFieldName,
+	).(interpreter.UInt64Value)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	return &ProposalKey{
+		Address:        keySignature.Address,
+		KeyIndex:       keySignature.KeyIndex,
+		SequenceNumber: uint64(sequenceNumber),
+	}
+}
+
+// optionalAddressFromValue returns the address of the given optional address value,
+// or nil if the value is nil.
+func optionalAddressFromValue(
+	inter *interpreter.Interpreter,
+	value interpreter.Value,
+	locationRange interpreter.LocationRange,
+) *common.Address {
+
+	someValue, ok := value.(*interpreter.SomeValue)
+	if !ok {
+		return nil
+	}
+
+	address, ok := someValue.InnerValue(inter, locationRange).(interpreter.AddressValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	result := common.Address(address)
+	return &result
+}
+
 // newTransactionResult Creates a "TransactionResult" indicating the status of the transaction execution.
-func newTransactionResult(inter *interpreter.Interpreter, result *TransactionResult) interpreter.Value {
+// The events of the result are imported using the given blockchain, if it is an EventImporter.
+func newTransactionResult(
+	inter *interpreter.Interpreter,
+	blockchain Blockchain,
+	result *TransactionResult,
+) interpreter.Value {
 	// Lookup and get 'ResultStatus' enum value.
 	resultStatusConstructor := getConstructor(inter, testResultStatusTypeName)
 	var status interpreter.Value
@@ -291,6 +544,23 @@ func newTransactionResult(inter *interpreter.Interpreter, result *TransactionRes
 
 	errValue := newErrorValue(inter, result.Error)
 
+	events := interpreter.NewArrayValue(
+		inter,
+		interpreter.EmptyLocationRange,
+		interpreter.NewVariableSizedStaticType(
+			inter,
+			interpreter.PrimitiveStaticTypeAnyStruct,
+		),
+		common.ZeroAddress,
+		importTransactionEvents(inter, blockchain, result.Events)...,
+	)
+
+	logs := newStringArrayValue(
+		inter,
+		interpreter.EmptyLocationRange,
+		result.Logs,
+	)
+
 	transactionResult, err := inter.InvokeExternally(
 		transactionResultConstructor,
 		transactionResultConstructor.Type,
@@ -304,9 +574,102 @@ func newTransactionResult(inter *interpreter.Interpreter, result *TransactionRes
 		panic(err)
 	}
 
+	// The events, logs, and usage are not passed to the constructor,
+	// so results can also be created by test code
+
+	transactionResultComposite, ok := transactionResult.(*interpreter.CompositeValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	transactionResultComposite.SetMember(
+		inter,
+		interpreter.EmptyLocationRange,
+		transactionResultEventsFieldName,
+		events,
+	)
+
+	transactionResultComposite.SetMember(
+		inter,
+		interpreter.EmptyLocationRange,
+		transactionResultLogsFieldName,
+		logs,
+	)
+
+	setResultUsage(
+		inter,
+		transactionResult,
+		result.ComputationUsed,
+		result.MemoryUsed,
+	)
+
 	return transactionResult
 }
 
+// importTransactionEvents imports the given events of a transaction result,
+// if the given blockchain is an EventImporter.
+func importTransactionEvents(
+	inter *interpreter.Interpreter,
+	blockchain Blockchain,
+	events []cadence.Event,
+) []interpreter.Value {
+
+	eventImporter, ok := blockchain.(EventImporter)
+	if !ok {
+		return nil
+	}
+
+	values := make([]interpreter.Value, 0, len(events))
+
+	for _, event := range events {
+		value, err := eventImporter.ImportEvent(inter, event)
+		if err != nil {
+			panic(err)
+		}
+
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// newStringArrayValue creates a "[String]" array value with the given strings.
+func newStringArrayValue(
+	inter *interpreter.Interpreter,
+	locationRange interpreter.LocationRange,
+	elements []string,
+) interpreter.Value {
+
+	arrayType := interpreter.NewVariableSizedStaticType(
+		inter,
+		interpreter.NewPrimitiveStaticType(
+			inter,
+			interpreter.PrimitiveStaticTypeString,
+		),
+	)
+
+	values := make([]interpreter.Value, len(elements))
+	for i, str := range elements {
+		str := str
+		memoryUsage := common.NewStringMemoryUsage(len(str))
+		values[i] = interpreter.NewStringValue(
+			inter,
+			memoryUsage,
+			func() string {
+				return str
+			},
+		)
+	}
+
+	return interpreter.NewArrayValue(
+		inter,
+		locationRange,
+		arrayType,
+		common.ZeroAddress,
+		values...,
+	)
+}
+
 func newErrorValue(inter *interpreter.Interpreter, err error) interpreter.Value {
 	if err == nil {
 		return interpreter.Nil
@@ -315,6 +678,29 @@ func newErrorValue(inter *interpreter.Interpreter, err error) interpreter.Value
 	// Create a 'Error' by calling its constructor.
 	errorConstructor := getConstructor(inter, testErrorTypeName)
 
+	return invokeErrorConstructor(inter, errorConstructor, err)
+}
+
+// newNestedErrorValue creates an 'Error' in a function of the 'Test' contract,
+// where the constructor is not available as a variable of the interpreter.
+func newNestedErrorValue(invocation interpreter.Invocation, err error) interpreter.Value {
+	if err == nil {
+		return interpreter.Nil
+	}
+
+	errorConstructor := getNestedTypeConstructorValue(
+		*invocation.Self,
+		testErrorTypeName,
+	)
+
+	return invokeErrorConstructor(invocation.Interpreter, errorConstructor, err)
+}
+
+func invokeErrorConstructor(
+	inter *interpreter.Interpreter,
+	errorConstructor *interpreter.HostFunctionValue,
+	err error,
+) interpreter.Value {
 	errorValue, invocationErr := inter.InvokeExternally(
 		errorConstructor,
 		errorConstructor.Type,
@@ -348,6 +734,62 @@ func (e TestFailedError) Error() string {
 	return fmt.Sprintf("test failed: %s", e.Err.Error())
 }
 
+// PropertyFailedError is reported when a property does not hold.
+// It includes the seed to reproduce the failure,
+// and the shrunk counterexample.
+type PropertyFailedError struct {
+	Seed           uint64
+	Runs           int
+	Shrinks        int
+	Counterexample string
+	// Err is the error the property failed with, if any
+	Err error
+}
+
+var _ errors.UserError = PropertyFailedError{}
+
+func (PropertyFailedError) IsUserError() {}
+
+func (e PropertyFailedError) Unwrap() error {
+	return e.Err
+}
+
+func (e PropertyFailedError) Error() string {
+	var builder strings.Builder
+	_, _ = fmt.Fprintf(
+		&builder,
+		"property does not hold after %d run(s) with seed %d: counterexample: %s",
+		e.Runs,
+		e.Seed,
+		e.Counterexample,
+	)
+	if e.Shrinks > 0 {
+		_, _ = fmt.Fprintf(&builder, " (shrunk %d time(s))", e.Shrinks)
+	}
+	if e.Err != nil {
+		_, _ = fmt.Fprintf(&builder, ": %s", e.Err.Error())
+	}
+	return builder.String()
+}
+
+// InvalidStubError is reported when the declaration of a stubbed function is invalid.
+type InvalidStubError struct {
+	Function string
+	Err      error
+}
+
+var _ errors.UserError = InvalidStubError{}
+
+func (InvalidStubError) IsUserError() {}
+
+func (e InvalidStubError) Unwrap() error {
+	return e.Err
+}
+
+func (e InvalidStubError) Error() string {
+	return fmt.Sprintf("invalid stub `%s`: %s", e.Function, e.Err.Error())
+}
+
 func newMatcherWithGenericTestFunction(
 	invocation interpreter.Invocation,
 	testFunc interpreter.FunctionValue,
@@ -423,6 +865,84 @@ func newMatcherWithGenericTestFunction(
 	return matcher
 }
 
+// newDescribedMatcher creates a matcher like newMatcherWithGenericTestFunction,
+// and sets its description, which is reported when a tested value does not match.
+func newDescribedMatcher(
+	invocation interpreter.Invocation,
+	testFunc interpreter.FunctionValue,
+	matcherTestFunctionType *sema.FunctionType,
+	description string,
+) interpreter.Value {
+	matcher, ok := newMatcherWithGenericTestFunction(
+		invocation,
+		testFunc,
+		matcherTestFunctionType,
+	).(*interpreter.CompositeValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+
+	inter := invocation.Interpreter
+
+	matcher.SetMember(
+		inter,
+		invocation.LocationRange,
+		matcherDescriptionFieldName,
+		interpreter.NewSomeValueNonCopying(
+			inter,
+			interpreter.NewUnmeteredStringValue(description),
+		),
+	)
+
+	return matcher
+}
+
+// matcherDescription returns the description of the given matcher, if any.
+func matcherDescription(
+	inter *interpreter.Interpreter,
+	matcher interpreter.MemberAccessibleValue,
+	locationRange interpreter.LocationRange,
+) (string, bool) {
+	someValue, ok := matcher.GetMember(
+		inter,
+		locationRange,
+		matcherDescriptionFieldName,
+	).(*interpreter.SomeValue)
+	if !ok {
+		return "", false
+	}
+
+	description, ok := someValue.InnerValue(inter, locationRange).(*interpreter.StringValue)
+	if !ok {
+		return "", false
+	}
+
+	return description.Str, true
+}
+
+// dereferenceValue returns the value referenced by the given value,
+// if it is a reference, so matchers can test resources through references.
+// Other values are returned as-is.
+func dereferenceValue(
+	inter *interpreter.Interpreter,
+	value interpreter.Value,
+	locationRange interpreter.LocationRange,
+) interpreter.Value {
+	reference, ok := value.(interpreter.ReferenceValue)
+	if !ok {
+		return value
+	}
+
+	referencedValue := reference.ReferencedValue(inter, locationRange, true)
+	if referencedValue == nil {
+		panic(interpreter.DereferenceError{
+			LocationRange: locationRange,
+		})
+	}
+
+	return *referencedValue
+}
+
 func TestCheckerContractValueHandler(
 	checker *sema.Checker,
 	declaration *ast.CompositeDeclaration,
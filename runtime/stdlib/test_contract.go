@@ -19,9 +19,20 @@
 package stdlib
 
 import (
+	"bytes"
+	"encoding/json"
+	goerrors "errors"
 	"fmt"
+	"io/fs"
+	"math/big"
+	"math/rand"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/cadence/runtime/ast"
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/errors"
@@ -45,6 +56,21 @@ type TestContractType struct {
 	containFunction          interpreter.FunctionValue
 	beLessThanFunction       interpreter.FunctionValue
 	expectFailureFunction    interpreter.FunctionValue
+	forAllFunction           interpreter.FunctionValue
+	emitFunction             interpreter.FunctionValue
+	emitCountFunction        interpreter.FunctionValue
+	notEmitFunction          interpreter.FunctionValue
+	emitInOrderFunction      interpreter.FunctionValue
+	beOfTypeFunction         interpreter.FunctionValue
+	beSubtypeOfFunction      interpreter.FunctionValue
+	beCloseToFunction        interpreter.FunctionValue
+	containKeyFunction       interpreter.FunctionValue
+	haveKeysFunction         interpreter.FunctionValue
+	matchRegexFunction       interpreter.FunctionValue
+	allElementsFunction      interpreter.FunctionValue
+	anyElementFunction       interpreter.FunctionValue
+	mockContractFunctionType *sema.FunctionType
+	stubContractFunctionType *sema.FunctionType
 }
 
 // 'Test.assert' function
@@ -284,6 +310,9 @@ func newTestTypeExpectFunction(functionType *sema.FunctionType) interpreter.Func
 					"given value is: %s",
 					value,
 				)
+				if description, ok := matcherDescription(inter, matcher, locationRange); ok {
+					message = fmt.Sprintf("%s, expected it to %s", message, description)
+				}
 				panic(AssertionError{
 					Message:       message,
 					LocationRange: invocation.LocationRange,
@@ -511,25 +540,32 @@ func newTestTypeEqualFunction(
 				matcherTestFunctionType,
 				func(invocation interpreter.Invocation) interpreter.Value {
 
-					thisValue, ok := invocation.Arguments[0].(interpreter.EquatableValue)
+					locationRange := invocation.LocationRange
+
+					thisValue, ok := dereferenceValue(
+						inter,
+						invocation.Arguments[0],
+						locationRange,
+					).(interpreter.EquatableValue)
 					if !ok {
 						panic(errors.NewUnreachableError())
 					}
 
 					equal := thisValue.Equal(
 						inter,
-						invocation.LocationRange,
-						otherValue,
+						locationRange,
+						dereferenceValue(inter, otherValue, locationRange),
 					)
 
 					return interpreter.AsBoolValue(equal)
 				},
 			)
 
-			return newMatcherWithGenericTestFunction(
+			return newDescribedMatcher(
 				invocation,
 				equalTestFunc,
 				matcherTestFunctionType,
+				fmt.Sprintf("be equal to %s", otherValue),
 			)
 		},
 	)
@@ -559,12 +595,20 @@ func newTestTypeBeEmptyFunction(
 	return interpreter.NewUnmeteredHostFunctionValue(
 		beEmptyFunctionType,
 		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+
 			beEmptyTestFunc := interpreter.NewHostFunctionValue(
 				nil,
 				matcherTestFunctionType,
 				func(invocation interpreter.Invocation) interpreter.Value {
+					testedValue := dereferenceValue(
+						inter,
+						invocation.Arguments[0],
+						invocation.LocationRange,
+					)
+
 					var isEmpty bool
-					switch value := invocation.Arguments[0].(type) {
+					switch value := testedValue.(type) {
 					case *interpreter.ArrayValue:
 						isEmpty = value.Count() == 0
 					case *interpreter.DictionaryValue:
@@ -577,10 +621,11 @@ func newTestTypeBeEmptyFunction(
 				},
 			)
 
-			return newMatcherWithGenericTestFunction(
+			return newDescribedMatcher(
 				invocation,
 				beEmptyTestFunc,
 				matcherTestFunctionType,
+				"be empty",
 			)
 		},
 	)
@@ -623,12 +668,20 @@ func newTestTypeHaveElementCountFunction(
 				panic(errors.NewUnreachableError())
 			}
 
+			inter := invocation.Interpreter
+
 			haveElementCountTestFunc := interpreter.NewHostFunctionValue(
 				nil,
 				matcherTestFunctionType,
 				func(invocation interpreter.Invocation) interpreter.Value {
+					testedValue := dereferenceValue(
+						inter,
+						invocation.Arguments[0],
+						invocation.LocationRange,
+					)
+
 					var matchingCount bool
-					switch value := invocation.Arguments[0].(type) {
+					switch value := testedValue.(type) {
 					case *interpreter.ArrayValue:
 						matchingCount = value.Count() == count.ToInt(invocation.LocationRange)
 					case *interpreter.DictionaryValue:
@@ -641,10 +694,11 @@ func newTestTypeHaveElementCountFunction(
 				},
 			)
 
-			return newMatcherWithGenericTestFunction(
+			return newDescribedMatcher(
 				invocation,
 				haveElementCountTestFunc,
 				matcherTestFunctionType,
+				fmt.Sprintf("have %s elements", count),
 			)
 		},
 	)
@@ -694,8 +748,14 @@ func newTestTypeContainFunction(
 				nil,
 				matcherTestFunctionType,
 				func(invocation interpreter.Invocation) interpreter.Value {
+					testedValue := dereferenceValue(
+						inter,
+						invocation.Arguments[0],
+						invocation.LocationRange,
+					)
+
 					var elementFound interpreter.BoolValue
-					switch value := invocation.Arguments[0].(type) {
+					switch value := testedValue.(type) {
 					case *interpreter.ArrayValue:
 						elementFound = value.Contains(
 							inter,
@@ -716,10 +776,11 @@ func newTestTypeContainFunction(
 				},
 			)
 
-			return newMatcherWithGenericTestFunction(
+			return newDescribedMatcher(
 				invocation,
 				containTestFunc,
 				matcherTestFunctionType,
+				fmt.Sprintf("contain %s", element),
 			)
 		},
 	)
@@ -768,7 +829,11 @@ func newTestTypeBeGreaterThanFunction(
 				nil,
 				matcherTestFunctionType,
 				func(invocation interpreter.Invocation) interpreter.Value {
-					thisValue, ok := invocation.Arguments[0].(interpreter.NumberValue)
+					thisValue, ok := dereferenceValue(
+						inter,
+						invocation.Arguments[0],
+						invocation.LocationRange,
+					).(interpreter.NumberValue)
 					if !ok {
 						panic(errors.NewUnreachableError())
 					}
@@ -783,10 +848,11 @@ func newTestTypeBeGreaterThanFunction(
 				},
 			)
 
-			return newMatcherWithGenericTestFunction(
+			return newDescribedMatcher(
 				invocation,
 				beGreaterThanTestFunc,
 				matcherTestFunctionType,
+				fmt.Sprintf("be greater than %s", otherValue),
 			)
 		},
 	)
@@ -921,7 +987,11 @@ func newTestTypeBeLessThanFunction(
 				nil,
 				matcherTestFunctionType,
 				func(invocation interpreter.Invocation) interpreter.Value {
-					thisValue, ok := invocation.Arguments[0].(interpreter.NumberValue)
+					thisValue, ok := dereferenceValue(
+						inter,
+						invocation.Arguments[0],
+						invocation.LocationRange,
+					).(interpreter.NumberValue)
 					if !ok {
 						panic(errors.NewUnreachableError())
 					}
@@ -936,202 +1006,2071 @@ func newTestTypeBeLessThanFunction(
 				},
 			)
 
-			return newMatcherWithGenericTestFunction(
+			return newDescribedMatcher(
 				invocation,
 				beLessThanTestFunc,
 				matcherTestFunctionType,
+				fmt.Sprintf("be less than %s", otherValue),
 			)
 		},
 	)
 }
 
-func newTestContractType() *TestContractType {
+// `Test.beOfType`
 
-	program, err := parser.ParseProgram(
-		nil,
-		contracts.TestContract,
-		parser.Config{},
-	)
-	if err != nil {
-		panic(err)
-	}
+const testTypeBeOfTypeFunctionName = "beOfType"
 
-	activation := sema.NewVariableActivation(sema.BaseValueActivation)
-	activation.DeclareValue(AssertFunction)
-	activation.DeclareValue(PanicFunction)
+const testTypeBeOfTypeFunctionDocString = `
+Returns a matcher that succeeds if the tested value is of the given type.
+If the tested value is a reference, the matcher also succeeds
+if the referenced value is of the given type.
+`
 
-	checker, err := sema.NewChecker(
-		program,
-		TestContractLocation,
-		nil,
-		&sema.Config{
-			BaseValueActivationHandler: func(_ common.Location) *sema.VariableActivation {
-				return activation
+func newTestTypeBeOfTypeFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		TypeParameters: []*sema.TypeParameter{},
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "type",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.MetaType,
+				),
 			},
-			AccessCheckMode: sema.AccessCheckModeStrict,
 		},
-	)
-	if err != nil {
-		panic(err)
-	}
-
-	err = checker.Check()
-	if err != nil {
-		panic(err)
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
 	}
+}
 
-	variable, ok := checker.Elaboration.GetGlobalType(testContractTypeName)
-	if !ok {
-		panic(errors.NewUnreachableError())
-	}
-	compositeType := variable.Type.(*sema.CompositeType)
+func newTestTypeBeOfTypeFunction(
+	beOfTypeFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return newTypeMatcherFunction(
+		beOfTypeFunctionType,
+		matcherTestFunctionType,
+		"be of type %s",
+		func(_ *interpreter.Interpreter, valueType, expectedType interpreter.StaticType) bool {
+			return valueType.Equal(expectedType)
+		},
+	)
+}
 
-	initializerTypes := make([]sema.Type, len(compositeType.ConstructorParameters))
-	for i, parameter := range compositeType.ConstructorParameters {
-		initializerTypes[i] = parameter.TypeAnnotation.Type
-	}
+// `Test.beSubtypeOf`
 
-	ty := &TestContractType{
-		Checker:          checker,
-		CompositeType:    compositeType,
-		InitializerTypes: initializerTypes,
-	}
+const testTypeBeSubtypeOfFunctionName = "beSubtypeOf"
 
-	blockchainBackendInterfaceType := ty.blockchainBackendInterfaceType()
+const testTypeBeSubtypeOfFunctionDocString = `
+Returns a matcher that succeeds if the type of the tested value is a subtype of the given type.
+If the tested value is a reference, the matcher also succeeds
+if the type of the referenced value is a subtype of the given type.
+`
 
-	emulatorBackendType := newTestEmulatorBackendType(blockchainBackendInterfaceType)
-	ty.emulatorBackendType = emulatorBackendType
+func newTestTypeBeSubtypeOfFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		TypeParameters: []*sema.TypeParameter{},
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "type",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.MetaType,
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
+	}
+}
 
-	// Enrich 'Test' contract elaboration with natively implemented composite types.
-	// e.g: 'EmulatorBackend' type.
-	checker.Elaboration.SetCompositeType(
-		emulatorBackendType.compositeType.ID(),
-		emulatorBackendType.compositeType,
+func newTestTypeBeSubtypeOfFunction(
+	beSubtypeOfFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return newTypeMatcherFunction(
+		beSubtypeOfFunctionType,
+		matcherTestFunctionType,
+		"be a subtype of %s",
+		func(inter *interpreter.Interpreter, valueType, expectedType interpreter.StaticType) bool {
+			return inter.IsSubType(valueType, expectedType)
+		},
 	)
+}
 
-	matcherType := ty.matcherType()
-	matcherTestFunctionType := compositeFunctionType(matcherType, matcherTestFunctionName)
+// newTypeMatcherFunction returns a function which creates a matcher
+// that tests the type of the tested value, or of the referenced value,
+// against the given type, using the given predicate.
+func newTypeMatcherFunction(
+	functionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+	descriptionFormat string,
+	matches func(inter *interpreter.Interpreter, valueType, expectedType interpreter.StaticType) bool,
+) interpreter.FunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		functionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			typeValue, ok := invocation.Arguments[0].(interpreter.TypeValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
 
-	// Test.assert()
-	compositeType.Members.Set(
-		testTypeAssertFunctionName,
-		sema.NewUnmeteredPublicFunctionMember(
-			compositeType,
-			testTypeAssertFunctionName,
-			testTypeAssertFunctionType,
-			testTypeAssertFunctionDocString,
-		),
-	)
+			inter := invocation.Interpreter
 
-	// Test.assertEqual()
-	compositeType.Members.Set(
-		testTypeAssertEqualFunctionName,
-		sema.NewUnmeteredPublicFunctionMember(
-			compositeType,
-			testTypeAssertEqualFunctionName,
-			testTypeAssertEqualFunctionType,
-			testTypeAssertEqualFunctionDocString,
-		),
-	)
+			typeTestFunc := interpreter.NewHostFunctionValue(
+				nil,
+				matcherTestFunctionType,
+				func(invocation interpreter.Invocation) interpreter.Value {
+					expectedType := typeValue.Type
+					if expectedType == nil {
+						return interpreter.FalseValue
+					}
 
-	// Test.fail()
-	compositeType.Members.Set(
-		testTypeFailFunctionName,
-		sema.NewUnmeteredPublicFunctionMember(
-			compositeType,
-			testTypeFailFunctionName,
-			testTypeFailFunctionType,
-			testTypeFailFunctionDocString,
-		),
-	)
+					value := invocation.Arguments[0]
+					if matches(inter, value.StaticType(inter), expectedType) {
+						return interpreter.TrueValue
+					}
 
-	// Test.readFile()
-	compositeType.Members.Set(
-		testTypeReadFileFunctionName,
-		sema.NewUnmeteredPublicFunctionMember(
-			compositeType,
-			testTypeReadFileFunctionName,
-			testTypeReadFileFunctionType,
-			testTypeReadFileFunctionDocString,
-		),
-	)
+					if _, ok := value.(interpreter.ReferenceValue); !ok {
+						return interpreter.FalseValue
+					}
 
-	// Test.expect()
-	testExpectFunctionType := newTestTypeExpectFunctionType(matcherType)
-	compositeType.Members.Set(
-		testTypeExpectFunctionName,
-		sema.NewUnmeteredPublicFunctionMember(
-			compositeType,
-			testTypeExpectFunctionName,
-			testExpectFunctionType,
-			testTypeExpectFunctionDocString,
-		),
-	)
-	ty.expectFunction = newTestTypeExpectFunction(testExpectFunctionType)
+					referencedValue := dereferenceValue(
+						inter,
+						value,
+						invocation.LocationRange,
+					)
 
-	// Test.newMatcher()
-	newMatcherFunctionType := newTestTypeNewMatcherFunctionType(matcherType)
-	compositeType.Members.Set(
-		testTypeNewMatcherFunctionName,
-		sema.NewUnmeteredPublicFunctionMember(
-			compositeType,
-			testTypeNewMatcherFunctionName,
-			newMatcherFunctionType,
-			testTypeNewMatcherFunctionDocString,
-		),
-	)
-	ty.newMatcherFunction = newTestTypeNewMatcherFunction(
-		newMatcherFunctionType,
-		matcherTestFunctionType,
-	)
+					return interpreter.AsBoolValue(
+						matches(inter, referencedValue.StaticType(inter), expectedType),
+					)
+				},
+			)
 
-	// Test.equal()
-	equalMatcherFunctionType := newTestTypeEqualFunctionType(matcherType)
-	compositeType.Members.Set(
-		testTypeEqualFunctionName,
-		sema.NewUnmeteredPublicFunctionMember(
-			compositeType,
-			testTypeEqualFunctionName,
-			equalMatcherFunctionType,
-			testTypeEqualFunctionDocString,
-		),
-	)
-	ty.equalFunction = newTestTypeEqualFunction(
-		equalMatcherFunctionType,
-		matcherTestFunctionType,
+			return newDescribedMatcher(
+				invocation,
+				typeTestFunc,
+				matcherTestFunctionType,
+				fmt.Sprintf(descriptionFormat, typeValueIdentifier(typeValue)),
+			)
+		},
 	)
+}
 
-	// Test.beEmpty()
-	beEmptyMatcherFunctionType := newTestTypeBeEmptyFunctionType(matcherType)
-	compositeType.Members.Set(
-		testTypeBeEmptyFunctionName,
-		sema.NewUnmeteredPublicFunctionMember(
-			compositeType,
-			testTypeBeEmptyFunctionName,
-			beEmptyMatcherFunctionType,
-			testTypeBeEmptyFunctionDocString,
-		),
-	)
-	ty.beEmptyFunction = newTestTypeBeEmptyFunction(
-		beEmptyMatcherFunctionType,
-		matcherTestFunctionType,
-	)
+// typeValueIdentifier returns the identifier of the given type value,
+// like the 'identifier' field of 'Type'.
+func typeValueIdentifier(typeValue interpreter.TypeValue) string {
+	if typeValue.Type == nil {
+		return ""
+	}
+	return string(typeValue.Type.ID())
+}
 
-	// Test.haveElementCount()
-	haveElementCountMatcherFunctionType := newTestTypeHaveElementCountFunctionType(matcherType)
-	compositeType.Members.Set(
-		testTypeHaveElementCountFunctionName,
-		sema.NewUnmeteredPublicFunctionMember(
-			compositeType,
-			testTypeHaveElementCountFunctionName,
-			haveElementCountMatcherFunctionType,
-			testTypeHaveElementCountFunctionDocString,
-		),
-	)
-	ty.haveElementCountFunction = newTestTypeHaveElementCountFunction(
-		haveElementCountMatcherFunctionType,
-		matcherTestFunctionType,
-	)
+// `Test.beCloseTo`
+
+const testTypeBeCloseToFunctionName = "beCloseTo"
+
+const testTypeBeCloseToFunctionDocString = `
+Returns a matcher that succeeds if the tested value is a fixed-point number,
+and differs from the given value by at most the given tolerance.
+`
+
+func newTestTypeBeCloseToFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		TypeParameters: []*sema.TypeParameter{},
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "value",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.FixedPointType,
+				),
+			},
+			{
+				Identifier: "within",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.FixedPointType,
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
+	}
+}
+
+func newTestTypeBeCloseToFunction(
+	beCloseToFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		beCloseToFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			expectedValue, ok := fixedPointValueToBigInt(invocation.Arguments[0])
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			tolerance, ok := fixedPointValueToBigInt(invocation.Arguments[1])
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			if tolerance.Sign() < 0 {
+				panic(errors.NewDefaultUserError("tolerance must not be negative"))
+			}
+
+			inter := invocation.Interpreter
+
+			beCloseToTestFunc := interpreter.NewHostFunctionValue(
+				nil,
+				matcherTestFunctionType,
+				func(invocation interpreter.Invocation) interpreter.Value {
+					testedValue := dereferenceValue(
+						inter,
+						invocation.Arguments[0],
+						invocation.LocationRange,
+					)
+
+					actualValue, ok := fixedPointValueToBigInt(testedValue)
+					if !ok {
+						panic(errors.NewDefaultUserError("expected Fix64 or UFix64 argument"))
+					}
+
+					difference := new(big.Int).Sub(actualValue, expectedValue)
+
+					return interpreter.AsBoolValue(difference.Abs(difference).Cmp(tolerance) <= 0)
+				},
+			)
+
+			return newDescribedMatcher(
+				invocation,
+				beCloseToTestFunc,
+				matcherTestFunctionType,
+				fmt.Sprintf(
+					"be close to %s within %s",
+					invocation.Arguments[0],
+					invocation.Arguments[1],
+				),
+			)
+		},
+	)
+}
+
+// fixedPointValueToBigInt returns the unscaled integer of the given Fix64 or UFix64 value.
+// Both types have the same scale, so the integers of values of either type are comparable.
+func fixedPointValueToBigInt(value interpreter.Value) (*big.Int, bool) {
+	switch value := value.(type) {
+	case interpreter.Fix64Value:
+		return big.NewInt(int64(value)), true
+	case interpreter.UFix64Value:
+		return new(big.Int).SetUint64(uint64(value)), true
+	default:
+		return nil, false
+	}
+}
+
+// `Test.containKey`
+
+const testTypeContainKeyFunctionName = "containKey"
+
+const testTypeContainKeyFunctionDocString = `
+Returns a matcher that succeeds if the tested value is a dictionary
+that contains an entry where the key is equal to the given value.
+`
+
+func newTestTypeContainKeyFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		TypeParameters: []*sema.TypeParameter{},
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "key",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.AnyStructType,
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
+	}
+}
+
+func newTestTypeContainKeyFunction(
+	containKeyFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		containKeyFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			key := invocation.Arguments[0]
+
+			inter := invocation.Interpreter
+
+			containKeyTestFunc := interpreter.NewHostFunctionValue(
+				nil,
+				matcherTestFunctionType,
+				func(invocation interpreter.Invocation) interpreter.Value {
+					locationRange := invocation.LocationRange
+
+					dictionary := dictionaryMatcherArgument(
+						inter,
+						invocation.Arguments[0],
+						locationRange,
+					)
+
+					return dictionary.ContainsKey(inter, locationRange, key)
+				},
+			)
+
+			return newDescribedMatcher(
+				invocation,
+				containKeyTestFunc,
+				matcherTestFunctionType,
+				fmt.Sprintf("contain key %s", key),
+			)
+		},
+	)
+}
+
+// `Test.haveKeys`
+
+const testTypeHaveKeysFunctionName = "haveKeys"
+
+const testTypeHaveKeysFunctionDocString = `
+Returns a matcher that succeeds if the tested value is a dictionary
+that contains entries for all of the given keys.
+`
+
+func newTestTypeHaveKeysFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		TypeParameters: []*sema.TypeParameter{},
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "keys",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					&sema.VariableSizedType{
+						Type: sema.AnyStructType,
+					},
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
+	}
+}
+
+func newTestTypeHaveKeysFunction(
+	haveKeysFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		haveKeysFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+
+			keys, err := arrayValueToSlice(inter, invocation.Arguments[0])
+			if err != nil {
+				panic(err)
+			}
+
+			haveKeysTestFunc := interpreter.NewHostFunctionValue(
+				nil,
+				matcherTestFunctionType,
+				func(invocation interpreter.Invocation) interpreter.Value {
+					locationRange := invocation.LocationRange
+
+					dictionary := dictionaryMatcherArgument(
+						inter,
+						invocation.Arguments[0],
+						locationRange,
+					)
+
+					for _, key := range keys {
+						if !dictionary.ContainsKey(inter, locationRange, key) {
+							return interpreter.FalseValue
+						}
+					}
+
+					return interpreter.TrueValue
+				},
+			)
+
+			return newDescribedMatcher(
+				invocation,
+				haveKeysTestFunc,
+				matcherTestFunctionType,
+				fmt.Sprintf("have keys %s", invocation.Arguments[0]),
+			)
+		},
+	)
+}
+
+// dictionaryMatcherArgument returns the dictionary tested by a matcher,
+// which may also be given as a reference.
+func dictionaryMatcherArgument(
+	inter *interpreter.Interpreter,
+	value interpreter.Value,
+	locationRange interpreter.LocationRange,
+) *interpreter.DictionaryValue {
+	dictionary, ok := dereferenceValue(inter, value, locationRange).(*interpreter.DictionaryValue)
+	if !ok {
+		panic(errors.NewDefaultUserError("expected Dictionary argument"))
+	}
+	return dictionary
+}
+
+// `Test.matchRegex`
+
+const testTypeMatchRegexFunctionName = "matchRegex"
+
+const testTypeMatchRegexFunctionDocString = `
+Returns a matcher that succeeds if the tested value is a string,
+and the given regular expression matches the string.
+The regular expression uses the RE2 syntax, and matches anywhere in the string, unless anchored.
+`
+
+func newTestTypeMatchRegexFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		TypeParameters: []*sema.TypeParameter{},
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "pattern",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.StringType,
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
+	}
+}
+
+func newTestTypeMatchRegexFunction(
+	matchRegexFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		matchRegexFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			pattern, ok := invocation.Arguments[0].(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			regex, err := regexp.Compile(pattern.Str)
+			if err != nil {
+				panic(errors.NewDefaultUserError("invalid regular expression: %s", err))
+			}
+
+			inter := invocation.Interpreter
+
+			matchRegexTestFunc := interpreter.NewHostFunctionValue(
+				nil,
+				matcherTestFunctionType,
+				func(invocation interpreter.Invocation) interpreter.Value {
+					str, ok := dereferenceValue(
+						inter,
+						invocation.Arguments[0],
+						invocation.LocationRange,
+					).(*interpreter.StringValue)
+					if !ok {
+						panic(errors.NewDefaultUserError("expected String argument"))
+					}
+
+					return interpreter.AsBoolValue(regex.MatchString(str.Str))
+				},
+			)
+
+			return newDescribedMatcher(
+				invocation,
+				matchRegexTestFunc,
+				matcherTestFunctionType,
+				fmt.Sprintf("match regular expression %s", pattern),
+			)
+		},
+	)
+}
+
+// `Test.allElements`
+
+const testTypeAllElementsFunctionName = "allElements"
+
+const testTypeAllElementsFunctionDocString = `
+Returns a matcher that succeeds if the tested value is an array or dictionary,
+and all elements of the array, or all values of the dictionary, match the given matcher.
+Resources are tested through references.
+`
+
+func newTestTypeAllElementsFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		TypeParameters: []*sema.TypeParameter{},
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "matcher",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					matcherType,
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
+	}
+}
+
+func newTestTypeAllElementsFunction(
+	allElementsFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return newElementsMatcherFunction(
+		allElementsFunctionType,
+		matcherTestFunctionType,
+		true,
+	)
+}
+
+// `Test.anyElement`
+
+const testTypeAnyElementFunctionName = "anyElement"
+
+const testTypeAnyElementFunctionDocString = `
+Returns a matcher that succeeds if the tested value is an array or dictionary,
+and at least one element of the array, or one value of the dictionary, matches the given matcher.
+Resources are tested through references.
+`
+
+func newTestTypeAnyElementFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		TypeParameters: []*sema.TypeParameter{},
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "matcher",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					matcherType,
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
+	}
+}
+
+func newTestTypeAnyElementFunction(
+	anyElementFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return newElementsMatcherFunction(
+		anyElementFunctionType,
+		matcherTestFunctionType,
+		false,
+	)
+}
+
+// newElementsMatcherFunction returns a function which creates a matcher
+// that tests the elements of the tested value with the given matcher.
+// The created matcher succeeds if all elements match, or, if all is false,
+// if at least one element matches.
+func newElementsMatcherFunction(
+	functionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+	all bool,
+) interpreter.FunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		functionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			matcher, ok := invocation.Arguments[0].(*interpreter.CompositeValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			inter := invocation.Interpreter
+
+			elementsTestFunc := interpreter.NewHostFunctionValue(
+				nil,
+				matcherTestFunctionType,
+				func(invocation interpreter.Invocation) interpreter.Value {
+					locationRange := invocation.LocationRange
+
+					// Without elements, all elements match, but no element matches
+					matches := all
+
+					iterateMatcherElements(
+						inter,
+						invocation.Arguments[0],
+						locationRange,
+						func(element interpreter.Value) bool {
+							matches = invokeMatcherTest(inter, matcher, element, locationRange)
+							// Stop at the first element that decides the result
+							return matches == all
+						},
+					)
+
+					return interpreter.AsBoolValue(matches)
+				},
+			)
+
+			quantifier := "any element"
+			if all {
+				quantifier = "all elements"
+			}
+
+			description, ok := matcherDescription(inter, matcher, invocation.LocationRange)
+			if !ok {
+				description = "match the given matcher"
+			}
+
+			return newDescribedMatcher(
+				invocation,
+				elementsTestFunc,
+				matcherTestFunctionType,
+				fmt.Sprintf("have %s %s", quantifier, description),
+			)
+		},
+	)
+}
+
+// iterateMatcherElements calls the given function with each element of the given array,
+// or each value of the given dictionary, until the function returns false.
+// The array or dictionary may also be given as a reference.
+// Resources are passed as references, so they can be tested by matchers.
+func iterateMatcherElements(
+	inter *interpreter.Interpreter,
+	value interpreter.Value,
+	locationRange interpreter.LocationRange,
+	f func(element interpreter.Value) bool,
+) {
+	elementValue := func(element interpreter.Value) interpreter.Value {
+		if !element.IsResourceKinded(inter) {
+			return element
+		}
+
+		return interpreter.NewEphemeralReferenceValue(
+			inter,
+			false,
+			element,
+			inter.MustConvertStaticToSemaType(element.StaticType(inter)),
+		)
+	}
+
+	switch value := dereferenceValue(inter, value, locationRange).(type) {
+	case *interpreter.ArrayValue:
+		value.Iterate(inter, func(element interpreter.Value) bool {
+			return f(elementValue(element))
+		})
+	case *interpreter.DictionaryValue:
+		value.Iterate(inter, func(_, element interpreter.Value) bool {
+			return f(elementValue(element))
+		})
+	default:
+		panic(errors.NewDefaultUserError("expected Array or Dictionary argument"))
+	}
+}
+
+// 'Test.emit' matcher
+
+const testTypeEmitFunctionName = "emit"
+
+const testTypeEmitFunctionDocString = `
+Returns a matcher that succeeds if the tested value is a transaction result,
+and the transaction emitted an event of the given type.
+If a predicate is given, the event must also satisfy the predicate.
+`
+
+func newTestTypeEmitFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "type",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.MetaType,
+				),
+			},
+			{
+				Identifier: "where",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					&sema.FunctionType{
+						Parameters: []sema.Parameter{
+							{
+								Label:      sema.ArgumentLabelNotRequired,
+								Identifier: "event",
+								TypeAnnotation: sema.NewTypeAnnotation(
+									sema.AnyStructType,
+								),
+							},
+						},
+						ReturnTypeAnnotation: sema.NewTypeAnnotation(
+							sema.BoolType,
+						),
+					},
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
+		// `where` parameter is optional
+		Arity: &sema.Arity{Min: 1, Max: 2},
+	}
+}
+
+func newTestTypeEmitFunction(
+	emitFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		emitFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			eventType, ok := invocation.Arguments[0].(interpreter.TypeValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			var predicate interpreter.FunctionValue
+			if len(invocation.Arguments) > 1 {
+				predicate, ok = invocation.Arguments[1].(interpreter.FunctionValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+			}
+
+			description := fmt.Sprintf("emit an event of type %s", eventType.Type)
+			if predicate != nil {
+				description += " satisfying the given predicate"
+			}
+
+			return newEventsMatcher(
+				invocation,
+				matcherTestFunctionType,
+				description,
+				func(inter *interpreter.Interpreter, events []interpreter.Value) bool {
+					for _, event := range events {
+						if !isEventOfType(inter, event, eventType) {
+							continue
+						}
+
+						if predicate != nil {
+							result, err := inter.InvokeExternally(
+								predicate,
+								predicate.FunctionType(),
+								[]interpreter.Value{
+									event,
+								},
+							)
+							if err != nil {
+								panic(err)
+							}
+
+							matches, ok := result.(interpreter.BoolValue)
+							if !ok {
+								panic(errors.NewUnreachableError())
+							}
+							if !matches {
+								continue
+							}
+						}
+
+						return true
+					}
+
+					return false
+				},
+			)
+		},
+	)
+}
+
+// 'Test.emitCount' matcher
+
+const testTypeEmitCountFunctionName = "emitCount"
+
+const testTypeEmitCountFunctionDocString = `
+Returns a matcher that succeeds if the tested value is a transaction result,
+and the transaction emitted exactly the given number of events of the given type.
+`
+
+func newTestTypeEmitCountFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "type",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.MetaType,
+				),
+			},
+			{
+				Identifier: "count",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.IntType,
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
+	}
+}
+
+func newTestTypeEmitCountFunction(
+	emitCountFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		emitCountFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			eventType, ok := invocation.Arguments[0].(interpreter.TypeValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			countValue, ok := invocation.Arguments[1].(interpreter.IntValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			count := countValue.ToInt(invocation.LocationRange)
+
+			return newEventsMatcher(
+				invocation,
+				matcherTestFunctionType,
+				fmt.Sprintf("emit %d events of type %s", count, eventType.Type),
+				func(inter *interpreter.Interpreter, events []interpreter.Value) bool {
+					return countEventsOfType(inter, events, eventType) == count
+				},
+			)
+		},
+	)
+}
+
+// 'Test.notEmit' matcher
+
+const testTypeNotEmitFunctionName = "notEmit"
+
+const testTypeNotEmitFunctionDocString = `
+Returns a matcher that succeeds if the tested value is a transaction result,
+and the transaction emitted no event of the given type.
+`
+
+func newTestTypeNotEmitFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "type",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.MetaType,
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
+	}
+}
+
+func newTestTypeNotEmitFunction(
+	notEmitFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		notEmitFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			eventType, ok := invocation.Arguments[0].(interpreter.TypeValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			return newEventsMatcher(
+				invocation,
+				matcherTestFunctionType,
+				fmt.Sprintf("not emit an event of type %s", eventType.Type),
+				func(inter *interpreter.Interpreter, events []interpreter.Value) bool {
+					return countEventsOfType(inter, events, eventType) == 0
+				},
+			)
+		},
+	)
+}
+
+// 'Test.emitInOrder' matcher
+
+const testTypeEmitInOrderFunctionName = "emitInOrder"
+
+const testTypeEmitInOrderFunctionDocString = `
+Returns a matcher that succeeds if the tested value is a transaction result,
+and the transaction emitted events of the given types, in the given order.
+Other events may be emitted before, after, and in between.
+`
+
+func newTestTypeEmitInOrderFunctionType(matcherType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		Parameters: []sema.Parameter{
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "types",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.NewVariableSizedType(nil, sema.MetaType),
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(matcherType),
+	}
+}
+
+func newTestTypeEmitInOrderFunction(
+	emitInOrderFunctionType *sema.FunctionType,
+	matcherTestFunctionType *sema.FunctionType,
+) interpreter.FunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		emitInOrderFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+
+			typeValues, err := arrayValueToSlice(inter, invocation.Arguments[0])
+			if err != nil {
+				panic(err)
+			}
+
+			eventTypes := make([]interpreter.TypeValue, len(typeValues))
+			typeNames := make([]string, len(typeValues))
+			for i, typeValue := range typeValues {
+				eventType, ok := typeValue.(interpreter.TypeValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+				eventTypes[i] = eventType
+				typeNames[i] = fmt.Sprint(eventType.Type)
+			}
+
+			return newEventsMatcher(
+				invocation,
+				matcherTestFunctionType,
+				fmt.Sprintf("emit events of types [%s] in order", strings.Join(typeNames, ", ")),
+				func(inter *interpreter.Interpreter, events []interpreter.Value) bool {
+					matched := 0
+					for _, event := range events {
+						if matched < len(eventTypes) &&
+							isEventOfType(inter, event, eventTypes[matched]) {
+
+							matched++
+						}
+					}
+					return matched == len(eventTypes)
+				},
+			)
+		},
+	)
+}
+
+// newEventsMatcher creates a matcher with the given description,
+// which tests the events of a 'TransactionResult' value with the given function.
+func newEventsMatcher(
+	invocation interpreter.Invocation,
+	matcherTestFunctionType *sema.FunctionType,
+	description string,
+	test func(inter *interpreter.Interpreter, events []interpreter.Value) bool,
+) interpreter.Value {
+
+	inter := invocation.Interpreter
+
+	testFunc := interpreter.NewHostFunctionValue(
+		nil,
+		matcherTestFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			events := transactionResultEvents(
+				inter,
+				invocation.Arguments[0],
+				invocation.LocationRange,
+			)
+
+			return interpreter.AsBoolValue(test(inter, events))
+		},
+	)
+
+	return newDescribedMatcher(
+		invocation,
+		testFunc,
+		matcherTestFunctionType,
+		description,
+	)
+}
+
+// isEventOfType returns true if the given event has exactly the given type.
+func isEventOfType(
+	inter *interpreter.Interpreter,
+	event interpreter.Value,
+	eventType interpreter.TypeValue,
+) bool {
+	return eventType.Type != nil &&
+		event.StaticType(inter).Equal(eventType.Type)
+}
+
+// countEventsOfType returns the number of the given events which have exactly the given type.
+func countEventsOfType(
+	inter *interpreter.Interpreter,
+	events []interpreter.Value,
+	eventType interpreter.TypeValue,
+) int {
+	count := 0
+	for _, event := range events {
+		if isEventOfType(inter, event, eventType) {
+			count++
+		}
+	}
+	return count
+}
+
+// transactionResultEvents returns the events of the given 'TransactionResult' value.
+func transactionResultEvents(
+	inter *interpreter.Interpreter,
+	value interpreter.Value,
+	locationRange interpreter.LocationRange,
+) []interpreter.Value {
+
+	transactionResult, ok := value.(*interpreter.CompositeValue)
+	if !ok ||
+		transactionResult.Location != TestContractLocation ||
+		transactionResult.QualifiedIdentifier != testTransactionResultQualifiedIdentifier {
+
+		panic(errors.NewDefaultUserError("expected TransactionResult argument"))
+	}
+
+	events, err := arrayValueToSlice(
+		inter,
+		transactionResult.GetMember(
+			inter,
+			locationRange,
+			transactionResultEventsFieldName,
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return events
+}
+
+// 'Test.forAll' function
+
+const testTypeForAllFunctionDocString = `
+Runs the given property with values produced by the given generator,
+and fails the test if the property does not hold for one of the values.
+The value is then shrunk to a minimal counterexample.
+The values are reproducible when a seed is given.
+By default, the property is run 100 times.
+`
+
+const testTypeForAllFunctionName = "forAll"
+
+const forAllDefaultRuns = 100
+
+const forAllMaxShrinks = 1000
+
+func newTestTypeForAllFunctionType(generatorType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		Parameters: []sema.Parameter{
+			{
+				Label:          sema.ArgumentLabelNotRequired,
+				Identifier:     "generator",
+				TypeAnnotation: sema.NewTypeAnnotation(generatorType),
+			},
+			{
+				Label:      sema.ArgumentLabelNotRequired,
+				Identifier: "property",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					&sema.FunctionType{
+						Parameters: []sema.Parameter{
+							{
+								Label:      sema.ArgumentLabelNotRequired,
+								Identifier: "value",
+								TypeAnnotation: sema.NewTypeAnnotation(
+									sema.AnyStructType,
+								),
+							},
+						},
+						ReturnTypeAnnotation: sema.NewTypeAnnotation(
+							sema.BoolType,
+						),
+					},
+				),
+			},
+			{
+				Identifier: "seed",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.UInt64Type,
+				),
+			},
+			{
+				Identifier: "runs",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.IntType,
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(
+			sema.VoidType,
+		),
+		// `seed` and `runs` parameters are optional
+		Arity: &sema.Arity{Min: 2, Max: 4},
+	}
+}
+
+func newTestTypeForAllFunction(functionType *sema.FunctionType) interpreter.FunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		functionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+			locationRange := invocation.LocationRange
+
+			generator, ok := invocation.Arguments[0].(*interpreter.CompositeValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			property, ok := invocation.Arguments[1].(interpreter.FunctionValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			seed := uint64(time.Now().UnixNano())
+			if len(invocation.Arguments) > 2 {
+				seedValue, ok := invocation.Arguments[2].(interpreter.UInt64Value)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+				seed = uint64(seedValue)
+			}
+
+			runs := forAllDefaultRuns
+			if len(invocation.Arguments) > 3 {
+				runsValue, ok := invocation.Arguments[3].(interpreter.IntValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+				runs = runsValue.ToInt(locationRange)
+			}
+
+			if runs <= 0 {
+				panic(errors.NewDefaultUserError("number of runs must be positive"))
+			}
+
+			check := newPropertyCheck(
+				inter,
+				generator,
+				property,
+				locationRange,
+			)
+
+			err := check.run(seed, runs)
+			if err != nil {
+				// Report the failure at the call of 'forAll',
+				// instead of the last statement of the property
+				panic(interpreter.PositionedError{
+					Err: TestFailedError{
+						Err: err,
+					},
+					Range: ast.NewUnmeteredRangeFromPositioned(locationRange),
+				})
+			}
+
+			return interpreter.Void
+		},
+	)
+}
+
+// propertyCheck runs a property against the values of a generator.
+type propertyCheck struct {
+	inter     *interpreter.Interpreter
+	generate  interpreter.FunctionValue
+	shrink    interpreter.FunctionValue
+	transform interpreter.FunctionValue
+	property  interpreter.FunctionValue
+}
+
+func newPropertyCheck(
+	inter *interpreter.Interpreter,
+	generator *interpreter.CompositeValue,
+	property interpreter.FunctionValue,
+	locationRange interpreter.LocationRange,
+) propertyCheck {
+
+	generatorFunction := func(name string) interpreter.FunctionValue {
+		function, ok := generator.GetMember(inter, locationRange, name).(interpreter.FunctionValue)
+		if !ok {
+			panic(errors.NewUnexpectedError(
+				"invalid type for '%s'. expected function",
+				name,
+			))
+		}
+		return function
+	}
+
+	return propertyCheck{
+		inter:     inter,
+		generate:  generatorFunction(generatorGenerateFieldName),
+		shrink:    generatorFunction(generatorShrinkFieldName),
+		transform: generatorFunction(generatorTransformFieldName),
+		property:  property,
+	}
+}
+
+// run runs the property the given number of times,
+// with the values generated from the given seed.
+// If the property does not hold for a value,
+// the value is shrunk and a PropertyFailedError is returned.
+func (c propertyCheck) run(seed uint64, runs int) error {
+	random := rand.New(rand.NewSource(int64(seed)))
+
+	for run := 1; run <= runs; run++ {
+		raw := c.invoke(
+			c.generate,
+			interpreter.NewUnmeteredUInt64Value(random.Uint64()),
+		)
+
+		value, err := c.check(raw)
+		if err == nil {
+			continue
+		}
+
+		value, shrinks, err := c.shrinkFailure(raw, value, err)
+
+		failure := PropertyFailedError{
+			Seed:    seed,
+			Runs:    run,
+			Shrinks: shrinks,
+		}
+		if value != nil {
+			failure.Counterexample = value.String()
+		}
+		if err != errPropertyDoesNotHold {
+			failure.Err = err
+		}
+
+		return failure
+	}
+
+	return nil
+}
+
+var errPropertyDoesNotHold = errors.NewDefaultUserError("property does not hold")
+
+// check runs the property for the given raw value.
+// It returns the value passed to the property, if any,
+// and an error if the property does not hold or failed.
+func (c propertyCheck) check(raw interpreter.Value) (value interpreter.Value, err error) {
+
+	defer c.inter.RecoverErrors(func(internalErr error) {
+		if errors.IsInternalError(internalErr) {
+			panic(internalErr)
+		}
+		err = internalErr
+	})
+
+	value = c.invoke(c.transform, raw)
+
+	holds, ok := c.invoke(c.property, value).(interpreter.BoolValue)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+	if !holds {
+		return value, errPropertyDoesNotHold
+	}
+
+	return value, nil
+}
+
+// shrinkFailure repeatedly replaces the given failing raw value
+// with the first of its shrink candidates which still fails,
+// until no candidate fails anymore.
+func (c propertyCheck) shrinkFailure(
+	raw interpreter.Value,
+	value interpreter.Value,
+	err error,
+) (
+	interpreter.Value,
+	int,
+	error,
+) {
+	shrinks := 0
+
+	for shrinks < forAllMaxShrinks {
+		candidates, sliceErr := arrayValueToSlice(
+			c.inter,
+			c.invoke(c.shrink, raw),
+		)
+		if sliceErr != nil {
+			panic(sliceErr)
+		}
+
+		shrunk := false
+		for _, candidate := range candidates {
+			candidateValue, candidateErr := c.check(candidate)
+			if candidateErr == nil {
+				continue
+			}
+
+			raw = candidate
+			value = candidateValue
+			err = candidateErr
+			shrinks++
+			shrunk = true
+			break
+		}
+
+		if !shrunk {
+			break
+		}
+	}
+
+	return value, shrinks, err
+}
+
+func (c propertyCheck) invoke(
+	function interpreter.FunctionValue,
+	argument interpreter.Value,
+) interpreter.Value {
+	result, err := c.inter.InvokeExternally(
+		function,
+		function.FunctionType(),
+		[]interpreter.Value{
+			argument,
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// 'Test.mockContract' function
+
+const testTypeMockContractFunctionDocString = `
+Replaces the contract with the given name by a test double,
+which is deployed from the given file, and initialized with the given arguments.
+All imports of the contract, from any address, resolve to the test double.
+`
+
+const testTypeMockContractFunctionName = "mockContract"
+
+func newTestTypeMockContractFunctionType(errorType *sema.CompositeType) *sema.FunctionType {
+	return &sema.FunctionType{
+		Parameters: []sema.Parameter{
+			{
+				Identifier: "name",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.StringType,
+				),
+			},
+			{
+				Identifier: "path",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.StringType,
+				),
+			},
+			{
+				Identifier: "arguments",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					&sema.VariableSizedType{
+						Type: sema.AnyStructType,
+					},
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(
+			&sema.OptionalType{
+				Type: errorType,
+			},
+		),
+	}
+}
+
+func newTestTypeMockContractFunction(
+	functionType *sema.FunctionType,
+	testFramework TestFramework,
+) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		functionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+
+			name, ok := invocation.Arguments[0].(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			path, ok := invocation.Arguments[1].(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			args, err := arrayValueToSlice(inter, invocation.Arguments[2])
+			if err != nil {
+				panic(err)
+			}
+
+			code, err := testFramework.ReadFile(path.Str)
+			if err != nil {
+				panic(err)
+			}
+
+			err = testFramework.EmulatorBackend().MockContract(
+				inter,
+				name.Str,
+				code,
+				args,
+			)
+
+			return newNestedErrorValue(invocation, err)
+		},
+	)
+}
+
+// 'Test.stubContract' function
+
+const testTypeStubContractFunctionDocString = `
+Replaces the contract with the given name by a test double,
+which only consists of the given stubbed functions.
+All imports of the contract, from any address, resolve to the test double.
+The stubbed functions return their canned values and record their invocations,
+which can be retrieved using 'calls'.
+`
+
+const testTypeStubContractFunctionName = "stubContract"
+
+func newTestTypeStubContractFunctionType(
+	stubType *sema.CompositeType,
+	errorType *sema.CompositeType,
+) *sema.FunctionType {
+	return &sema.FunctionType{
+		Parameters: []sema.Parameter{
+			{
+				Identifier: "name",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					sema.StringType,
+				),
+			},
+			{
+				Identifier: "stubs",
+				TypeAnnotation: sema.NewTypeAnnotation(
+					&sema.VariableSizedType{
+						Type: stubType,
+					},
+				),
+			},
+		},
+		ReturnTypeAnnotation: sema.NewTypeAnnotation(
+			&sema.OptionalType{
+				Type: errorType,
+			},
+		),
+	}
+}
+
+func newTestTypeStubContractFunction(
+	functionType *sema.FunctionType,
+	testFramework TestFramework,
+) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		functionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+			locationRange := invocation.LocationRange
+
+			name, ok := invocation.Arguments[0].(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			stubs, err := arrayValueToSlice(inter, invocation.Arguments[1])
+			if err != nil {
+				panic(err)
+			}
+
+			var functions, calls strings.Builder
+			returns := make([]interpreter.Value, 0, len(stubs))
+
+			for index, stub := range stubs {
+				stub, ok := stub.(*interpreter.CompositeValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				function, ok := stub.GetMember(
+					inter,
+					locationRange,
+					stubFunctionFieldName,
+				).(*interpreter.StringValue)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+
+				functionName, code, err := stubFunctionCode(function.Str, index)
+				if err != nil {
+					panic(err)
+				}
+				functions.WriteString(code)
+
+				// Each stubbed function starts without recorded calls,
+				// so 'Test.calls' can tell stubbed and unknown functions apart
+				if index > 0 {
+					calls.WriteString(", ")
+				}
+				calls.WriteString("\"")
+				calls.WriteString(functionName)
+				calls.WriteString("\": []")
+
+				returns = append(
+					returns,
+					stub.GetMember(inter, locationRange, stubReturnsFieldName),
+				)
+			}
+
+			code := fmt.Sprintf(
+				stubContractTemplate,
+				name.Str,
+				calls.String(),
+				functions.String(),
+			)
+
+			stubReturns := interpreter.NewArrayValue(
+				inter,
+				locationRange,
+				interpreter.NewVariableSizedStaticType(
+					inter,
+					interpreter.PrimitiveStaticTypeAnyStruct,
+				),
+				common.ZeroAddress,
+				returns...,
+			)
+
+			err = testFramework.EmulatorBackend().MockContract(
+				inter,
+				name.Str,
+				code,
+				[]interpreter.Value{stubReturns},
+			)
+
+			return newNestedErrorValue(invocation, err)
+		},
+	)
+}
+
+// stubContractTemplate is the code of a test double created using 'Test.stubContract',
+// without the stubbed functions.
+const stubContractTemplate = `pub contract %s {
+
+    pub let stubReturns: [AnyStruct]
+
+    pub var stubCalls: {String: [[AnyStruct]]}
+
+    init(stubReturns: [AnyStruct]) {
+        self.stubReturns = stubReturns
+        self.stubCalls = {%s}
+    }
+
+    access(self) fun recordStubCall(_ function: String, _ arguments: [AnyStruct]) {
+        var calls = self.stubCalls[function] ?? []
+        calls.append(arguments)
+        self.stubCalls[function] = calls
+    }
+%s}
+`
+
+// stubFunctionCode returns the name and the code of the stubbed function with the given declaration,
+// which records its invocations, and returns the canned value at the given index.
+func stubFunctionCode(declaration string, index int) (name string, code string, err error) {
+	program, err := parser.ParseProgram(
+		nil,
+		[]byte(declaration+" {}"),
+		parser.Config{},
+	)
+	if err != nil {
+		return "", "", InvalidStubError{
+			Function: declaration,
+			Err:      err,
+		}
+	}
+
+	declarations := program.Declarations()
+	functionDeclarations := program.FunctionDeclarations()
+	if len(declarations) != 1 || len(functionDeclarations) != 1 {
+		return "", "", InvalidStubError{
+			Function: declaration,
+			Err:      errors.NewDefaultUserError("expected a single function declaration"),
+		}
+	}
+
+	functionDeclaration := functionDeclarations[0]
+	name = functionDeclaration.Identifier.Identifier
+
+	var builder strings.Builder
+	builder.WriteString("\n    ")
+
+	if functionDeclaration.Access == ast.AccessNotSpecified {
+		builder.WriteString("pub ")
+	}
+	builder.WriteString(declaration)
+	builder.WriteString(" {\n        self.recordStubCall(\"")
+	builder.WriteString(name)
+	builder.WriteString("\", [")
+
+	if functionDeclaration.ParameterList != nil {
+		for i, parameter := range functionDeclaration.ParameterList.Parameters {
+			if parameter.TypeAnnotation.IsResource {
+				return "", "", InvalidStubError{
+					Function: declaration,
+					Err:      errors.NewDefaultUserError("resource parameters are not supported"),
+				}
+			}
+
+			if i > 0 {
+				builder.WriteString(", ")
+			}
+			builder.WriteString(parameter.Identifier.Identifier)
+		}
+	}
+
+	builder.WriteString("])\n")
+
+	returnTypeAnnotation := functionDeclaration.ReturnTypeAnnotation
+	if returnTypeAnnotation != nil && returnTypeAnnotation.Type != nil {
+		if returnTypeAnnotation.IsResource {
+			return "", "", InvalidStubError{
+				Function: declaration,
+				Err:      errors.NewDefaultUserError("resource return types are not supported"),
+			}
+		}
+
+		returnType := returnTypeAnnotation.Type.String()
+		if returnType != sema.VoidType.Name {
+			builder.WriteString(fmt.Sprintf(
+				"        return self.stubReturns[%d] as! %s\n",
+				index,
+				returnType,
+			))
+		}
+	}
+
+	builder.WriteString("    }\n")
+
+	return name, builder.String(), nil
+}
+
+// 'Test.matchSnapshot' function
+
+const testTypeMatchSnapshotFunctionDocString = `
+Fails the test-case if the given value does not match the snapshot with the given name.
+The snapshot is stored as JSON-Cadence in a file next to the test file.
+If the snapshot does not exist yet, or snapshots are updated, it is written instead.
+`
+
+const testTypeMatchSnapshotFunctionName = "matchSnapshot"
+
+const snapshotFileSuffix = ".snapshot.json"
+
+var testTypeMatchSnapshotFunctionType = &sema.FunctionType{
+	Parameters: []sema.Parameter{
+		{
+			Label:      sema.ArgumentLabelNotRequired,
+			Identifier: "value",
+			TypeAnnotation: sema.NewTypeAnnotation(
+				sema.AnyStructType,
+			),
+		},
+		{
+			Label:      sema.ArgumentLabelNotRequired,
+			Identifier: "name",
+			TypeAnnotation: sema.NewTypeAnnotation(
+				sema.StringType,
+			),
+		},
+	},
+	ReturnTypeAnnotation: sema.NewTypeAnnotation(
+		sema.VoidType,
+	),
+}
+
+func newTestTypeMatchSnapshotFunction(testFramework TestFramework) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		testTypeMatchSnapshotFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+
+			value := invocation.Arguments[0]
+
+			name, ok := invocation.Arguments[1].(*interpreter.StringValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+			err := checkSnapshotName(name.Str)
+			if err != nil {
+				panic(err)
+			}
+
+			valueExporter, ok := testFramework.(ValueExporter)
+			if !ok {
+				panic(errors.NewDefaultUserError("snapshots are not supported by the test framework"))
+			}
+
+			actual, err := valueExporter.ExportValue(inter, value)
+			if err != nil {
+				panic(err)
+			}
+			normalizeSnapshotValue(actual)
+
+			path := name.Str + snapshotFileSuffix
+
+			if !shouldUpdateSnapshots(testFramework) {
+				content, err := testFramework.ReadFile(path)
+				if err == nil {
+					expected, err := jsoncdc.Decode(nil, []byte(content))
+					if err != nil {
+						panic(errors.NewDefaultUserError("invalid snapshot %s: %s", path, err))
+					}
+
+					differences := cadence.Diff(expected, actual)
+					if len(differences) > 0 {
+						panic(AssertionError{
+							Message:       snapshotMismatchMessage(name.Str, differences),
+							LocationRange: invocation.LocationRange,
+						})
+					}
+
+					return interpreter.Void
+				}
+
+				if !goerrors.Is(err, fs.ErrNotExist) {
+					panic(err)
+				}
+			}
+
+			encoded, err := encodeSnapshot(actual)
+			if err != nil {
+				panic(err)
+			}
+
+			err = testFramework.WriteFile(path, encoded)
+			if err != nil {
+				panic(err)
+			}
+
+			return interpreter.Void
+		},
+	)
+}
+
+// checkSnapshotName returns an error if the given snapshot name is empty,
+// or if it could refer to a file outside the directory of the test file.
+func checkSnapshotName(name string) error {
+	if name == "" {
+		return errors.NewDefaultUserError("snapshot name must not be empty")
+	}
+
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return errors.NewDefaultUserError(
+			"invalid snapshot name %q: must not contain path separators or '..'",
+			name,
+		)
+	}
+
+	return nil
+}
+
+// shouldUpdateSnapshots returns true if the given test framework
+// requests that snapshots are regenerated.
+func shouldUpdateSnapshots(testFramework TestFramework) bool {
+	snapshotUpdater, ok := testFramework.(SnapshotUpdater)
+	return ok && snapshotUpdater.UpdateSnapshots()
+}
+
+func snapshotMismatchMessage(name string, differences []cadence.Difference) string {
+	var builder strings.Builder
+	_, _ = fmt.Fprintf(&builder, "value does not match snapshot %s (snapshot != actual):", name)
+	for _, difference := range differences {
+		builder.WriteString("\n  ")
+		builder.WriteString(difference.String())
+	}
+	return builder.String()
+}
+
+// encodeSnapshot encodes the given value as indented JSON-Cadence.
+func encodeSnapshot(value cadence.Value) (string, error) {
+	encoded, err := jsoncdc.Encode(value)
+	if err != nil {
+		return "", err
+	}
+
+	var builder bytes.Buffer
+	err = json.Indent(&builder, encoded, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	builder.WriteByte('\n')
+
+	return builder.String(), nil
+}
+
+// normalizeSnapshotValue sorts the entries of all dictionaries in the given value by their encoded keys,
+// so the encoding of the value is deterministic.
+func normalizeSnapshotValue(value cadence.Value) {
+	switch value := value.(type) {
+	case cadence.Optional:
+		normalizeSnapshotValue(value.Value)
+
+	case cadence.Array:
+		for _, element := range value.Values {
+			normalizeSnapshotValue(element)
+		}
+
+	case cadence.Dictionary:
+		keys := make([]string, len(value.Pairs))
+		for i, pair := range value.Pairs {
+			normalizeSnapshotValue(pair.Key)
+			normalizeSnapshotValue(pair.Value)
+
+			encodedKey, err := jsoncdc.Encode(pair.Key)
+			if err != nil {
+				panic(err)
+			}
+			keys[i] = string(encodedKey)
+		}
+
+		sort.Sort(snapshotPairs{
+			pairs: value.Pairs,
+			keys:  keys,
+		})
+
+	case cadence.Struct:
+		normalizeSnapshotFields(value.Fields)
+	case cadence.Resource:
+		normalizeSnapshotFields(value.Fields)
+	case cadence.Event:
+		normalizeSnapshotFields(value.Fields)
+	case cadence.Contract:
+		normalizeSnapshotFields(value.Fields)
+	case cadence.Attachment:
+		normalizeSnapshotFields(value.Fields)
+	case cadence.Enum:
+		normalizeSnapshotFields(value.Fields)
+	}
+}
+
+func normalizeSnapshotFields(fields []cadence.Value) {
+	for _, field := range fields {
+		normalizeSnapshotValue(field)
+	}
+}
+
+// snapshotPairs sorts dictionary entries by their encoded keys.
+type snapshotPairs struct {
+	pairs []cadence.KeyValuePair
+	keys  []string
+}
+
+var _ sort.Interface = snapshotPairs{}
+
+func (p snapshotPairs) Len() int {
+	return len(p.pairs)
+}
+
+func (p snapshotPairs) Less(i, j int) bool {
+	return p.keys[i] < p.keys[j]
+}
+
+func (p snapshotPairs) Swap(i, j int) {
+	p.pairs[i], p.pairs[j] = p.pairs[j], p.pairs[i]
+	p.keys[i], p.keys[j] = p.keys[j], p.keys[i]
+}
+
+func newTestContractType() *TestContractType {
+
+	program, err := parser.ParseProgram(
+		nil,
+		contracts.TestContract,
+		parser.Config{},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	activation := sema.NewVariableActivation(sema.BaseValueActivation)
+	activation.DeclareValue(AssertFunction)
+	activation.DeclareValue(PanicFunction)
+
+	checker, err := sema.NewChecker(
+		program,
+		TestContractLocation,
+		nil,
+		&sema.Config{
+			BaseValueActivationHandler: func(_ common.Location) *sema.VariableActivation {
+				return activation
+			},
+			AccessCheckMode: sema.AccessCheckModeStrict,
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	err = checker.Check()
+	if err != nil {
+		panic(err)
+	}
+
+	variable, ok := checker.Elaboration.GetGlobalType(testContractTypeName)
+	if !ok {
+		panic(errors.NewUnreachableError())
+	}
+	compositeType := variable.Type.(*sema.CompositeType)
+
+	initializerTypes := make([]sema.Type, len(compositeType.ConstructorParameters))
+	for i, parameter := range compositeType.ConstructorParameters {
+		initializerTypes[i] = parameter.TypeAnnotation.Type
+	}
+
+	ty := &TestContractType{
+		Checker:          checker,
+		CompositeType:    compositeType,
+		InitializerTypes: initializerTypes,
+	}
+
+	blockchainBackendInterfaceType := ty.blockchainBackendInterfaceType()
+
+	emulatorBackendType := newTestEmulatorBackendType(blockchainBackendInterfaceType)
+	ty.emulatorBackendType = emulatorBackendType
+
+	// Enrich 'Test' contract elaboration with natively implemented composite types.
+	// e.g: 'EmulatorBackend' type.
+	checker.Elaboration.SetCompositeType(
+		emulatorBackendType.compositeType.ID(),
+		emulatorBackendType.compositeType,
+	)
+
+	matcherType := ty.matcherType()
+	matcherTestFunctionType := compositeFunctionType(matcherType, matcherTestFunctionName)
+
+	// Test.assert()
+	compositeType.Members.Set(
+		testTypeAssertFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeAssertFunctionName,
+			testTypeAssertFunctionType,
+			testTypeAssertFunctionDocString,
+		),
+	)
+
+	// Test.assertEqual()
+	compositeType.Members.Set(
+		testTypeAssertEqualFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeAssertEqualFunctionName,
+			testTypeAssertEqualFunctionType,
+			testTypeAssertEqualFunctionDocString,
+		),
+	)
+
+	// Test.fail()
+	compositeType.Members.Set(
+		testTypeFailFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeFailFunctionName,
+			testTypeFailFunctionType,
+			testTypeFailFunctionDocString,
+		),
+	)
+
+	// Test.readFile()
+	compositeType.Members.Set(
+		testTypeReadFileFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeReadFileFunctionName,
+			testTypeReadFileFunctionType,
+			testTypeReadFileFunctionDocString,
+		),
+	)
+
+	// Test.matchSnapshot()
+	compositeType.Members.Set(
+		testTypeMatchSnapshotFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeMatchSnapshotFunctionName,
+			testTypeMatchSnapshotFunctionType,
+			testTypeMatchSnapshotFunctionDocString,
+		),
+	)
+
+	// Test.expect()
+	testExpectFunctionType := newTestTypeExpectFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeExpectFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeExpectFunctionName,
+			testExpectFunctionType,
+			testTypeExpectFunctionDocString,
+		),
+	)
+	ty.expectFunction = newTestTypeExpectFunction(testExpectFunctionType)
+
+	// Test.newMatcher()
+	newMatcherFunctionType := newTestTypeNewMatcherFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeNewMatcherFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeNewMatcherFunctionName,
+			newMatcherFunctionType,
+			testTypeNewMatcherFunctionDocString,
+		),
+	)
+	ty.newMatcherFunction = newTestTypeNewMatcherFunction(
+		newMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.equal()
+	equalMatcherFunctionType := newTestTypeEqualFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeEqualFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeEqualFunctionName,
+			equalMatcherFunctionType,
+			testTypeEqualFunctionDocString,
+		),
+	)
+	ty.equalFunction = newTestTypeEqualFunction(
+		equalMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.beEmpty()
+	beEmptyMatcherFunctionType := newTestTypeBeEmptyFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeBeEmptyFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeBeEmptyFunctionName,
+			beEmptyMatcherFunctionType,
+			testTypeBeEmptyFunctionDocString,
+		),
+	)
+	ty.beEmptyFunction = newTestTypeBeEmptyFunction(
+		beEmptyMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.haveElementCount()
+	haveElementCountMatcherFunctionType := newTestTypeHaveElementCountFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeHaveElementCountFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeHaveElementCountFunctionName,
+			haveElementCountMatcherFunctionType,
+			testTypeHaveElementCountFunctionDocString,
+		),
+	)
+	ty.haveElementCountFunction = newTestTypeHaveElementCountFunction(
+		haveElementCountMatcherFunctionType,
+		matcherTestFunctionType,
+	)
 
 	// Test.contain()
 	containMatcherFunctionType := newTestTypeContainFunctionType(matcherType)
@@ -1181,6 +3120,134 @@ func newTestContractType() *TestContractType {
 		matcherTestFunctionType,
 	)
 
+	// Test.beOfType()
+	beOfTypeMatcherFunctionType := newTestTypeBeOfTypeFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeBeOfTypeFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeBeOfTypeFunctionName,
+			beOfTypeMatcherFunctionType,
+			testTypeBeOfTypeFunctionDocString,
+		),
+	)
+	ty.beOfTypeFunction = newTestTypeBeOfTypeFunction(
+		beOfTypeMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.beSubtypeOf()
+	beSubtypeOfMatcherFunctionType := newTestTypeBeSubtypeOfFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeBeSubtypeOfFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeBeSubtypeOfFunctionName,
+			beSubtypeOfMatcherFunctionType,
+			testTypeBeSubtypeOfFunctionDocString,
+		),
+	)
+	ty.beSubtypeOfFunction = newTestTypeBeSubtypeOfFunction(
+		beSubtypeOfMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.beCloseTo()
+	beCloseToMatcherFunctionType := newTestTypeBeCloseToFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeBeCloseToFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeBeCloseToFunctionName,
+			beCloseToMatcherFunctionType,
+			testTypeBeCloseToFunctionDocString,
+		),
+	)
+	ty.beCloseToFunction = newTestTypeBeCloseToFunction(
+		beCloseToMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.containKey()
+	containKeyMatcherFunctionType := newTestTypeContainKeyFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeContainKeyFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeContainKeyFunctionName,
+			containKeyMatcherFunctionType,
+			testTypeContainKeyFunctionDocString,
+		),
+	)
+	ty.containKeyFunction = newTestTypeContainKeyFunction(
+		containKeyMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.haveKeys()
+	haveKeysMatcherFunctionType := newTestTypeHaveKeysFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeHaveKeysFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeHaveKeysFunctionName,
+			haveKeysMatcherFunctionType,
+			testTypeHaveKeysFunctionDocString,
+		),
+	)
+	ty.haveKeysFunction = newTestTypeHaveKeysFunction(
+		haveKeysMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.matchRegex()
+	matchRegexMatcherFunctionType := newTestTypeMatchRegexFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeMatchRegexFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeMatchRegexFunctionName,
+			matchRegexMatcherFunctionType,
+			testTypeMatchRegexFunctionDocString,
+		),
+	)
+	ty.matchRegexFunction = newTestTypeMatchRegexFunction(
+		matchRegexMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.allElements()
+	allElementsMatcherFunctionType := newTestTypeAllElementsFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeAllElementsFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeAllElementsFunctionName,
+			allElementsMatcherFunctionType,
+			testTypeAllElementsFunctionDocString,
+		),
+	)
+	ty.allElementsFunction = newTestTypeAllElementsFunction(
+		allElementsMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.anyElement()
+	anyElementMatcherFunctionType := newTestTypeAnyElementFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeAnyElementFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeAnyElementFunctionName,
+			anyElementMatcherFunctionType,
+			testTypeAnyElementFunctionDocString,
+		),
+	)
+	ty.anyElementFunction = newTestTypeAnyElementFunction(
+		anyElementMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
 	// Test.expectFailure()
 	expectFailureFunctionType := newTestTypeExpectFailureFunctionType()
 	compositeType.Members.Set(
@@ -1195,6 +3262,111 @@ func newTestContractType() *TestContractType {
 	ty.expectFailureFunction = newTestTypeExpectFailureFunction(
 		expectFailureFunctionType,
 	)
+
+	// Test.forAll()
+	forAllFunctionType := newTestTypeForAllFunctionType(ty.generatorType())
+	compositeType.Members.Set(
+		testTypeForAllFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeForAllFunctionName,
+			forAllFunctionType,
+			testTypeForAllFunctionDocString,
+		),
+	)
+	ty.forAllFunction = newTestTypeForAllFunction(forAllFunctionType)
+
+	// Test.emit()
+	emitMatcherFunctionType := newTestTypeEmitFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeEmitFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeEmitFunctionName,
+			emitMatcherFunctionType,
+			testTypeEmitFunctionDocString,
+		),
+	)
+	ty.emitFunction = newTestTypeEmitFunction(
+		emitMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.emitCount()
+	emitCountMatcherFunctionType := newTestTypeEmitCountFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeEmitCountFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeEmitCountFunctionName,
+			emitCountMatcherFunctionType,
+			testTypeEmitCountFunctionDocString,
+		),
+	)
+	ty.emitCountFunction = newTestTypeEmitCountFunction(
+		emitCountMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.notEmit()
+	notEmitMatcherFunctionType := newTestTypeNotEmitFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeNotEmitFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeNotEmitFunctionName,
+			notEmitMatcherFunctionType,
+			testTypeNotEmitFunctionDocString,
+		),
+	)
+	ty.notEmitFunction = newTestTypeNotEmitFunction(
+		notEmitMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.emitInOrder()
+	emitInOrderMatcherFunctionType := newTestTypeEmitInOrderFunctionType(matcherType)
+	compositeType.Members.Set(
+		testTypeEmitInOrderFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeEmitInOrderFunctionName,
+			emitInOrderMatcherFunctionType,
+			testTypeEmitInOrderFunctionDocString,
+		),
+	)
+	ty.emitInOrderFunction = newTestTypeEmitInOrderFunction(
+		emitInOrderMatcherFunctionType,
+		matcherTestFunctionType,
+	)
+
+	// Test.mockContract()
+	ty.mockContractFunctionType = newTestTypeMockContractFunctionType(ty.errorType())
+	compositeType.Members.Set(
+		testTypeMockContractFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeMockContractFunctionName,
+			ty.mockContractFunctionType,
+			testTypeMockContractFunctionDocString,
+		),
+	)
+
+	// Test.stubContract()
+	ty.stubContractFunctionType = newTestTypeStubContractFunctionType(
+		ty.stubType(),
+		ty.errorType(),
+	)
+	compositeType.Members.Set(
+		testTypeStubContractFunctionName,
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testTypeStubContractFunctionName,
+			ty.stubContractFunctionType,
+			testTypeStubContractFunctionDocString,
+		),
+	)
+
 	compositeType.ResolveMembers()
 
 	return ty
@@ -1220,20 +3392,36 @@ func (t *TestContractType) blockchainBackendInterfaceType() *sema.InterfaceType
 }
 
 func (t *TestContractType) matcherType() *sema.CompositeType {
-	typ, ok := t.CompositeType.NestedTypes.Get(testMatcherTypeName)
+	return t.nestedStructType(testMatcherTypeName)
+}
+
+func (t *TestContractType) generatorType() *sema.CompositeType {
+	return t.nestedStructType(testGeneratorTypeName)
+}
+
+func (t *TestContractType) stubType() *sema.CompositeType {
+	return t.nestedStructType(testStubTypeName)
+}
+
+func (t *TestContractType) errorType() *sema.CompositeType {
+	return t.nestedStructType(testErrorTypeName)
+}
+
+func (t *TestContractType) nestedStructType(name string) *sema.CompositeType {
+	typ, ok := t.CompositeType.NestedTypes.Get(name)
 	if !ok {
-		panic(typeNotFoundError(testContractTypeName, testMatcherTypeName))
+		panic(typeNotFoundError(testContractTypeName, name))
 	}
 
-	matcherType, ok := typ.(*sema.CompositeType)
-	if !ok || matcherType.Kind != common.CompositeKindStructure {
+	structType, ok := typ.(*sema.CompositeType)
+	if !ok || structType.Kind != common.CompositeKindStructure {
 		panic(errors.NewUnexpectedError(
 			"invalid type for '%s'. expected struct type",
-			testMatcherTypeName,
+			name,
 		))
 	}
 
-	return matcherType
+	return structType
 }
 
 func (t *TestContractType) NewTestContract(
@@ -1271,6 +3459,8 @@ func (t *TestContractType) NewTestContract(
 	compositeValue.Functions[testTypeExpectFunctionName] = t.expectFunction
 	compositeValue.Functions[testTypeReadFileFunctionName] =
 		newTestTypeReadFileFunction(testFramework)
+	compositeValue.Functions[testTypeMatchSnapshotFunctionName] =
+		newTestTypeMatchSnapshotFunction(testFramework)
 
 	// Inject natively implemented matchers
 	compositeValue.Functions[testTypeNewMatcherFunctionName] = t.newMatcherFunction
@@ -1281,6 +3471,27 @@ func (t *TestContractType) NewTestContract(
 	compositeValue.Functions[testTypeBeGreaterThanFunctionName] = t.beGreaterThanFunction
 	compositeValue.Functions[testTypeBeLessThanFunctionName] = t.beLessThanFunction
 	compositeValue.Functions[testExpectFailureFunctionName] = t.expectFailureFunction
+	compositeValue.Functions[testTypeEmitFunctionName] = t.emitFunction
+	compositeValue.Functions[testTypeEmitCountFunctionName] = t.emitCountFunction
+	compositeValue.Functions[testTypeNotEmitFunctionName] = t.notEmitFunction
+	compositeValue.Functions[testTypeEmitInOrderFunctionName] = t.emitInOrderFunction
+	compositeValue.Functions[testTypeBeOfTypeFunctionName] = t.beOfTypeFunction
+	compositeValue.Functions[testTypeBeSubtypeOfFunctionName] = t.beSubtypeOfFunction
+	compositeValue.Functions[testTypeBeCloseToFunctionName] = t.beCloseToFunction
+	compositeValue.Functions[testTypeContainKeyFunctionName] = t.containKeyFunction
+	compositeValue.Functions[testTypeHaveKeysFunctionName] = t.haveKeysFunction
+	compositeValue.Functions[testTypeMatchRegexFunctionName] = t.matchRegexFunction
+	compositeValue.Functions[testTypeAllElementsFunctionName] = t.allElementsFunction
+	compositeValue.Functions[testTypeAnyElementFunctionName] = t.anyElementFunction
+
+	// Inject natively implemented property-based testing functions
+	compositeValue.Functions[testTypeForAllFunctionName] = t.forAllFunction
+
+	// Inject natively implemented mocking functions
+	compositeValue.Functions[testTypeMockContractFunctionName] =
+		newTestTypeMockContractFunction(t.mockContractFunctionType, testFramework)
+	compositeValue.Functions[testTypeStubContractFunctionName] =
+		newTestTypeStubContractFunction(t.stubContractFunctionType, testFramework)
 
 	return compositeValue, nil
 }
@@ -21,6 +21,7 @@ package stdlib
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/errors"
@@ -44,6 +45,7 @@ type testEmulatorBackendType struct {
 	commitBlockFunctionType            *sema.FunctionType
 	deployContractFunctionType         *sema.FunctionType
 	logsFunctionType                   *sema.FunctionType
+	coverageFunctionType               *sema.FunctionType
 	serviceAccountFunctionType         *sema.FunctionType
 	eventsFunctionType                 *sema.FunctionType
 	resetFunctionType                  *sema.FunctionType
@@ -51,6 +53,13 @@ type testEmulatorBackendType struct {
 	createSnapshotFunctionType         *sema.FunctionType
 	loadSnapshotFunctionType           *sema.FunctionType
 	getAccountFunctionType             *sema.FunctionType
+	createAccountWithKeysFunctionType  *sema.FunctionType
+	sequenceNumberFunctionType         *sema.FunctionType
+	setRandomSeedFunctionType          *sema.FunctionType
+	queueRandomValuesFunctionType      *sema.FunctionType
+	setBlockHeightFunctionType         *sema.FunctionType
+	setBlockIDFunctionType             *sema.FunctionType
+	setBlockTimestampFunctionType      *sema.FunctionType
 }
 
 func newTestEmulatorBackendType(
@@ -91,6 +100,11 @@ func newTestEmulatorBackendType(
 		testEmulatorBackendTypeLogsFunctionName,
 	)
 
+	coverageFunctionType := interfaceFunctionType(
+		blockchainBackendInterfaceType,
+		testEmulatorBackendTypeCoverageFunctionName,
+	)
+
 	serviceAccountFunctionType := interfaceFunctionType(
 		blockchainBackendInterfaceType,
 		testEmulatorBackendTypeServiceAccountFunctionName,
@@ -126,6 +140,41 @@ func newTestEmulatorBackendType(
 		testEmulatorBackendTypeGetAccountFunctionName,
 	)
 
+	createAccountWithKeysFunctionType := interfaceFunctionType(
+		blockchainBackendInterfaceType,
+		testEmulatorBackendTypeCreateAccountWithKeysFunctionName,
+	)
+
+	sequenceNumberFunctionType := interfaceFunctionType(
+		blockchainBackendInterfaceType,
+		testEmulatorBackendTypeSequenceNumberFunctionName,
+	)
+
+	setRandomSeedFunctionType := interfaceFunctionType(
+		blockchainBackendInterfaceType,
+		testEmulatorBackendTypeSetRandomSeedFunctionName,
+	)
+
+	queueRandomValuesFunctionType := interfaceFunctionType(
+		blockchainBackendInterfaceType,
+		testEmulatorBackendTypeQueueRandomValuesFunctionName,
+	)
+
+	setBlockHeightFunctionType := interfaceFunctionType(
+		blockchainBackendInterfaceType,
+		testEmulatorBackendTypeSetBlockHeightFunctionName,
+	)
+
+	setBlockIDFunctionType := interfaceFunctionType(
+		blockchainBackendInterfaceType,
+		testEmulatorBackendTypeSetBlockIDFunctionName,
+	)
+
+	setBlockTimestampFunctionType := interfaceFunctionType(
+		blockchainBackendInterfaceType,
+		testEmulatorBackendTypeSetBlockTimestampFunctionName,
+	)
+
 	compositeType := &sema.CompositeType{
 		Identifier: testEmulatorBackendTypeName,
 		Kind:       common.CompositeKindStructure,
@@ -178,6 +227,12 @@ func newTestEmulatorBackendType(
 			logsFunctionType,
 			testEmulatorBackendTypeLogsFunctionDocString,
 		),
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testEmulatorBackendTypeCoverageFunctionName,
+			coverageFunctionType,
+			testEmulatorBackendTypeCoverageFunctionDocString,
+		),
 		sema.NewUnmeteredPublicFunctionMember(
 			compositeType,
 			testEmulatorBackendTypeServiceAccountFunctionName,
@@ -220,6 +275,48 @@ func newTestEmulatorBackendType(
 			getAccountFunctionType,
 			testEmulatorBackendTypeGetAccountFunctionDocString,
 		),
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testEmulatorBackendTypeCreateAccountWithKeysFunctionName,
+			createAccountWithKeysFunctionType,
+			testEmulatorBackendTypeCreateAccountWithKeysFunctionDocString,
+		),
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testEmulatorBackendTypeSequenceNumberFunctionName,
+			sequenceNumberFunctionType,
+			testEmulatorBackendTypeSequenceNumberFunctionDocString,
+		),
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testEmulatorBackendTypeSetRandomSeedFunctionName,
+			setRandomSeedFunctionType,
+			testEmulatorBackendTypeSetRandomSeedFunctionDocString,
+		),
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testEmulatorBackendTypeQueueRandomValuesFunctionName,
+			queueRandomValuesFunctionType,
+			testEmulatorBackendTypeQueueRandomValuesFunctionDocString,
+		),
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testEmulatorBackendTypeSetBlockHeightFunctionName,
+			setBlockHeightFunctionType,
+			testEmulatorBackendTypeSetBlockHeightFunctionDocString,
+		),
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testEmulatorBackendTypeSetBlockIDFunctionName,
+			setBlockIDFunctionType,
+			testEmulatorBackendTypeSetBlockIDFunctionDocString,
+		),
+		sema.NewUnmeteredPublicFunctionMember(
+			compositeType,
+			testEmulatorBackendTypeSetBlockTimestampFunctionName,
+			setBlockTimestampFunctionType,
+			testEmulatorBackendTypeSetBlockTimestampFunctionDocString,
+		),
 	}
 
 	compositeType.Members = sema.MembersAsMap(members)
@@ -234,6 +331,7 @@ func newTestEmulatorBackendType(
 		commitBlockFunctionType:            commitBlockFunctionType,
 		deployContractFunctionType:         deployContractFunctionType,
 		logsFunctionType:                   logsFunctionType,
+		coverageFunctionType:               coverageFunctionType,
 		serviceAccountFunctionType:         serviceAccountFunctionType,
 		eventsFunctionType:                 eventsFunctionType,
 		resetFunctionType:                  resetFunctionType,
@@ -241,6 +339,13 @@ func newTestEmulatorBackendType(
 		createSnapshotFunctionType:         createSnapshotFunctionType,
 		loadSnapshotFunctionType:           loadSnapshotFunctionType,
 		getAccountFunctionType:             getAccountFunctionType,
+		createAccountWithKeysFunctionType:  createAccountWithKeysFunctionType,
+		sequenceNumberFunctionType:         sequenceNumberFunctionType,
+		setRandomSeedFunctionType:          setRandomSeedFunctionType,
+		queueRandomValuesFunctionType:      queueRandomValuesFunctionType,
+		setBlockHeightFunctionType:         setBlockHeightFunctionType,
+		setBlockIDFunctionType:             setBlockIDFunctionType,
+		setBlockTimestampFunctionType:      setBlockTimestampFunctionType,
 	}
 }
 
@@ -311,6 +416,43 @@ func (t *testEmulatorBackendType) newCreateAccountFunction(
 	)
 }
 
+// 'EmulatorBackend.createAccountWithKeys' function
+
+const testEmulatorBackendTypeCreateAccountWithKeysFunctionName = "createAccountWithKeys"
+
+const testEmulatorBackendTypeCreateAccountWithKeysFunctionDocString = `
+Creates an account with the given keys, by submitting an account creation transaction.
+The transaction is paid by the service account.
+`
+
+func (t *testEmulatorBackendType) newCreateAccountWithKeysFunction(
+	blockchain Blockchain,
+) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		t.createAccountWithKeysFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+			locationRange := invocation.LocationRange
+
+			keys := keysArrayValueToSlice(inter, invocation.Arguments[0], locationRange)
+
+			account, err := blockchain.CreateAccountWithKeys(keys)
+			if err != nil {
+				panic(PanicError{
+					Message:       err.Error(),
+					LocationRange: locationRange,
+				})
+			}
+
+			return newTestAccountValue(
+				inter,
+				locationRange,
+				account,
+			)
+		},
+	)
+}
+
 func newTestAccountValue(
 	inter *interpreter.Interpreter,
 	locationRange interpreter.LocationRange,
@@ -320,10 +462,16 @@ func newTestAccountValue(
 	// Create address value
 	address := interpreter.NewAddressValue(nil, account.Address)
 
+	// Accounts without keys have an empty public key
+	accountPublicKey := account.PublicKey
+	if accountPublicKey == nil {
+		accountPublicKey = &PublicKey{}
+	}
+
 	publicKey := NewPublicKeyValue(
 		inter,
 		locationRange,
-		account.PublicKey,
+		accountPublicKey,
 	)
 
 	// Create an 'Account' by calling its constructor.
@@ -384,6 +532,48 @@ func (t *testEmulatorBackendType) newGetAccountFunction(
 	)
 }
 
+// 'EmulatorBackend.sequenceNumber' function
+
+const testEmulatorBackendTypeSequenceNumberFunctionName = "sequenceNumber"
+
+const testEmulatorBackendTypeSequenceNumberFunctionDocString = `
+Returns the sequence number of the key with the given index of the account with the given address.
+`
+
+func (t *testEmulatorBackendType) newSequenceNumberFunction(
+	blockchain Blockchain,
+) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		t.sequenceNumberFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			locationRange := invocation.LocationRange
+
+			address, ok := invocation.Arguments[0].(interpreter.AddressValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			keyIndex, ok := invocation.Arguments[1].(interpreter.IntValue)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			sequenceNumber, err := blockchain.SequenceNumber(
+				common.Address(address),
+				keyIndex.ToInt(locationRange),
+			)
+			if err != nil {
+				panic(PanicError{
+					Message:       err.Error(),
+					LocationRange: locationRange,
+				})
+			}
+
+			return interpreter.NewUnmeteredUInt64Value(sequenceNumber)
+		},
+	)
+}
+
 // 'EmulatorBackend.addTransaction' function
 
 const testEmulatorBackendTypeAddTransactionFunctionName = "addTransaction"
@@ -396,6 +586,9 @@ const testTransactionTypeCodeFieldName = "code"
 const testTransactionTypeAuthorizersFieldName = "authorizers"
 const testTransactionTypeSignersFieldName = "signers"
 const testTransactionTypeArgumentsFieldName = "arguments"
+const testTransactionTypePayerFieldName = "payer"
+const testTransactionTypeProposalKeyFieldName = "proposalKey"
+const testTransactionTypeKeySignaturesFieldName = "keySignatures"
 
 func (t *testEmulatorBackendType) newAddTransactionFunction(
 	blockchain Blockchain,
@@ -455,12 +648,48 @@ func (t *testEmulatorBackendType) newAddTransactionFunction(
 				panic(errors.NewUnexpectedErrorFromCause(err))
 			}
 
+			// Get payer, proposal key, and key signatures
+			payer := optionalAddressFromValue(
+				inter,
+				transactionValue.GetMember(
+					inter,
+					locationRange,
+					testTransactionTypePayerFieldName,
+				),
+				locationRange,
+			)
+
+			proposalKey := proposalKeyFromValue(
+				inter,
+				transactionValue.GetMember(
+					inter,
+					locationRange,
+					testTransactionTypeProposalKeyFieldName,
+				),
+				locationRange,
+			)
+
+			keySignatures := keySignaturesArrayValueToSlice(
+				inter,
+				transactionValue.GetMember(
+					inter,
+					locationRange,
+					testTransactionTypeKeySignaturesFieldName,
+				),
+				locationRange,
+			)
+
 			err = blockchain.AddTransaction(
 				inter,
-				code.Str,
-				authorizers,
-				signerAccounts,
-				args,
+				&Transaction{
+					Code:          code.Str,
+					Authorizers:   authorizers,
+					Signers:       signerAccounts,
+					Arguments:     args,
+					Payer:         payer,
+					ProposalKey:   proposalKey,
+					KeySignatures: keySignatures,
+				},
 			)
 
 			if err != nil {
@@ -494,7 +723,7 @@ func (t *testEmulatorBackendType) newExecuteNextTransactionFunction(
 				return interpreter.Nil
 			}
 
-			return newTransactionResult(invocation.Interpreter, result)
+			return newTransactionResult(invocation.Interpreter, blockchain, result)
 		},
 	)
 }
@@ -584,35 +813,55 @@ func (t *testEmulatorBackendType) newLogsFunction(
 		t.logsFunctionType,
 		func(invocation interpreter.Invocation) interpreter.Value {
 			logs := blockchain.Logs()
-			inter := invocation.Interpreter
 
-			arrayType := interpreter.NewVariableSizedStaticType(
-				inter,
-				interpreter.NewPrimitiveStaticType(
-					inter,
-					interpreter.PrimitiveStaticTypeString,
-				),
+			return newStringArrayValue(
+				invocation.Interpreter,
+				invocation.LocationRange,
+				logs,
 			)
+		},
+	)
+}
 
-			values := make([]interpreter.Value, len(logs))
-			for i, log := range logs {
-				memoryUsage := common.NewStringMemoryUsage(len(log))
-				values[i] = interpreter.NewStringValue(
-					inter,
-					memoryUsage,
-					func() string {
-						return log
-					},
-				)
+// 'EmulatorBackend.coverage' function
+
+const testEmulatorBackendTypeCoverageFunctionName = "coverage"
+
+const testEmulatorBackendTypeCoverageFunctionDocString = `
+Returns the coverage of the contracts, up to the calling point,
+or nil if the blockchain does not collect coverage.
+`
+
+func (t *testEmulatorBackendType) newCoverageFunction(
+	blockchain Blockchain,
+) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		t.coverageFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			coverage := blockchain.Coverage()
+			if coverage == nil {
+				return interpreter.Nil
 			}
 
-			return interpreter.NewArrayValue(
-				inter,
-				invocation.LocationRange,
-				arrayType,
-				common.ZeroAddress,
-				values...,
+			inter := invocation.Interpreter
+
+			// Create a 'Coverage' by calling its constructor.
+			coverageConstructor := getConstructor(inter, testCoverageTypeName)
+			coverageValue, err := inter.InvokeExternally(
+				coverageConstructor,
+				coverageConstructor.Type,
+				[]interpreter.Value{
+					interpreter.NewUnmeteredIntValueFromInt64(int64(coverage.Locations)),
+					interpreter.NewUnmeteredIntValueFromInt64(int64(coverage.Statements)),
+					interpreter.NewUnmeteredIntValueFromInt64(int64(coverage.Hits)),
+					interpreter.NewUnmeteredIntValueFromInt64(int64(coverage.Misses)),
+				},
 			)
+			if err != nil {
+				panic(err)
+			}
+
+			return interpreter.NewUnmeteredSomeValueNonCopying(coverageValue)
 		},
 	)
 }
@@ -784,6 +1033,155 @@ func (t *testEmulatorBackendType) newLoadSnapshotFunction(
 	)
 }
 
+// 'EmulatorBackend.setRandomSeed' function
+
+const testEmulatorBackendTypeSetRandomSeedFunctionName = "setRandomSeed"
+
+const testEmulatorBackendTypeSetRandomSeedFunctionDocString = `
+Seeds the random number generator of the blockchain.
+`
+
+func (t *testEmulatorBackendType) newSetRandomSeedFunction(
+	blockchain Blockchain,
+) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		t.setRandomSeedFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			seed, ok := invocation.Arguments[0].(interpreter.UInt64Value)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			blockchain.SetRandomSeed(uint64(seed))
+			return interpreter.Void
+		},
+	)
+}
+
+// 'EmulatorBackend.queueRandomValues' function
+
+const testEmulatorBackendTypeQueueRandomValuesFunctionName = "queueRandomValues"
+
+const testEmulatorBackendTypeQueueRandomValuesFunctionDocString = `
+Queues the given values, which are returned in order
+by the next requests for random values.
+`
+
+func (t *testEmulatorBackendType) newQueueRandomValuesFunction(
+	blockchain Blockchain,
+) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		t.queueRandomValuesFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			elements, err := arrayValueToSlice(
+				invocation.Interpreter,
+				invocation.Arguments[0],
+			)
+			if err != nil {
+				panic(err)
+			}
+
+			values := make([]uint64, len(elements))
+			for i, element := range elements {
+				value, ok := element.(interpreter.UInt64Value)
+				if !ok {
+					panic(errors.NewUnreachableError())
+				}
+				values[i] = uint64(value)
+			}
+
+			blockchain.QueueRandomValues(values)
+			return interpreter.Void
+		},
+	)
+}
+
+// 'EmulatorBackend.setBlockHeight' function
+
+const testEmulatorBackendTypeSetBlockHeightFunctionName = "setBlockHeight"
+
+const testEmulatorBackendTypeSetBlockHeightFunctionDocString = `
+Sets the height of the pending block.
+`
+
+func (t *testEmulatorBackendType) newSetBlockHeightFunction(
+	blockchain Blockchain,
+) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		t.setBlockHeightFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			height, ok := invocation.Arguments[0].(interpreter.UInt64Value)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			err := blockchain.SetBlockHeight(uint64(height))
+			return newErrorValue(invocation.Interpreter, err)
+		},
+	)
+}
+
+// 'EmulatorBackend.setBlockID' function
+
+const testEmulatorBackendTypeSetBlockIDFunctionName = "setBlockID"
+
+const testEmulatorBackendTypeSetBlockIDFunctionDocString = `
+Sets the ID of the pending block.
+`
+
+func (t *testEmulatorBackendType) newSetBlockIDFunction(
+	blockchain Blockchain,
+) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		t.setBlockIDFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			inter := invocation.Interpreter
+
+			id, err := interpreter.ByteArrayValueToByteSlice(
+				inter,
+				invocation.Arguments[0],
+				invocation.LocationRange,
+			)
+			if err != nil {
+				panic(err)
+			}
+
+			err = blockchain.SetBlockID(id)
+			return newErrorValue(inter, err)
+		},
+	)
+}
+
+// 'EmulatorBackend.setBlockTimestamp' function
+
+const testEmulatorBackendTypeSetBlockTimestampFunctionName = "setBlockTimestamp"
+
+const testEmulatorBackendTypeSetBlockTimestampFunctionDocString = `
+Sets the timestamp of the pending block,
+in seconds since the Unix epoch.
+`
+
+func (t *testEmulatorBackendType) newSetBlockTimestampFunction(
+	blockchain Blockchain,
+) *interpreter.HostFunctionValue {
+	return interpreter.NewUnmeteredHostFunctionValue(
+		t.setBlockTimestampFunctionType,
+		func(invocation interpreter.Invocation) interpreter.Value {
+			timestamp, ok := invocation.Arguments[0].(interpreter.UFix64Value)
+			if !ok {
+				panic(errors.NewUnreachableError())
+			}
+
+			seconds := uint64(timestamp) / sema.Fix64Factor
+			fraction := uint64(timestamp) % sema.Fix64Factor
+			nanoseconds := fraction * (uint64(time.Second) / sema.Fix64Factor)
+
+			blockchain.SetBlockTimestamp(time.Unix(int64(seconds), int64(nanoseconds)))
+			return interpreter.Void
+		},
+	)
+}
+
 func (t *testEmulatorBackendType) newEmulatorBackend(
 	inter *interpreter.Interpreter,
 	blockchain Blockchain,
@@ -817,6 +1215,10 @@ func (t *testEmulatorBackendType) newEmulatorBackend(
 			Name:  testEmulatorBackendTypeLogsFunctionName,
 			Value: t.newLogsFunction(blockchain),
 		},
+		{
+			Name:  testEmulatorBackendTypeCoverageFunctionName,
+			Value: t.newCoverageFunction(blockchain),
+		},
 		{
 			Name:  testEmulatorBackendTypeServiceAccountFunctionName,
 			Value: t.newServiceAccountFunction(blockchain),
@@ -845,6 +1247,34 @@ func (t *testEmulatorBackendType) newEmulatorBackend(
 			Name:  testEmulatorBackendTypeGetAccountFunctionName,
 			Value: t.newGetAccountFunction(blockchain),
 		},
+		{
+			Name:  testEmulatorBackendTypeCreateAccountWithKeysFunctionName,
+			Value: t.newCreateAccountWithKeysFunction(blockchain),
+		},
+		{
+			Name:  testEmulatorBackendTypeSequenceNumberFunctionName,
+			Value: t.newSequenceNumberFunction(blockchain),
+		},
+		{
+			Name:  testEmulatorBackendTypeSetRandomSeedFunctionName,
+			Value: t.newSetRandomSeedFunction(blockchain),
+		},
+		{
+			Name:  testEmulatorBackendTypeQueueRandomValuesFunctionName,
+			Value: t.newQueueRandomValuesFunction(blockchain),
+		},
+		{
+			Name:  testEmulatorBackendTypeSetBlockHeightFunctionName,
+			Value: t.newSetBlockHeightFunction(blockchain),
+		},
+		{
+			Name:  testEmulatorBackendTypeSetBlockIDFunctionName,
+			Value: t.newSetBlockIDFunction(blockchain),
+		},
+		{
+			Name:  testEmulatorBackendTypeSetBlockTimestampFunctionName,
+			Value: t.newSetBlockTimestampFunction(blockchain),
+		},
 	}
 
 	// TODO: Use SimpleCompositeValue
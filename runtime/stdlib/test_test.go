@@ -22,11 +22,16 @@ package stdlib_test
 import (
 	"errors"
 	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/onflow/cadence"
 	"github.com/onflow/cadence/runtime"
 	"github.com/onflow/cadence/runtime/activations"
 	"github.com/onflow/cadence/runtime/ast"
@@ -61,9 +66,13 @@ func newTestContractInterpreterWithTestFramework(
 	)
 	require.NoError(t, err)
 
+	hashAlgorithmConstructor := stdlib.NewHashAlgorithmConstructor(nil)
+
 	baseValueActivation := sema.NewVariableActivation(sema.BaseValueActivation)
 	baseValueActivation.DeclareValue(stdlib.AssertFunction)
 	baseValueActivation.DeclareValue(stdlib.PanicFunction)
+	baseValueActivation.DeclareValue(stdlib.SignatureAlgorithmConstructor)
+	baseValueActivation.DeclareValue(hashAlgorithmConstructor)
 
 	checker, err := sema.NewChecker(
 		program,
@@ -107,6 +116,8 @@ func newTestContractInterpreterWithTestFramework(
 	baseActivation := activations.NewActivation(nil, interpreter.BaseActivation)
 	interpreter.Declare(baseActivation, stdlib.AssertFunction)
 	interpreter.Declare(baseActivation, stdlib.PanicFunction)
+	interpreter.Declare(baseActivation, stdlib.SignatureAlgorithmConstructor)
+	interpreter.Declare(baseActivation, hashAlgorithmConstructor)
 
 	inter, err := interpreter.NewInterpreter(
 		interpreter.ProgramFromChecker(checker),
@@ -875,10 +886,13 @@ func TestAssertEqual(t *testing.T) {
 		_, err = inter.Invoke("testNotEqual")
 		require.Error(t, err)
 		assert.ErrorAs(t, err, &stdlib.AssertionError{})
+		// NOTE: The order of the printed entries depends on the hash seed of the dictionaries,
+		// which is derived from their storage IDs. The Test contract allocates its generators (Test.Gen)
+		// when it is initialized, so the dictionaries of the test get different storage IDs
 		assert.ErrorContains(
 			t,
 			err,
-			"not equal: expected: {1: true, 2: false}, actual: {2: true, 1: true}",
+			"not equal: expected: {2: false, 1: true}, actual: {2: true, 1: true}",
 		)
 	})
 
@@ -1694,281 +1708,218 @@ func TestTestBeLessThanMatcher(t *testing.T) {
 	})
 }
 
-func TestTestExpect(t *testing.T) {
+func TestTestBeOfTypeMatcher(t *testing.T) {
 
 	t.Parallel()
 
-	t.Run("success", func(t *testing.T) {
+	t.Run("matcher beOfType", func(t *testing.T) {
 		t.Parallel()
 
 		script := `
-           import Test
-
-           pub fun test() {
-               Test.expect("this string", Test.equal("this string"))
-           }
-        `
-
-		inter, err := newTestContractInterpreter(t, script)
-		require.NoError(t, err)
+		    import Test
 
-		_, err = inter.Invoke("test")
-		require.NoError(t, err)
-	})
+		    pub fun testMatch(): Bool {
+		        let beInt = Test.beOfType(Type<Int>())
 
-	t.Run("fail", func(t *testing.T) {
-		t.Parallel()
+		        return beInt.test(42)
+		    }
 
-		script := `
-           import Test
+		    pub fun testNoMatch(): Bool {
+		        let beInt = Test.beOfType(Type<Int>())
 
-           pub fun test() {
-               Test.expect("this string", Test.equal("other string"))
-           }
-        `
+		        return beInt.test("42")
+		    }
+		`
 
 		inter, err := newTestContractInterpreter(t, script)
 		require.NoError(t, err)
 
-		_, err = inter.Invoke("test")
-		require.Error(t, err)
+		result, err := inter.Invoke("testMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
 
-		assertionErr := &stdlib.AssertionError{}
-		assert.ErrorAs(t, err, assertionErr)
-		assert.Equal(t, "given value is: \"this string\"", assertionErr.Message)
-		assert.Equal(t, "test", assertionErr.LocationRange.Location.String())
-		assert.Equal(t, 5, assertionErr.LocationRange.StartPosition().Line)
+		result, err = inter.Invoke("testNoMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.FalseValue, result)
 	})
 
-	t.Run("different types", func(t *testing.T) {
+	t.Run("matcher beOfType with reference", func(t *testing.T) {
 		t.Parallel()
 
 		script := `
-           import Test
+		    import Test
 
-           pub fun test() {
-               Test.expect("string", Test.equal(1))
-           }
-        `
+		    pub resource Foo {}
 
-		inter, err := newTestContractInterpreter(t, script)
-		require.NoError(t, err)
+		    pub fun testMatch(): Bool {
+		        let foo <- create Foo()
+		        let ref = &foo as &Foo
 
-		_, err = inter.Invoke("test")
-		require.Error(t, err)
-		assert.ErrorAs(t, err, &stdlib.AssertionError{})
-	})
+		        let matches = Test.beOfType(Type<@Foo>()).test(ref)
+		            && Test.beOfType(Type<&Foo>()).test(ref)
 
-	t.Run("with explicit types", func(t *testing.T) {
-		t.Parallel()
+		        destroy foo
+		        return matches
+		    }
 
-		script := `
-           import Test
+		    pub fun testNoMatch(): Bool {
+		        let foo <- create Foo()
+		        let ref = &foo as &Foo
 
-           pub fun test() {
-               Test.expect<String>("hello", Test.equal("hello"))
-           }
-        `
+		        let matches = Test.beOfType(Type<Int>()).test(ref)
+
+		        destroy foo
+		        return matches
+		    }
+		`
 
 		inter, err := newTestContractInterpreter(t, script)
 		require.NoError(t, err)
 
-		_, err = inter.Invoke("test")
+		result, err := inter.Invoke("testMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
+
+		result, err = inter.Invoke("testNoMatch")
 		require.NoError(t, err)
+		assert.Equal(t, interpreter.FalseValue, result)
 	})
 
-	t.Run("mismatching types", func(t *testing.T) {
+	t.Run("matcher beSubtypeOf", func(t *testing.T) {
 		t.Parallel()
 
 		script := `
-           import Test
-
-           pub fun test() {
-               Test.expect<Int>("string", Test.equal(1))
-           }
-        `
-
-		_, err := newTestContractInterpreter(t, script)
+		    import Test
 
-		errs := checker.RequireCheckerErrors(t, err, 2)
-		assert.IsType(t, &sema.TypeParameterTypeMismatchError{}, errs[0])
-		assert.IsType(t, &sema.TypeMismatchError{}, errs[1])
-	})
+		    pub fun testMatch(): Bool {
+		        let beNumber = Test.beSubtypeOf(Type<Number>())
 
-	t.Run("resource with resource matcher", func(t *testing.T) {
-		t.Parallel()
+		        return beNumber.test(42)
+		    }
 
-		script := `
-           import Test
+		    pub fun testNoMatch(): Bool {
+		        let beNumber = Test.beSubtypeOf(Type<Number>())
 
-           pub fun test() {
-               let f1 <- create Foo()
-               let f2 <- create Foo()
-               Test.expect(<-f1, Test.equal(<-f2))
-           }
+		        return beNumber.test("42")
+		    }
+		`
 
-           pub resource Foo {}
-        `
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
 
-		_, err := newTestContractInterpreter(t, script)
+		result, err := inter.Invoke("testMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
 
-		errs := checker.RequireCheckerErrors(t, err, 2)
-		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
-		assert.IsType(t, &sema.TypeMismatchError{}, errs[1])
+		result, err = inter.Invoke("testNoMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.FalseValue, result)
 	})
 
-	t.Run("resource with struct matcher", func(t *testing.T) {
+	t.Run("matcher beSubtypeOf with reference", func(t *testing.T) {
 		t.Parallel()
 
 		script := `
-           import Test
-
-           pub fun test() {
-               let foo <- create Foo()
-               let bar = Bar()
-               Test.expect(<-foo, Test.equal(bar))
-           }
-
-           pub resource Foo {}
-           pub struct Bar {}
-        `
-
-		_, err := newTestContractInterpreter(t, script)
+		    import Test
 
-		errs := checker.RequireCheckerErrors(t, err, 1)
-		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
-	})
+		    pub resource interface Named {}
 
-	t.Run("struct with resource matcher", func(t *testing.T) {
-		t.Parallel()
+		    pub resource Foo: Named {}
 
-		script := `
-           import Test
+		    pub fun test(): Bool {
+		        let foo <- create Foo()
+		        let ref = &foo as &AnyResource
 
-           pub fun test() {
-               let foo = Foo()
-               let bar <- create Bar()
-               Test.expect(foo, Test.equal(<-bar))
-           }
+		        let matches = Test.beSubtypeOf(Type<@AnyResource{Named}>()).test(ref)
 
-           pub struct Foo {}
-           pub resource Bar {}
-        `
+		        destroy foo
+		        return matches
+		    }
+		`
 
-		_, err := newTestContractInterpreter(t, script)
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
 
-		errs := checker.RequireCheckerErrors(t, err, 1)
-		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
 	})
 }
 
-func TestTestExpectFailure(t *testing.T) {
+func TestTestBeCloseToMatcher(t *testing.T) {
 
 	t.Parallel()
 
-	t.Run("expect failure with no failure found", func(t *testing.T) {
+	t.Run("matcher beCloseTo with UFix64", func(t *testing.T) {
 		t.Parallel()
 
 		script := `
 		    import Test
 
-		    pub fun test() {
-		        let foo = Foo(answer: 42)
-		        Test.expectFailure(fun(): Void {
-		            foo.correctAnswer(42)
-		        }, errorMessageSubstring: "wrong answer!")
-		    }
+		    pub fun testMatch(): Bool {
+		        let closeToOne = Test.beCloseTo(1.0, within: 0.01)
 
-		    pub struct Foo {
-		        priv let answer: UInt8
+		        return closeToOne.test(1.01) && closeToOne.test(0.99)
+		    }
 
-		        init(answer: UInt8) {
-		            self.answer = answer
-		        }
+		    pub fun testNoMatch(): Bool {
+		        let closeToOne = Test.beCloseTo(1.0, within: 0.01)
 
-		        pub fun correctAnswer(_ input: UInt8): Bool {
-		            if self.answer != input {
-		                panic("wrong answer!")
-		            }
-		            return true
-		        }
+		        return closeToOne.test(1.02)
 		    }
 		`
 
 		inter, err := newTestContractInterpreter(t, script)
 		require.NoError(t, err)
 
-		_, err = inter.Invoke("test")
-		require.Error(t, err)
-		assert.ErrorContains(
-			t,
-			err,
-			"Expected a failure, but found none.",
-		)
+		result, err := inter.Invoke("testMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
+
+		result, err = inter.Invoke("testNoMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.FalseValue, result)
 	})
 
-	t.Run("expect failure with matching error message", func(t *testing.T) {
+	t.Run("matcher beCloseTo with Fix64", func(t *testing.T) {
 		t.Parallel()
 
 		script := `
 		    import Test
 
-		    pub fun test() {
-		        let foo = Foo(answer: 42)
-		        Test.expectFailure(fun(): Void {
-		            foo.correctAnswer(43)
-		        }, errorMessageSubstring: "wrong answer!")
-		    }
+		    pub fun testMatch(): Bool {
+		        let closeToMinusOne = Test.beCloseTo(-1.0, within: 0.5)
 
-		    pub struct Foo {
-		        priv let answer: UInt8
+		        return closeToMinusOne.test(-0.5) && closeToMinusOne.test(-1.5)
+		    }
 
-		        init(answer: UInt8) {
-		            self.answer = answer
-		        }
+		    pub fun testNoMatch(): Bool {
+		        let closeToMinusOne = Test.beCloseTo(-1.0, within: 0.5)
 
-		        pub fun correctAnswer(_ input: UInt8): Bool {
-		            if self.answer != input {
-		                panic("wrong answer!")
-		            }
-		            return true
-		        }
+		        return closeToMinusOne.test(0.0 as Fix64)
 		    }
 		`
 
 		inter, err := newTestContractInterpreter(t, script)
 		require.NoError(t, err)
 
-		_, err = inter.Invoke("test")
+		result, err := inter.Invoke("testMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
+
+		result, err = inter.Invoke("testNoMatch")
 		require.NoError(t, err)
+		assert.Equal(t, interpreter.FalseValue, result)
 	})
 
-	t.Run("expect failure with mismatching error message", func(t *testing.T) {
+	t.Run("matcher beCloseTo with negative tolerance", func(t *testing.T) {
 		t.Parallel()
 
 		script := `
 		    import Test
 
 		    pub fun test() {
-		        let foo = Foo(answer: 42)
-		        Test.expectFailure(fun(): Void {
-		            foo.correctAnswer(43)
-		        }, errorMessageSubstring: "what is wrong?")
-		    }
-
-		    pub struct Foo {
-		        priv let answer: UInt8
-
-		        init(answer: UInt8) {
-		            self.answer = answer
-		        }
-
-		        pub fun correctAnswer(_ input: UInt8): Bool {
-		            if self.answer != input {
-		                panic("wrong answer!")
-		            }
-		            return true
-		        }
+		        Test.beCloseTo(1.0, within: -0.01)
 		    }
 		`
 
@@ -1977,80 +1928,1609 @@ func TestTestExpectFailure(t *testing.T) {
 
 		_, err = inter.Invoke("test")
 		require.Error(t, err)
-		assert.ErrorContains(
-			t,
-			err,
-			"Expected error message to include: \"what is wrong?\".",
-		)
+		assert.ErrorAs(t, err, &cdcErrors.DefaultUserError{})
+		assert.ErrorContains(t, err, "tolerance must not be negative")
 	})
 
-	t.Run("expect failure with wrong function signature", func(t *testing.T) {
+	t.Run("matcher beCloseTo with type mismatch", func(t *testing.T) {
 		t.Parallel()
 
 		script := `
 		    import Test
 
-		    pub fun test() {
-		        let foo = Foo(answer: 42)
-		        Test.expectFailure(fun(answer: UInt64): Foo {
-		            foo.correctAnswer(42)
-		            return foo
-		        }, errorMessageSubstring: "wrong answer")
+		    pub fun test(): Bool {
+		        let closeToOne = Test.beCloseTo(1.0, within: 0.01)
+
+		        return closeToOne.test(1)
 		    }
+		`
 
-		    pub struct Foo {
-		        priv let answer: UInt8
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
 
-		        init(answer: UInt8) {
-		            self.answer = answer
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &cdcErrors.DefaultUserError{})
+		assert.ErrorContains(t, err, "expected Fix64 or UFix64 argument")
+	})
+}
+
+func TestTestDictionaryKeyMatchers(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("matcher containKey", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun testMatch(): Bool {
+		        let containOne = Test.containKey("one")
+		        let dict: {String: Int} = {"one": 1, "two": 2}
+
+		        return containOne.test(dict)
+		    }
+
+		    pub fun testNoMatch(): Bool {
+		        let containThree = Test.containKey("three")
+		        let dict: {String: Int} = {"one": 1, "two": 2}
+
+		        return containThree.test(dict)
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		result, err := inter.Invoke("testMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
+
+		result, err = inter.Invoke("testNoMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.FalseValue, result)
+	})
+
+	t.Run("matcher containKey with reference to resources", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub resource Foo {}
+
+		    pub fun test(): Bool {
+		        let foos <- {1: <-create Foo()}
+
+		        let matches = Test.containKey(1).test(&foos as &{Int: Foo})
+
+		        destroy foos
+		        return matches
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
+	})
+
+	t.Run("matcher haveKeys", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun testMatch(): Bool {
+		        let haveKeys = Test.haveKeys(["one", "two"])
+		        let dict: {String: Int} = {"one": 1, "two": 2, "three": 3}
+
+		        return haveKeys.test(dict)
+		    }
+
+		    pub fun testNoMatch(): Bool {
+		        let haveKeys = Test.haveKeys(["one", "four"])
+		        let dict: {String: Int} = {"one": 1, "two": 2, "three": 3}
+
+		        return haveKeys.test(dict)
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		result, err := inter.Invoke("testMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
+
+		result, err = inter.Invoke("testNoMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.FalseValue, result)
+	})
+
+	t.Run("matcher containKey with type mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test(): Bool {
+		        let containOne = Test.containKey("one")
+
+		        return containOne.test(["one"])
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &cdcErrors.DefaultUserError{})
+		assert.ErrorContains(t, err, "expected Dictionary argument")
+	})
+}
+
+func TestTestMatchRegexMatcher(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("matcher matchRegex", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun testMatch(): Bool {
+		        let matchHex = Test.matchRegex("^0x[0-9a-f]+$")
+
+		        return matchHex.test("0x01cf0e2f2f715450")
+		    }
+
+		    pub fun testNoMatch(): Bool {
+		        let matchHex = Test.matchRegex("^0x[0-9a-f]+$")
+
+		        return matchHex.test("0xZZ")
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		result, err := inter.Invoke("testMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
+
+		result, err = inter.Invoke("testNoMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.FalseValue, result)
+	})
+
+	t.Run("matcher matchRegex with invalid pattern", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        Test.matchRegex("[0-9")
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &cdcErrors.DefaultUserError{})
+		assert.ErrorContains(t, err, "invalid regular expression")
+	})
+
+	t.Run("matcher matchRegex with type mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test(): Bool {
+		        let matchDigits = Test.matchRegex("[0-9]+")
+
+		        return matchDigits.test(42)
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &cdcErrors.DefaultUserError{})
+		assert.ErrorContains(t, err, "expected String argument")
+	})
+}
+
+func TestTestElementsMatchers(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("matcher allElements", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun testMatch(): Bool {
+		        let allPositive = Test.allElements(Test.beGreaterThan(0))
+
+		        return allPositive.test([1, 2, 3]) && allPositive.test([])
+		    }
+
+		    pub fun testNoMatch(): Bool {
+		        let allPositive = Test.allElements(Test.beGreaterThan(0))
+
+		        return allPositive.test([1, -2, 3])
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		result, err := inter.Invoke("testMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
+
+		result, err = inter.Invoke("testNoMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.FalseValue, result)
+	})
+
+	t.Run("matcher anyElement with Dictionary", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun testMatch(): Bool {
+		        let anyNegative = Test.anyElement(Test.beLessThan(0))
+		        let dict: {String: Int} = {"a": 1, "b": -2}
+
+		        return anyNegative.test(dict)
+		    }
+
+		    pub fun testNoMatch(): Bool {
+		        let anyNegative = Test.anyElement(Test.beLessThan(0))
+		        let dict: {String: Int} = {"a": 1, "b": 2}
+
+		        return anyNegative.test(dict) || anyNegative.test([])
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		result, err := inter.Invoke("testMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
+
+		result, err = inter.Invoke("testNoMatch")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.FalseValue, result)
+	})
+
+	t.Run("matcher allElements with reference to resources", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub resource Foo {
+		        pub let id: Int
+
+		        init(id: Int) {
+		            self.id = id
 		        }
+		    }
+
+		    pub fun test(): Bool {
+		        let foos <- [<-create Foo(id: 1), <-create Foo(id: 2)]
+
+		        let matcher = Test.allElements(
+		            Test.beOfType(Type<@Foo>()).and(
+		                Test.newMatcher(fun (foo: &Foo): Bool {
+		                    return foo.id > 0
+		                })
+		            )
+		        )
+		        let matches = matcher.test(&foos as &[Foo])
+
+		        destroy foos
+		        return matches
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		result, err := inter.Invoke("test")
+		require.NoError(t, err)
+		assert.Equal(t, interpreter.TrueValue, result)
+	})
+
+	t.Run("matcher allElements with type mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test(): Bool {
+		        let allPositive = Test.allElements(Test.beGreaterThan(0))
+
+		        return allPositive.test(1)
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &cdcErrors.DefaultUserError{})
+		assert.ErrorContains(t, err, "expected Array or Dictionary argument")
+	})
+}
+
+func TestTestEmitMatchers(t *testing.T) {
+
+	t.Parallel()
+
+	const declarations = `
+        import Test
+
+        pub event Deposited(amount: Int)
+
+        pub event Withdrawn(amount: Int)
+
+        pub event Fee(amount: Int)
+
+        pub fun newResult(_ events: [String]): Test.TransactionResult {
+            Test.addTransaction(
+                Test.Transaction(
+                    code: "transaction {}",
+                    authorizers: [],
+                    signers: [],
+                    arguments: events
+                )
+            )
+            return Test.executeNextTransaction()!
+        }
+    `
+
+	// The mocked blockchain emits the events described by the arguments of a transaction,
+	// in the form "<type>:<amount>"
+	newInterpreter := func(t *testing.T, script string) (*interpreter.Interpreter, error) {
+		var transactions []*stdlib.Transaction
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					addTransaction: func(_ *interpreter.Interpreter, tx *stdlib.Transaction) error {
+						transactions = append(transactions, tx)
+						return nil
+					},
+					executeTransaction: func() *stdlib.TransactionResult {
+						tx := transactions[0]
+						transactions = transactions[1:]
+
+						events := make([]cadence.Event, len(tx.Arguments))
+						for i, argument := range tx.Arguments {
+							qualifiedIdentifier, amount, _ := strings.Cut(
+								argument.(*interpreter.StringValue).Str,
+								":",
+							)
+							value, err := strconv.ParseInt(amount, 10, 64)
+							require.NoError(t, err)
+
+							events[i] = newTestEvent(qualifiedIdentifier, value)
+						}
+
+						return &stdlib.TransactionResult{
+							Events: events,
+						}
+					},
+				}
+			},
+		}
+
+		return newTestContractInterpreterWithTestFramework(t, script, testFramework)
+	}
+
+	t.Run("emit", func(t *testing.T) {
+		t.Parallel()
+
+		const script = declarations + `
+            pub fun testMatch() {
+                let txResult = newResult(["Fee:1", "Deposited:10"])
+                Test.expect(txResult, Test.emit(Type<Deposited>()))
+            }
+
+            pub fun testNoMatch() {
+                let txResult = newResult(["Fee:1", "Withdrawn:10"])
+                Test.expect(txResult, Test.emit(Type<Deposited>()))
+            }
+        `
+
+		inter, err := newInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testMatch")
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testNoMatch")
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &stdlib.AssertionError{})
+	})
+
+	t.Run("emit where", func(t *testing.T) {
+		t.Parallel()
+
+		const script = declarations + `
+            pub fun testMatch() {
+                let txResult = newResult(["Deposited:10", "Deposited:20"])
+                Test.expect(txResult, Test.emit(Type<Deposited>(), where: fun (event: AnyStruct): Bool {
+                    return (event as! Deposited).amount == 20
+                }))
+            }
+
+            pub fun testNoMatch() {
+                let txResult = newResult(["Deposited:10", "Withdrawn:30"])
+                Test.expect(txResult, Test.emit(Type<Deposited>(), where: fun (event: AnyStruct): Bool {
+                    return (event as! Deposited).amount > 20
+                }))
+            }
+        `
+
+		inter, err := newInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testMatch")
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testNoMatch")
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &stdlib.AssertionError{})
+	})
+
+	t.Run("emit count", func(t *testing.T) {
+		t.Parallel()
+
+		const script = declarations + `
+            pub fun testMatch() {
+                let txResult = newResult(["Deposited:10", "Fee:1", "Deposited:20"])
+                Test.expect(txResult, Test.emitCount(Type<Deposited>(), count: 2))
+                Test.expect(txResult, Test.emitCount(Type<Fee>(), count: 1))
+            }
+
+            pub fun testNoMatch() {
+                let txResult = newResult(["Deposited:10", "Fee:1", "Deposited:20"])
+                Test.expect(txResult, Test.emitCount(Type<Deposited>(), count: 1))
+            }
+        `
+
+		inter, err := newInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testMatch")
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testNoMatch")
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &stdlib.AssertionError{})
+	})
+
+	t.Run("not emit", func(t *testing.T) {
+		t.Parallel()
+
+		const script = declarations + `
+            pub fun testMatch() {
+                let txResult = newResult(["Deposited:10"])
+                Test.expect(txResult, Test.notEmit(Type<Withdrawn>()))
+            }
+
+            pub fun testNoMatch() {
+                let txResult = newResult(["Deposited:10"])
+                Test.expect(txResult, Test.notEmit(Type<Deposited>()))
+            }
+        `
+
+		inter, err := newInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testMatch")
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testNoMatch")
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &stdlib.AssertionError{})
+	})
+
+	t.Run("emit in order", func(t *testing.T) {
+		t.Parallel()
+
+		const script = declarations + `
+            pub fun testMatch() {
+                let txResult = newResult(["Withdrawn:10", "Fee:1", "Deposited:10"])
+                Test.expect(txResult, Test.emitInOrder([Type<Withdrawn>(), Type<Deposited>()]))
+                Test.expect(txResult, Test.emitInOrder([]))
+            }
+
+            pub fun testNoMatch() {
+                let txResult = newResult(["Withdrawn:10", "Fee:1", "Deposited:10"])
+                Test.expect(txResult, Test.emitInOrder([Type<Deposited>(), Type<Withdrawn>()]))
+            }
+        `
+
+		inter, err := newInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testMatch")
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testNoMatch")
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &stdlib.AssertionError{})
+	})
+
+	t.Run("events of type", func(t *testing.T) {
+		t.Parallel()
+
+		const script = declarations + `
+            pub fun test() {
+                let txResult = newResult(["Deposited:10", "Fee:1", "Deposited:20"])
+                let deposits = txResult.eventsOfType(Type<Deposited>())
+                Test.assertEqual(2, deposits.length)
+                Test.assertEqual(20, (deposits[1] as! Deposited).amount)
+            }
+        `
+
+		inter, err := newInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+	})
+
+	t.Run("not a transaction result", func(t *testing.T) {
+		t.Parallel()
+
+		const script = declarations + `
+            pub fun test() {
+                Test.expect(1, Test.emit(Type<Deposited>()))
+            }
+        `
+
+		inter, err := newInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.ErrorContains(t, err, "expected TransactionResult argument")
+	})
+}
+
+func TestTestExpect(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+           import Test
+
+           pub fun test() {
+               Test.expect("this string", Test.equal("this string"))
+           }
+        `
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+           import Test
+
+           pub fun test() {
+               Test.expect("this string", Test.equal("other string"))
+           }
+        `
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+
+		assertionErr := &stdlib.AssertionError{}
+		assert.ErrorAs(t, err, assertionErr)
+		assert.Equal(
+			t,
+			"given value is: \"this string\", expected it to be equal to \"other string\"",
+			assertionErr.Message,
+		)
+		assert.Equal(t, "test", assertionErr.LocationRange.Location.String())
+		assert.Equal(t, 5, assertionErr.LocationRange.StartPosition().Line)
+	})
+
+	t.Run("fail with combined matchers", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+           import Test
+
+           pub fun test() {
+               Test.expect([1, 2], Test.not(Test.beEmpty()).and(Test.haveElementCount(3)))
+           }
+        `
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+
+		assertionErr := &stdlib.AssertionError{}
+		assert.ErrorAs(t, err, assertionErr)
+		assert.Equal(
+			t,
+			"given value is: [1, 2], expected it to not be empty and have 3 elements",
+			assertionErr.Message,
+		)
+	})
+
+	t.Run("reference to resource", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+           import Test
+
+           pub resource Vault {
+               pub let balance: UFix64
+
+               init(balance: UFix64) {
+                   self.balance = balance
+               }
+           }
+
+           pub fun test() {
+               let vaults <- [<-create Vault(balance: 1.0), <-create Vault(balance: 2.0)]
+               let ref = &vaults as &[Vault]
+
+               Test.expect(ref, Test.haveElementCount(2))
+               Test.expect(ref, Test.allElements(Test.beOfType(Type<@Vault>())))
+               Test.expect(ref[1].balance, Test.beCloseTo(2.0, within: 0.0))
+
+               destroy vaults
+           }
+        `
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+	})
+
+	t.Run("different types", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+           import Test
+
+           pub fun test() {
+               Test.expect("string", Test.equal(1))
+           }
+        `
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+		assert.ErrorAs(t, err, &stdlib.AssertionError{})
+	})
+
+	t.Run("with explicit types", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+           import Test
+
+           pub fun test() {
+               Test.expect<String>("hello", Test.equal("hello"))
+           }
+        `
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+	})
+
+	t.Run("mismatching types", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+           import Test
+
+           pub fun test() {
+               Test.expect<Int>("string", Test.equal(1))
+           }
+        `
+
+		_, err := newTestContractInterpreter(t, script)
+
+		errs := checker.RequireCheckerErrors(t, err, 2)
+		assert.IsType(t, &sema.TypeParameterTypeMismatchError{}, errs[0])
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[1])
+	})
+
+	t.Run("resource with resource matcher", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+           import Test
+
+           pub fun test() {
+               let f1 <- create Foo()
+               let f2 <- create Foo()
+               Test.expect(<-f1, Test.equal(<-f2))
+           }
+
+           pub resource Foo {}
+        `
+
+		_, err := newTestContractInterpreter(t, script)
+
+		errs := checker.RequireCheckerErrors(t, err, 2)
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[1])
+	})
+
+	t.Run("resource with struct matcher", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+           import Test
+
+           pub fun test() {
+               let foo <- create Foo()
+               let bar = Bar()
+               Test.expect(<-foo, Test.equal(bar))
+           }
+
+           pub resource Foo {}
+           pub struct Bar {}
+        `
+
+		_, err := newTestContractInterpreter(t, script)
+
+		errs := checker.RequireCheckerErrors(t, err, 1)
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	})
+
+	t.Run("struct with resource matcher", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+           import Test
+
+           pub fun test() {
+               let foo = Foo()
+               let bar <- create Bar()
+               Test.expect(foo, Test.equal(<-bar))
+           }
+
+           pub struct Foo {}
+           pub resource Bar {}
+        `
+
+		_, err := newTestContractInterpreter(t, script)
+
+		errs := checker.RequireCheckerErrors(t, err, 1)
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	})
+}
+
+func TestTestExpectFailure(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("expect failure with no failure found", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        let foo = Foo(answer: 42)
+		        Test.expectFailure(fun(): Void {
+		            foo.correctAnswer(42)
+		        }, errorMessageSubstring: "wrong answer!")
+		    }
+
+		    pub struct Foo {
+		        priv let answer: UInt8
+
+		        init(answer: UInt8) {
+		            self.answer = answer
+		        }
+
+		        pub fun correctAnswer(_ input: UInt8): Bool {
+		            if self.answer != input {
+		                panic("wrong answer!")
+		            }
+		            return true
+		        }
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+		assert.ErrorContains(
+			t,
+			err,
+			"Expected a failure, but found none.",
+		)
+	})
+
+	t.Run("expect failure with matching error message", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        let foo = Foo(answer: 42)
+		        Test.expectFailure(fun(): Void {
+		            foo.correctAnswer(43)
+		        }, errorMessageSubstring: "wrong answer!")
+		    }
+
+		    pub struct Foo {
+		        priv let answer: UInt8
+
+		        init(answer: UInt8) {
+		            self.answer = answer
+		        }
+
+		        pub fun correctAnswer(_ input: UInt8): Bool {
+		            if self.answer != input {
+		                panic("wrong answer!")
+		            }
+		            return true
+		        }
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+	})
+
+	t.Run("expect failure with mismatching error message", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        let foo = Foo(answer: 42)
+		        Test.expectFailure(fun(): Void {
+		            foo.correctAnswer(43)
+		        }, errorMessageSubstring: "what is wrong?")
+		    }
+
+		    pub struct Foo {
+		        priv let answer: UInt8
+
+		        init(answer: UInt8) {
+		            self.answer = answer
+		        }
+
+		        pub fun correctAnswer(_ input: UInt8): Bool {
+		            if self.answer != input {
+		                panic("wrong answer!")
+		            }
+		            return true
+		        }
+		    }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.Error(t, err)
+		assert.ErrorContains(
+			t,
+			err,
+			"Expected error message to include: \"what is wrong?\".",
+		)
+	})
+
+	t.Run("expect failure with wrong function signature", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        let foo = Foo(answer: 42)
+		        Test.expectFailure(fun(answer: UInt64): Foo {
+		            foo.correctAnswer(42)
+		            return foo
+		        }, errorMessageSubstring: "wrong answer")
+		    }
+
+		    pub struct Foo {
+		        priv let answer: UInt8
+
+		        init(answer: UInt8) {
+		            self.answer = answer
+		        }
+
+		        pub fun correctAnswer(_ input: UInt8): Bool {
+		            if self.answer != input {
+		                panic("wrong answer!")
+		            }
+		            return true
+		        }
+		    }
+		`
+
+		_, err := newTestContractInterpreter(t, script)
+		errs := checker.RequireCheckerErrors(t, err, 1)
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	})
+
+	t.Run("expect failure with wrong error message type", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        let foo = Foo(answer: 42)
+		        Test.expectFailure(fun(): Void {
+		            foo.correctAnswer(42)
+		        }, errorMessageSubstring: ["wrong answer"])
+		    }
+
+		    pub struct Foo {
+		        priv let answer: UInt8
+
+		        init(answer: UInt8) {
+		            self.answer = answer
+		        }
+
+		        pub fun correctAnswer(_ input: UInt8): Bool {
+		            if self.answer != input {
+		                panic("wrong answer!")
+		            }
+		            return true
+		        }
+		    }
+		`
+
+		_, err := newTestContractInterpreter(t, script)
+		errs := checker.RequireCheckerErrors(t, err, 1)
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+	})
+}
+
+func TestTestForAll(t *testing.T) {
+
+	t.Parallel()
+
+	runProperty := func(t *testing.T, script string) error {
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		return err
+	}
+
+	requirePropertyFailure := func(t *testing.T, err error) stdlib.PropertyFailedError {
+		require.Error(t, err)
+
+		testFailedErr := &stdlib.TestFailedError{}
+		require.ErrorAs(t, err, testFailedErr)
+
+		propertyFailedErr := &stdlib.PropertyFailedError{}
+		require.ErrorAs(t, err, propertyFailedErr)
+		return *propertyFailedErr
+	}
+
+	t.Run("holds", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        Test.forAll(Test.Gen.int(min: -100, max: 100), fun (value: AnyStruct): Bool {
+		            let n = value as! Int
+		            return n >= -100 && n <= 100
+		        })
+		    }
+		`
+
+		err := runProperty(t, script)
+		require.NoError(t, err)
+	})
+
+	t.Run("shrinks int", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        Test.forAll(Test.Gen.int(min: 0, max: 1000), fun (value: AnyStruct): Bool {
+		            return (value as! Int) < 10
+		        }, seed: 42)
+		    }
+		`
+
+		failure := requirePropertyFailure(t, runProperty(t, script))
+		assert.Equal(t, uint64(42), failure.Seed)
+		assert.Equal(t, "10", failure.Counterexample)
+		assert.Nil(t, failure.Err)
+		assert.ErrorContains(
+			t,
+			failure,
+			"property does not hold after 1 run(s) with seed 42: counterexample: 10",
+		)
+	})
+
+	t.Run("shrinks towards bound", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        Test.forAll(Test.Gen.int(min: -50, max: -20), fun (value: AnyStruct): Bool {
+		            return (value as! Int) > -30
+		        }, seed: 1)
+		    }
+		`
+
+		failure := requirePropertyFailure(t, runProperty(t, script))
+		assert.Equal(t, "-30", failure.Counterexample)
+	})
+
+	t.Run("reproducible", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        Test.forAll(Test.Gen.int(min: 0, max: 1000), fun (value: AnyStruct): Bool {
+		            return (value as! Int) % 7 != 3
+		        }, seed: 7, runs: 500)
+		    }
+		`
+
+		first := requirePropertyFailure(t, runProperty(t, script))
+		second := requirePropertyFailure(t, runProperty(t, script))
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("runs", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub var runs = 0
+
+		    pub fun test() {
+		        Test.forAll(Test.Gen.int(min: 0, max: 10), fun (value: AnyStruct): Bool {
+		            runs = runs + 1
+		            return true
+		        }, seed: 1, runs: 25)
+		        Test.assertEqual(25, runs)
+
+		        runs = 0
+		        Test.forAll(Test.Gen.int(min: 0, max: 10), fun (value: AnyStruct): Bool {
+		            runs = runs + 1
+		            return true
+		        })
+		        Test.assertEqual(100, runs)
+		    }
+		`
+
+		err := runProperty(t, script)
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid runs", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        Test.forAll(Test.Gen.int(min: 0, max: 10), fun (value: AnyStruct): Bool {
+		            return true
+		        }, seed: 1, runs: 0)
+		    }
+		`
+
+		err := runProperty(t, script)
+		require.ErrorContains(t, err, "number of runs must be positive")
+	})
+
+	t.Run("assertion failure", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        Test.forAll(Test.Gen.int(min: 0, max: 1000), fun (value: AnyStruct): Bool {
+		            Test.assert((value as! Int) < 100, message: "too large")
+		            return true
+		        }, seed: 3)
+		    }
+		`
+
+		failure := requirePropertyFailure(t, runProperty(t, script))
+		assert.Equal(t, "100", failure.Counterexample)
+		require.Error(t, failure.Err)
+
+		assertionErr := &stdlib.AssertionError{}
+		require.ErrorAs(t, failure.Err, assertionErr)
+		assert.Equal(t, "too large", assertionErr.Message)
+	})
+
+	t.Run("ufix64", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        Test.forAll(Test.Gen.ufix64(), fun (value: AnyStruct): Bool {
+		            return (value as! UFix64) < 5.0
+		        }, seed: 5)
+		    }
+		`
+
+		failure := requirePropertyFailure(t, runProperty(t, script))
+		assert.Equal(t, "5.00000000", failure.Counterexample)
+	})
+
+	t.Run("string", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        Test.forAll(Test.Gen.string(), fun (value: AnyStruct): Bool {
+		            return (value as! String).length < 3
+		        }, seed: 11)
+		    }
+		`
+
+		failure := requirePropertyFailure(t, runProperty(t, script))
+		assert.Len(t, failure.Counterexample, len(`"abc"`))
+	})
+
+	t.Run("address", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        Test.forAll(Test.Gen.address(), fun (value: AnyStruct): Bool {
+		            return (value as! Address) == 0x0
+		        }, seed: 13)
+		    }
+		`
+
+		failure := requirePropertyFailure(t, runProperty(t, script))
+		assert.Equal(t, "0x0000000000000001", failure.Counterexample)
+	})
+
+	t.Run("array", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        let ints = Test.Gen.int(min: 0, max: 100)
+		        Test.forAll(Test.Gen.array(of: ints), fun (value: AnyStruct): Bool {
+		            var sum = 0
+		            for element in value as! [AnyStruct] {
+		                sum = sum + (element as! Int)
+		            }
+		            return sum < 50
+		        }, seed: 17)
+		    }
+		`
+
+		failure := requirePropertyFailure(t, runProperty(t, script))
+		assert.Equal(t, "[50]", failure.Counterexample)
+	})
+
+	t.Run("dictionary", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        let dictionaries = Test.Gen.dictionary(
+		            keys: Test.Gen.int(min: 0, max: 100),
+		            values: Test.Gen.string()
+		        )
+		        Test.forAll(dictionaries, fun (value: AnyStruct): Bool {
+		            let dictionary = value as! {HashableStruct: AnyStruct}
+		            return dictionary[0] == nil
+		        }, seed: 19, runs: 1000)
+		    }
+		`
+
+		failure := requirePropertyFailure(t, runProperty(t, script))
+		assert.Equal(t, `{0: ""}`, failure.Counterexample)
+	})
+
+	t.Run("oneOf and map", func(t *testing.T) {
+		t.Parallel()
+
+		script := `
+		    import Test
+
+		    pub fun test() {
+		        let values = Test.Gen.oneOf([
+		            Test.Gen.int(min: 0, max: 100),
+		            Test.Gen.map(Test.Gen.string(), fun (value: AnyStruct): AnyStruct {
+		                return (value as! String).length
+		            }),
+		            Test.Gen.int(min: 0, max: 100).map(fun (value: AnyStruct): AnyStruct {
+		                return (value as! Int) * 2
+		            })
+		        ])
+		        Test.forAll(values, fun (value: AnyStruct): Bool {
+		            return (value as! Int) < 20
+		        }, seed: 23)
+		    }
+		`
+
+		failure := requirePropertyFailure(t, runProperty(t, script))
+		assert.Equal(t, "20", failure.Counterexample)
+	})
+}
+
+func TestTestStubContract(t *testing.T) {
+
+	t.Parallel()
+
+	t.Run("functions", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun test() {
+                let err = Test.stubContract(
+                    name: "Oracle",
+                    stubs: [
+                        Test.Stub(
+                            function: "fun price(_ symbol: String, at height: UInt64): UFix64",
+                            returns: 1.5
+                        ),
+                        Test.Stub(
+                            function: "access(all) fun reset()",
+                            returns: nil
+                        )
+                    ]
+                )
+
+                Test.expect(err, Test.beNil())
+            }
+		`
+
+		var code string
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					mockContract: func(
+						_ *interpreter.Interpreter,
+						name string,
+						stubCode string,
+						_ []interpreter.Value,
+					) error {
+						assert.Equal(t, "Oracle", name)
+						code = stubCode
+						return nil
+					},
+				}
+			},
+		}
+
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+
+		const expected = `pub contract Oracle {
+
+    pub let stubReturns: [AnyStruct]
+
+    pub var stubCalls: {String: [[AnyStruct]]}
+
+    init(stubReturns: [AnyStruct]) {
+        self.stubReturns = stubReturns
+        self.stubCalls = {"price": [], "reset": []}
+    }
+
+    access(self) fun recordStubCall(_ function: String, _ arguments: [AnyStruct]) {
+        var calls = self.stubCalls[function] ?? []
+        calls.append(arguments)
+        self.stubCalls[function] = calls
+    }
+
+    pub fun price(_ symbol: String, at height: UInt64): UFix64 {
+        self.recordStubCall("price", [symbol, height])
+        return self.stubReturns[0] as! UFix64
+    }
+
+    access(all) fun reset() {
+        self.recordStubCall("reset", [])
+    }
+}
+`
+		assert.Equal(t, expected, code)
+	})
+
+	t.Run("invalid declaration", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun test() {
+                Test.stubContract(
+                    name: "Oracle",
+                    stubs: [Test.Stub(function: "fun a() {} fun b()", returns: nil)]
+                )
+            }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.ErrorAs(t, err, &stdlib.InvalidStubError{})
+		assert.ErrorContains(t, err, "expected a single function declaration")
+	})
+
+	t.Run("resource parameter", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun test() {
+                Test.stubContract(
+                    name: "Vault",
+                    stubs: [Test.Stub(function: "fun deposit(from: @R)", returns: nil)]
+                )
+            }
+		`
+
+		inter, err := newTestContractInterpreter(t, script)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.ErrorAs(t, err, &stdlib.InvalidStubError{})
+		assert.ErrorContains(t, err, "resource parameters are not supported")
+	})
+}
+
+func TestTestMatchSnapshot(t *testing.T) {
+
+	t.Parallel()
+
+	const script = `
+        import Test
+
+        pub struct Item {
+            pub let name: String
+            pub let amounts: {String: UFix64}
+
+            init(name: String, amounts: {String: UFix64}) {
+                self.name = name
+                self.amounts = amounts
+            }
+        }
+
+        pub fun test(name: String, amount: UFix64) {
+            let item = Item(name: name, amounts: {"b": amount, "a": 1.0, "c": 2.0})
+            Test.matchSnapshot(item, "item")
+        }
+	`
+
+	newFramework := func(files map[string]string, update bool) *mockedTestFramework {
+		return &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{}
+			},
+			readFile: func(path string) (string, error) {
+				content, ok := files[path]
+				if !ok {
+					return "", fs.ErrNotExist
+				}
+				return content, nil
+			},
+			writeFile: func(path string, content string) error {
+				files[path] = content
+				return nil
+			},
+			updateSnapshots: update,
+		}
+	}
+
+	run := func(framework *mockedTestFramework, name string, amount uint64) error {
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, framework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke(
+			"test",
+			interpreter.NewUnmeteredStringValue(name),
+			interpreter.NewUnmeteredUFix64Value(amount),
+		)
+		return err
+	}
+
+	t.Run("written on first run", func(t *testing.T) {
+		t.Parallel()
+
+		files := map[string]string{}
+
+		err := run(newFramework(files, false), "first", 300000000)
+		require.NoError(t, err)
+
+		require.Contains(t, files, "item.snapshot.json")
+
+		snapshot := files["item.snapshot.json"]
+		assert.Contains(t, snapshot, "\n  \"type\": \"Struct\"")
+
+		// Dictionary entries are sorted by key
+		a := strings.Index(snapshot, `"a"`)
+		b := strings.Index(snapshot, `"b"`)
+		c := strings.Index(snapshot, `"c"`)
+		assert.True(t, a < b && b < c)
+	})
+
+	t.Run("match", func(t *testing.T) {
+		t.Parallel()
+
+		files := map[string]string{}
+
+		err := run(newFramework(files, false), "first", 300000000)
+		require.NoError(t, err)
+
+		err = run(newFramework(files, false), "first", 300000000)
+		require.NoError(t, err)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		files := map[string]string{}
+
+		err := run(newFramework(files, false), "first", 300000000)
+		require.NoError(t, err)
+
+		snapshot := files["item.snapshot.json"]
+
+		err = run(newFramework(files, false), "second", 400000000)
+		require.ErrorAs(t, err, &stdlib.AssertionError{})
+		assert.ErrorContains(t, err, "value does not match snapshot item (snapshot != actual)")
+		assert.ErrorContains(t, err, `.name: value changed: "first" != "second"`)
+		assert.ErrorContains(t, err, `.amounts["b"]: value changed: 3.00000000 != 4.00000000`)
+
+		// A mismatching snapshot is not overwritten
+		assert.Equal(t, snapshot, files["item.snapshot.json"])
+	})
+
+	t.Run("update", func(t *testing.T) {
+		t.Parallel()
+
+		files := map[string]string{}
+
+		err := run(newFramework(files, false), "first", 300000000)
+		require.NoError(t, err)
+
+		err = run(newFramework(files, true), "second", 400000000)
+		require.NoError(t, err)
+
+		err = run(newFramework(files, false), "second", 400000000)
+		require.NoError(t, err)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		t.Parallel()
 
-		        pub fun correctAnswer(_ input: UInt8): Bool {
-		            if self.answer != input {
-		                panic("wrong answer!")
-		            }
-		            return true
-		        }
-		    }
+		const script = `
+            import Test
+
+            pub fun test() {
+                Test.matchSnapshot(1, "")
+            }
 		`
 
-		_, err := newTestContractInterpreter(t, script)
-		errs := checker.RequireCheckerErrors(t, err, 1)
-		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+		inter, err := newTestContractInterpreterWithTestFramework(
+			t,
+			script,
+			newFramework(map[string]string{}, false),
+		)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.ErrorContains(t, err, "snapshot name must not be empty")
 	})
 
-	t.Run("expect failure with wrong error message type", func(t *testing.T) {
+	t.Run("invalid names", func(t *testing.T) {
 		t.Parallel()
 
-		script := `
-		    import Test
+		const script = `
+            import Test
 
-		    pub fun test() {
-		        let foo = Foo(answer: 42)
-		        Test.expectFailure(fun(): Void {
-		            foo.correctAnswer(42)
-		        }, errorMessageSubstring: ["wrong answer"])
-		    }
+            pub fun test(name: String) {
+                Test.matchSnapshot(1, name)
+            }
+		`
 
-		    pub struct Foo {
-		        priv let answer: UInt8
+		for _, name := range []string{"../item", "..", "a/b", `a\b`, "/item"} {
+			files := map[string]string{}
 
-		        init(answer: UInt8) {
-		            self.answer = answer
-		        }
+			inter, err := newTestContractInterpreterWithTestFramework(
+				t,
+				script,
+				newFramework(files, false),
+			)
+			require.NoError(t, err)
 
-		        pub fun correctAnswer(_ input: UInt8): Bool {
-		            if self.answer != input {
-		                panic("wrong answer!")
-		            }
-		            return true
-		        }
-		    }
-		`
+			_, err = inter.Invoke("test", interpreter.NewUnmeteredStringValue(name))
+			require.ErrorContains(t, err, "invalid snapshot name")
 
-		_, err := newTestContractInterpreter(t, script)
-		errs := checker.RequireCheckerErrors(t, err, 1)
-		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+			assert.Empty(t, files)
+		}
 	})
 }
 
@@ -2151,6 +3631,154 @@ func TestBlockchain(t *testing.T) {
 		assert.True(t, eventsInvoked)
 	})
 
+	t.Run("execute next transaction, events and logs", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub event Emitted(amount: Int)
+
+            pub fun test() {
+                let result = Test.executeNextTransaction()!
+
+                Test.expect(result, Test.beSucceeded())
+                Test.assertEqual(1, result.events.length)
+                Test.assertEqual(42, (result.events[0] as! Emitted).amount)
+                Test.assertEqual(["first", "second"], result.logs)
+                Test.expect(result, Test.emit(Type<Emitted>()))
+            }
+		`
+
+		executeTransactionInvoked := false
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					executeTransaction: func() *stdlib.TransactionResult {
+						executeTransactionInvoked = true
+						return &stdlib.TransactionResult{
+							Events: []cadence.Event{
+								newTestEvent("Emitted", 42),
+							},
+							Logs: []string{"first", "second"},
+						}
+					},
+				}
+			},
+		}
+
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.True(t, executeTransactionInvoked)
+	})
+
+	t.Run("execute next transaction, computation and memory used", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun test() {
+                let result = Test.executeNextTransaction()!
+
+                Test.assertEqual(100 as UInt64, Test.computationUsed(result))
+                Test.assertEqual(2000 as UInt64, Test.memoryUsed(result))
+                Test.assert(Test.computationUsed(result) < 500, message: "too expensive")
+            }
+		`
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					executeTransaction: func() *stdlib.TransactionResult {
+						return &stdlib.TransactionResult{
+							ComputationUsed: 100,
+							MemoryUsed:      2000,
+						}
+					},
+				}
+			},
+		}
+
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+	})
+
+	t.Run("coverage", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun test() {
+                let coverage = Test.coverage()!
+
+                Test.assertEqual(2, coverage.locations)
+                Test.assertEqual(8, coverage.statements)
+                Test.assertEqual(6, coverage.hits)
+                Test.assertEqual(2, coverage.misses)
+                Test.assertEqual(75.0, coverage.percentage())
+            }
+		`
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					coverage: func() *stdlib.Coverage {
+						return &stdlib.Coverage{
+							Locations:  2,
+							Statements: 8,
+							Hits:       6,
+							Misses:     2,
+						}
+					},
+				}
+			},
+		}
+
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+	})
+
+	t.Run("coverage not collected", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun test() {
+                Test.expect(Test.coverage(), Test.beNil())
+            }
+		`
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					coverage: func() *stdlib.Coverage {
+						return nil
+					},
+				}
+			},
+		}
+
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+	})
+
 	t.Run("reset", func(t *testing.T) {
 		t.Parallel()
 
@@ -2213,28 +3841,130 @@ func TestBlockchain(t *testing.T) {
 		assert.False(t, resetInvoked)
 	})
 
-	t.Run("moveTime forward", func(t *testing.T) {
+	t.Run("moveTime forward", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun testMoveForward() {
+                // timeDelta is the representation of 35 days,
+                // in the form of seconds.
+                let timeDelta = Fix64(35 * 24 * 60 * 60)
+                Test.moveTime(by: timeDelta)
+            }
+		`
+
+		moveTimeInvoked := false
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					moveTime: func(timeDelta int64) {
+						moveTimeInvoked = true
+						assert.Equal(t, int64(3024000), timeDelta)
+					},
+				}
+			},
+		}
+
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testMoveForward")
+		require.NoError(t, err)
+
+		assert.True(t, moveTimeInvoked)
+	})
+
+	t.Run("moveTime backward", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun testMoveBackward() {
+                // timeDelta is the representation of 35 days,
+                // in the form of seconds.
+                let timeDelta = Fix64(35 * 24 * 60 * 60) * -1.0
+                Test.moveTime(by: timeDelta)
+            }
+		`
+
+		moveTimeInvoked := false
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					moveTime: func(timeDelta int64) {
+						moveTimeInvoked = true
+						assert.Equal(t, int64(-3024000), timeDelta)
+					},
+				}
+			},
+		}
+
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("testMoveBackward")
+		require.NoError(t, err)
+
+		assert.True(t, moveTimeInvoked)
+	})
+
+	t.Run("moveTime with invalid time delta", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun testMoveTime() {
+                Test.moveTime(by: 3000)
+            }
+		`
+
+		moveTimeInvoked := false
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					moveTime: func(timeDelta int64) {
+						moveTimeInvoked = true
+					},
+				}
+			},
+		}
+
+		_, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		errs := checker.RequireCheckerErrors(t, err, 1)
+		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
+		assert.False(t, moveTimeInvoked)
+	})
+
+	t.Run("setRandomSeed and queueRandomValues", func(t *testing.T) {
 		t.Parallel()
 
 		const script = `
             import Test
 
-            pub fun testMoveForward() {
-                // timeDelta is the representation of 35 days,
-                // in the form of seconds.
-                let timeDelta = Fix64(35 * 24 * 60 * 60)
-                Test.moveTime(by: timeDelta)
+            pub fun test() {
+                Test.setRandomSeed(42)
+                Test.queueRandomValues([1, 2, 3])
             }
 		`
 
-		moveTimeInvoked := false
+		var seed uint64
+		var values []uint64
 
 		testFramework := &mockedTestFramework{
 			emulatorBackend: func() stdlib.Blockchain {
 				return &mockedBlockchain{
-					moveTime: func(timeDelta int64) {
-						moveTimeInvoked = true
-						assert.Equal(t, int64(3024000), timeDelta)
+					setRandomSeed: func(s uint64) {
+						seed = s
+					},
+					queueRandomValues: func(v []uint64) {
+						values = v
 					},
 				}
 			},
@@ -2243,34 +3973,43 @@ func TestBlockchain(t *testing.T) {
 		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
 		require.NoError(t, err)
 
-		_, err = inter.Invoke("testMoveForward")
+		_, err = inter.Invoke("test")
 		require.NoError(t, err)
 
-		assert.True(t, moveTimeInvoked)
+		assert.Equal(t, uint64(42), seed)
+		assert.Equal(t, []uint64{1, 2, 3}, values)
 	})
 
-	t.Run("moveTime backward", func(t *testing.T) {
+	t.Run("setBlockHeight, setBlockID, and setBlockTimestamp", func(t *testing.T) {
 		t.Parallel()
 
 		const script = `
             import Test
 
-            pub fun testMoveBackward() {
-                // timeDelta is the representation of 35 days,
-                // in the form of seconds.
-                let timeDelta = Fix64(35 * 24 * 60 * 60) * -1.0
-                Test.moveTime(by: timeDelta)
+            pub fun test() {
+                Test.setBlockHeight(100)
+                Test.setBlockID("0102".decodeHex())
+                Test.setBlockTimestamp(1700000000.5)
             }
 		`
 
-		moveTimeInvoked := false
+		var height uint64
+		var id []byte
+		var timestamp time.Time
 
 		testFramework := &mockedTestFramework{
 			emulatorBackend: func() stdlib.Blockchain {
 				return &mockedBlockchain{
-					moveTime: func(timeDelta int64) {
-						moveTimeInvoked = true
-						assert.Equal(t, int64(-3024000), timeDelta)
+					setBlockHeight: func(h uint64) error {
+						height = h
+						return nil
+					},
+					setBlockID: func(i []byte) error {
+						id = i
+						return nil
+					},
+					setBlockTimestamp: func(value time.Time) {
+						timestamp = value
 					},
 				}
 			},
@@ -2279,39 +4018,40 @@ func TestBlockchain(t *testing.T) {
 		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
 		require.NoError(t, err)
 
-		_, err = inter.Invoke("testMoveBackward")
+		_, err = inter.Invoke("test")
 		require.NoError(t, err)
 
-		assert.True(t, moveTimeInvoked)
+		assert.Equal(t, uint64(100), height)
+		assert.Equal(t, []byte{1, 2}, id)
+		assert.Equal(t, time.Unix(1700000000, 500_000_000), timestamp)
 	})
 
-	t.Run("moveTime with invalid time delta", func(t *testing.T) {
+	t.Run("setBlockHeight failure", func(t *testing.T) {
 		t.Parallel()
 
 		const script = `
             import Test
 
-            pub fun testMoveTime() {
-                Test.moveTime(by: 3000)
+            pub fun test() {
+                Test.setBlockHeight(1)
             }
 		`
 
-		moveTimeInvoked := false
-
 		testFramework := &mockedTestFramework{
 			emulatorBackend: func() stdlib.Blockchain {
 				return &mockedBlockchain{
-					moveTime: func(timeDelta int64) {
-						moveTimeInvoked = true
+					setBlockHeight: func(height uint64) error {
+						return fmt.Errorf("invalid block height %d", height)
 					},
 				}
 			},
 		}
 
-		_, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
-		errs := checker.RequireCheckerErrors(t, err, 1)
-		assert.IsType(t, &sema.TypeMismatchError{}, errs[0])
-		assert.False(t, moveTimeInvoked)
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.ErrorContains(t, err, "panic: invalid block height 1")
 	})
 
 	t.Run("createSnapshot", func(t *testing.T) {
@@ -2564,6 +4304,120 @@ func TestBlockchain(t *testing.T) {
 		assert.True(t, deployContractInvoked)
 	})
 
+	t.Run("stubContract", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun test() {
+                let err = Test.stubContract(
+                    name: "Oracle",
+                    stubs: [
+                        Test.Stub(
+                            function: "fun price(_ symbol: String): UFix64",
+                            returns: 1.5
+                        )
+                    ]
+                )
+
+                Test.expect(err, Test.beNil())
+            }
+		`
+
+		mockContractInvoked := false
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					mockContract: func(
+						inter *interpreter.Interpreter,
+						name string,
+						code string,
+						arguments []interpreter.Value,
+					) error {
+						mockContractInvoked = true
+						assert.Equal(t, "Oracle", name)
+						assert.Contains(t, code, "pub contract Oracle {")
+						assert.Contains(t, code, "pub fun price(_ symbol: String): UFix64 {")
+						require.Equal(t, 1, len(arguments))
+
+						returns := arguments[0].(*interpreter.ArrayValue)
+						require.Equal(t, 1, returns.Count())
+						assert.Equal(
+							t,
+							interpreter.NewUnmeteredUFix64Value(150000000),
+							returns.Get(inter, interpreter.EmptyLocationRange, 0),
+						)
+
+						return nil
+					},
+				}
+			},
+		}
+
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.True(t, mockContractInvoked)
+	})
+
+	t.Run("mockContract with failure", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun test() {
+                let err = Test.mockContract(
+                    name: "FooContract",
+                    path: "./contracts/FooContract.cdc",
+                    arguments: []
+                )
+
+                Test.assertEqual(
+                    "failed to mock contract: FooContract",
+                    err!.message
+                )
+            }
+		`
+
+		mockContractInvoked := false
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					mockContract: func(
+						inter *interpreter.Interpreter,
+						name string,
+						code string,
+						arguments []interpreter.Value,
+					) error {
+						mockContractInvoked = true
+						assert.Equal(t, "pub contract FooContract {}", code)
+
+						return fmt.Errorf("failed to mock contract: %s", name)
+					},
+				}
+			},
+			readFile: func(path string) (string, error) {
+				assert.Equal(t, "./contracts/FooContract.cdc", path)
+				return "pub contract FooContract {}", nil
+			},
+		}
+
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.True(t, mockContractInvoked)
+	})
+
 	t.Run("getAccount", func(t *testing.T) {
 		t.Parallel()
 
@@ -2649,12 +4503,138 @@ func TestBlockchain(t *testing.T) {
 		assert.True(t, getAccountInvoked)
 	})
 
+	t.Run("createAccountWithKeys", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun test() {
+                let account = Test.createAccountWithKeys([
+                    Test.Key(
+                        signatureAlgorithm: SignatureAlgorithm.ECDSA_secp256k1,
+                        hashAlgorithm: HashAlgorithm.SHA2_256,
+                        weight: 500.0
+                    )
+                ])
+                Test.assertEqual(0x0000000000000009 as Address, account.address)
+            }
+		`
+
+		var keys []stdlib.Key
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					createAccountWithKeys: func(accountKeys []stdlib.Key) (*stdlib.Account, error) {
+						keys = accountKeys
+
+						return &stdlib.Account{
+							Address: common.MustBytesToAddress([]byte{0x9}),
+							PublicKey: &stdlib.PublicKey{
+								PublicKey: []byte{1, 2, 3},
+								SignAlgo:  sema.SignatureAlgorithmECDSA_secp256k1,
+							},
+						}, nil
+					},
+				}
+			},
+		}
+
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+
+		assert.Equal(t,
+			[]stdlib.Key{
+				{
+					SignAlgo: sema.SignatureAlgorithmECDSA_secp256k1,
+					HashAlgo: sema.HashAlgorithmSHA2_256,
+					Weight:   500,
+				},
+			},
+			keys,
+		)
+	})
+
+	t.Run("addTransaction with payer, proposal key, and key signatures", func(t *testing.T) {
+		t.Parallel()
+
+		const script = `
+            import Test
+
+            pub fun test() {
+                let tx = Test.Transaction(
+                    code: "transaction {}",
+                    authorizers: [0x2],
+                    signers: [],
+                    arguments: []
+                )
+                    .withPayer(0x3)
+                    .withProposalKey(Test.ProposalKey(address: 0x2, keyIndex: 1, sequenceNumber: 4))
+                    .withKeySignatures([Test.KeySignature(address: 0x2, keyIndex: 1)])
+                    .withKeySignatures([Test.KeySignature(address: 0x3, keyIndex: 0)])
+
+                Test.addTransaction(tx)
+            }
+		`
+
+		var transaction *stdlib.Transaction
+
+		testFramework := &mockedTestFramework{
+			emulatorBackend: func() stdlib.Blockchain {
+				return &mockedBlockchain{
+					addTransaction: func(_ *interpreter.Interpreter, tx *stdlib.Transaction) error {
+						transaction = tx
+						return nil
+					},
+				}
+			},
+		}
+
+		inter, err := newTestContractInterpreterWithTestFramework(t, script, testFramework)
+		require.NoError(t, err)
+
+		_, err = inter.Invoke("test")
+		require.NoError(t, err)
+
+		payer := common.MustBytesToAddress([]byte{0x3})
+
+		require.NotNil(t, transaction)
+		assert.Equal(t, &payer, transaction.Payer)
+		assert.Equal(t,
+			&stdlib.ProposalKey{
+				Address:        common.MustBytesToAddress([]byte{0x2}),
+				KeyIndex:       1,
+				SequenceNumber: 4,
+			},
+			transaction.ProposalKey,
+		)
+		assert.Equal(t,
+			[]stdlib.KeySignature{
+				{
+					Address:  common.MustBytesToAddress([]byte{0x2}),
+					KeyIndex: 1,
+				},
+				{
+					Address:  payer,
+					KeyIndex: 0,
+				},
+			},
+			transaction.KeySignatures,
+		)
+	})
+
 	// TODO: Add more tests for the remaining functions.
 }
 
 type mockedTestFramework struct {
 	emulatorBackend func() stdlib.Blockchain
 	readFile        func(s string) (string, error)
+	writeFile       func(path string, content string) error
+	updateSnapshots bool
 }
 
 var _ stdlib.TestFramework = &mockedTestFramework{}
@@ -2675,25 +4655,71 @@ func (m mockedTestFramework) ReadFile(fileName string) (string, error) {
 	return m.readFile(fileName)
 }
 
+func (m mockedTestFramework) WriteFile(path string, content string) error {
+	if m.writeFile == nil {
+		panic("'WriteFile' is not implemented")
+	}
+
+	return m.writeFile(path, content)
+}
+
+func (m mockedTestFramework) UpdateSnapshots() bool {
+	return m.updateSnapshots
+}
+
+func (m mockedTestFramework) ExportValue(
+	inter *interpreter.Interpreter,
+	value interpreter.Value,
+) (cadence.Value, error) {
+	return runtime.ExportValue(value, inter, interpreter.EmptyLocationRange)
+}
+
+// newTestEvent returns an event with the given amount,
+// of the event type with the given name, declared in the test location.
+func newTestEvent(qualifiedIdentifier string, amount int64) cadence.Event {
+	return cadence.NewEvent([]cadence.Value{
+		cadence.NewInt(int(amount)),
+	}).WithType(&cadence.EventType{
+		Location:            utils.TestLocation,
+		QualifiedIdentifier: qualifiedIdentifier,
+		Fields: []cadence.Field{
+			{
+				Identifier: "amount",
+				Type:       cadence.IntType{},
+			},
+		},
+	})
+}
+
 type mockedBlockchain struct {
-	runScript          func(inter *interpreter.Interpreter, code string, arguments []interpreter.Value)
-	createAccount      func() (*stdlib.Account, error)
-	getAccount         func(interpreter.AddressValue) (*stdlib.Account, error)
-	addTransaction     func(inter *interpreter.Interpreter, code string, authorizers []common.Address, signers []*stdlib.Account, arguments []interpreter.Value) error
-	executeTransaction func() *stdlib.TransactionResult
-	commitBlock        func() error
-	deployContract     func(inter *interpreter.Interpreter, name string, path string, arguments []interpreter.Value) error
-	stdlibHandler      func() stdlib.StandardLibraryHandler
-	logs               func() []string
-	serviceAccount     func() (*stdlib.Account, error)
-	events             func(inter *interpreter.Interpreter, eventType interpreter.StaticType) interpreter.Value
-	reset              func(uint64)
-	moveTime           func(int64)
-	createSnapshot     func(string) error
-	loadSnapshot       func(string) error
+	runScript             func(inter *interpreter.Interpreter, code string, arguments []interpreter.Value)
+	createAccount         func() (*stdlib.Account, error)
+	createAccountWithKeys func(keys []stdlib.Key) (*stdlib.Account, error)
+	getAccount            func(interpreter.AddressValue) (*stdlib.Account, error)
+	sequenceNumber        func(address common.Address, keyIndex int) (uint64, error)
+	addTransaction        func(inter *interpreter.Interpreter, transaction *stdlib.Transaction) error
+	executeTransaction    func() *stdlib.TransactionResult
+	commitBlock           func() error
+	deployContract        func(inter *interpreter.Interpreter, name string, path string, arguments []interpreter.Value) error
+	mockContract          func(inter *interpreter.Interpreter, name string, code string, arguments []interpreter.Value) error
+	stdlibHandler         func() stdlib.StandardLibraryHandler
+	logs                  func() []string
+	coverage              func() *stdlib.Coverage
+	serviceAccount        func() (*stdlib.Account, error)
+	events                func(inter *interpreter.Interpreter, eventType interpreter.StaticType) interpreter.Value
+	reset                 func(uint64)
+	moveTime              func(int64)
+	createSnapshot        func(string) error
+	loadSnapshot          func(string) error
+	setRandomSeed         func(uint64)
+	queueRandomValues     func([]uint64)
+	setBlockHeight        func(uint64) error
+	setBlockID            func([]byte) error
+	setBlockTimestamp     func(time.Time)
 }
 
 var _ stdlib.Blockchain = &mockedBlockchain{}
+var _ stdlib.EventImporter = &mockedBlockchain{}
 
 func (m mockedBlockchain) RunScript(
 	inter *interpreter.Interpreter,
@@ -2715,6 +4741,14 @@ func (m mockedBlockchain) CreateAccount() (*stdlib.Account, error) {
 	return m.createAccount()
 }
 
+func (m mockedBlockchain) CreateAccountWithKeys(keys []stdlib.Key) (*stdlib.Account, error) {
+	if m.createAccountWithKeys == nil {
+		panic("'CreateAccountWithKeys' is not implemented")
+	}
+
+	return m.createAccountWithKeys(keys)
+}
+
 func (m mockedBlockchain) GetAccount(address interpreter.AddressValue) (*stdlib.Account, error) {
 	if m.getAccount == nil {
 		panic("'getAccount' is not implemented")
@@ -2723,18 +4757,23 @@ func (m mockedBlockchain) GetAccount(address interpreter.AddressValue) (*stdlib.
 	return m.getAccount(address)
 }
 
+func (m mockedBlockchain) SequenceNumber(address common.Address, keyIndex int) (uint64, error) {
+	if m.sequenceNumber == nil {
+		panic("'SequenceNumber' is not implemented")
+	}
+
+	return m.sequenceNumber(address, keyIndex)
+}
+
 func (m mockedBlockchain) AddTransaction(
 	inter *interpreter.Interpreter,
-	code string,
-	authorizers []common.Address,
-	signers []*stdlib.Account,
-	arguments []interpreter.Value,
+	transaction *stdlib.Transaction,
 ) error {
 	if m.addTransaction == nil {
 		panic("'AddTransaction' is not implemented")
 	}
 
-	return m.addTransaction(inter, code, authorizers, signers, arguments)
+	return m.addTransaction(inter, transaction)
 }
 
 func (m mockedBlockchain) ExecuteNextTransaction() *stdlib.TransactionResult {
@@ -2745,6 +4784,19 @@ func (m mockedBlockchain) ExecuteNextTransaction() *stdlib.TransactionResult {
 	return m.executeTransaction()
 }
 
+func (m mockedBlockchain) ImportEvent(
+	inter *interpreter.Interpreter,
+	event cadence.Event,
+) (interpreter.Value, error) {
+	return runtime.ImportValue(
+		inter,
+		interpreter.EmptyLocationRange,
+		nil,
+		event,
+		nil,
+	)
+}
+
 func (m mockedBlockchain) CommitBlock() error {
 	if m.commitBlock == nil {
 		panic("'CommitBlock' is not implemented")
@@ -2766,6 +4818,19 @@ func (m mockedBlockchain) DeployContract(
 	return m.deployContract(inter, name, path, arguments)
 }
 
+func (m mockedBlockchain) MockContract(
+	inter *interpreter.Interpreter,
+	name string,
+	code string,
+	arguments []interpreter.Value,
+) error {
+	if m.mockContract == nil {
+		panic("'MockContract' is not implemented")
+	}
+
+	return m.mockContract(inter, name, code, arguments)
+}
+
 func (m mockedBlockchain) StandardLibraryHandler() stdlib.StandardLibraryHandler {
 	if m.stdlibHandler == nil {
 		panic("'StandardLibraryHandler' is not implemented")
@@ -2782,6 +4847,14 @@ func (m mockedBlockchain) Logs() []string {
 	return m.logs()
 }
 
+func (m mockedBlockchain) Coverage() *stdlib.Coverage {
+	if m.coverage == nil {
+		panic("'Coverage' is not implemented")
+	}
+
+	return m.coverage()
+}
+
 func (m mockedBlockchain) ServiceAccount() (*stdlib.Account, error) {
 	if m.serviceAccount == nil {
 		panic("'ServiceAccount' is not implemented")
@@ -2832,3 +4905,43 @@ func (m mockedBlockchain) LoadSnapshot(name string) error {
 
 	return m.loadSnapshot(name)
 }
+
+func (m mockedBlockchain) SetRandomSeed(seed uint64) {
+	if m.setRandomSeed == nil {
+		panic("'SetRandomSeed' is not implemented")
+	}
+
+	m.setRandomSeed(seed)
+}
+
+func (m mockedBlockchain) QueueRandomValues(values []uint64) {
+	if m.queueRandomValues == nil {
+		panic("'QueueRandomValues' is not implemented")
+	}
+
+	m.queueRandomValues(values)
+}
+
+func (m mockedBlockchain) SetBlockHeight(height uint64) error {
+	if m.setBlockHeight == nil {
+		panic("'SetBlockHeight' is not implemented")
+	}
+
+	return m.setBlockHeight(height)
+}
+
+func (m mockedBlockchain) SetBlockID(id []byte) error {
+	if m.setBlockID == nil {
+		panic("'SetBlockID' is not implemented")
+	}
+
+	return m.setBlockID(id)
+}
+
+func (m mockedBlockchain) SetBlockTimestamp(timestamp time.Time) {
+	if m.setBlockTimestamp == nil {
+		panic("'SetBlockTimestamp' is not implemented")
+	}
+
+	m.setBlockTimestamp(timestamp)
+}
@@ -38,8 +38,16 @@ type Storage struct {
 	newStorageMaps  *orderedmap.OrderedMap[interpreter.StorageKey, atree.StorageIndex]
 	storageMaps     map[interpreter.StorageKey]*interpreter.StorageMap
 	contractUpdates *orderedmap.OrderedMap[interpreter.StorageKey, *interpreter.CompositeValue]
-	Ledger          atree.Ledger
-	memoryGauge     common.MemoryGauge
+	// storageUsages caches the storage usage of stored values.
+	// The cache is invalidated when any slab is stored or removed.
+	storageUsages map[storageUsageKey]*interpreter.StorageUsage
+	Ledger        atree.Ledger
+	memoryGauge   common.MemoryGauge
+}
+
+type storageUsageKey struct {
+	interpreter.StorageKey
+	identifier string
 }
 
 var _ atree.SlabStorage = &Storage{}
@@ -226,6 +234,47 @@ func (s *Storage) writeContractUpdate(
 	}
 }
 
+func (s *Storage) Store(id atree.StorageID, slab atree.Slab) error {
+	s.storageUsages = nil
+	return s.PersistentSlabStorage.Store(id, slab)
+}
+
+func (s *Storage) Remove(id atree.StorageID) error {
+	s.storageUsages = nil
+	return s.PersistentSlabStorage.Remove(id)
+}
+
+// StorageUsage returns the amount of storage used by the value
+// stored under the given identifier in the given domain of the given account,
+// or nil if no value is stored.
+//
+// The result is cached until storage is modified.
+func (s *Storage) StorageUsage(
+	inter *interpreter.Interpreter,
+	address common.Address,
+	domain string,
+	identifier string,
+) *interpreter.StorageUsage {
+	key := storageUsageKey{
+		StorageKey: interpreter.NewStorageKey(s.memoryGauge, address, domain),
+		identifier: identifier,
+	}
+
+	usage, ok := s.storageUsages[key]
+	if ok {
+		return usage
+	}
+
+	usage = inter.StorageUsage(address, domain, identifier)
+
+	if s.storageUsages == nil {
+		s.storageUsages = map[storageUsageKey]*interpreter.StorageUsage{}
+	}
+	s.storageUsages[key] = usage
+
+	return usage
+}
+
 // Commit serializes/saves all values in the readCache in storage (through the runtime interface).
 func (s *Storage) Commit(inter *interpreter.Interpreter, commitContractUpdates bool) error {
 
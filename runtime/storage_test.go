@@ -2023,6 +2023,364 @@ func TestRuntimeStorageUsed(t *testing.T) {
 
 }
 
+func TestRuntimeStorageUsage(t *testing.T) {
+
+	t.Parallel()
+
+	runtime := newTestInterpreterRuntime()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+
+	accountCodes := map[common.Location][]byte{}
+	ledger := newTestLedger(nil, nil)
+
+	var logs []string
+
+	runtimeInterface := &testRuntimeInterface{
+		storage: ledger,
+		getSigningAccounts: func() ([]Address, error) {
+			return []Address{address}, nil
+		},
+		resolveLocation: singleIdentifierLocationResolver(t),
+		updateAccountContractCode: func(location common.AddressLocation, code []byte) error {
+			accountCodes[location] = code
+			return nil
+		},
+		getAccountContractCode: func(location common.AddressLocation) (code []byte, err error) {
+			return accountCodes[location], nil
+		},
+		emitEvent: func(event cadence.Event) error {
+			return nil
+		},
+		log: func(message string) {
+			logs = append(logs, message)
+		},
+	}
+
+	nextTransactionLocation := newTransactionLocationGenerator()
+
+	deployTx := DeploymentTransaction("Test", []byte(`
+      pub contract Test {
+
+          pub struct Inner {
+              pub let values: [Int]
+
+              init() {
+                  self.values = []
+                  var i = 0
+                  while i < 1000 {
+                      self.values.append(i)
+                      i = i + 1
+                  }
+              }
+
+              pub fun add(_ value: Int) {
+                  self.values.append(value)
+              }
+          }
+
+          pub struct Outer {
+              pub let name: String
+              pub let inner: Inner
+
+              init() {
+                  self.name = "outer"
+                  self.inner = Inner()
+              }
+          }
+      }
+    `))
+
+	err := runtime.ExecuteTransaction(
+		Script{
+			Source: deployTx,
+		},
+		Context{
+			Interface: runtimeInterface,
+			Location:  nextTransactionLocation(),
+		},
+	)
+	require.NoError(t, err)
+
+	err = runtime.ExecuteTransaction(
+		Script{
+			Source: []byte(`
+              import Test from 0x1
+
+              transaction {
+                  prepare(signer: AuthAccount) {
+                      assert(signer.storageUsage(at: /storage/outer) == nil)
+
+                      signer.save(Test.Outer(), to: /storage/outer)
+                      signer.save(1, to: /storage/number)
+
+                      let usage = signer.storageUsage(at: /storage/outer)!
+                      assert(usage.length == 1004)
+                      assert(usage["/storage/outer.inner.values[0]"]! > 0)
+                      assert(usage["/storage/outer.inner.values[999]"]! > 0)
+                      assert(usage["/storage/outer.inner.values[1000]"] == nil)
+
+                      let total = usage["/storage/outer"]!
+                      let inner = usage["/storage/outer.inner"]!
+                      let values = usage["/storage/outer.inner.values"]!
+                      let name = usage["/storage/outer.name"]!
+
+                      assert(total > inner + name)
+                      assert(inner > values)
+                      assert(values > 1000)
+
+                      // Cached result is invalidated when storage is modified
+
+                      assert(signer.storageUsage(at: /storage/outer)! == usage)
+
+                      signer.borrow<&Test.Outer>(from: /storage/outer)!.inner.add(1000)
+
+                      let newUsage = signer.storageUsage(at: /storage/outer)!
+                      assert(newUsage["/storage/outer.inner.values"]! > values)
+                      assert(newUsage["/storage/outer"]! > total)
+
+                      log(signer.storageUsage(at: /storage/number))
+                  }
+              }
+            `),
+		},
+		Context{
+			Interface: runtimeInterface,
+			Location:  nextTransactionLocation(),
+		},
+	)
+	require.NoError(t, err)
+
+	require.Len(t, logs, 1)
+	require.Regexp(t, `^\{"/storage/number": \d+\}$`, logs[0])
+
+	usages, err := runtime.StorageUsage(
+		address,
+		Context{
+			Interface: runtimeInterface,
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, usages, 2)
+
+	usagesByName := map[string]*interpreter.StorageUsage{}
+	for _, usage := range usages {
+		usagesByName[usage.Name] = usage
+	}
+
+	outerUsage := usagesByName["outer"]
+	require.NotNil(t, outerUsage)
+	require.Equal(t,
+		common.TypeID("A.0000000000000001.Test.Outer"),
+		outerUsage.Type.ID(),
+	)
+	require.Len(t, outerUsage.Members, 2)
+
+	var memberSize uint64
+	for _, member := range outerUsage.Members {
+		memberSize += member.Size
+	}
+	require.Greater(t, outerUsage.Size, memberSize)
+
+	numberUsage := usagesByName["number"]
+	require.NotNil(t, numberUsage)
+	require.Equal(t, interpreter.PrimitiveStaticTypeInt, numberUsage.Type)
+	require.Empty(t, numberUsage.Members)
+}
+
+func TestRuntimeStorageUsageMetering(t *testing.T) {
+
+	t.Parallel()
+
+	runtime := newTestInterpreterRuntime()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+
+	var loops uint
+
+	runtimeInterface := &testRuntimeInterface{
+		storage: newTestLedger(nil, nil),
+		getSigningAccounts: func() ([]Address, error) {
+			return []Address{address}, nil
+		},
+		meterComputation: func(kind common.ComputationKind, intensity uint) error {
+			if kind == common.ComputationKindLoop {
+				loops += intensity
+			}
+			return nil
+		},
+	}
+
+	nextTransactionLocation := newTransactionLocationGenerator()
+
+	err := runtime.ExecuteTransaction(
+		Script{
+			Source: []byte(`
+              transaction {
+                  prepare(signer: AuthAccount) {
+                      let values: [Int] = []
+                      var i = 0
+                      while i < 1000 {
+                          values.append(i)
+                          i = i + 1
+                      }
+                      signer.save(values, to: /storage/values)
+                  }
+              }
+            `),
+		},
+		Context{
+			Interface: runtimeInterface,
+			Location:  nextTransactionLocation(),
+		},
+	)
+	require.NoError(t, err)
+
+	const entries = 1001
+
+	// The elements of the array are stored in a few slabs,
+	// but each entry of the result is metered, also when the result is cached
+
+	for _, calls := range []uint{1, 2} {
+
+		loops = 0
+
+		err = runtime.ExecuteTransaction(
+			Script{
+				Source: []byte(fmt.Sprintf(`
+                  transaction {
+                      prepare(signer: AuthAccount) {
+                          var i = 0
+                          while i < %d {
+                              assert(signer.storageUsage(at: /storage/values)!.length == %d)
+                              i = i + 1
+                          }
+                      }
+                  }
+                `, calls, entries)),
+			},
+			Context{
+				Interface: runtimeInterface,
+				Location:  nextTransactionLocation(),
+			},
+		)
+		require.NoError(t, err)
+
+		assert.GreaterOrEqual(t, loops, calls*entries)
+	}
+}
+
+func TestRuntimeStorageUsageCollections(t *testing.T) {
+
+	t.Parallel()
+
+	runtime := newTestInterpreterRuntime()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+
+	runtimeInterface := &testRuntimeInterface{
+		storage: newTestLedger(nil, nil),
+		getSigningAccounts: func() ([]Address, error) {
+			return []Address{address}, nil
+		},
+	}
+
+	err := runtime.ExecuteTransaction(
+		Script{
+			Source: []byte(`
+              transaction {
+                  prepare(signer: AuthAccount) {
+                      signer.save(["a", "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"], to: /storage/array)
+                      signer.save({"short": [1], "long": [1, 2, 3, 4, 5, 6, 7, 8]}, to: /storage/dictionary)
+
+                      let usage = signer.storageUsage(at: /storage/dictionary)!
+                      assert(usage.length == 12)
+                      assert(usage["/storage/dictionary[\"long\"]"]! > usage["/storage/dictionary[\"short\"]"]!)
+                      assert(usage["/storage/dictionary[\"long\"][7]"]! > 0)
+                  }
+              }
+            `),
+		},
+		Context{
+			Interface: runtimeInterface,
+			Location:  common.TransactionLocation{},
+		},
+	)
+	require.NoError(t, err)
+
+	usages, err := runtime.StorageUsage(
+		address,
+		Context{
+			Interface: runtimeInterface,
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, usages, 2)
+
+	usagesByName := map[string]*interpreter.StorageUsage{}
+	for _, usage := range usages {
+		usagesByName[usage.Name] = usage
+	}
+
+	t.Run("array", func(t *testing.T) {
+
+		t.Parallel()
+
+		arrayUsage := usagesByName["array"]
+		require.NotNil(t, arrayUsage)
+		require.Len(t, arrayUsage.Members, 2)
+
+		shortUsage := arrayUsage.Members[0]
+		longUsage := arrayUsage.Members[1]
+
+		assert.Equal(t, "[0]", shortUsage.Name)
+		assert.Equal(t, interpreter.PrimitiveStaticTypeString, shortUsage.Type)
+		assert.Equal(t, "[1]", longUsage.Name)
+
+		// The elements differ in the length of the string (43 bytes),
+		// and in the length of the encoded string's header (1 byte)
+		assert.Equal(t, uint64(44), longUsage.Size-shortUsage.Size)
+
+		assert.Greater(t, arrayUsage.Size, shortUsage.Size+longUsage.Size)
+	})
+
+	t.Run("dictionary", func(t *testing.T) {
+
+		t.Parallel()
+
+		dictionaryUsage := usagesByName["dictionary"]
+		require.NotNil(t, dictionaryUsage)
+		require.Len(t, dictionaryUsage.Members, 2)
+
+		membersByName := map[string]*interpreter.StorageUsage{}
+		for _, member := range dictionaryUsage.Members {
+			membersByName[member.Name] = member
+		}
+
+		shortUsage := membersByName[`["short"]`]
+		require.NotNil(t, shortUsage)
+		require.Len(t, shortUsage.Members, 1)
+
+		longUsage := membersByName[`["long"]`]
+		require.NotNil(t, longUsage)
+		require.Len(t, longUsage.Members, 8)
+
+		var elementsSize uint64
+		for _, element := range longUsage.Members {
+			assert.Equal(t, shortUsage.Members[0].Size, element.Size)
+			elementsSize += element.Size
+		}
+		assert.Greater(t, longUsage.Size, elementsSize)
+		assert.Greater(t, longUsage.Size, shortUsage.Size)
+
+		var paths []string
+		dictionaryUsage.ForEach("/storage/dictionary", func(path string, _ *interpreter.StorageUsage) {
+			paths = append(paths, path)
+		})
+		assert.Contains(t, paths, `/storage/dictionary["long"][7]`)
+	})
+}
+
 func TestSortContractUpdates(t *testing.T) {
 
 	t.Parallel()
@@ -4192,3 +4550,409 @@ func TestRuntimeStorageIteration(t *testing.T) {
 		})
 	})
 }
+
+func TestRuntimeStorageIterationPagination(t *testing.T) {
+
+	t.Parallel()
+
+	address := common.MustBytesToAddress([]byte{0x1})
+
+	newRuntimeInterface := func(
+		ledger testLedger,
+		accountCodes map[common.Location][]byte,
+		contractIsBroken *bool,
+		logs *[]string,
+	) Interface {
+		return &testRuntimeInterface{
+			storage: ledger,
+			getSigningAccounts: func() ([]Address, error) {
+				return []Address{address}, nil
+			},
+			resolveLocation: singleIdentifierLocationResolver(t),
+			updateAccountContractCode: func(location common.AddressLocation, code []byte) error {
+				accountCodes[location] = code
+				return nil
+			},
+			getAccountContractCode: func(location common.AddressLocation) (code []byte, err error) {
+				if *contractIsBroken {
+					// Contract has a syntax problem
+					return []byte(`BROKEN`), nil
+				}
+
+				return accountCodes[location], nil
+			},
+			emitEvent: func(event cadence.Event) error {
+				return nil
+			},
+			log: func(message string) {
+				*logs = append(*logs, message)
+			},
+		}
+	}
+
+	const setupTx = `
+      import Test from 0x1
+
+      transaction {
+          prepare(signer: AuthAccount) {
+              signer.save("one", to: /storage/a)
+              signer.save(2, to: /storage/b)
+              signer.save(Test.Foo(), to: /storage/c)
+              signer.save("four", to: /storage/d)
+              signer.save(5, to: /storage/e)
+              signer.save(Test.Foo(), to: /storage/f)
+              signer.save("seven", to: /storage/g)
+          }
+      }
+    `
+
+	setup := func(t *testing.T) (Runtime, func() Interface, func() common.TransactionLocation, *bool, *[]string) {
+		runtime := newTestInterpreterRuntime()
+		ledger := newTestLedger(nil, nil)
+		accountCodes := map[common.Location][]byte{}
+		contractIsBroken := false
+		var logs []string
+
+		newRuntimeInterface := func() Interface {
+			return newRuntimeInterface(ledger, accountCodes, &contractIsBroken, &logs)
+		}
+		nextTransactionLocation := newTransactionLocationGenerator()
+
+		deployTx := DeploymentTransaction("Test", []byte(`
+          pub contract Test {
+              pub struct Foo {}
+          }
+        `))
+
+		for _, tx := range [][]byte{deployTx, []byte(setupTx)} {
+			err := runtime.ExecuteTransaction(
+				Script{
+					Source: tx,
+				},
+				Context{
+					Interface: newRuntimeInterface(),
+					Location:  nextTransactionLocation(),
+				},
+			)
+			require.NoError(t, err)
+		}
+
+		return runtime, newRuntimeInterface, nextTransactionLocation, &contractIsBroken, &logs
+	}
+
+	t.Run("pages", func(t *testing.T) {
+
+		t.Parallel()
+
+		runtime, newRuntimeInterface, nextTransactionLocation, _, _ := setup(t)
+
+		err := runtime.ExecuteTransaction(
+			Script{
+				Source: []byte(`
+                  transaction {
+                      prepare(account: AuthAccount) {
+                          assert(account.storedCount == 7)
+
+                          let all = account.storagePaths
+                          var paths: [StoragePath] = []
+
+                          var page = account.getStoragePaths(after: nil, limit: 3)
+                          while page.length > 0 {
+                              assert(page.length <= 3)
+                              paths.appendAll(page)
+                              page = account.getStoragePaths(after: page[page.length - 1], limit: 3)
+                          }
+
+                          // Order is the same as the order of storagePaths
+                          assert(paths == all)
+
+                          assert(account.getStoragePaths(after: nil, limit: 0).length == 0)
+                          assert(account.getStoragePaths(after: nil, limit: 100) == all)
+                      }
+                  }
+                `),
+			},
+			Context{
+				Interface: newRuntimeInterface(),
+				Location:  nextTransactionLocation(),
+			},
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("type filter", func(t *testing.T) {
+
+		t.Parallel()
+
+		runtime, newRuntimeInterface, nextTransactionLocation, _, logs := setup(t)
+
+		err := runtime.ExecuteTransaction(
+			Script{
+				Source: []byte(`
+                  import Test from 0x1
+
+                  transaction {
+                      prepare(account: AuthAccount) {
+                          var strings: [StoragePath] = []
+
+                          var page = account.getStoragePathsOfType<String>(after: nil, limit: 2)
+                          while page.length > 0 {
+                              strings.appendAll(page)
+                              page = account.getStoragePathsOfType<String>(after: page[page.length - 1], limit: 2)
+                          }
+                          assert(strings.length == 3)
+                          for path in strings {
+                              account.borrow<&String>(from: path)!
+                          }
+
+                          assert(account.getStoragePathsOfType<Test.Foo>(after: nil, limit: 10).length == 2)
+                          assert(account.getStoragePathsOfType<AnyStruct>(after: nil, limit: 10).length == 7)
+                          assert(account.getStoragePathsOfType<@AnyResource>(after: nil, limit: 10).length == 0)
+                      }
+                  }
+                `),
+			},
+			Context{
+				Interface: newRuntimeInterface(),
+				Location:  nextTransactionLocation(),
+			},
+		)
+		require.NoError(t, err)
+		require.Empty(t, *logs)
+	})
+
+	t.Run("broken type", func(t *testing.T) {
+
+		t.Parallel()
+
+		runtime, newRuntimeInterface, nextTransactionLocation, contractIsBroken, _ := setup(t)
+
+		*contractIsBroken = true
+
+		err := runtime.ExecuteTransaction(
+			Script{
+				Source: []byte(`
+                  transaction {
+                      prepare(account: AuthAccount) {
+                          // Values with broken types are skipped
+                          assert(account.getStoragePathsOfType<AnyStruct>(after: nil, limit: 10).length == 5)
+                          assert(account.getStoragePaths(after: nil, limit: 10).length == 7)
+                      }
+                  }
+                `),
+			},
+			Context{
+				Interface: newRuntimeInterface(),
+				Location:  nextTransactionLocation(),
+			},
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("removed cursor", func(t *testing.T) {
+
+		t.Parallel()
+
+		runtime, newRuntimeInterface, nextTransactionLocation, _, _ := setup(t)
+
+		err := runtime.ExecuteTransaction(
+			Script{
+				Source: []byte(`
+                  transaction {
+                      prepare(account: AuthAccount) {
+                          let all = account.storagePaths
+
+                          // The page after a removed path starts at the path which followed it
+                          account.load<AnyStruct>(from: all[2])
+                          let page = account.getStoragePaths(after: all[2], limit: 10)
+                          assert(page == all.slice(from: 3, upTo: all.length))
+
+                          // The page after a path which never stored a value
+                          // starts at the path which would follow it
+                          let remaining = account.storagePaths
+                          let paths = account.getStoragePaths(after: /storage/missing, limit: 10)
+                          assert(paths == remaining.slice(from: remaining.length - paths.length, upTo: remaining.length))
+                      }
+                  }
+                `),
+			},
+			Context{
+				Interface: newRuntimeInterface(),
+				Location:  nextTransactionLocation(),
+			},
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("negative limit", func(t *testing.T) {
+
+		t.Parallel()
+
+		runtime, newRuntimeInterface, nextTransactionLocation, _, _ := setup(t)
+
+		err := runtime.ExecuteTransaction(
+			Script{
+				Source: []byte(`
+                  transaction {
+                      prepare(account: AuthAccount) {
+                          account.getStoragePaths(after: nil, limit: -1)
+                      }
+                  }
+                `),
+			},
+			Context{
+				Interface: newRuntimeInterface(),
+				Location:  nextTransactionLocation(),
+			},
+		)
+		RequireError(t, err)
+
+		require.ErrorAs(t, err, &interpreter.InvalidStorageIterationLimitError{})
+	})
+
+	t.Run("mutation between pages", func(t *testing.T) {
+
+		t.Parallel()
+
+		runtime, newRuntimeInterface, nextTransactionLocation, _, _ := setup(t)
+
+		err := runtime.ExecuteTransaction(
+			Script{
+				Source: []byte(`
+                  transaction {
+                      prepare(account: AuthAccount) {
+                          let first = account.getStoragePaths(after: nil, limit: 3)
+
+                          // Mutating the storage invalidates the iteration of the previous page,
+                          // the next page is still found
+                          let all = account.storagePaths
+                          account.load<AnyStruct>(from: all[all.length - 1])
+
+                          var paths = first
+                          var page = account.getStoragePaths(after: first[2], limit: 3)
+                          while page.length > 0 {
+                              paths.appendAll(page)
+                              page = account.getStoragePaths(after: page[page.length - 1], limit: 3)
+                          }
+
+                          assert(paths == account.storagePaths)
+                      }
+                  }
+                `),
+			},
+			Context{
+				Interface: newRuntimeInterface(),
+				Location:  nextTransactionLocation(),
+			},
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("computation of a page is independent of the cursor", func(t *testing.T) {
+
+		t.Parallel()
+
+		// Enough paths for the storage map to consist of multiple slabs
+		const pathCount = 1000
+		const pageSize = 10
+
+		var loops uint
+		runtime := newTestInterpreterRuntime()
+		runtimeInterface := &testRuntimeInterface{
+			storage: newTestLedger(nil, nil),
+			getSigningAccounts: func() ([]Address, error) {
+				return []Address{address}, nil
+			},
+			decodeArgument: func(b []byte, t cadence.Type) (cadence.Value, error) {
+				return json.Decode(nil, b)
+			},
+			meterComputation: func(compKind common.ComputationKind, intensity uint) error {
+				if compKind == common.ComputationKindLoop {
+					loops += intensity
+				}
+				return nil
+			},
+		}
+		nextTransactionLocation := newTransactionLocationGenerator()
+		nextScriptLocation := newScriptLocationGenerator()
+
+		err := runtime.ExecuteTransaction(
+			Script{
+				Source: []byte(fmt.Sprintf(`
+                  transaction {
+                      prepare(account: AuthAccount) {
+                          var i = 0
+                          while i < %d {
+                              account.save(i, to: StoragePath(identifier: "path".concat(i.toString()))!)
+                              i = i + 1
+                          }
+                      }
+                  }
+                `, pathCount)),
+			},
+			Context{
+				Interface: runtimeInterface,
+				Location:  nextTransactionLocation(),
+			},
+		)
+		require.NoError(t, err)
+
+		allPaths, err := runtime.ExecuteScript(
+			Script{
+				Source: []byte(`
+                  pub fun main(): [StoragePath] {
+                      return getAuthAccount(0x1).storagePaths
+                  }
+                `),
+			},
+			Context{
+				Interface: runtimeInterface,
+				Location:  nextScriptLocation(),
+			},
+		)
+		require.NoError(t, err)
+
+		// Each page is requested by a separate script,
+		// so no iteration state can be shared between pages
+
+		var paths []cadence.Value
+		var after cadence.Value = cadence.NewOptional(nil)
+
+		for {
+			encodedAfter, err := json.Encode(after)
+			require.NoError(t, err)
+
+			loops = 0
+
+			page, err := runtime.ExecuteScript(
+				Script{
+					Source: []byte(fmt.Sprintf(`
+                      pub fun main(after: StoragePath?): [StoragePath] {
+                          return getAuthAccount(0x1).getStoragePaths(after: after, limit: %d)
+                      }
+                    `, pageSize)),
+					Arguments: [][]byte{encodedAfter},
+				},
+				Context{
+					Interface: runtimeInterface,
+					Location:  nextScriptLocation(),
+				},
+			)
+			require.NoError(t, err)
+
+			// The iteration of a page is metered once per path,
+			// and once for the end of the storage map
+			assert.LessOrEqual(t, loops, uint(pageSize+1))
+
+			pageValues := page.(cadence.Array).Values
+			if len(pageValues) == 0 {
+				break
+			}
+			paths = append(paths, pageValues...)
+			after = cadence.NewOptional(pageValues[len(pageValues)-1])
+		}
+
+		require.Len(t, paths, pathCount)
+		assert.Equal(t, allPaths.(cadence.Array).Values, paths)
+	})
+}
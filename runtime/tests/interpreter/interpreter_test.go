@@ -9536,6 +9536,9 @@ func newTestAuthAccountValue(gauge common.MemoryGauge, addressValue interpreter.
 		returnZeroUInt64,
 		panicFunctionValue,
 		panicFunctionValue,
+		func() interpreter.FunctionValue {
+			return panicFunctionValue
+		},
 		func() interpreter.Value {
 			return interpreter.NewAuthAccountContractsValue(
 				gauge,
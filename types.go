@@ -1111,7 +1111,9 @@ func GetFieldsMappedByName(v HasFields) map[string]Value {
 	return fieldsMap
 }
 
-// DecodeFields decodes a HasFields into a struct
+// DecodeFields decodes a HasFields into a struct.
+// Struct fields are mapped to Cadence fields by their `cadence` tag,
+// which is parsed the same way as by Marshal and Unmarshal
 func DecodeFields(hasFields HasFields, s interface{}) error {
 	v := reflect.ValueOf(s)
 	if !v.IsValid() || v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
@@ -1125,11 +1127,11 @@ func DecodeFields(hasFields HasFields, s interface{}) error {
 
 	for i := 0; i < v.NumField(); i++ {
 		structField := t.Field(i)
-		tag := structField.Tag
 		fieldValue := v.Field(i)
 
-		cadenceFieldNameTag := tag.Get("cadence")
-		if cadenceFieldNameTag == "" {
+		// The type override of the tag is only used by Marshal
+		cadenceFieldNameTag, _, ok := parseStructFieldTag(structField)
+		if !ok {
 			continue
 		}
 